@@ -0,0 +1,65 @@
+package address
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrInvalid is returned for strings that are not 0x followed by 40 hex
+// characters
+var ErrInvalid = errors.New("invalid address format")
+
+var pattern = regexp.MustCompile(`^0[xX][0-9a-fA-F]{40}$`)
+
+// Address is a wallet address in its EIP-55 checksummed form, as stored in the
+// user table and signed into access tokens. Get one from Parse or FromCommon
+// rather than by conversion, so that the same wallet always has the same
+// Address whatever case the client sent.
+type Address string
+
+// Parse accepts an address in any case, with or without surrounding spaces,
+// and returns its checksummed form
+func Parse(s string) (Address, error) {
+	s = strings.TrimSpace(s)
+	if !pattern.MatchString(s) {
+		return "", ErrInvalid
+	}
+	return Address(common.HexToAddress(s).Hex()), nil
+}
+
+// Valid reports whether s can be parsed as an address
+func Valid(s string) bool {
+	return pattern.MatchString(strings.TrimSpace(s))
+}
+
+// FromCommon returns the checksummed form of a go-ethereum address, such as
+// one recovered from a signature
+func FromCommon(a common.Address) Address {
+	return Address(a.Hex())
+}
+
+// Normalize returns the checksummed form of a, or a unchanged when it is not
+// a valid address
+func (a Address) Normalize() Address {
+	if parsed, err := Parse(string(a)); err == nil {
+		return parsed
+	}
+	return a
+}
+
+// Key returns a case-insensitive form of a for cache keys and comparisons
+func (a Address) Key() string {
+	return strings.ToLower(strings.TrimSpace(string(a)))
+}
+
+// Equal reports whether a and b are the same wallet, ignoring case
+func (a Address) Equal(b Address) bool {
+	return a.Key() == b.Key()
+}
+
+func (a Address) String() string {
+	return string(a)
+}
@@ -0,0 +1,85 @@
+package address
+
+import "testing"
+
+// Checksummed addresses from the EIP-55 test vectors
+const (
+	allCaps = "0x52908400098527886E0F7030069857D2E4169EE7"
+	mixed   = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    Address
+		wantErr bool
+	}{
+		{name: "checksummed", in: mixed, want: mixed},
+		{name: "lower case", in: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", want: mixed},
+		{name: "upper case", in: "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", want: mixed},
+		{name: "upper case prefix", in: "0X5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", want: mixed},
+		{name: "all caps checksum", in: "0x52908400098527886e0f7030069857d2e4169ee7", want: allCaps},
+		{name: "surrounding spaces", in: "  " + mixed + "\n", want: mixed},
+		{name: "empty", in: "", wantErr: true},
+		{name: "no prefix", in: "5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", wantErr: true},
+		{name: "too short", in: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea", wantErr: true},
+		{name: "too long", in: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed00", wantErr: true},
+		{name: "not hex", in: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaeg", wantErr: true},
+		{name: "inner space", in: "0x5aaeb6053f3e94c9b9a0 f33669435e7ef1beaed", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.wantErr {
+				if err != ErrInvalid {
+					t.Fatalf("Parse(%q) = %q, %v, want ErrInvalid", tt.in, got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("Parse(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+			}
+			if !Valid(tt.in) {
+				t.Errorf("Valid(%q) = false", tt.in)
+			}
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Address
+		want string
+	}{
+		{name: "checksummed", in: mixed, want: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"},
+		{name: "upper case", in: "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", want: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"},
+		{name: "surrounding spaces", in: " " + mixed + " ", want: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"},
+		{name: "invalid kept", in: "Not-An-Address", want: "not-an-address"},
+		{name: "empty", in: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.in.Key(); got != tt.want {
+				t.Errorf("Key() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEqualAndNormalize(t *testing.T) {
+	lower := Address("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	if !lower.Equal(mixed) {
+		t.Errorf("%s and %s are not equal", lower, mixed)
+	}
+	if lower.Equal(allCaps) {
+		t.Errorf("%s and %s are equal", lower, allCaps)
+	}
+	if got := lower.Normalize(); got != mixed {
+		t.Errorf("Normalize() = %q, want %q", got, mixed)
+	}
+	if got := Address("invalid").Normalize(); got != "invalid" {
+		t.Errorf("Normalize() of an invalid address = %q, want it unchanged", got)
+	}
+}
@@ -0,0 +1,114 @@
+package chain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"wata-bot-BE/internal/address"
+)
+
+// currencies lists the currencies held in user balances; each network maps
+// some of them to a token contract
+var currencies = map[string]bool{"wata": true, "usdt": true}
+
+// Conf describes an EVM network that users deposit from and withdraw to
+type Conf struct {
+	// Id is the EIP-155 chain id, such as 1 for Ethereum or 56 for BNB Chain
+	Id   int64
+	Name string
+	// RpcUrl is the HTTP(S) JSON-RPC endpoint that /healthz and /readyz call
+	// to check the node serves Id; the network is not checked when empty
+	RpcUrl string `json:",optional"`
+	// Confirmations is how many blocks a deposit needs before it is reported
+	// to /api/user/deposit, published to clients by /api/chains
+	Confirmations int `json:",default=12"`
+	// Tokens maps each currency available on the network to its token contract
+	Tokens map[string]string
+}
+
+// Token returns the contract of currency on the network
+func (c Conf) Token(currency string) (address.Address, bool) {
+	token, ok := c.Tokens[strings.ToLower(currency)]
+	if !ok {
+		return "", false
+	}
+	return address.Address(token), true
+}
+
+// Validate reports the first problem with the configured networks: none
+// configured, a duplicate or non-positive id, an RPC endpoint that is not an
+// http(s) URL, or a token that is not a currency or not a contract address
+func Validate(confs []Conf) error {
+	if len(confs) == 0 {
+		return fmt.Errorf("no chains configured")
+	}
+	seen := make(map[int64]bool, len(confs))
+	for _, c := range confs {
+		if c.Id <= 0 {
+			return fmt.Errorf("chain %q: id must be positive", c.Name)
+		}
+		if seen[c.Id] {
+			return fmt.Errorf("chain %d: configured twice", c.Id)
+		}
+		seen[c.Id] = true
+		if c.RpcUrl != "" {
+			if u, err := url.Parse(c.RpcUrl); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("chain %d: rpc url must be an http(s) URL", c.Id)
+			}
+		}
+		if len(c.Tokens) == 0 {
+			return fmt.Errorf("chain %d: no tokens configured", c.Id)
+		}
+		for currency, token := range c.Tokens {
+			if !currencies[strings.ToLower(currency)] {
+				return fmt.Errorf("chain %d: unknown currency %q", c.Id, currency)
+			}
+			if !address.Valid(token) {
+				return fmt.Errorf("chain %d: invalid %s token contract %q", c.Id, currency, token)
+			}
+		}
+	}
+	return nil
+}
+
+// Registry holds the supported networks. The first configured network is the
+// default for users and requests that do not name one.
+type Registry struct {
+	chains []Conf
+	byId   map[int64]Conf
+}
+
+// NewRegistry returns a registry of confs, which must have passed Validate.
+// Currencies are lowercased and token contracts checksummed.
+func NewRegistry(confs []Conf) *Registry {
+	r := &Registry{
+		byId: make(map[int64]Conf, len(confs)),
+	}
+	for _, c := range confs {
+		tokens := make(map[string]string, len(c.Tokens))
+		for currency, token := range c.Tokens {
+			tokens[strings.ToLower(currency)] = address.Address(token).Normalize().String()
+		}
+		c.Tokens = tokens
+		r.chains = append(r.chains, c)
+		r.byId[c.Id] = c
+	}
+	return r
+}
+
+// All returns every network in configuration order
+func (r *Registry) All() []Conf {
+	return r.chains
+}
+
+// Default returns the network used when none is named
+func (r *Registry) Default() Conf {
+	return r.chains[0]
+}
+
+// Find returns the network with chain id id
+func (r *Registry) Find(id int64) (Conf, bool) {
+	c, ok := r.byId[id]
+	return c, ok
+}
@@ -0,0 +1,91 @@
+package chain
+
+import (
+	"strings"
+	"testing"
+)
+
+const (
+	usdtToken = "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+	wataToken = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+)
+
+func TestValidate(t *testing.T) {
+	ethereum := Conf{Id: 1, Name: "Ethereum", RpcUrl: "https://eth.example.com", Tokens: map[string]string{"usdt": usdtToken, "WATA": wataToken}}
+	bsc := Conf{Id: 56, Name: "BNB Chain", Tokens: map[string]string{"usdt": usdtToken}}
+	with := func(c Conf, modify func(c *Conf)) Conf {
+		tokens := make(map[string]string, len(c.Tokens))
+		for k, v := range c.Tokens {
+			tokens[k] = v
+		}
+		c.Tokens = tokens
+		modify(&c)
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		confs   []Conf
+		wantErr string
+	}{
+		{name: "valid", confs: []Conf{ethereum, bsc}},
+		{name: "no rpc url", confs: []Conf{with(ethereum, func(c *Conf) { c.RpcUrl = "" })}},
+		{name: "lowercase token", confs: []Conf{with(ethereum, func(c *Conf) { c.Tokens["usdt"] = strings.ToLower(usdtToken) })}},
+		{name: "none", wantErr: "no chains configured"},
+		{name: "zero id", confs: []Conf{with(ethereum, func(c *Conf) { c.Id = 0 })}, wantErr: "id must be positive"},
+		{name: "negative id", confs: []Conf{with(ethereum, func(c *Conf) { c.Id = -1 })}, wantErr: "id must be positive"},
+		{name: "duplicate id", confs: []Conf{ethereum, with(bsc, func(c *Conf) { c.Id = 1 })}, wantErr: "chain 1: configured twice"},
+		{name: "rpc url without scheme", confs: []Conf{with(ethereum, func(c *Conf) { c.RpcUrl = "eth.example.com" })}, wantErr: "rpc url"},
+		{name: "rpc url with other scheme", confs: []Conf{with(ethereum, func(c *Conf) { c.RpcUrl = "ws://eth.example.com" })}, wantErr: "rpc url"},
+		{name: "rpc url without host", confs: []Conf{with(ethereum, func(c *Conf) { c.RpcUrl = "https://" })}, wantErr: "rpc url"},
+		{name: "no tokens", confs: []Conf{with(ethereum, func(c *Conf) { c.Tokens = nil })}, wantErr: "no tokens configured"},
+		{name: "unknown currency", confs: []Conf{with(ethereum, func(c *Conf) { c.Tokens["btc"] = usdtToken })}, wantErr: `unknown currency "btc"`},
+		{name: "invalid token", confs: []Conf{with(ethereum, func(c *Conf) { c.Tokens["usdt"] = "0x123" })}, wantErr: "invalid usdt token contract"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.confs)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry([]Conf{
+		{Id: 1, Name: "Ethereum", Tokens: map[string]string{"USDT": strings.ToLower(usdtToken)}},
+		{Id: 56, Name: "BNB Chain", Tokens: map[string]string{"wata": wataToken}},
+	})
+
+	if got := r.Default().Id; got != 1 {
+		t.Errorf("Default().Id = %d, want 1", got)
+	}
+	if got := len(r.All()); got != 2 {
+		t.Errorf("len(All()) = %d, want 2", got)
+	}
+	if _, ok := r.Find(137); ok {
+		t.Error("Find(137) found an unconfigured chain")
+	}
+
+	c, ok := r.Find(1)
+	if !ok {
+		t.Fatal("Find(1) did not find Ethereum")
+	}
+	token, ok := c.Token("Usdt")
+	if !ok {
+		t.Fatal(`Token("Usdt") not found`)
+	}
+	if token.String() != usdtToken {
+		t.Errorf("Token() = %s, want the checksummed %s", token, usdtToken)
+	}
+	if _, ok := c.Token("wata"); ok {
+		t.Error(`Token("wata") found a token not configured on Ethereum`)
+	}
+}
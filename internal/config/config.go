@@ -5,17 +5,183 @@ import (
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"wata-bot-BE/internal/chain"
+	"wata-bot-BE/internal/errorlog"
+	"wata-bot-BE/internal/event"
+	"wata-bot-BE/internal/middleware"
+	"wata-bot-BE/internal/notify"
 
 	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/redis"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
 	"github.com/zeromicro/go-zero/rest"
 )
 
 type Config struct {
 	rest.RestConf
-	Database  sqlx.SqlConf
-	Cache     cache.CacheConf `json:",optional"`
-	JWTSecret string          `json:",default=your-secret-key-change-in-production"`
+	Database     sqlx.SqlConf
+	Cache        cache.CacheConf `json:",optional"`
+	JWTSecret    string          `json:",default=your-secret-key-change-in-production"`
+	Engine       EngineConf      `json:",optional"`
+	Settlement   SettlementConf
+	Waitlist     WaitlistConf
+	Notification NotificationConf
+	Outbox       OutboxConf
+	Admin        AdminConf `json:",optional"`
+	Webhooks     WebhooksConf
+	Stream       StreamConf
+	RateLimit    RateLimitConf
+	Cors         middleware.CorsConf
+	RequestLog   middleware.RequestLogConf
+	ErrorLog     errorlog.Conf
+	Health       HealthConf
+	// Chains lists the networks users deposit from and withdraw to; the first
+	// is the default
+	Chains []chain.Conf `json:",optional"`
+}
+
+// HealthConf configures the dependency checks behind /healthz and /readyz
+type HealthConf struct {
+	// Timeout bounds each dependency check; a slower dependency is reported down
+	Timeout time.Duration `json:",default=2s"`
+}
+
+// RateLimitConf configures request rate limits per route group
+type RateLimitConf struct {
+	Enabled   bool   `json:",default=true"`
+	KeyPrefix string `json:",default=wata:ratelimit"`
+	// TrustProxy takes the client IP from X-Forwarded-For; only enable it
+	// behind reverse proxies that append to the header
+	TrustProxy bool `json:",optional"`
+	// ProxyHops is how many trusted reverse proxies append to X-Forwarded-For.
+	// The client IP is the entry that many places from the right; entries
+	// further left are set by the client.
+	ProxyHops int `json:",default=1,range=[1:10]"`
+	// Redis shares the counts between replicas; the first Cache node is used
+	// when unset, and requests are counted per replica when neither is configured
+	Redis redis.RedisConf `json:",optional"`
+	// Auth limits wallet sign-in, which creates users
+	Auth AuthRateLimitConf
+	// Balance limits deposits and withdrawals
+	Balance BalanceRateLimitConf
+	// Api applies to every route in addition to the group policies
+	Api ApiRateLimitConf
+}
+
+// The route group policies have the fields of ratelimit.PolicyConf and differ
+// only in their default quotas, which apply when a quota is not configured.
+// A quota set to 0 disables that dimension.
+type (
+	AuthRateLimitConf struct {
+		Period  time.Duration `json:",default=1m"`
+		Ip      int           `json:",default=10,range=[0:]"`
+		Address int           `json:",optional,range=[0:]"`
+	}
+
+	BalanceRateLimitConf struct {
+		Period  time.Duration `json:",default=1m"`
+		Ip      int           `json:",default=30,range=[0:]"`
+		Address int           `json:",default=10,range=[0:]"`
+	}
+
+	ApiRateLimitConf struct {
+		Period  time.Duration `json:",default=1m"`
+		Ip      int           `json:",default=600,range=[0:]"`
+		Address int           `json:",optional,range=[0:]"`
+	}
+)
+
+// StreamConf configures the real-time update stream
+type StreamConf struct {
+	// Heartbeat is how often connections receive a heartbeat event
+	Heartbeat time.Duration `json:",default=15s"`
+	// BufferSize is how many updates may wait for a connection before it is
+	// dropped as too slow
+	BufferSize int `json:",default=64"`
+	MaxTopics  int `json:",default=20"`
+	// Channel is the Redis pub/sub channel shared by all replicas
+	Channel string `json:",default=wata:stream"`
+	// Redis carries updates between replicas; the first Cache node is used when
+	// unset, and updates stay in-process when neither is configured
+	Redis redis.RedisConf `json:",optional"`
+}
+
+// AdminConf configures access to operator endpoints
+type AdminConf struct {
+	// ApiKey is sent in the X-Admin-Key header; admin endpoints are disabled when empty
+	ApiKey string `json:",optional"`
+}
+
+// WebhooksConf configures the dispatcher that sends domain events to partner
+// webhook endpoints
+type WebhooksConf struct {
+	Enabled   bool          `json:",default=true"`
+	Interval  time.Duration `json:",default=5s"`
+	BatchSize int           `json:",default=100"`
+	// A failed delivery is retried after RetryBase, doubling up to RetryMax,
+	// until MaxAttempts have been made; it can then be replayed manually
+	MaxAttempts int           `json:",default=8"`
+	RetryBase   time.Duration `json:",default=30s"`
+	RetryMax    time.Duration `json:",default=6h"`
+	// Timeout bounds one delivery request
+	Timeout time.Duration `json:",default=10s"`
+}
+
+// OutboxConf configures the dispatcher that delivers domain events from the
+// outbox to in-process handlers and external sinks
+type OutboxConf struct {
+	Enabled   bool          `json:",default=true"`
+	Interval  time.Duration `json:",default=2s"`
+	BatchSize int           `json:",default=100"`
+	// A failed dispatch is retried after RetryBase, doubling up to RetryMax,
+	// until MaxAttempts have been made; the event is then marked failed
+	MaxAttempts int           `json:",default=10"`
+	RetryBase   time.Duration `json:",default=5s"`
+	RetryMax    time.Duration `json:",default=10m"`
+	// LogEvents writes every dispatched event to the service log
+	LogEvents bool             `json:",optional"`
+	Sinks     []event.SinkConf `json:",optional"`
+}
+
+// NotificationConf configures notification delivery over external channels
+type NotificationConf struct {
+	// Enabled runs the dispatcher that sends queued deliveries
+	Enabled   bool          `json:",default=true"`
+	Interval  time.Duration `json:",default=10s"`
+	BatchSize int           `json:",default=100"`
+	// A failed delivery is retried after RetryBase, doubling up to RetryMax,
+	// until MaxAttempts have been made
+	MaxAttempts int           `json:",default=6"`
+	RetryBase   time.Duration `json:",default=30s"`
+	RetryMax    time.Duration `json:",default=1h"`
+	// Fake records deliveries in memory instead of sending them (local development)
+	Fake     bool                `json:",optional"`
+	SMTP     notify.SMTPConf     `json:",optional"`
+	Telegram notify.TelegramConf `json:",optional"`
+	Webhook  notify.WebhookConf  `json:",optional"`
+}
+
+// WaitlistConf configures bot waitlists
+type WaitlistConf struct {
+	// OfferTTL is how long freed capacity stays reserved for the offered user
+	OfferTTL time.Duration `json:",default=24h"`
+}
+
+// SettlementConf configures the background process that matures and settles subscriptions
+type SettlementConf struct {
+	Enabled  bool          `json:",default=true"`
+	Interval time.Duration `json:",default=1m"`
+}
+
+// EngineConf configures access for the trading engine that pushes bot data
+type EngineConf struct {
+	// ApiKey is sent by the engine in the X-Engine-Key header; ingestion is disabled when empty
+	ApiKey string `json:",optional"`
+	// HmacSecret signs trade ingestion requests; signed endpoints are disabled when empty
+	HmacSecret string `json:",optional"`
 }
 
 // LoadFromEnv loads configuration from environment variables
@@ -35,6 +201,72 @@ func (c *Config) LoadFromEnv() {
 		c.JWTSecret = jwtSecret
 	}
 
+	// Trading engine API key
+	if engineApiKey := os.Getenv("ENGINE_API_KEY"); engineApiKey != "" {
+		c.Engine.ApiKey = engineApiKey
+	}
+	if engineHmacSecret := os.Getenv("ENGINE_HMAC_SECRET"); engineHmacSecret != "" {
+		c.Engine.HmacSecret = engineHmacSecret
+	}
+
+	// Chain RPC endpoints, which often embed a provider API key
+	for i := range c.Chains {
+		if rpcUrl := os.Getenv(fmt.Sprintf("CHAIN_%d_RPC_URL", c.Chains[i].Id)); rpcUrl != "" {
+			c.Chains[i].RpcUrl = rpcUrl
+		}
+	}
+
+	// Admin API key
+	if adminApiKey := os.Getenv("ADMIN_API_KEY"); adminApiKey != "" {
+		c.Admin.ApiKey = adminApiKey
+	}
+
+	// CORS
+	if origins := os.Getenv("CORS_ORIGINS"); origins != "" {
+		c.Cors.Origins = splitEnvList(origins)
+	}
+	if methods := os.Getenv("CORS_METHODS"); methods != "" {
+		c.Cors.Methods = splitEnvList(methods)
+	}
+	if headers := os.Getenv("CORS_HEADERS"); headers != "" {
+		c.Cors.Headers = splitEnvList(headers)
+	}
+	if exposeHeaders := os.Getenv("CORS_EXPOSE_HEADERS"); exposeHeaders != "" {
+		c.Cors.ExposeHeaders = splitEnvList(exposeHeaders)
+	}
+	if credentials := os.Getenv("CORS_ALLOW_CREDENTIALS"); credentials != "" {
+		if allow, err := strconv.ParseBool(credentials); err == nil {
+			c.Cors.AllowCredentials = allow
+		}
+	}
+	if maxAge := os.Getenv("CORS_MAX_AGE"); maxAge != "" {
+		if d, err := time.ParseDuration(maxAge); err == nil {
+			c.Cors.MaxAge = d
+		}
+	}
+
+	// Notification channels
+	if smtpHost := os.Getenv("SMTP_HOST"); smtpHost != "" {
+		c.Notification.SMTP.Host = smtpHost
+	}
+	if smtpPort := os.Getenv("SMTP_PORT"); smtpPort != "" {
+		if p, err := strconv.Atoi(smtpPort); err == nil {
+			c.Notification.SMTP.Port = p
+		}
+	}
+	if smtpUsername := os.Getenv("SMTP_USERNAME"); smtpUsername != "" {
+		c.Notification.SMTP.Username = smtpUsername
+	}
+	if smtpPassword := os.Getenv("SMTP_PASSWORD"); smtpPassword != "" {
+		c.Notification.SMTP.Password = smtpPassword
+	}
+	if smtpFrom := os.Getenv("SMTP_FROM"); smtpFrom != "" {
+		c.Notification.SMTP.From = smtpFrom
+	}
+	if telegramBotToken := os.Getenv("TELEGRAM_BOT_TOKEN"); telegramBotToken != "" {
+		c.Notification.Telegram.BotToken = telegramBotToken
+	}
+
 	// Database configuration - only override if env vars are set
 	if os.Getenv("DB_HOST") != "" || os.Getenv("DB_USER") != "" || os.Getenv("DB_NAME") != "" {
 		dbHost := getEnvOrDefault("DB_HOST", "localhost")
@@ -51,6 +283,33 @@ func (c *Config) LoadFromEnv() {
 			dbUser, dbPassword, dbHost, dbPort, dbName, dbCharset, encodedTimezone)
 	}
 
+	// Tracing: exporting to an OTLP collector only needs its endpoint
+	if otlpEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); otlpEndpoint != "" {
+		c.Telemetry.Endpoint = otlpEndpoint
+		c.Telemetry.Batcher = getEnvOrDefault("OTEL_EXPORTER_OTLP_PROTOCOL", "otlpgrpc")
+		if c.Telemetry.Name == "" {
+			c.Telemetry.Name = c.Name
+		}
+	}
+	if sampler := os.Getenv("OTEL_TRACES_SAMPLER_ARG"); sampler != "" {
+		if ratio, err := strconv.ParseFloat(sampler, 64); err == nil {
+			c.Telemetry.Sampler = ratio
+		}
+	}
+
+	// Error reporting
+	if sentryDsn := os.Getenv("SENTRY_DSN"); sentryDsn != "" {
+		c.ErrorLog.Sentry.Dsn = sentryDsn
+	}
+	if sentryEnvironment := os.Getenv("SENTRY_ENVIRONMENT"); sentryEnvironment != "" {
+		c.ErrorLog.Sentry.Environment = sentryEnvironment
+	}
+	if keepDays := os.Getenv("ERROR_LOG_KEEP_DAYS"); keepDays != "" {
+		if days, err := strconv.Atoi(keepDays); err == nil {
+			c.ErrorLog.KeepDays = days
+		}
+	}
+
 	// Log configuration
 	if logServiceName := os.Getenv("LOG_SERVICE_NAME"); logServiceName != "" {
 		c.Log.ServiceName = logServiceName
@@ -76,6 +335,17 @@ func (c *Config) LoadFromEnv() {
 	}
 }
 
+// splitEnvList splits a comma-separated environment value, dropping empty items
+func splitEnvList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // getEnvOrDefault returns environment variable value or default value
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -83,4 +353,3 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
-
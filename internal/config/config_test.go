@@ -0,0 +1,113 @@
+package config
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"wata-bot-BE/internal/chain"
+
+	"github.com/zeromicro/go-zero/core/conf"
+)
+
+// workflowConfig returns the production config that the deploy workflow
+// writes, with its GitHub expressions evaluated as if no secret were set
+func workflowConfig(t *testing.T) []byte {
+	t.Helper()
+	b, err := os.ReadFile("../../.github/workflows/be.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, body, ok := strings.Cut(string(b), "cat > etc/wata-bot-api.prod.yaml <<EOF\n")
+	if !ok {
+		t.Fatal("be.yml does not write etc/wata-bot-api.prod.yaml")
+	}
+	body, _, _ = strings.Cut(body, "\n          EOF\n")
+
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		lines = append(lines, strings.TrimPrefix(line, "          "))
+	}
+	expr := regexp.MustCompile(`\$\{\{[^}]*\}\}`)
+	return expr.ReplaceAll([]byte(strings.Join(lines, "\n")), nil)
+}
+
+func TestConfigFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		load func(t *testing.T) []byte
+	}{
+		{name: "etc/wata-bot-api.yaml", load: readFile("../../etc/wata-bot-api.yaml")},
+		{name: "etc/wata-bot-api.dev.yaml", load: readFile("../../etc/wata-bot-api.dev.yaml")},
+		{name: "deploy workflow", load: workflowConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			if err := conf.LoadFromYamlBytes(tt.load(t), &c); err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			// The same checks as startup
+			if err := chain.Validate(c.Chains); err != nil {
+				t.Errorf("chains: %v", err)
+			}
+		})
+	}
+}
+
+func TestRateLimitDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want RateLimitConf
+	}{
+		{
+			name: "section missing",
+			want: RateLimitConf{
+				Auth:    AuthRateLimitConf{Period: time.Minute, Ip: 10},
+				Balance: BalanceRateLimitConf{Period: time.Minute, Ip: 30, Address: 10},
+				Api:     ApiRateLimitConf{Period: time.Minute, Ip: 600},
+			},
+		},
+		{
+			name: "quotas overridden and disabled",
+			yaml: "RateLimit:\n  Balance:\n    Ip: 0\n  Api:\n    Period: 10s\n    Ip: 100\n",
+			want: RateLimitConf{
+				Auth:    AuthRateLimitConf{Period: time.Minute, Ip: 10},
+				Balance: BalanceRateLimitConf{Period: time.Minute, Ip: 0, Address: 10},
+				Api:     ApiRateLimitConf{Period: 10 * time.Second, Ip: 100},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Config
+			yaml := "Name: test\nHost: 127.0.0.1\nPort: 8888\nDatabase:\n  DataSource: dsn\n" + tt.yaml
+			if err := conf.LoadFromYamlBytes([]byte(yaml), &c); err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			got := RateLimitConf{Auth: c.RateLimit.Auth, Balance: c.RateLimit.Balance, Api: c.RateLimit.Api}
+			if got != tt.want {
+				t.Errorf("RateLimit = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	var c Config
+	err := conf.LoadFromYamlBytes([]byte("Name: test\nHost: 127.0.0.1\nPort: 8888\nDatabase:\n  DataSource: dsn\nRateLimit:\n  Auth:\n    Ip: -1\n"), &c)
+	if err == nil {
+		t.Error("negative quota loaded, want an error")
+	}
+}
+
+func readFile(path string) func(t *testing.T) []byte {
+	return func(t *testing.T) []byte {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+}
@@ -0,0 +1,206 @@
+package errorlog
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/requestid"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Conf configures where error reports are written
+type Conf struct {
+	// Path is the directory of the daily error files, logs/error by default
+	Path string `json:",optional"`
+	// KeepDays is how many daily files are kept; 0 keeps them all
+	KeepDays int `json:",default=30"`
+	// Stack records the stack trace of every report
+	Stack  bool `json:",default=true"`
+	Sentry SentryConf
+}
+
+// Entry is one error report, written as a JSON line
+type Entry struct {
+	Time      time.Time              `json:"time"`
+	Message   string                 `json:"message"`
+	Error     string                 `json:"error,omitempty"`
+	ErrorCode string                 `json:"error_code,omitempty"`
+	RequestId string                 `json:"request_id,omitempty"`
+	TraceId   string                 `json:"trace_id,omitempty"`
+	Context   map[string]interface{} `json:"context,omitempty"`
+	Stack     []Frame                `json:"stack,omitempty"`
+}
+
+// Frame is one call in a stack trace, innermost first
+type Frame struct {
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Sink receives error reports. Write is called from the reporting goroutine
+// and must not block for long.
+type Sink interface {
+	Write(entry *Entry) error
+	Close() error
+}
+
+// Reporter sends every report to all of its sinks
+type Reporter struct {
+	sinks []Sink
+	stack bool
+}
+
+func NewReporter(stack bool, sinks ...Sink) *Reporter {
+	return &Reporter{
+		sinks: sinks,
+		stack: stack,
+	}
+}
+
+// New returns a reporter writing daily files under c.Path and, when a DSN is
+// configured, to Sentry
+func New(c Conf) (*Reporter, error) {
+	file, err := NewFileSink(c.Path, c.KeepDays)
+	if err != nil {
+		return nil, err
+	}
+	sinks := []Sink{file}
+	if c.Sentry.Dsn != "" {
+		sentry, err := NewSentrySink(c.Sentry)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sentry)
+	}
+	return NewReporter(c.Stack, sinks...), nil
+}
+
+// Report records err with the request id, trace id and error code found in
+// ctx and err. fields adds request details such as the method and path.
+func (r *Reporter) Report(ctx context.Context, message string, err error, fields map[string]interface{}) {
+	entry := &Entry{
+		Time:      time.Now(),
+		Message:   message,
+		RequestId: requestid.FromContext(ctx),
+		Context:   fields,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+		var apiErr *model.APIError
+		if errors.As(err, &apiErr) {
+			entry.ErrorCode = apiErr.Code
+		}
+	}
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.HasTraceID() {
+		entry.TraceId = spanCtx.TraceID().String()
+	}
+	if r.stack {
+		entry.Stack = callers()
+	}
+
+	if reported, ok := ctx.Value(reportedKey{}).(*atomic.Bool); ok {
+		reported.Store(true)
+	}
+
+	for _, sink := range r.sinks {
+		if err := sink.Write(entry); err != nil {
+			logx.WithContext(ctx).Errorf("Failed to write error report: %v", err)
+		}
+	}
+}
+
+// Close flushes and closes every sink
+func (r *Reporter) Close() error {
+	var errs []error
+	for _, sink := range r.sinks {
+		errs = append(errs, sink.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// pkgPrefix prefixes the names of this package's functions, whose frames are
+// left out of stack traces
+var pkgPrefix = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	slash := strings.LastIndex(name, "/")
+	return name[:slash+strings.Index(name[slash:], ".")+1]
+}()
+
+// callers returns the stack of the code reporting an error
+func callers() []Frame {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var stack []Frame
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, pkgPrefix) && !strings.HasPrefix(frame.Function, "runtime.") {
+			stack = append(stack, Frame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			})
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+type reportedKey struct{}
+
+// NewContext returns a copy of ctx that remembers whether an error was reported
+// with it, or a context derived from it, see Reported
+func NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, reportedKey{}, new(atomic.Bool))
+}
+
+// Reported reports whether an error was reported with ctx since NewContext
+func Reported(ctx context.Context) bool {
+	reported, ok := ctx.Value(reportedKey{}).(*atomic.Bool)
+	return ok && reported.Load()
+}
+
+var (
+	defaultReporter = NewReporter(false)
+	defaultMu       sync.RWMutex
+)
+
+// Setup replaces the process-wide reporter used by Report
+func Setup(reporter *Reporter) {
+	defaultMu.Lock()
+	defaultReporter = reporter
+	defaultMu.Unlock()
+}
+
+// Report records err on the process-wide reporter; it is a no-op until Setup
+func Report(ctx context.Context, message string, err error) {
+	ReportWithFields(ctx, message, err, nil)
+}
+
+// ReportWithFields is Report with request details such as the method and path
+func ReportWithFields(ctx context.Context, message string, err error, fields map[string]interface{}) {
+	defaultMu.RLock()
+	reporter := defaultReporter
+	defaultMu.RUnlock()
+	reporter.Report(ctx, message, err, fields)
+}
+
+// Close closes the process-wide reporter
+func Close() error {
+	defaultMu.RLock()
+	reporter := defaultReporter
+	defaultMu.RUnlock()
+	return reporter.Close()
+}
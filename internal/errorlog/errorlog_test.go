@@ -0,0 +1,95 @@
+package errorlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/requestid"
+)
+
+// memorySink keeps the entries written to it
+type memorySink struct {
+	entries []*Entry
+}
+
+func (s *memorySink) Write(entry *Entry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *memorySink) Close() error {
+	return nil
+}
+
+func TestReporterReport(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantError string
+		wantCode  string
+	}{
+		{name: "no error"},
+		{name: "plain error", err: errors.New("boom"), wantError: "boom"},
+		{name: "api error", err: model.NewAPIError(model.ErrCodeDatabaseError, "db down"), wantError: "db down", wantCode: model.ErrCodeDatabaseError},
+		{name: "wrapped api error", err: fmt.Errorf("settle: %w", model.NewAPIError(model.ErrCodeDatabaseError, "db down")), wantError: "settle: db down", wantCode: model.ErrCodeDatabaseError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &memorySink{}
+			ctx := requestid.NewContext(context.Background(), "req-1")
+			NewReporter(true, sink).Report(ctx, "Handler error", tt.err, map[string]interface{}{"path": "/api/bots"})
+
+			if len(sink.entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(sink.entries))
+			}
+			entry := sink.entries[0]
+			if entry.Error != tt.wantError || entry.ErrorCode != tt.wantCode {
+				t.Errorf("error, code = %q, %q, want %q, %q", entry.Error, entry.ErrorCode, tt.wantError, tt.wantCode)
+			}
+			if entry.RequestId != "req-1" || entry.Context["path"] != "/api/bots" {
+				t.Errorf("request id, context = %q, %v", entry.RequestId, entry.Context)
+			}
+			// Frames of this package, the test included, are left out
+			if len(entry.Stack) == 0 {
+				t.Error("stack is empty")
+			}
+			for _, frame := range entry.Stack {
+				if strings.HasPrefix(frame.Function, pkgPrefix) {
+					t.Errorf("stack includes %s", frame.Function)
+				}
+			}
+		})
+	}
+}
+
+func TestReporterWithoutStack(t *testing.T) {
+	sink := &memorySink{}
+	NewReporter(false, sink).Report(context.Background(), "Handler error", errors.New("boom"), nil)
+	if len(sink.entries) != 1 || sink.entries[0].Stack != nil {
+		t.Errorf("entries = %v, want one without a stack", sink.entries)
+	}
+}
+
+func TestReported(t *testing.T) {
+	reporter := NewReporter(false, &memorySink{})
+
+	if Reported(context.Background()) {
+		t.Error("Reported() without NewContext = true")
+	}
+	ctx := NewContext(context.Background())
+	if Reported(ctx) {
+		t.Error("Reported() before any report = true")
+	}
+	// Reports made with a derived context count
+	reporter.Report(requestid.NewContext(ctx, "req-1"), "failed", errors.New("boom"), nil)
+	if !Reported(ctx) {
+		t.Error("Reported() after a report = false")
+	}
+	if Reported(NewContext(ctx)) {
+		t.Error("Reported() of a new context = true")
+	}
+}
@@ -0,0 +1,107 @@
+package errorlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	filePrefix = "error-"
+	fileSuffix = ".log"
+	dayLayout  = "2006-01-02"
+)
+
+// FileSink writes entries as JSON lines to one file per day, named
+// error-2006-01-02.log, and removes files older than keepDays
+type FileSink struct {
+	dir      string
+	keepDays int
+
+	mu   sync.Mutex
+	day  string
+	file *os.File
+}
+
+func NewFileSink(dir string, keepDays int) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create error log directory: %v", err)
+	}
+	return &FileSink{
+		dir:      dir,
+		keepDays: keepDays,
+	}, nil
+}
+
+func (s *FileSink) Write(entry *Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.rotate(entry.Time); err != nil {
+		return err
+	}
+	_, err = s.file.Write(line)
+	return err
+}
+
+// rotate opens the file of the entry's day, closing the previous one and
+// pruning expired files when the day changes
+func (s *FileSink) rotate(now time.Time) error {
+	day := now.Format(dayLayout)
+	if s.file != nil && day == s.day {
+		return nil
+	}
+
+	file, err := os.OpenFile(filepath.Join(s.dir, filePrefix+day+fileSuffix), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open error log file: %v", err)
+	}
+	if s.file != nil {
+		s.file.Close()
+	}
+	s.file = file
+	s.day = day
+	s.prune(now)
+	return nil
+}
+
+// prune removes the daily files that fall outside the retention window
+func (s *FileSink) prune(now time.Time) {
+	if s.keepDays <= 0 {
+		return
+	}
+	matches, err := filepath.Glob(filepath.Join(s.dir, filePrefix+"*"+fileSuffix))
+	if err != nil {
+		return
+	}
+	oldest := now.AddDate(0, 0, -s.keepDays+1).Format(dayLayout)
+	for _, path := range matches {
+		day := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), filePrefix), fileSuffix)
+		if _, err := time.Parse(dayLayout, day); err != nil || day >= oldest {
+			continue
+		}
+		os.Remove(path)
+	}
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,60 @@
+package errorlog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileSinkRotatesAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	// Files outside the 3 day window, a file inside it and an unrelated file
+	for _, name := range []string{"error-2026-02-20.log", "error-2026-02-26.log", "error-2026-02-27.log", "notes.log"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sink, err := NewFileSink(dir, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	day1 := time.Date(2026, 2, 28, 23, 59, 0, 0, time.UTC)
+	day2 := time.Date(2026, 3, 1, 0, 1, 0, 0, time.UTC)
+	for _, entry := range []*Entry{
+		{Time: day1, Message: "first"},
+		{Time: day1, Message: "second"},
+		{Time: day2, Message: "third"},
+	} {
+		if err := sink.Write(entry); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	want := []string{"error-2026-02-27.log", "error-2026-02-28.log", "error-2026-03-01.log", "notes.log"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("files = %v, want %v", names, want)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(dir, "error-2026-02-28.log"))
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("lines in the first day's file = %d, want 2", len(lines))
+	}
+	var entry Entry
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil || entry.Message != "second" {
+		t.Errorf("second line = %s, want the second entry", lines[1])
+	}
+}
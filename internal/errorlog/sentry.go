@@ -0,0 +1,228 @@
+package errorlog
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// SentryConf configures reporting to Sentry or a Sentry-compatible service
+// such as GlitchTip
+type SentryConf struct {
+	// Dsn is the project's client key URL; reporting is disabled when empty
+	Dsn         string `json:",optional"`
+	Environment string `json:",optional"`
+	Release     string `json:",optional"`
+	// Timeout bounds one request to the service
+	Timeout time.Duration `json:",default=5s"`
+	// BufferSize is how many reports may wait to be sent before new ones are
+	// dropped
+	BufferSize int `json:",default=100"`
+}
+
+// SentrySink sends entries to the store endpoint of a Sentry DSN in the
+// background, so reporting never waits for the service
+type SentrySink struct {
+	c          SentryConf
+	storeURL   string
+	authHeader string
+	serverName string
+	client     *http.Client
+
+	mu     sync.RWMutex
+	closed bool
+	events chan []byte
+	wg     sync.WaitGroup
+}
+
+func NewSentrySink(c SentryConf) (*SentrySink, error) {
+	dsn, err := url.Parse(c.Dsn)
+	if err != nil || dsn.User == nil || dsn.User.Username() == "" || dsn.Host == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN")
+	}
+	path := strings.Trim(dsn.Path, "/")
+	slash := strings.LastIndex(path, "/")
+	projectId := path[slash+1:]
+	if projectId == "" {
+		return nil, fmt.Errorf("invalid Sentry DSN: missing project id")
+	}
+	prefix := ""
+	if slash >= 0 {
+		prefix = "/" + path[:slash]
+	}
+
+	auth := fmt.Sprintf("Sentry sentry_version=7, sentry_client=wata-bot/1.0, sentry_key=%s", dsn.User.Username())
+	if secret, ok := dsn.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+	serverName, _ := os.Hostname()
+
+	s := &SentrySink{
+		c:          c,
+		storeURL:   fmt.Sprintf("%s://%s%s/api/%s/store/", dsn.Scheme, dsn.Host, prefix, projectId),
+		authHeader: auth,
+		serverName: serverName,
+		client:     &http.Client{Timeout: c.Timeout},
+		events:     make(chan []byte, c.BufferSize),
+	}
+	s.wg.Add(1)
+	go s.run()
+	return s, nil
+}
+
+type sentryEvent struct {
+	EventId     string                 `json:"event_id"`
+	Timestamp   string                 `json:"timestamp"`
+	Level       string                 `json:"level"`
+	Platform    string                 `json:"platform"`
+	Logger      string                 `json:"logger"`
+	ServerName  string                 `json:"server_name,omitempty"`
+	Environment string                 `json:"environment,omitempty"`
+	Release     string                 `json:"release,omitempty"`
+	Message     string                 `json:"message"`
+	Exception   *sentryExceptions      `json:"exception,omitempty"`
+	Tags        map[string]string      `json:"tags,omitempty"`
+	Extra       map[string]interface{} `json:"extra,omitempty"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type       string            `json:"type"`
+	Value      string            `json:"value"`
+	Stacktrace *sentryStacktrace `json:"stacktrace,omitempty"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	Filename string `json:"filename"`
+	Lineno   int    `json:"lineno"`
+}
+
+func (s *SentrySink) Write(entry *Entry) error {
+	event := sentryEvent{
+		EventId:     newEventId(),
+		Timestamp:   entry.Time.UTC().Format(time.RFC3339),
+		Level:       "error",
+		Platform:    "go",
+		Logger:      "wata-bot",
+		ServerName:  s.serverName,
+		Environment: s.c.Environment,
+		Release:     s.c.Release,
+		Message:     entry.Message,
+		Tags:        map[string]string{},
+		Extra:       entry.Context,
+	}
+	if entry.Error != "" {
+		exception := sentryException{
+			Type:  entry.Message,
+			Value: entry.Error,
+		}
+		if len(entry.Stack) > 0 {
+			// Sentry lists frames outermost first
+			frames := make([]sentryFrame, 0, len(entry.Stack))
+			for i := len(entry.Stack) - 1; i >= 0; i-- {
+				frames = append(frames, sentryFrame{
+					Function: entry.Stack[i].Function,
+					Filename: entry.Stack[i].File,
+					Lineno:   entry.Stack[i].Line,
+				})
+			}
+			exception.Stacktrace = &sentryStacktrace{Frames: frames}
+		}
+		event.Exception = &sentryExceptions{Values: []sentryException{exception}}
+	}
+	for key, value := range map[string]string{
+		"error_code": entry.ErrorCode,
+		"request_id": entry.RequestId,
+		"trace_id":   entry.TraceId,
+	} {
+		if value != "" {
+			event.Tags[key] = value
+		}
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil
+	}
+	select {
+	case s.events <- body:
+		return nil
+	default:
+		return fmt.Errorf("sentry buffer full, dropping error report")
+	}
+}
+
+func (s *SentrySink) run() {
+	defer s.wg.Done()
+	for body := range s.events {
+		if err := s.send(body); err != nil {
+			logx.Errorf("Failed to send error report to Sentry: %v", err)
+		}
+	}
+}
+
+func (s *SentrySink) send(body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.c.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.authHeader)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close sends the reports still waiting and stops the sink
+func (s *SentrySink) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.events)
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+func newEventId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
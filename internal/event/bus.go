@@ -0,0 +1,94 @@
+// Package event dispatches domain events read from the outbox to in-process
+// handlers and external sinks. Delivery is at-least-once: an event whose
+// dispatch fails is retried as a whole, so handlers and sinks must tolerate
+// seeing the same event id more than once.
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AllEvents subscribes a handler to every event type
+const AllEvents = "*"
+
+// Event is a domain event as stored in the outbox
+type Event struct {
+	Id          int64           `json:"id"`
+	Type        string          `json:"type"`
+	AggregateId string          `json:"aggregateId"` // Id of the user, subscription or transaction the event is about
+	Payload     json.RawMessage `json:"payload"`
+	OccurredAt  time.Time       `json:"occurredAt"`
+}
+
+// Decode unmarshals the payload into v
+func (e Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// Handler reacts to an event in process
+type Handler func(ctx context.Context, evt Event) error
+
+// Sink forwards events outside the process
+type Sink interface {
+	Publish(ctx context.Context, evt Event) error
+}
+
+type namedHandler struct {
+	name    string
+	handler Handler
+}
+
+// Bus routes events to the handlers subscribed to their type and to every sink
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]namedHandler
+	sinks    []Sink
+}
+
+func NewBus() *Bus {
+	return &Bus{
+		handlers: make(map[string][]namedHandler),
+	}
+}
+
+// Subscribe registers handler for eventType, or for every type with AllEvents.
+// name identifies the handler in errors.
+func (b *Bus) Subscribe(eventType, name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], namedHandler{name: name, handler: handler})
+}
+
+// AddSink registers a sink that receives every event
+func (b *Bus) AddSink(sink Sink) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sinks = append(b.sinks, sink)
+}
+
+// Dispatch hands evt to its handlers and the sinks. Every one of them runs even
+// if an earlier one fails; the returned error joins their failures.
+func (b *Bus) Dispatch(ctx context.Context, evt Event) error {
+	b.mu.RLock()
+	handlers := append(append([]namedHandler(nil), b.handlers[evt.Type]...), b.handlers[AllEvents]...)
+	sinks := append([]Sink(nil), b.sinks...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, h := range handlers {
+		if err := h.handler(ctx, evt); err != nil {
+			errs = append(errs, fmt.Errorf("handler %s: %w", h.name, err))
+		}
+	}
+	for _, sink := range sinks {
+		if err := sink.Publish(ctx, evt); err != nil {
+			errs = append(errs, fmt.Errorf("sink %T: %w", sink, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,83 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// SinkConf configures an HTTP sink
+type SinkConf struct {
+	Url string
+	// Events limits the sink to these event types, empty for all
+	Events  []string      `json:",optional"`
+	Timeout time.Duration `json:",default=10s"`
+}
+
+// HTTPSink POSTs every event as JSON to a URL. The X-Event-Id header lets the
+// receiver drop duplicates.
+type HTTPSink struct {
+	url    string
+	events map[string]bool
+	client *http.Client
+}
+
+func NewHTTPSink(c SinkConf) *HTTPSink {
+	var events map[string]bool
+	if len(c.Events) > 0 {
+		events = make(map[string]bool, len(c.Events))
+		for _, e := range c.Events {
+			events[e] = true
+		}
+	}
+	return &HTTPSink{
+		url:    c.Url,
+		events: events,
+		client: &http.Client{Timeout: c.Timeout},
+	}
+}
+
+func (s *HTTPSink) Publish(ctx context.Context, evt Event) error {
+	if s.events != nil && !s.events[evt.Type] {
+		return nil
+	}
+
+	body, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", strconv.FormatInt(evt.Id, 10))
+	req.Header.Set("X-Event-Type", evt.Type)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned status %d: %s", s.url, resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// LogSink writes every event to the service log
+type LogSink struct{}
+
+func (LogSink) Publish(ctx context.Context, evt Event) error {
+	logx.WithContext(ctx).Infof("Domain event %d %s (%s): %s", evt.Id, evt.Type, evt.AggregateId, evt.Payload)
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/zeromicro/go-zero/rest/httpx"
 	"wata-bot-BE/internal/logic"
 	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
 )
 
 func BotsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
@@ -21,3 +22,39 @@ func BotsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 }
 
 
+
+func BotDetailHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.BotDetailReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+
+		l := logic.NewBotLogic(r.Context(), svcCtx)
+		resp, err := l.BotDetail(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+func BotChangelogHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.BotChangelogReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+
+		l := logic.NewBotLogic(r.Context(), svcCtx)
+		resp, err := l.BotChangelog(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
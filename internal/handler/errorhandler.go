@@ -5,42 +5,35 @@ import (
 	"errors"
 	"net/http"
 
+	"wata-bot-BE/internal/errorlog"
 	"wata-bot-BE/internal/model"
-	"wata-bot-BE/internal/types"
-	"wata-bot-BE/internal/utils"
+	"wata-bot-BE/internal/response"
 
 	"github.com/zeromicro/go-zero/core/logx"
-	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
-// ErrorHandler wraps httpx.ErrorCtx to log errors and return formatted error response
+// ErrorHandler logs err and writes it as an ErrorResp; the HTTP status comes
+// from the error catalog, see model.LookupError
 func ErrorHandler(ctx context.Context, w http.ResponseWriter, err error) {
-	// Log all errors to file
-	context := map[string]interface{}{
-		"error": err.Error(),
-	}
-	utils.WriteErrorLogWithContext("Handler error", err, context)
-	logx.Errorf("Handler error: %v", err)
+	// Report all errors to the error log
+	errorlog.Report(ctx, "Handler error", err)
+	logx.WithContext(ctx).Errorf("Handler error: %v", err)
+
+	response.Error(ctx, w, err)
+}
 
-	// Check if error is APIError with error code
+// invalidRequest wraps an error from httpx.Parse, such as a missing field or a
+// malformed body, so that it is returned as a bad request. API errors, such as
+// the field errors of the validator, are returned as they are.
+func invalidRequest(err error) error {
 	var apiErr *model.APIError
 	if errors.As(err, &apiErr) {
-		// Return formatted error response with error code
-		// Use BadRequest for client errors, InternalServerError for server errors
-		statusCode := http.StatusBadRequest
-		if apiErr.Code == model.ErrCodeInternalServerError {
-			statusCode = http.StatusInternalServerError
-		}
-		httpx.WriteJsonCtx(ctx, w, statusCode, types.ErrorResp{
-			ErrorCode: apiErr.Code,
-			Message:   apiErr.Message,
-		})
-		return
+		return err
 	}
+	return model.NewAPIError(model.ErrCodeInvalidRequest, err.Error())
+}
 
-	// Default error response for unknown errors
-	httpx.WriteJsonCtx(ctx, w, http.StatusInternalServerError, types.ErrorResp{
-		ErrorCode: model.ErrCodeInternalServerError,
-		Message:   err.Error(),
-	})
+// NotFoundHandler answers requests for unknown routes
+func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
+	response.Error(r.Context(), w, model.NewAPIErrorFromCode(model.ErrCodeNotFound))
 }
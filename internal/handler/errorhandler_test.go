@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"wata-bot-BE/internal/model"
+)
+
+func TestInvalidRequest(t *testing.T) {
+	fieldErr := model.NewAPIErrorFromCode(model.ErrCodeInvalidAddressFormat)
+	fieldErr.Fields = []model.FieldError{{Field: "address", Rule: "address"}}
+
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   string
+		wantFields int
+	}{
+		{name: "parse error", err: errors.New("field \"amount\" is not set"), wantCode: model.ErrCodeInvalidRequest},
+		{name: "validation error", err: fieldErr, wantCode: model.ErrCodeInvalidAddressFormat, wantFields: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var apiErr *model.APIError
+			if !errors.As(invalidRequest(tt.err), &apiErr) {
+				t.Fatalf("invalidRequest() is not an APIError")
+			}
+			if apiErr.Code != tt.wantCode || len(apiErr.Fields) != tt.wantFields {
+				t.Errorf("invalidRequest() = code %s with %d fields, want %s with %d", apiErr.Code, len(apiErr.Fields), tt.wantCode, tt.wantFields)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"wata-bot-BE/internal/health"
+	"wata-bot-BE/internal/logic"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+)
+
+func LivenessHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := logic.NewHealthLogic(r.Context(), svcCtx)
+		resp, err := l.Liveness()
+		writeHealth(w, r, resp, err)
+	}
+}
+
+func HealthHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := logic.NewHealthLogic(r.Context(), svcCtx)
+		resp, err := l.Health()
+		writeHealth(w, r, resp, err)
+	}
+}
+
+func ReadinessHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := logic.NewHealthLogic(r.Context(), svcCtx)
+		resp, err := l.Readiness()
+		writeHealth(w, r, resp, err)
+	}
+}
+
+// writeHealth answers 200 when the status is up and 503 otherwise, so probes
+// and load balancers can go by the status code alone
+func writeHealth(w http.ResponseWriter, r *http.Request, resp *types.HealthResp, err error) {
+	if err != nil {
+		ErrorHandler(r.Context(), w, err)
+		return
+	}
+	// Probes must always see the current state
+	w.Header().Set("Cache-Control", "no-store")
+	if resp.Status != health.StatusUp {
+		httpx.WriteJsonCtx(r.Context(), w, http.StatusServiceUnavailable, resp)
+		return
+	}
+	httpx.OkJsonCtx(r.Context(), w, resp)
+}
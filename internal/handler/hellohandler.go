@@ -13,7 +13,7 @@ func HelloHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.HelloReq
 		if err := httpx.Parse(r, &req); err != nil {
-			ErrorHandler(r.Context(), w, err)
+			ErrorHandler(r.Context(), w, invalidRequest(err))
 			return
 		}
 
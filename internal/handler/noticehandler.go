@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"wata-bot-BE/internal/logic"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+)
+
+func GetBotNoticesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GetBotNoticesReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+
+		l := logic.NewNoticeLogic(r.Context(), svcCtx)
+		resp, err := l.GetBotNotices(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+func MarkBotNoticesReadHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.MarkBotNoticesReadReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+
+		l := logic.NewNoticeLogic(r.Context(), svcCtx)
+		resp, err := l.MarkBotNoticesRead(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
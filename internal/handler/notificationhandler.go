@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"wata-bot-BE/internal/logic"
+	"wata-bot-BE/internal/middleware"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+)
+
+func GetNotificationsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GetNotificationsReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+		req.Address = middleware.AddressFromContext(r.Context())
+
+		l := logic.NewNotificationLogic(r.Context(), svcCtx)
+		resp, err := l.GetNotifications(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+func MarkNotificationsReadHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.MarkNotificationsReadReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+		req.Address = middleware.AddressFromContext(r.Context())
+
+		l := logic.NewNotificationLogic(r.Context(), svcCtx)
+		resp, err := l.MarkNotificationsRead(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+func GetNotificationPreferencesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.GetNotificationPreferencesReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+		req.Address = middleware.AddressFromContext(r.Context())
+
+		l := logic.NewNotificationLogic(r.Context(), svcCtx)
+		resp, err := l.GetNotificationPreferences(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+func UpdateNotificationPreferenceHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.UpdateNotificationPreferenceReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+		req.Address = middleware.AddressFromContext(r.Context())
+
+		l := logic.NewNotificationLogic(r.Context(), svcCtx)
+		resp, err := l.UpdateNotificationPreference(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"wata-bot-BE/internal/logic"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func IngestPerformanceHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.IngestPerformanceReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+
+		l := logic.NewPerformanceLogic(r.Context(), svcCtx)
+		resp, err := l.IngestPerformance(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+func BotPerformanceHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.BotPerformanceReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+
+		l := logic.NewPerformanceLogic(r.Context(), svcCtx)
+		resp, err := l.BotPerformance(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
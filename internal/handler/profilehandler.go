@@ -13,7 +13,7 @@ func GetProfileHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.GetProfileReq
 		if err := httpx.Parse(r, &req); err != nil {
-			ErrorHandler(r.Context(), w, err)
+			ErrorHandler(r.Context(), w, invalidRequest(err))
 			return
 		}
 
@@ -27,3 +27,20 @@ func GetProfileHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	}
 }
 
+func UpdateLanguageHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.UpdateLanguageReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+
+		l := logic.NewProfileLogic(r.Context(), svcCtx)
+		resp, err := l.UpdateLanguage(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -1,4 +1,3 @@
-// Code generated by goctl. DO NOT EDIT.
 package handler
 
 import (
@@ -18,19 +17,34 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Handler: HelloHandler(serverCtx),
 			},
 			{
-				Method:  http.MethodPost,
-				Path:    "/auth/wallet",
-				Handler: WalletAuthHandler(serverCtx),
+				Method:  http.MethodGet,
+				Path:    "/api/bots",
+				Handler: BotsHandler(serverCtx),
 			},
 			{
-				Method:  http.MethodPost,
-				Path:    "/auth/wallet-not-sign",
-				Handler: WalletAuthNotSignHandler(serverCtx),
+				Method:  http.MethodGet,
+				Path:    "/api/chains",
+				Handler: ChainsHandler(serverCtx),
 			},
 			{
 				Method:  http.MethodGet,
-				Path:    "/api/bots",
-				Handler: BotsHandler(serverCtx),
+				Path:    "/api/bots/:id",
+				Handler: BotDetailHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/bots/:id/performance",
+				Handler: BotPerformanceHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/bots/:id/trades",
+				Handler: BotTradesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/api/bots/:id/changelog",
+				Handler: BotChangelogHandler(serverCtx),
 			},
 			{
 				Method:  http.MethodPost,
@@ -47,6 +61,36 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/api/user/bots/unsubscribe",
 				Handler: UnsubscribeBotHandler(serverCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/user/bots/auto-renew",
+				Handler: UpdateAutoRenewHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/user/bots/waitlist",
+				Handler: GetWaitlistHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/user/bots/waitlist/join",
+				Handler: JoinWaitlistHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/user/bots/waitlist/leave",
+				Handler: LeaveWaitlistHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/user/bots/notices",
+				Handler: GetBotNoticesHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/api/user/bots/notices/read",
+				Handler: MarkBotNoticesReadHandler(serverCtx),
+			},
 			{
 				Method:  http.MethodPost,
 				Path:    "/api/user/profile",
@@ -54,14 +98,190 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 			},
 			{
 				Method:  http.MethodPost,
-				Path:    "/api/user/deposit",
-				Handler: DepositHandler(serverCtx),
+				Path:    "/api/user/profile/language",
+				Handler: UpdateLanguageHandler(serverCtx),
+			},
+		},
+	)
+
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/livez",
+				Handler: LivenessHandler(serverCtx),
 			},
 			{
-				Method:  http.MethodPost,
-				Path:    "/api/user/withdraw",
-				Handler: WithdrawHandler(serverCtx),
+				Method:  http.MethodGet,
+				Path:    "/healthz",
+				Handler: HealthHandler(serverCtx),
+			},
+			{
+				Method:  http.MethodGet,
+				Path:    "/readyz",
+				Handler: ReadinessHandler(serverCtx),
 			},
 		},
 	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.AuthRateLimit},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/auth/wallet",
+					Handler: WalletAuthHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/auth/wallet-not-sign",
+					Handler: WalletAuthNotSignHandler(serverCtx),
+				},
+			}...,
+		),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.BalanceRateLimit},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/api/user/deposit",
+					Handler: DepositHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/api/user/withdraw",
+					Handler: WithdrawHandler(serverCtx),
+				},
+			}...,
+		),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.EngineAuth},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/api/engine/bots/:id/performance",
+					Handler: IngestPerformanceHandler(serverCtx),
+				},
+			}...,
+		),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.EngineAuth, serverCtx.EngineSignature},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/api/engine/bots/:id/trades",
+					Handler: IngestTradesHandler(serverCtx),
+				},
+			}...,
+		),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.AdminAuth},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/api/admin/webhooks",
+					Handler: CreateWebhookHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/api/admin/webhooks",
+					Handler: GetWebhooksHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPut,
+					Path:    "/api/admin/webhooks/:id",
+					Handler: UpdateWebhookHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodDelete,
+					Path:    "/api/admin/webhooks/:id",
+					Handler: DeleteWebhookHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/api/admin/webhooks/:id/deliveries",
+					Handler: GetWebhookDeliveriesHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodGet,
+					Path:    "/api/admin/webhook-deliveries/:id",
+					Handler: GetWebhookDeliveryHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/api/admin/webhook-deliveries/:id/replay",
+					Handler: ReplayWebhookDeliveryHandler(serverCtx),
+				},
+			}...,
+		),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.UserAuth},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/api/user/notifications",
+					Handler: GetNotificationsHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/api/user/notifications/read",
+					Handler: MarkNotificationsReadHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/api/user/notifications/preferences",
+					Handler: GetNotificationPreferencesHandler(serverCtx),
+				},
+				{
+					Method:  http.MethodPost,
+					Path:    "/api/user/notifications/preferences/update",
+					Handler: UpdateNotificationPreferenceHandler(serverCtx),
+				},
+			}...,
+		),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.UserAuth},
+			[]rest.Route{
+				{
+					Method:  http.MethodGet,
+					Path:    "/api/stream",
+					Handler: StreamHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithSSE(),
+		rest.WithTimeout(0),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.UserAuth},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/api/stream/subscriptions",
+					Handler: UpdateStreamSubscriptionsHandler(serverCtx),
+				},
+			}...,
+		),
+	)
 }
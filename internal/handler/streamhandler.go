@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"wata-bot-BE/internal/logic"
+	"wata-bot-BE/internal/middleware"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+)
+
+func StreamHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.StreamReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+		req.Address = middleware.AddressFromContext(r.Context())
+
+		l := logic.NewStreamLogic(r.Context(), svcCtx)
+		conn, err := l.Open(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+			return
+		}
+		// The client has gone once a write fails; nothing left to report
+		l.Serve(conn, &sseWriter{w: w, rc: http.NewResponseController(w)})
+	}
+}
+
+func UpdateStreamSubscriptionsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.UpdateStreamSubscriptionsReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+		req.Address = middleware.AddressFromContext(r.Context())
+
+		l := logic.NewStreamLogic(r.Context(), svcCtx)
+		resp, err := l.UpdateSubscriptions(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+// sseWriter writes server-sent events and flushes each one immediately
+type sseWriter struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (s *sseWriter) WriteEvent(event string, data []byte) error {
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
@@ -13,7 +13,7 @@ func GetUserBotsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.GetUserBotsReq
 		if err := httpx.Parse(r, &req); err != nil {
-			ErrorHandler(r.Context(), w, err)
+			ErrorHandler(r.Context(), w, invalidRequest(err))
 			return
 		}
 
@@ -31,7 +31,7 @@ func SubscribeBotHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.SubscribeBotReq
 		if err := httpx.Parse(r, &req); err != nil {
-			ErrorHandler(r.Context(), w, err)
+			ErrorHandler(r.Context(), w, invalidRequest(err))
 			return
 		}
 
@@ -49,7 +49,7 @@ func UnsubscribeBotHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.UnsubscribeBotReq
 		if err := httpx.Parse(r, &req); err != nil {
-			ErrorHandler(r.Context(), w, err)
+			ErrorHandler(r.Context(), w, invalidRequest(err))
 			return
 		}
 
@@ -63,4 +63,20 @@ func UnsubscribeBotHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	}
 }
 
+func UpdateAutoRenewHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.UpdateAutoRenewReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
 
+		l := logic.NewSubscriptionLogic(r.Context(), svcCtx)
+		resp, err := l.UpdateAutoRenew(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
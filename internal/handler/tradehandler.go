@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"wata-bot-BE/internal/logic"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func IngestTradesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.IngestTradesReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+
+		l := logic.NewTradeLogic(r.Context(), svcCtx)
+		resp, err := l.IngestTrades(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
+
+func BotTradesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.BotTradesReq
+		if err := httpx.Parse(r, &req); err != nil {
+			ErrorHandler(r.Context(), w, invalidRequest(err))
+			return
+		}
+
+		l := logic.NewTradeLogic(r.Context(), svcCtx)
+		resp, err := l.BotTrades(&req)
+		if err != nil {
+			ErrorHandler(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
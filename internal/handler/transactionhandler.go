@@ -13,7 +13,7 @@ func DepositHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.DepositReq
 		if err := httpx.Parse(r, &req); err != nil {
-			ErrorHandler(r.Context(), w, err)
+			ErrorHandler(r.Context(), w, invalidRequest(err))
 			return
 		}
 
@@ -31,7 +31,7 @@ func WithdrawHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.WithdrawReq
 		if err := httpx.Parse(r, &req); err != nil {
-			ErrorHandler(r.Context(), w, err)
+			ErrorHandler(r.Context(), w, invalidRequest(err))
 			return
 		}
 
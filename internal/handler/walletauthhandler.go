@@ -14,7 +14,7 @@ func WalletAuthHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.WalletAuthReq
 		if err := httpx.Parse(r, &req); err != nil {
-			ErrorHandler(r.Context(), w, err)
+			ErrorHandler(r.Context(), w, invalidRequest(err))
 			return
 		}
 
@@ -32,7 +32,7 @@ func WalletAuthNotSignHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.WalletAuthNotSignReq
 		if err := httpx.Parse(r, &req); err != nil {
-			ErrorHandler(r.Context(), w, err)
+			ErrorHandler(r.Context(), w, invalidRequest(err))
 			return
 		}
 
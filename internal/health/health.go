@@ -0,0 +1,196 @@
+package health
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+// Statuses of a dependency and of the service as a whole
+const (
+	StatusUp           = "up"
+	StatusDown         = "down"
+	StatusShuttingDown = "shutting_down"
+)
+
+// CheckFunc returns an error when the dependency cannot serve requests
+type CheckFunc func(ctx context.Context) error
+
+// Result is the outcome of one dependency check
+type Result struct {
+	Name    string
+	Status  string
+	Latency time.Duration
+	Error   string
+}
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker runs the registered dependency checks and tracks whether the
+// service is shutting down
+type Checker struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []check
+
+	shuttingDown atomic.Bool
+}
+
+// NewChecker returns a checker that gives each check at most timeout
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add registers a dependency check under name, such as "mysql"
+func (c *Checker) Add(name string, fn CheckFunc) {
+	c.mu.Lock()
+	c.checks = append(c.checks, check{name: name, fn: fn})
+	c.mu.Unlock()
+}
+
+// Check runs every check concurrently and returns the results in the order
+// the checks were added, and whether all dependencies are up
+func (c *Checker) Check(ctx context.Context) ([]Result, bool) {
+	c.mu.RLock()
+	checks := c.checks
+	c.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, ch := range checks {
+		wg.Add(1)
+		go func(i int, ch check) {
+			defer wg.Done()
+			results[i] = c.run(ctx, ch)
+		}(i, ch)
+	}
+	wg.Wait()
+
+	healthy := true
+	for _, res := range results {
+		if res.Status != StatusUp {
+			healthy = false
+		}
+	}
+	return results, healthy
+}
+
+func (c *Checker) run(ctx context.Context, ch check) (res Result) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	res.Name = ch.name
+	start := time.Now()
+	defer func() {
+		res.Latency = time.Since(start)
+	}()
+
+	// A check that ignores its context must not hold up the probe
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("%v", p)
+			}
+		}()
+		done <- ch.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+		return res
+	}
+	res.Status = StatusUp
+	return res
+}
+
+// SetShuttingDown marks the service as shutting down so that it reports not
+// ready and load balancers stop sending it new requests
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// ShuttingDown reports whether SetShuttingDown has been called
+func (c *Checker) ShuttingDown() bool {
+	return c.shuttingDown.Load()
+}
+
+// MySQL checks that the database answers a query
+func MySQL(conn sqlx.SqlConn) CheckFunc {
+	return func(ctx context.Context) error {
+		var result int
+		return conn.QueryRowCtx(ctx, &result, "SELECT 1")
+	}
+}
+
+// Redis checks that a Redis node answers PING
+func Redis(r *redis.Redis) CheckFunc {
+	return func(ctx context.Context) error {
+		if !r.PingCtx(ctx) {
+			return fmt.Errorf("redis %s did not answer PING", r.Addr)
+		}
+		return nil
+	}
+}
+
+// EVM checks that the HTTP JSON-RPC node at rpcUrl answers eth_chainId with
+// chainId, so a node pointed at the wrong network is reported down
+func EVM(client *http.Client, rpcUrl string, chainId int64) CheckFunc {
+	body := []byte(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`)
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, rpcUrl, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("rpc answered HTTP %d", resp.StatusCode)
+		}
+
+		var result struct {
+			Result string `json:"result"`
+			Error  *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return err
+		}
+		if result.Error != nil {
+			return fmt.Errorf("rpc error: %s", result.Error.Message)
+		}
+		id, err := hexutil.DecodeBig(result.Result)
+		if err != nil {
+			return fmt.Errorf("rpc returned chain id %q: %w", result.Result, err)
+		}
+		if !id.IsInt64() || id.Int64() != chainId {
+			return fmt.Errorf("rpc serves chain %s, expected %d", id, chainId)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,102 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckerCheck(t *testing.T) {
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("connection refused") }
+	hang := func(ctx context.Context) error {
+		// Ignores ctx, as a misbehaving client would
+		time.Sleep(time.Second)
+		return nil
+	}
+	panics := func(ctx context.Context) error { panic("boom") }
+
+	tests := []struct {
+		name     string
+		checks   map[string]CheckFunc
+		order    []string
+		statuses []string
+		errors   []string
+		healthy  bool
+	}{
+		{
+			name:     "all up",
+			checks:   map[string]CheckFunc{"mysql": up, "redis": up},
+			order:    []string{"mysql", "redis"},
+			statuses: []string{StatusUp, StatusUp},
+			errors:   []string{"", ""},
+			healthy:  true,
+		},
+		{
+			name:     "one down",
+			checks:   map[string]CheckFunc{"mysql": up, "redis": down},
+			order:    []string{"mysql", "redis"},
+			statuses: []string{StatusUp, StatusDown},
+			errors:   []string{"", "connection refused"},
+			healthy:  false,
+		},
+		{
+			name:     "timeout",
+			checks:   map[string]CheckFunc{"mysql": hang},
+			order:    []string{"mysql"},
+			statuses: []string{StatusDown},
+			errors:   []string{context.DeadlineExceeded.Error()},
+			healthy:  false,
+		},
+		{
+			name:     "panic",
+			checks:   map[string]CheckFunc{"redis": panics},
+			order:    []string{"redis"},
+			statuses: []string{StatusDown},
+			errors:   []string{"boom"},
+			healthy:  false,
+		},
+		{
+			name:    "no checks",
+			healthy: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker(50 * time.Millisecond)
+			for _, name := range tt.order {
+				c.Add(name, tt.checks[name])
+			}
+
+			start := time.Now()
+			results, healthy := c.Check(context.Background())
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("Check took %v, want it bounded by the timeout", elapsed)
+			}
+			if healthy != tt.healthy {
+				t.Errorf("healthy = %v, want %v", healthy, tt.healthy)
+			}
+			if len(results) != len(tt.order) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.order))
+			}
+			for i, res := range results {
+				if res.Name != tt.order[i] || res.Status != tt.statuses[i] || res.Error != tt.errors[i] {
+					t.Errorf("results[%d] = %+v, want %s %s %q", i, res, tt.order[i], tt.statuses[i], tt.errors[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCheckerShuttingDown(t *testing.T) {
+	c := NewChecker(time.Second)
+	if c.ShuttingDown() {
+		t.Fatal("new checker is shutting down")
+	}
+	c.SetShuttingDown()
+	if !c.ShuttingDown() {
+		t.Fatal("ShuttingDown() = false after SetShuttingDown")
+	}
+}
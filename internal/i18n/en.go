@@ -0,0 +1,67 @@
+package i18n
+
+var en = map[string]string{
+	// Errors, keyed by the error catalog
+	"error.invalid_address_format":   "invalid address format",
+	"error.invalid_signature":        "invalid signature",
+	"error.invalid_message":          "invalid message",
+	"error.invalid_request":          "invalid request",
+	"error.token_generation_failed":  "failed to generate tokens",
+	"error.unauthorized":             "unauthorized",
+	"error.forbidden":                "forbidden",
+	"error.database_error":           "database error",
+	"error.failed_to_create_user":    "failed to create user",
+	"error.failed_to_find_user":      "failed to find user",
+	"error.invalid_currency":         "invalid currency. Must be 'wata' or 'usdt'",
+	"error.invalid_amount":           "invalid amount",
+	"error.insufficient_balance":     "insufficient balance",
+	"error.failed_to_update_balance": "failed to update balance",
+	"error.bot_not_found":            "bot not found",
+	"error.invalid_performance_data": "invalid performance data",
+	"error.invalid_trade_data":       "invalid trade data",
+	"error.subscription_not_found":   "subscription not found",
+	"error.invalid_auto_renew":       "invalid auto-renew settings",
+	"error.bot_full":                 "bot is full, join the waitlist to be offered the next free slot",
+	"error.invalid_duration":         "invalid duration, must be one of the bot's durationDays",
+	"error.internal_server_error":    "internal server error",
+
+	"error.invalid_notification_channel": "invalid notification channel. Must be 'email', 'telegram' or 'webhook'",
+	"error.invalid_notification_target":  "invalid notification target",
+	"error.invalid_notification_event":   "invalid notification event",
+	"error.webhook_not_found":            "webhook endpoint not found",
+	"error.invalid_webhook_url":          "invalid webhook url. Must be an http(s) URL",
+	"error.invalid_webhook_event":        "invalid webhook event",
+	"error.webhook_delivery_not_found":   "webhook delivery not found",
+	"error.webhook_delivery_pending":     "webhook delivery is still pending",
+	"error.invalid_delivery_query":       "invalid webhook delivery query",
+	"error.invalid_stream_topic":         "invalid stream topic. Must be 'balances', 'transactions' or 'bot:<id>'",
+	"error.too_many_requests":            "too many requests, retry after the time in the Retry-After header",
+	"error.not_found":                    "not found",
+	"error.conflict":                     "the request conflicts with the current state of the resource",
+	"error.user_not_found":               "user not found",
+	"error.invalid_language":             "invalid language. Must be 'en' or 'vi'",
+	"error.unsupported_chain":            "unsupported chain, see /api/chains",
+	"error.currency_not_on_chain":        "currency is not available on this chain",
+
+	// Request validation, keyed by rule; %s is the rule parameter
+	"validation.required": "is required",
+	"validation.address":  "must be 0x followed by 40 hex characters",
+	"validation.amount":   "must be a positive decimal such as 10.5",
+	"validation.currency": "must be 'wata' or 'usdt'",
+	"validation.duration": "must be a number of days between 1 and 3650",
+	"validation.botid":    "must be a bot id of up to 20 letters, digits, '-' or '_'",
+	"validation.min":      "must be at least %s",
+	"validation.max":      "must be at most %s",
+
+	// Notifications, keyed by event; fields come from the notification data
+	"notification.deposit_completed.subject":    "Deposit received",
+	"notification.deposit_completed.body":       "Your deposit of {{.amount}} {{.currency}} has been credited. New balance: {{.balance}} {{.currency}}.",
+	"notification.withdraw_completed.subject":   "Withdrawal completed",
+	"notification.withdraw_completed.body":      "Your withdrawal of {{.amount}} {{.currency}} has been processed. Remaining balance: {{.balance}} {{.currency}}.",
+	"notification.subscription_settled.subject": "{{.botName}} position settled",
+	"notification.subscription_settled.body":    "Your {{.amount}} USDT position in {{.botName}} matured after {{.durationDays}} days and settled at {{.finalValue}} USDT.",
+	"notification.subscription_renewed.subject": "{{.botName}} position renewed",
+	"notification.subscription_renewed.body":    "Your position in {{.botName}} renewed into a new {{.durationDays}} day term with {{.amount}} USDT.",
+	"notification.waitlist_offered.subject":     "A slot in {{.botName}} is available",
+	"notification.waitlist_offered.body":        "A slot for {{.amount}} USDT in {{.botName}} is reserved for you until {{.expiresAt}}. Subscribe before then to take it.",
+}
@@ -0,0 +1,105 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Supported languages
+const (
+	En = "en"
+	Vi = "vi"
+)
+
+// Default is used when a client or user has no supported preference
+const Default = En
+
+// Languages lists every supported language
+var Languages = []string{En, Vi}
+
+// messages maps each language to its translations, keyed like
+// "error.insufficient_balance" or "notification.deposit_completed.subject"
+var messages = map[string]map[string]string{
+	En: en,
+	Vi: vi,
+}
+
+type languageKey struct{}
+
+// Normalize returns the supported language of a tag such as "vi-VN" or "EN",
+// and whether it is supported
+func Normalize(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if _, ok := messages[tag]; ok {
+		return tag, true
+	}
+	return "", false
+}
+
+// Match returns the supported language the client prefers most in an
+// Accept-Language header, or Default when it names none
+func Match(acceptLanguage string) string {
+	best, bestQ := Default, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		lang, ok := Normalize(tag)
+		if ok && q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
+
+// NewContext returns a copy of ctx carrying the request language
+func NewContext(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// FromContext returns the request language carried by ctx, or Default
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(languageKey{}).(string); ok {
+		return lang
+	}
+	return Default
+}
+
+// T returns the translation of key in lang, falling back to Default
+func T(lang, key string) (string, bool) {
+	if msg, ok := messages[lang][key]; ok {
+		return msg, true
+	}
+	msg, ok := messages[Default][key]
+	return msg, ok
+}
+
+// Check returns an error listing every key that lacks a translation in one
+// of the supported languages
+func Check(keys []string) error {
+	var missing []string
+	for _, lang := range Languages {
+		for _, key := range keys {
+			if messages[lang][key] == "" {
+				missing = append(missing, lang+":"+key)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("missing translations: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package i18n
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", En},
+		{"vi", Vi},
+		{"vi-VN,vi;q=0.9,en;q=0.8", Vi},
+		{"en-US,vi;q=0.5", En},
+		{"fr-FR,vi;q=0.3", Vi},
+		{"fr-FR,de;q=0.9", En},
+		{"en;q=0.2,VI_vn;q=0.7", Vi},
+		{"vi;q=abc,en;q=0.1", En},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := Match(tt.header); got != tt.want {
+				t.Errorf("Match(%q) = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != Default {
+		t.Errorf("FromContext(empty) = %q, want %q", got, Default)
+	}
+	if got := FromContext(NewContext(context.Background(), Vi)); got != Vi {
+		t.Errorf("FromContext = %q, want %q", got, Vi)
+	}
+}
+
+func TestT(t *testing.T) {
+	if _, ok := T(Vi, "no.such.key"); ok {
+		t.Error("T found a missing key")
+	}
+	en, _ := T(En, "error.bot_full")
+	vi, _ := T(Vi, "error.bot_full")
+	if en == "" || vi == "" || en == vi {
+		t.Errorf("error.bot_full = %q (en), %q (vi), want two translations", en, vi)
+	}
+	if got, _ := T("fr", "error.bot_full"); got != en {
+		t.Errorf("T(fr) = %q, want the English fallback %q", got, en)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if err := Check([]string{"error.bot_full"}); err != nil {
+		t.Errorf("Check = %v", err)
+	}
+	err := Check([]string{"error.bot_full", "error.no_such_key"})
+	if err == nil || !strings.Contains(err.Error(), "en:error.no_such_key") || !strings.Contains(err.Error(), "vi:error.no_such_key") {
+		t.Errorf("Check = %v, want the missing key in both languages", err)
+	}
+}
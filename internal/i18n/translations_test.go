@@ -0,0 +1,51 @@
+package i18n_test
+
+import (
+	"strings"
+	"testing"
+
+	"wata-bot-BE/internal/i18n"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/notify"
+	"wata-bot-BE/internal/validate"
+)
+
+// TestTranslationsComplete fails when an error, notification or validation
+// message lacks a translation in one of the supported languages
+func TestTranslationsComplete(t *testing.T) {
+	var errorKeys []string
+	for _, spec := range model.ErrorSpecs() {
+		errorKeys = append(errorKeys, spec.Key)
+	}
+
+	tests := []struct {
+		name string
+		keys []string
+	}{
+		{name: "error catalog", keys: errorKeys},
+		{name: "notification templates", keys: notify.MessageKeys()},
+		{name: "validation messages", keys: validate.MessageKeys()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.keys) == 0 {
+				t.Fatal("no keys to check")
+			}
+			if err := i18n.Check(tt.keys); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCheckReportsMissingKeys(t *testing.T) {
+	err := i18n.Check([]string{"error.does_not_exist"})
+	if err == nil {
+		t.Fatal("Check accepted a key without translations")
+	}
+	for _, lang := range i18n.Languages {
+		if want := lang + ":error.does_not_exist"; !strings.Contains(err.Error(), want) {
+			t.Errorf("Check error %q does not list %s", err, want)
+		}
+	}
+}
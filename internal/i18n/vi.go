@@ -0,0 +1,67 @@
+package i18n
+
+var vi = map[string]string{
+	// Errors, keyed by the error catalog
+	"error.invalid_address_format":   "định dạng địa chỉ ví không hợp lệ",
+	"error.invalid_signature":        "chữ ký không hợp lệ",
+	"error.invalid_message":          "thông điệp không hợp lệ",
+	"error.invalid_request":          "yêu cầu không hợp lệ",
+	"error.token_generation_failed":  "không thể tạo token",
+	"error.unauthorized":             "chưa xác thực",
+	"error.forbidden":                "không có quyền truy cập",
+	"error.database_error":           "lỗi cơ sở dữ liệu",
+	"error.failed_to_create_user":    "không thể tạo người dùng",
+	"error.failed_to_find_user":      "không thể tìm người dùng",
+	"error.invalid_currency":         "loại tiền không hợp lệ. Phải là 'wata' hoặc 'usdt'",
+	"error.invalid_amount":           "số tiền không hợp lệ",
+	"error.insufficient_balance":     "số dư không đủ",
+	"error.failed_to_update_balance": "không thể cập nhật số dư",
+	"error.bot_not_found":            "không tìm thấy bot",
+	"error.invalid_performance_data": "dữ liệu hiệu suất không hợp lệ",
+	"error.invalid_trade_data":       "dữ liệu giao dịch của bot không hợp lệ",
+	"error.subscription_not_found":   "không tìm thấy gói đăng ký",
+	"error.invalid_auto_renew":       "cài đặt tự động gia hạn không hợp lệ",
+	"error.bot_full":                 "bot đã đủ chỗ, hãy vào danh sách chờ để được mời khi có chỗ trống",
+	"error.invalid_duration":         "thời hạn không hợp lệ, phải là một trong durationDays của bot",
+	"error.internal_server_error":    "lỗi máy chủ nội bộ",
+
+	"error.invalid_notification_channel": "kênh thông báo không hợp lệ. Phải là 'email', 'telegram' hoặc 'webhook'",
+	"error.invalid_notification_target":  "địa chỉ nhận thông báo không hợp lệ",
+	"error.invalid_notification_event":   "sự kiện thông báo không hợp lệ",
+	"error.webhook_not_found":            "không tìm thấy webhook endpoint",
+	"error.invalid_webhook_url":          "URL webhook không hợp lệ. Phải là URL http(s)",
+	"error.invalid_webhook_event":        "sự kiện webhook không hợp lệ",
+	"error.webhook_delivery_not_found":   "không tìm thấy lần gửi webhook",
+	"error.webhook_delivery_pending":     "lần gửi webhook vẫn đang chờ xử lý",
+	"error.invalid_delivery_query":       "truy vấn lần gửi webhook không hợp lệ",
+	"error.invalid_stream_topic":         "topic stream không hợp lệ. Phải là 'balances', 'transactions' hoặc 'bot:<id>'",
+	"error.too_many_requests":            "quá nhiều yêu cầu, hãy thử lại sau thời gian trong header Retry-After",
+	"error.not_found":                    "không tìm thấy",
+	"error.conflict":                     "yêu cầu xung đột với trạng thái hiện tại của tài nguyên",
+	"error.user_not_found":               "không tìm thấy người dùng",
+	"error.invalid_language":             "ngôn ngữ không hợp lệ. Phải là 'en' hoặc 'vi'",
+	"error.unsupported_chain":            "chain không được hỗ trợ, xem /api/chains",
+	"error.currency_not_on_chain":        "loại tiền không có trên chain này",
+
+	// Request validation, keyed by rule; %s is the rule parameter
+	"validation.required": "không được để trống",
+	"validation.address":  "phải là 0x theo sau bởi 40 ký tự hex",
+	"validation.amount":   "phải là số thập phân dương, ví dụ 10.5",
+	"validation.currency": "phải là 'wata' hoặc 'usdt'",
+	"validation.duration": "phải là số ngày từ 1 đến 3650",
+	"validation.botid":    "phải là bot id tối đa 20 ký tự gồm chữ, số, '-' hoặc '_'",
+	"validation.min":      "phải lớn hơn hoặc bằng %s",
+	"validation.max":      "phải nhỏ hơn hoặc bằng %s",
+
+	// Notifications, keyed by event; fields come from the notification data
+	"notification.deposit_completed.subject":    "Đã nhận tiền nạp",
+	"notification.deposit_completed.body":       "Khoản nạp {{.amount}} {{.currency}} của bạn đã được ghi có. Số dư mới: {{.balance}} {{.currency}}.",
+	"notification.withdraw_completed.subject":   "Rút tiền hoàn tất",
+	"notification.withdraw_completed.body":      "Yêu cầu rút {{.amount}} {{.currency}} của bạn đã được xử lý. Số dư còn lại: {{.balance}} {{.currency}}.",
+	"notification.subscription_settled.subject": "Vị thế {{.botName}} đã tất toán",
+	"notification.subscription_settled.body":    "Vị thế {{.amount}} USDT của bạn trong {{.botName}} đã đáo hạn sau {{.durationDays}} ngày và được tất toán ở mức {{.finalValue}} USDT.",
+	"notification.subscription_renewed.subject": "Vị thế {{.botName}} đã gia hạn",
+	"notification.subscription_renewed.body":    "Vị thế của bạn trong {{.botName}} đã được gia hạn sang kỳ mới {{.durationDays}} ngày với {{.amount}} USDT.",
+	"notification.waitlist_offered.subject":     "{{.botName}} đã có chỗ trống",
+	"notification.waitlist_offered.body":        "Một suất {{.amount}} USDT trong {{.botName}} được giữ cho bạn đến {{.expiresAt}}. Hãy đăng ký trước thời điểm đó để nhận suất.",
+}
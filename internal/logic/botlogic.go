@@ -3,8 +3,11 @@ package logic
 import (
 	"context"
 	"encoding/json"
+	"strconv"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"wata-bot-BE/internal/address"
 	"wata-bot-BE/internal/model"
 	"wata-bot-BE/internal/svc"
 	"wata-bot-BE/internal/types"
@@ -26,7 +29,7 @@ func NewBotLogic(ctx context.Context, svcCtx *svc.ServiceContext) *BotLogic {
 
 func (l *BotLogic) Bots() (resp *types.BotsResp, err error) {
 	// Query all active bots from database
-	dbBots, err := l.svcCtx.BotModel.FindAllActive()
+	dbBots, err := l.svcCtx.BotModel.FindAllActive(l.ctx)
 	if err != nil {
 		if err == model.ErrNotFound {
 			l.logger.Infof("No bots found in database")
@@ -93,3 +96,228 @@ func (l *BotLogic) Bots() (resp *types.BotsResp, err error) {
 	}, nil
 }
 
+
+// BotDetail returns a single bot with live stats and, when an address is given,
+// the requesting user's own subscription state
+func (l *BotLogic) BotDetail(req *types.BotDetailReq) (resp *types.BotDetailResp, err error) {
+	// FindOne is served from the bot id cache key
+	bot, err := l.svcCtx.BotModel.FindOne(l.ctx, req.Id)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeBotNotFound, model.ErrMsgBotNotFound)
+		}
+		l.logger.Errorf("Failed to find bot %s: %v", req.Id, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	// Live subscriber count instead of the denormalized bot.Subscribers column
+	subscriberCount, err := l.svcCtx.UserBotSubscriptionModel.CountByBotId(l.ctx, bot.Id)
+	if err != nil {
+		l.logger.Errorf("Failed to count subscribers for bot %s: %v", bot.Id, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	totalValueLocked, err := l.svcCtx.UserBotSubscriptionModel.SumAmountByBotId(l.ctx, bot.Id)
+	if err != nil {
+		l.logger.Errorf("Failed to sum subscription amounts for bot %s: %v", bot.Id, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	tvl, err := strconv.ParseFloat(totalValueLocked, 64)
+	if err == nil {
+		totalValueLocked = formatDecimal(tvl)
+	}
+
+	waitlistLength, err := l.svcCtx.BotWaitlistModel.CountWaitingByBotId(l.ctx, bot.Id)
+	if err != nil {
+		l.logger.Errorf("Failed to count waitlist for bot %s: %v", bot.Id, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	// Anyone already queued goes first, so the bot is full for newcomers too
+	full := waitlistLength > 0 ||
+		(bot.MaxAllocation > 0 && tvl+float64(bot.MinInvestment) > bot.MaxAllocation) ||
+		(bot.MaxSubscribers > 0 && subscriberCount >= int64(bot.MaxSubscribers))
+
+	durationDays, err := parseDurationDays(bot.DurationDays)
+	if err != nil {
+		l.logger.Errorf("Failed to parse duration_days for bot %s: %v", bot.Id, err)
+	}
+
+	data := types.BotDetailData{
+		Bot: botToAPI(bot, durationDays),
+		Stats: types.BotStats{
+			SubscriberCount:  subscriberCount,
+			TotalValueLocked: totalValueLocked,
+			Full:             full,
+			WaitlistLength:   waitlistLength,
+		},
+	}
+
+	if req.Address != "" {
+		state, err := l.subscriptionState(req.Address, bot.Id)
+		if err != nil {
+			return nil, err
+		}
+		data.Subscription = state
+	}
+
+	return &types.BotDetailResp{
+		Message: "success",
+		Data:    data,
+	}, nil
+}
+
+// BotChangelog returns every version of the bot's terms, newest first
+func (l *BotLogic) BotChangelog(req *types.BotChangelogReq) (resp *types.BotChangelogResp, err error) {
+	if _, err := l.svcCtx.BotModel.FindOne(l.ctx, req.Id); err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeBotNotFound, model.ErrMsgBotNotFound)
+		}
+		l.logger.Errorf("Failed to find bot %s: %v", req.Id, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	versions, err := l.svcCtx.BotVersionModel.FindByBotId(l.ctx, req.Id)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find versions for bot %s: %v", req.Id, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	entries := make([]types.BotVersionEntry, 0, len(versions))
+	for _, v := range versions {
+		durationDays, err := parseDurationDays(v.DurationDays)
+		if err != nil {
+			l.logger.Errorf("Failed to parse duration_days for bot %s version %d: %v", v.BotId, v.Version, err)
+		}
+		entries = append(entries, types.BotVersionEntry{
+			Version:   v.Version,
+			CreatedAt: v.CreatedAt.Format(time.RFC3339),
+			Changes:   termChangesToAPI(v.ParseChanges()),
+			Terms: types.BotTerms{
+				RiskLevel:             v.RiskLevel,
+				DurationDays:          durationDays,
+				ExpectedReturnPercent: v.ExpectedReturnPercent,
+				AprDisplay:            v.AprDisplay,
+				MinInvestment:         v.MinInvestment,
+				MaxInvestment:         v.MaxInvestment,
+				LockupPeriod:          v.LockupPeriod,
+				ExpectedReturn:        v.ExpectedReturn,
+				TradingPair:           v.TradingPair,
+			},
+		})
+	}
+
+	return &types.BotChangelogResp{
+		Message: "success",
+		Data:    entries,
+	}, nil
+}
+
+func termChangesToAPI(changes []model.BotTermChange) []types.BotTermChange {
+	resp := make([]types.BotTermChange, 0, len(changes))
+	for _, c := range changes {
+		resp = append(resp, types.BotTermChange{Field: c.Field, From: c.From, To: c.To})
+	}
+	return resp
+}
+
+// subscriptionState looks up whether the user behind addr is subscribed to botId
+func (l *BotLogic) subscriptionState(addr address.Address, botId string) (*types.BotSubscriptionState, error) {
+	user, err := l.svcCtx.UserModel.FindOneByAddress(l.ctx, addr)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return &types.BotSubscriptionState{Subscribed: false}, nil
+		}
+		l.logger.Errorf("Failed to find user by address: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
+	}
+
+	active, err := l.svcCtx.UserBotSubscriptionModel.FindActiveByUserIdAndBotId(l.ctx, user.Id, botId)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find subscription: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	if len(active) == 0 {
+		return &types.BotSubscriptionState{Subscribed: false}, nil
+	}
+
+	// Report the combined amount of all active positions and the latest term chosen
+	var total float64
+	for _, sub := range active {
+		if amount, err := strconv.ParseFloat(sub.Amount, 64); err == nil {
+			total += amount
+		}
+	}
+	latest := active[len(active)-1]
+	durationDay, _ := strconv.Atoi(latest.DurationDay)
+	return &types.BotSubscriptionState{
+		Subscribed:      true,
+		ActivePositions: len(active),
+		DurationDays:    durationDay,
+		Amount:          formatDecimal(total),
+		SubscribedAt:    active[0].StartedAt.Format(time.RFC3339),
+	}, nil
+}
+
+// defaultDurationDays is used when a bot has no valid duration_days array
+var defaultDurationDays = []int{5, 15, 30, 60, 90, 180}
+
+// parseDurationDays decodes the duration_days JSON array stored on a bot,
+// falling back to defaultDurationDays when it is empty or malformed
+func parseDurationDays(raw string) ([]int, error) {
+	if raw == "" {
+		return defaultDurationDays, nil
+	}
+	var durationDays []int
+	if err := json.Unmarshal([]byte(raw), &durationDays); err != nil {
+		return defaultDurationDays, err
+	}
+	return durationDays, nil
+}
+
+// isOfferedDuration reports whether days is one of the bot's durationDays
+func isOfferedDuration(bot *model.Bot, days int) bool {
+	if days <= 0 {
+		return false
+	}
+	durationDays, _ := parseDurationDays(bot.DurationDays)
+	for _, d := range durationDays {
+		if d == days {
+			return true
+		}
+	}
+	return false
+}
+
+// botToAPI converts a bot row to its API representation
+func botToAPI(bot *model.Bot, durationDays []int) types.Bot {
+	return types.Bot{
+		Id:                    bot.Id,
+		Name:                  bot.Name,
+		IconLetter:            bot.IconLetter,
+		RiskLevel:             bot.RiskLevel,
+		DurationDays:          durationDays,
+		ExpectedReturnPercent: bot.ExpectedReturnPercent,
+		AprDisplay:            bot.AprDisplay,
+		MinInvestment:         bot.MinInvestment,
+		MaxInvestment:         bot.MaxInvestment,
+		InvestmentRange:       bot.InvestmentRange,
+		Subscribers:           bot.Subscribers,
+		Author:                bot.Author,
+		Description:           bot.Description,
+		IsActive:              bot.IsActive,
+		MaxAllocation:         bot.MaxAllocation,
+		MaxSubscribers:        bot.MaxSubscribers,
+		Version:               bot.Version,
+		Metrics: types.BotMetrics{
+			LockupPeriod:   bot.LockupPeriod,
+			ExpectedReturn: bot.ExpectedReturn,
+			MinInvestment:  bot.MinInvestmentDisplay,
+			MaxInvestment:  bot.MaxInvestmentDisplay,
+			Roi30d:         bot.Roi30d,
+			WinRate:        bot.WinRate,
+			TradingPair:    bot.TradingPair,
+			TotalTrades:    bot.TotalTrades,
+			Pnl30d:         bot.Pnl30d,
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package logic
+
+import (
+	"context"
+
+	"wata-bot-BE/internal/chain"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type ChainLogic struct {
+	logger logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewChainLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ChainLogic {
+	return &ChainLogic{
+		logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Chains lists the supported networks with their token contracts, so clients
+// know where to send deposits and which chain_id to pass
+func (l *ChainLogic) Chains() (resp *types.ChainsResp, err error) {
+	defaultId := l.svcCtx.Chains.Default().Id
+	var data []types.Chain
+	for _, c := range l.svcCtx.Chains.All() {
+		data = append(data, types.Chain{
+			Id:            c.Id,
+			Name:          c.Name,
+			Confirmations: c.Confirmations,
+			Tokens:        c.Tokens,
+			Default:       c.Id == defaultId,
+		})
+	}
+	return &types.ChainsResp{
+		Message: "success",
+		Data:    data,
+	}, nil
+}
+
+// resolveChain returns the network a request targets. A chain id given by the
+// client must be supported; without one, the user's chain is used while it is
+// still configured, and the default network otherwise.
+func resolveChain(chains *chain.Registry, requested, userChainId int64) (chain.Conf, error) {
+	if requested != 0 {
+		c, ok := chains.Find(requested)
+		if !ok {
+			return chain.Conf{}, model.NewAPIError(model.ErrCodeUnsupportedChain, model.ErrMsgUnsupportedChain)
+		}
+		return c, nil
+	}
+	if c, ok := chains.Find(userChainId); ok {
+		return c, nil
+	}
+	return chains.Default(), nil
+}
@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"errors"
+	"testing"
+
+	"wata-bot-BE/internal/chain"
+	"wata-bot-BE/internal/model"
+)
+
+func TestResolveChain(t *testing.T) {
+	chains := chain.NewRegistry([]chain.Conf{
+		{Id: 1, Name: "Ethereum", Tokens: map[string]string{"usdt": "0xdAC17F958D2ee523a2206206994597C13D831ec7"}},
+		{Id: 56, Name: "BNB Chain", Tokens: map[string]string{"usdt": "0xdAC17F958D2ee523a2206206994597C13D831ec7"}},
+	})
+
+	tests := []struct {
+		name        string
+		requested   int64
+		userChainId int64
+		want        int64
+		wantCode    string
+	}{
+		{name: "requested", requested: 56, userChainId: 1, want: 56},
+		{name: "requested unsupported", requested: 137, userChainId: 1, wantCode: model.ErrCodeUnsupportedChain},
+		{name: "requested negative", requested: -1, wantCode: model.ErrCodeUnsupportedChain},
+		{name: "user chain", userChainId: 56, want: 56},
+		{name: "user chain no longer configured", userChainId: 137, want: 1},
+		{name: "no chain", want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveChain(chains, tt.requested, tt.userChainId)
+			if tt.wantCode != "" {
+				var apiErr *model.APIError
+				if !errors.As(err, &apiErr) || apiErr.Code != tt.wantCode {
+					t.Fatalf("resolveChain() error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveChain() error = %v", err)
+			}
+			if got.Id != tt.want {
+				t.Errorf("resolveChain() = chain %d, want %d", got.Id, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package logic
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"wata-bot-BE/internal/event"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/notify"
+	"wata-bot-BE/internal/svc"
+)
+
+// RegisterEventHandlers subscribes the in-process reactions to domain events.
+// Events are delivered at least once, so every handler must be idempotent.
+func RegisterEventHandlers(svcCtx *svc.ServiceContext) {
+	bus := svcCtx.EventBus
+
+	bus.Subscribe(model.EventDepositCompleted, "notify-deposit", func(ctx context.Context, evt event.Event) error {
+		return notifyBalanceChange(ctx, svcCtx, evt, notify.EventDepositCompleted)
+	})
+	bus.Subscribe(model.EventWithdrawRequested, "notify-withdraw", func(ctx context.Context, evt event.Event) error {
+		return notifyBalanceChange(ctx, svcCtx, evt, notify.EventWithdrawCompleted)
+	})
+
+	// A cancelled position frees capacity for waitlisted users
+	bus.Subscribe(model.EventUnsubscribed, "offer-waitlist-slots", func(ctx context.Context, evt event.Event) error {
+		var payload model.UnsubscribedEvent
+		if err := evt.Decode(&payload); err != nil {
+			return err
+		}
+		NewWaitlistLogic(ctx, svcCtx).OfferSlots(payload.BotId)
+		return nil
+	})
+
+	// Connected clients see deposits, withdrawals and the new balances live
+	for _, eventType := range []string{model.EventDepositCompleted, model.EventWithdrawRequested} {
+		bus.Subscribe(eventType, "stream-balance", func(ctx context.Context, evt event.Event) error {
+			var payload model.BalanceEvent
+			if err := evt.Decode(&payload); err != nil {
+				return err
+			}
+			return NewStreamLogic(ctx, svcCtx).PublishBalanceChange(evt.Type, payload)
+		})
+	}
+
+	// Every event is forwarded to the partner endpoints subscribed to it
+	bus.Subscribe(event.AllEvents, "queue-webhooks", func(ctx context.Context, evt event.Event) error {
+		return NewWebhookLogic(ctx, svcCtx).QueueWebhookDeliveries(evt)
+	})
+}
+
+func notifyBalanceChange(ctx context.Context, svcCtx *svc.ServiceContext, evt event.Event, notification string) error {
+	var payload model.BalanceEvent
+	if err := evt.Decode(&payload); err != nil {
+		return err
+	}
+	return NewNotificationLogic(ctx, svcCtx).NotifyOnce("event:"+strconv.FormatInt(evt.Id, 10), payload.UserId, notification, map[string]string{
+		"amount":   payload.Amount,
+		"currency": strings.ToUpper(payload.Currency),
+		"balance":  payload.BalanceAfter,
+	})
+}
@@ -0,0 +1,76 @@
+package logic
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"wata-bot-BE/internal/health"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type HealthLogic struct {
+	logger logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewHealthLogic(ctx context.Context, svcCtx *svc.ServiceContext) *HealthLogic {
+	return &HealthLogic{
+		logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Liveness reports that the process is serving requests; it checks no
+// dependencies, so an outage of MySQL or Redis does not get the service
+// restarted
+func (l *HealthLogic) Liveness() (resp *types.HealthResp, err error) {
+	return &types.HealthResp{
+		Status: health.StatusUp,
+	}, nil
+}
+
+// Health checks every dependency and reports each one's status and latency
+func (l *HealthLogic) Health() (resp *types.HealthResp, err error) {
+	return l.check(), nil
+}
+
+// Readiness is Health, except that it reports shutting_down without checking
+// anything once graceful shutdown has started
+func (l *HealthLogic) Readiness() (resp *types.HealthResp, err error) {
+	if l.svcCtx.Health.ShuttingDown() {
+		return &types.HealthResp{
+			Status: health.StatusShuttingDown,
+		}, nil
+	}
+	return l.check(), nil
+}
+
+func (l *HealthLogic) check() *types.HealthResp {
+	results, healthy := l.svcCtx.Health.Check(l.ctx)
+
+	resp := &types.HealthResp{
+		Status: health.StatusUp,
+		Checks: make([]types.DependencyCheck, 0, len(results)),
+	}
+	if !healthy {
+		resp.Status = health.StatusDown
+	}
+	for _, res := range results {
+		if res.Status != health.StatusUp {
+			l.logger.Errorf("Health check %s failed after %v: %s", res.Name, res.Latency, res.Error)
+		}
+		resp.Checks = append(resp.Checks, types.DependencyCheck{
+			Name:      res.Name,
+			Status:    res.Status,
+			LatencyMs: math.Round(float64(res.Latency)/float64(time.Millisecond)*1000) / 1000,
+			Error:     res.Error,
+		})
+	}
+	return resp
+}
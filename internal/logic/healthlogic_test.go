@@ -0,0 +1,42 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"wata-bot-BE/internal/health"
+	"wata-bot-BE/internal/svc"
+)
+
+func TestHealthLogic(t *testing.T) {
+	checker := health.NewChecker(time.Second)
+	checker.Add("mysql", func(ctx context.Context) error { return nil })
+	checker.Add("redis", func(ctx context.Context) error { return errors.New("connection refused") })
+	l := NewHealthLogic(context.Background(), &svc.ServiceContext{Health: checker})
+
+	resp, _ := l.Liveness()
+	if resp.Status != health.StatusUp || len(resp.Checks) != 0 {
+		t.Errorf("Liveness() = %+v, want up without checks", resp)
+	}
+
+	resp, _ = l.Health()
+	if resp.Status != health.StatusDown || len(resp.Checks) != 2 {
+		t.Fatalf("Health() = %+v, want down with 2 checks", resp)
+	}
+	if c := resp.Checks[1]; c.Name != "redis" || c.Status != health.StatusDown || c.Error != "connection refused" {
+		t.Errorf("Checks[1] = %+v, want redis down", c)
+	}
+
+	resp, _ = l.Readiness()
+	if resp.Status != health.StatusDown {
+		t.Errorf("Readiness() status = %s, want %s", resp.Status, health.StatusDown)
+	}
+
+	checker.SetShuttingDown()
+	resp, _ = l.Readiness()
+	if resp.Status != health.StatusShuttingDown || len(resp.Checks) != 0 {
+		t.Errorf("Readiness() after shutdown = %+v, want shutting_down without checks", resp)
+	}
+}
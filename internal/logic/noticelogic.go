@@ -0,0 +1,104 @@
+package logic
+
+import (
+	"context"
+	"time"
+
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxNotices bounds how many notices are returned at once
+const maxNotices = 100
+
+type NoticeLogic struct {
+	logger logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewNoticeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NoticeLogic {
+	return &NoticeLogic{
+		logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// GetBotNotices returns the strategy change notices generated for the user's
+// subscriptions, newest first
+func (l *NoticeLogic) GetBotNotices(req *types.GetBotNoticesReq) (resp *types.BotNoticesResp, err error) {
+	user, err := l.svcCtx.UserModel.FindOneByAddress(l.ctx, req.Address)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return &types.BotNoticesResp{
+				Message: "success",
+				Data:    []types.BotNotice{},
+			}, nil
+		}
+		l.logger.Errorf("Failed to find user by address: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
+	}
+
+	notices, err := l.svcCtx.BotChangeNoticeModel.FindByUserId(l.ctx, user.Id, req.UnreadOnly, maxNotices)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find notices: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	data := make([]types.BotNotice, 0, len(notices))
+	for _, n := range notices {
+		notice := types.BotNotice{
+			Id:          n.Id,
+			BotId:       n.BotId,
+			FromVersion: n.FromVersion,
+			ToVersion:   n.ToVersion,
+			Changes:     []types.BotTermChange{},
+			Read:        n.ReadAt.Valid,
+			CreatedAt:   n.CreatedAt.Format(time.RFC3339),
+		}
+		if bot, err := l.svcCtx.BotModel.FindOne(l.ctx, n.BotId); err == nil {
+			notice.BotName = bot.Name
+		}
+		version, err := l.svcCtx.BotVersionModel.FindOneByBotIdAndVersion(l.ctx, n.BotId, n.ToVersion)
+		if err != nil && err != model.ErrNotFound {
+			l.logger.Errorf("Failed to find bot %s version %d: %v", n.BotId, n.ToVersion, err)
+			return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+		}
+		if version != nil {
+			notice.Changes = termChangesToAPI(version.ParseChanges())
+		}
+		data = append(data, notice)
+	}
+
+	return &types.BotNoticesResp{
+		Message: "success",
+		Data:    data,
+	}, nil
+}
+
+// MarkBotNoticesRead marks the given notices, or all of them, as read
+func (l *NoticeLogic) MarkBotNoticesRead(req *types.MarkBotNoticesReadReq) (resp *types.MarkBotNoticesReadResp, err error) {
+	user, err := l.svcCtx.UserModel.FindOneByAddress(l.ctx, req.Address)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeUserNotFound, model.ErrMsgUserNotFound)
+		}
+		l.logger.Errorf("Failed to find user by address: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
+	}
+
+	updated, err := l.svcCtx.BotChangeNoticeModel.MarkRead(l.ctx, user.Id, req.Ids, time.Now())
+	if err != nil {
+		l.logger.Errorf("Failed to mark notices read: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	return &types.MarkBotNoticesReadResp{
+		Message: "success",
+		Updated: updated,
+	}, nil
+}
@@ -0,0 +1,142 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/notify"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// deliveryLease is how long a claimed delivery is hidden from other dispatchers
+// while it is being sent
+const deliveryLease = time.Minute
+
+// NotificationDispatchLogic sends queued notification deliveries over their
+// channels. Failed attempts are retried with exponential backoff until
+// Notification.MaxAttempts is reached. Deliveries are claimed before sending,
+// so running it on several replicas at once is safe.
+type NotificationDispatchLogic struct {
+	logger logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func NewNotificationDispatchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationDispatchLogic {
+	return &NotificationDispatchLogic{
+		logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		done:   make(chan struct{}),
+	}
+}
+
+// Start runs a dispatch pass every interval until Stop is called
+func (l *NotificationDispatchLogic) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		l.RunOnce()
+		select {
+		case <-ticker.C:
+		case <-l.done:
+			return
+		}
+	}
+}
+
+func (l *NotificationDispatchLogic) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.done)
+	})
+}
+
+// RunOnce sends the deliveries that are due
+func (l *NotificationDispatchLogic) RunOnce() {
+	conf := l.svcCtx.Config.Notification
+	due, err := l.svcCtx.NotificationDeliveryModel.FindDue(l.ctx, time.Now(), conf.BatchSize)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find due notification deliveries: %v", err)
+		return
+	}
+
+	sent, failed := 0, 0
+	for _, delivery := range due {
+		ok, err := l.svcCtx.NotificationDeliveryModel.Claim(l.ctx, delivery.Id, time.Now(), deliveryLease)
+		if err != nil {
+			l.logger.Errorf("Failed to claim notification delivery %d: %v", delivery.Id, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if l.deliver(delivery) {
+			sent++
+		} else {
+			failed++
+		}
+	}
+	if sent > 0 || failed > 0 {
+		l.logger.Infof("Notification dispatch pass: %d sent, %d failed", sent, failed)
+	}
+}
+
+// deliver makes one attempt and records its outcome
+func (l *NotificationDispatchLogic) deliver(delivery *model.NotificationDelivery) bool {
+	err := l.send(delivery)
+	if err == nil {
+		if err := l.svcCtx.NotificationDeliveryModel.MarkSent(l.ctx, delivery.Id, time.Now()); err != nil {
+			l.logger.Errorf("Failed to mark notification delivery %d sent: %v", delivery.Id, err)
+		}
+		return true
+	}
+
+	conf := l.svcCtx.Config.Notification
+	attempt := delivery.Attempts + 1
+	final := attempt >= conf.MaxAttempts || err == notify.ErrNotConfigured
+	retryAt := time.Now().Add(utils.Backoff(attempt, conf.RetryBase, conf.RetryMax))
+	if final {
+		l.logger.Errorf("Giving up on notification delivery %d over %s after %d attempts: %v", delivery.Id, delivery.Channel, attempt, err)
+	} else {
+		l.logger.Infof("Notification delivery %d over %s failed, retrying at %s: %v", delivery.Id, delivery.Channel, retryAt.Format(time.RFC3339), err)
+	}
+	if err := l.svcCtx.NotificationDeliveryModel.MarkFailed(l.ctx, delivery.Id, err.Error(), retryAt, final); err != nil {
+		l.logger.Errorf("Failed to record notification delivery %d failure: %v", delivery.Id, err)
+	}
+	return false
+}
+
+func (l *NotificationDispatchLogic) send(delivery *model.NotificationDelivery) error {
+	sender, ok := l.svcCtx.Notifiers[delivery.Channel]
+	if !ok {
+		return notify.ErrNotConfigured
+	}
+
+	notification, err := l.svcCtx.NotificationModel.FindOne(l.ctx, delivery.NotificationId)
+	if err != nil {
+		return err
+	}
+	var data map[string]string
+	if notification.Data != "" {
+		json.Unmarshal([]byte(notification.Data), &data)
+	}
+
+	ctx, cancel := context.WithTimeout(l.ctx, deliveryLease/2)
+	defer cancel()
+	return sender.Send(ctx, delivery.Target, notify.Message{
+		Event:   notification.Event,
+		Subject: notification.Title,
+		Body:    notification.Body,
+		Data:    data,
+	})
+}
@@ -0,0 +1,292 @@
+package logic
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/mail"
+	"strings"
+	"time"
+
+	"wata-bot-BE/internal/address"
+	"wata-bot-BE/internal/i18n"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/notify"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxNotifications bounds how many inbox entries are returned at once
+const maxNotifications = 100
+
+type NotificationLogic struct {
+	logger logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewNotificationLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NotificationLogic {
+	return &NotificationLogic{
+		logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Notify renders the event's template, stores it in the user's inbox and queues
+// a delivery for every channel the user opted into. Failures are logged and
+// never fail the operation that triggered the notification.
+func (l *NotificationLogic) Notify(userId int64, event string, data map[string]string) {
+	if err := l.NotifyOnce("", userId, event, data); err != nil {
+		l.logger.Errorf("Failed to store %s notification for user %d: %v", event, userId, err)
+	}
+}
+
+// NotifyOnce is Notify for callers that may run more than once for the same
+// occurrence, such as domain event handlers: a second call with the same key
+// is a no-op. An empty key disables the check.
+func (l *NotificationLogic) NotifyOnce(key string, userId int64, event string, data map[string]string) error {
+	msg, err := notify.Render(event, l.userLanguage(userId), data)
+	if err != nil {
+		return err
+	}
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	prefs, err := l.svcCtx.NotificationPreferenceModel.FindByUserId(l.ctx, userId)
+	if err != nil && err != model.ErrNotFound {
+		// Still store the inbox entry; only external delivery is lost
+		l.logger.Errorf("Failed to find notification preferences for user %d: %v", userId, err)
+	}
+	var deliveries []*model.NotificationDelivery
+	for _, pref := range prefs {
+		if pref.Wants(event) {
+			deliveries = append(deliveries, &model.NotificationDelivery{
+				Channel: pref.Channel,
+				Target:  pref.Target,
+			})
+		}
+	}
+
+	_, err = l.svcCtx.NotificationModel.Insert(l.ctx, &model.Notification{
+		UserId:    userId,
+		Event:     event,
+		Title:     msg.Subject,
+		Body:      msg.Body,
+		Data:      string(dataJSON),
+		DedupeKey: sql.NullString{String: key, Valid: key != ""},
+	}, deliveries)
+	return err
+}
+
+// GetNotifications returns the user's inbox, newest first
+func (l *NotificationLogic) GetNotifications(req *types.GetNotificationsReq) (resp *types.NotificationsResp, err error) {
+	user, err := l.svcCtx.UserModel.FindOneByAddress(l.ctx, req.Address)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return &types.NotificationsResp{
+				Message: "success",
+				Data:    []types.Notification{},
+			}, nil
+		}
+		l.logger.Errorf("Failed to find user by address: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
+	}
+
+	notifications, err := l.svcCtx.NotificationModel.FindByUserId(l.ctx, user.Id, req.UnreadOnly, maxNotifications)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find notifications: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	unread, err := l.svcCtx.NotificationModel.CountUnread(l.ctx, user.Id)
+	if err != nil {
+		l.logger.Errorf("Failed to count unread notifications: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	data := make([]types.Notification, 0, len(notifications))
+	for _, n := range notifications {
+		var fields map[string]string
+		if n.Data != "" {
+			json.Unmarshal([]byte(n.Data), &fields)
+		}
+		data = append(data, types.Notification{
+			Id:        n.Id,
+			Event:     n.Event,
+			Title:     n.Title,
+			Body:      n.Body,
+			Data:      fields,
+			Read:      n.ReadAt.Valid,
+			CreatedAt: n.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return &types.NotificationsResp{
+		Message: "success",
+		Unread:  unread,
+		Data:    data,
+	}, nil
+}
+
+// MarkNotificationsRead marks the given inbox entries, or all of them, as read
+func (l *NotificationLogic) MarkNotificationsRead(req *types.MarkNotificationsReadReq) (resp *types.MarkNotificationsReadResp, err error) {
+	user, err := l.findUser(req.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := l.svcCtx.NotificationModel.MarkRead(l.ctx, user.Id, req.Ids, time.Now())
+	if err != nil {
+		l.logger.Errorf("Failed to mark notifications read: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	return &types.MarkNotificationsReadResp{
+		Message: "success",
+		Updated: updated,
+	}, nil
+}
+
+// GetNotificationPreferences returns the user's external channel settings
+func (l *NotificationLogic) GetNotificationPreferences(req *types.GetNotificationPreferencesReq) (resp *types.NotificationPreferencesResp, err error) {
+	resp = &types.NotificationPreferencesResp{
+		Message: "success",
+		Events:  notify.Events,
+		Data:    []types.NotificationPreference{},
+	}
+
+	user, err := l.svcCtx.UserModel.FindOneByAddress(l.ctx, req.Address)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return resp, nil
+		}
+		l.logger.Errorf("Failed to find user by address: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
+	}
+
+	prefs, err := l.svcCtx.NotificationPreferenceModel.FindByUserId(l.ctx, user.Id)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find notification preferences: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	for _, pref := range prefs {
+		resp.Data = append(resp.Data, preferenceToAPI(pref))
+	}
+	return resp, nil
+}
+
+// UpdateNotificationPreference enables, disables or retargets one channel
+func (l *NotificationLogic) UpdateNotificationPreference(req *types.UpdateNotificationPreferenceReq) (resp *types.NotificationPreferenceResp, err error) {
+	channel := strings.ToLower(strings.TrimSpace(req.Channel))
+	if !notify.IsChannel(channel) {
+		return nil, model.NewAPIError(model.ErrCodeInvalidNotificationChannel, model.ErrMsgInvalidNotificationChannel)
+	}
+	for _, event := range req.Events {
+		if !notify.IsEvent(event) {
+			return nil, model.NewAPIErrorf(model.ErrCodeInvalidNotificationEvent, "invalid notification event: %s", event)
+		}
+	}
+
+	user, err := l.findUser(req.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	pref, err := l.svcCtx.NotificationPreferenceModel.FindOneByUserIdAndChannel(l.ctx, user.Id, channel)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find notification preference: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	if pref == nil {
+		pref = &model.NotificationPreference{
+			UserId:  user.Id,
+			Channel: channel,
+		}
+	}
+
+	if target := strings.TrimSpace(req.Target); target != "" {
+		if err := validateNotificationTarget(channel, target); err != nil {
+			return nil, err
+		}
+		pref.Target = target
+	}
+	if req.Enabled && pref.Target == "" {
+		return nil, model.NewAPIError(model.ErrCodeInvalidNotificationTarget, "target is required to enable a channel")
+	}
+	pref.Enabled = req.Enabled
+	pref.Events = strings.Join(req.Events, ",")
+
+	if err := l.svcCtx.NotificationPreferenceModel.Upsert(l.ctx, pref); err != nil {
+		l.logger.Errorf("Failed to save notification preference: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	return &types.NotificationPreferenceResp{
+		Message: "success",
+		Data:    preferenceToAPI(pref),
+	}, nil
+}
+
+func (l *NotificationLogic) findUser(addr address.Address) (*model.User, error) {
+	user, err := l.svcCtx.UserModel.FindOneByAddress(l.ctx, addr)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeUserNotFound, model.ErrMsgUserNotFound)
+		}
+		l.logger.Errorf("Failed to find user by address: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
+	}
+	return user, nil
+}
+
+// userLanguage returns the language the user prefers notifications in, or the
+// default language when they have not chosen one
+func (l *NotificationLogic) userLanguage(userId int64) string {
+	user, err := l.svcCtx.UserModel.FindOne(l.ctx, userId)
+	if err != nil {
+		if err != model.ErrNotFound {
+			l.logger.Errorf("Failed to find language of user %d: %v", userId, err)
+		}
+		return i18n.Default
+	}
+	if lang, ok := i18n.Normalize(user.Language); ok {
+		return lang
+	}
+	return i18n.Default
+}
+
+// validateNotificationTarget checks the target has the channel's format
+func validateNotificationTarget(channel, target string) error {
+	switch channel {
+	case notify.ChannelEmail:
+		if addr, err := mail.ParseAddress(target); err != nil || addr.Address != target {
+			return model.NewAPIError(model.ErrCodeInvalidNotificationTarget, "target must be an email address")
+		}
+	case notify.ChannelWebhook:
+		if err := notify.ValidateWebhookURL(target); err != nil {
+			return model.NewAPIError(model.ErrCodeInvalidNotificationTarget, "target must be an https URL on a public host")
+		}
+	case notify.ChannelTelegram:
+		if strings.ContainsAny(target, " \t\r\n") {
+			return model.NewAPIError(model.ErrCodeInvalidNotificationTarget, "target must be a Telegram chat id")
+		}
+	}
+	return nil
+}
+
+func preferenceToAPI(pref *model.NotificationPreference) types.NotificationPreference {
+	events := pref.EventList()
+	if events == nil {
+		events = []string{}
+	}
+	return types.NotificationPreference{
+		Channel: pref.Channel,
+		Target:  pref.Target,
+		Enabled: pref.Enabled,
+		Events:  events,
+	}
+}
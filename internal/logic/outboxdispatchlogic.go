@@ -0,0 +1,125 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"wata-bot-BE/internal/event"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// outboxLease is how long a claimed event is hidden from other dispatchers
+// while its handlers run
+const outboxLease = 2 * time.Minute
+
+// OutboxDispatchLogic delivers domain events from the outbox to the event bus.
+// An event is marked dispatched only after every handler and sink accepted it;
+// otherwise it is retried with exponential backoff, giving at-least-once
+// delivery. Events are claimed before dispatch, so running it on several
+// replicas at once is safe.
+type OutboxDispatchLogic struct {
+	logger logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func NewOutboxDispatchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *OutboxDispatchLogic {
+	return &OutboxDispatchLogic{
+		logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		done:   make(chan struct{}),
+	}
+}
+
+// Start runs a dispatch pass every interval until Stop is called
+func (l *OutboxDispatchLogic) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		l.RunOnce()
+		select {
+		case <-ticker.C:
+		case <-l.done:
+			return
+		}
+	}
+}
+
+func (l *OutboxDispatchLogic) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.done)
+	})
+}
+
+// RunOnce dispatches the events that are due, oldest first
+func (l *OutboxDispatchLogic) RunOnce() {
+	due, err := l.svcCtx.OutboxModel.FindDue(l.ctx, time.Now(), l.svcCtx.Config.Outbox.BatchSize)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find due outbox events: %v", err)
+		return
+	}
+
+	dispatched, failed := 0, 0
+	for _, row := range due {
+		ok, err := l.svcCtx.OutboxModel.Claim(l.ctx, row.Id, time.Now(), outboxLease)
+		if err != nil {
+			l.logger.Errorf("Failed to claim outbox event %d: %v", row.Id, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if l.dispatch(row) {
+			dispatched++
+		} else {
+			failed++
+		}
+	}
+	if dispatched > 0 || failed > 0 {
+		l.logger.Infof("Outbox dispatch pass: %d dispatched, %d failed", dispatched, failed)
+	}
+}
+
+func (l *OutboxDispatchLogic) dispatch(row *model.OutboxEvent) bool {
+	ctx, cancel := context.WithTimeout(l.ctx, outboxLease/2)
+	defer cancel()
+
+	err := l.svcCtx.EventBus.Dispatch(ctx, event.Event{
+		Id:          row.Id,
+		Type:        row.EventType,
+		AggregateId: row.AggregateId,
+		Payload:     json.RawMessage(row.Payload),
+		OccurredAt:  row.CreatedAt,
+	})
+	if err == nil {
+		if err := l.svcCtx.OutboxModel.MarkDispatched(l.ctx, row.Id, time.Now()); err != nil {
+			l.logger.Errorf("Failed to mark outbox event %d dispatched: %v", row.Id, err)
+		}
+		return true
+	}
+
+	conf := l.svcCtx.Config.Outbox
+	attempt := row.Attempts + 1
+	final := attempt >= conf.MaxAttempts
+	retryAt := time.Now().Add(utils.Backoff(attempt, conf.RetryBase, conf.RetryMax))
+	if final {
+		l.logger.Errorf("Giving up on outbox event %d %s after %d attempts: %v", row.Id, row.EventType, attempt, err)
+	} else {
+		l.logger.Infof("Outbox event %d %s failed, retrying at %s: %v", row.Id, row.EventType, retryAt.Format(time.RFC3339), err)
+	}
+	if err := l.svcCtx.OutboxModel.MarkFailed(l.ctx, row.Id, err.Error(), retryAt, final); err != nil {
+		l.logger.Errorf("Failed to record outbox event %d failure: %v", row.Id, err)
+	}
+	return false
+}
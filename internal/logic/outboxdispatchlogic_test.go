@@ -0,0 +1,90 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"wata-bot-BE/internal/config"
+	"wata-bot-BE/internal/event"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/svc"
+)
+
+// fakeOutboxModel serves due events from memory and records what the
+// dispatcher did with them; methods the tests do not use panic
+type fakeOutboxModel struct {
+	model.OutboxModel
+	due        []*model.OutboxEvent
+	claimed    map[int64]bool // Events another dispatcher already holds
+	dispatched []int64
+	failed     map[int64]bool // Event id to whether the failure was final
+}
+
+func (m *fakeOutboxModel) FindDue(ctx context.Context, now time.Time, limit int) ([]*model.OutboxEvent, error) {
+	return m.due, nil
+}
+
+func (m *fakeOutboxModel) Claim(ctx context.Context, id int64, now time.Time, lease time.Duration) (bool, error) {
+	if m.claimed[id] {
+		return false, nil
+	}
+	m.claimed[id] = true
+	return true, nil
+}
+
+func (m *fakeOutboxModel) MarkDispatched(ctx context.Context, id int64, at time.Time) error {
+	m.dispatched = append(m.dispatched, id)
+	return nil
+}
+
+func (m *fakeOutboxModel) MarkFailed(ctx context.Context, id int64, lastError string, retryAt time.Time, final bool) error {
+	m.failed[id] = final
+	return nil
+}
+
+func TestOutboxDispatchRunOnce(t *testing.T) {
+	outbox := &fakeOutboxModel{
+		due: []*model.OutboxEvent{
+			{Id: 1, EventType: model.EventSubscribed, Payload: "{}"},
+			{Id: 2, EventType: model.EventDepositCompleted, Payload: "{}"},
+			{Id: 3, EventType: model.EventDepositCompleted, Payload: "{}", Attempts: 2},
+			{Id: 4, EventType: model.EventSubscribed, Payload: "{}"},
+		},
+		claimed: map[int64]bool{4: true},
+		failed:  make(map[int64]bool),
+	}
+
+	bus := event.NewBus()
+	var seen []int64
+	bus.Subscribe(event.AllEvents, "record", func(ctx context.Context, evt event.Event) error {
+		seen = append(seen, evt.Id)
+		return nil
+	})
+	bus.Subscribe(model.EventDepositCompleted, "flaky", func(ctx context.Context, evt event.Event) error {
+		return errors.New("unavailable")
+	})
+
+	svcCtx := &svc.ServiceContext{
+		Config: config.Config{Outbox: config.OutboxConf{
+			BatchSize: 10, MaxAttempts: 3, RetryBase: time.Second, RetryMax: time.Minute,
+		}},
+		OutboxModel: outbox,
+		EventBus:    bus,
+	}
+	NewOutboxDispatchLogic(context.Background(), svcCtx).RunOnce()
+
+	if len(seen) != 3 {
+		t.Errorf("handled events = %v, want 1, 2 and 3; 4 is claimed elsewhere", seen)
+	}
+	if len(outbox.dispatched) != 1 || outbox.dispatched[0] != 1 {
+		t.Errorf("dispatched = %v, want [1]", outbox.dispatched)
+	}
+	if final, ok := outbox.failed[2]; !ok || final {
+		t.Errorf("event 2 failed = %v, %v, want a retry", final, ok)
+	}
+	if final, ok := outbox.failed[3]; !ok || !final {
+		t.Errorf("event 3 failed = %v, %v, want final after its third attempt", final, ok)
+	}
+}
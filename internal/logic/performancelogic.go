@@ -0,0 +1,326 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// metricsWindowDays is the window used for roi30d, winRate and pnl30d
+	metricsWindowDays = 30
+
+	// maxSnapshotsPerRequest bounds a single ingestion call
+	maxSnapshotsPerRequest = 366
+)
+
+type PerformanceLogic struct {
+	logger logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewPerformanceLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PerformanceLogic {
+	return &PerformanceLogic{
+		logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// IngestPerformance stores daily snapshots pushed by the trading engine and
+// recomputes the 30-day metrics on the bot row
+func (l *PerformanceLogic) IngestPerformance(req *types.IngestPerformanceReq) (resp *types.IngestPerformanceResp, err error) {
+	if len(req.Snapshots) == 0 || len(req.Snapshots) > maxSnapshotsPerRequest {
+		return nil, model.NewAPIErrorf(model.ErrCodeInvalidPerformanceData, "snapshots must contain between 1 and %d entries", maxSnapshotsPerRequest)
+	}
+
+	if _, err := l.svcCtx.BotModel.FindOne(l.ctx, req.BotId); err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeBotNotFound, model.ErrMsgBotNotFound)
+		}
+		l.logger.Errorf("Failed to find bot %s: %v", req.BotId, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	// Validate everything before writing so a bad entry doesn't leave a partial import
+	snapshots := make([]*model.BotPerformanceSnapshot, 0, len(req.Snapshots))
+	for _, s := range req.Snapshots {
+		date, err := time.ParseInLocation(dateLayout, s.Date, time.Local)
+		if err != nil {
+			return nil, model.NewAPIErrorf(model.ErrCodeInvalidPerformanceData, "invalid date %q, expected YYYY-MM-DD", s.Date)
+		}
+		if s.Trades < 0 || s.WinningTrades < 0 || s.WinningTrades > s.Trades {
+			return nil, model.NewAPIErrorf(model.ErrCodeInvalidPerformanceData, "invalid trade counts for %s", s.Date)
+		}
+		if s.RoiPercent <= -100 || math.IsNaN(s.RoiPercent) || math.IsInf(s.RoiPercent, 0) || math.IsNaN(s.Pnl) || math.IsInf(s.Pnl, 0) {
+			return nil, model.NewAPIErrorf(model.ErrCodeInvalidPerformanceData, "invalid pnl or roiPercent for %s", s.Date)
+		}
+		snapshots = append(snapshots, &model.BotPerformanceSnapshot{
+			BotId:         req.BotId,
+			SnapshotDate:  date,
+			Pnl:           s.Pnl,
+			RoiPercent:    s.RoiPercent,
+			Trades:        s.Trades,
+			WinningTrades: s.WinningTrades,
+		})
+	}
+
+	for _, snapshot := range snapshots {
+		if _, err := l.svcCtx.BotPerformanceSnapshotModel.Upsert(l.ctx, snapshot); err != nil {
+			l.logger.Errorf("Failed to store performance snapshot for bot %s on %s: %v",
+				req.BotId, snapshot.SnapshotDate.Format(dateLayout), err)
+			return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+		}
+	}
+
+	metrics, err := l.RecomputeMetrics(req.BotId)
+	if err != nil {
+		return nil, err
+	}
+
+	l.logger.Infof("Ingested %d performance snapshots for bot %s", len(snapshots), req.BotId)
+
+	return &types.IngestPerformanceResp{
+		Message: "success",
+		Data: types.BotMetrics{
+			Roi30d:      metrics.Roi30d,
+			WinRate:     metrics.WinRate,
+			TotalTrades: metrics.TotalTrades,
+			Pnl30d:      metrics.Pnl30d,
+		},
+	}, nil
+}
+
+// RecomputeMetrics derives roi30d, winRate, pnl30d and totalTrades from the
+// stored snapshots and trade log and writes them to the bot row
+func (l *PerformanceLogic) RecomputeMetrics(botId string) (*model.BotMetrics, error) {
+	to := truncateToDay(time.Now())
+	from := to.AddDate(0, 0, -(metricsWindowDays - 1))
+
+	snapshots, err := l.svcCtx.BotPerformanceSnapshotModel.FindByBotIdBetween(l.ctx, botId, from, to)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to load performance snapshots for bot %s: %v", botId, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	totalTrades, err := l.svcCtx.BotPerformanceSnapshotModel.SumTradesByBotId(l.ctx, botId)
+	if err != nil {
+		l.logger.Errorf("Failed to sum trades for bot %s: %v", botId, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	window := aggregateSnapshots(snapshots)
+	metrics := &model.BotMetrics{
+		Roi30d:      formatPercent(window.RoiPercent),
+		WinRate:     formatPercent(winRatePercent(window.WinningTrades, window.Trades)),
+		TotalTrades: int(totalTrades),
+		Pnl30d:      math.Round(window.Pnl*100) / 100,
+	}
+
+	// Trades reported individually take precedence over the daily snapshot counts
+	tradeCount, err := l.svcCtx.BotTradeModel.CountByBotId(l.ctx, botId)
+	if err != nil {
+		l.logger.Errorf("Failed to count trades for bot %s: %v", botId, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	if tradeCount > 0 {
+		stats, err := l.svcCtx.BotTradeModel.StatsByBotId(l.ctx, botId, from)
+		if err != nil {
+			l.logger.Errorf("Failed to load trade stats for bot %s: %v", botId, err)
+			return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+		}
+		metrics.TotalTrades = int(tradeCount)
+		metrics.WinRate = formatPercent(winRatePercent(int(stats.WinningTrades), int(stats.Trades)))
+	}
+
+	if err := l.svcCtx.BotModel.UpdateMetrics(l.ctx, botId, *metrics); err != nil {
+		l.logger.Errorf("Failed to update metrics for bot %s: %v", botId, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	NewStreamLogic(l.ctx, l.svcCtx).PublishBotMetrics(botId, metrics)
+
+	return metrics, nil
+}
+
+// BotPerformance returns the bot's performance series bucketed by day, week or month
+func (l *PerformanceLogic) BotPerformance(req *types.BotPerformanceReq) (resp *types.BotPerformanceResp, err error) {
+	if _, err := l.svcCtx.BotModel.FindOne(l.ctx, req.BotId); err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeBotNotFound, model.ErrMsgBotNotFound)
+		}
+		l.logger.Errorf("Failed to find bot %s: %v", req.BotId, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	from, to, err := performanceRange(req.Granularity, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := l.svcCtx.BotPerformanceSnapshotModel.FindByBotIdBetween(l.ctx, req.BotId, from, to)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to load performance snapshots for bot %s: %v", req.BotId, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	return &types.BotPerformanceResp{
+		Message: "success",
+		Data: types.BotPerformanceData{
+			BotId:       req.BotId,
+			Granularity: req.Granularity,
+			Points:      bucketSnapshots(snapshots, req.Granularity),
+		},
+	}, nil
+}
+
+// maxPerformanceDays bounds the days a performance series may span per
+// granularity, so a request cannot load years of daily snapshots
+var maxPerformanceDays = map[string]int{
+	"day":   366,
+	"week":  2 * 366,
+	"month": 5 * 366,
+}
+
+// performanceRange resolves the requested date range, defaulting to a window
+// that suits the granularity
+func performanceRange(granularity, fromStr, toStr string) (time.Time, time.Time, error) {
+	maxDays, ok := maxPerformanceDays[granularity]
+	if !ok {
+		return time.Time{}, time.Time{}, model.NewAPIErrorf(model.ErrCodeInvalidPerformanceData, "invalid granularity %q, expected day, week or month", granularity)
+	}
+
+	to := truncateToDay(time.Now())
+	if toStr != "" {
+		t, err := time.ParseInLocation(dateLayout, toStr, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, model.NewAPIErrorf(model.ErrCodeInvalidPerformanceData, "invalid to date %q, expected YYYY-MM-DD", toStr)
+		}
+		to = t
+	}
+
+	var from time.Time
+	switch granularity {
+	case "week":
+		from = to.AddDate(0, -6, 0)
+	case "month":
+		from = to.AddDate(-1, 0, 0)
+	case "day":
+		from = to.AddDate(0, 0, -(metricsWindowDays - 1))
+	}
+	if fromStr != "" {
+		f, err := time.ParseInLocation(dateLayout, fromStr, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, model.NewAPIErrorf(model.ErrCodeInvalidPerformanceData, "invalid from date %q, expected YYYY-MM-DD", fromStr)
+		}
+		from = f
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, model.NewAPIError(model.ErrCodeInvalidPerformanceData, "from must not be after to")
+	}
+	if from.Before(to.AddDate(0, 0, -(maxDays - 1))) {
+		return time.Time{}, time.Time{}, model.NewAPIErrorf(model.ErrCodeInvalidPerformanceData, "the range can span at most %d days with %s granularity", maxDays, granularity)
+	}
+	return from, to, nil
+}
+
+// bucketSnapshots groups ordered snapshots into day, week (Monday based) or month buckets
+func bucketSnapshots(snapshots []*model.BotPerformanceSnapshot, granularity string) []types.PerformancePoint {
+	points := make([]types.PerformancePoint, 0)
+	cumulative := 1.0
+	var bucket []*model.BotPerformanceSnapshot
+	var bucketStart time.Time
+
+	flush := func() {
+		if len(bucket) == 0 {
+			return
+		}
+		agg := aggregateSnapshots(bucket)
+		cumulative *= 1 + agg.RoiPercent/100
+		points = append(points, types.PerformancePoint{
+			Period:               bucketStart.Format(dateLayout),
+			Pnl:                  math.Round(agg.Pnl*100) / 100,
+			RoiPercent:           roundPercent(agg.RoiPercent),
+			CumulativeRoiPercent: roundPercent((cumulative - 1) * 100),
+			Trades:               agg.Trades,
+			WinningTrades:        agg.WinningTrades,
+		})
+		bucket = nil
+	}
+
+	for _, s := range snapshots {
+		start := periodStart(s.SnapshotDate, granularity)
+		if !start.Equal(bucketStart) {
+			flush()
+			bucketStart = start
+		}
+		bucket = append(bucket, s)
+	}
+	flush()
+
+	return points
+}
+
+// snapshotAggregate is the combined performance of a run of daily snapshots
+type snapshotAggregate struct {
+	Pnl           float64
+	RoiPercent    float64 // Compounded return over the run
+	Trades        int
+	WinningTrades int
+}
+
+func aggregateSnapshots(snapshots []*model.BotPerformanceSnapshot) snapshotAggregate {
+	var agg snapshotAggregate
+	growth := 1.0
+	for _, s := range snapshots {
+		agg.Pnl += s.Pnl
+		agg.Trades += s.Trades
+		agg.WinningTrades += s.WinningTrades
+		growth *= 1 + s.RoiPercent/100
+	}
+	agg.RoiPercent = (growth - 1) * 100
+	return agg
+}
+
+func periodStart(date time.Time, granularity string) time.Time {
+	date = truncateToDay(date)
+	switch granularity {
+	case "week":
+		offset := (int(date.Weekday()) + 6) % 7
+		return date.AddDate(0, 0, -offset)
+	case "month":
+		return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	default:
+		return date
+	}
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func winRatePercent(winningTrades, trades int) float64 {
+	if trades == 0 {
+		return 0
+	}
+	return float64(winningTrades) / float64(trades) * 100
+}
+
+func roundPercent(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// formatPercent matches the "16.49%" display format used by the bot metrics
+func formatPercent(v float64) string {
+	return fmt.Sprintf("%.2f%%", v)
+}
@@ -0,0 +1,115 @@
+package logic
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/types"
+)
+
+func date(s string) time.Time {
+	t, err := time.ParseInLocation(dateLayout, s, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestBucketSnapshots(t *testing.T) {
+	// 2026-03-01 is a Sunday, so it closes the week that started on 2026-02-23
+	snapshots := []*model.BotPerformanceSnapshot{
+		{SnapshotDate: date("2026-03-01"), Pnl: 10, RoiPercent: 10, Trades: 2, WinningTrades: 1},
+		{SnapshotDate: date("2026-03-02"), Pnl: 12, RoiPercent: 10, Trades: 3, WinningTrades: 2},
+		{SnapshotDate: date("2026-03-03"), Pnl: -6, RoiPercent: -5, Trades: 4, WinningTrades: 1},
+		{SnapshotDate: date("2026-04-01"), Pnl: 2.5, RoiPercent: 2, Trades: 1, WinningTrades: 1},
+	}
+
+	tests := []struct {
+		granularity string
+		want        []types.PerformancePoint
+	}{
+		{
+			granularity: "day",
+			want: []types.PerformancePoint{
+				{Period: "2026-03-01", Pnl: 10, RoiPercent: 10, CumulativeRoiPercent: 10, Trades: 2, WinningTrades: 1},
+				{Period: "2026-03-02", Pnl: 12, RoiPercent: 10, CumulativeRoiPercent: 21, Trades: 3, WinningTrades: 2},
+				{Period: "2026-03-03", Pnl: -6, RoiPercent: -5, CumulativeRoiPercent: 14.95, Trades: 4, WinningTrades: 1},
+				{Period: "2026-04-01", Pnl: 2.5, RoiPercent: 2, CumulativeRoiPercent: 17.25, Trades: 1, WinningTrades: 1},
+			},
+		},
+		{
+			granularity: "week",
+			want: []types.PerformancePoint{
+				{Period: "2026-02-23", Pnl: 10, RoiPercent: 10, CumulativeRoiPercent: 10, Trades: 2, WinningTrades: 1},
+				{Period: "2026-03-02", Pnl: 6, RoiPercent: 4.5, CumulativeRoiPercent: 14.95, Trades: 7, WinningTrades: 3},
+				{Period: "2026-03-30", Pnl: 2.5, RoiPercent: 2, CumulativeRoiPercent: 17.25, Trades: 1, WinningTrades: 1},
+			},
+		},
+		{
+			granularity: "month",
+			want: []types.PerformancePoint{
+				{Period: "2026-03-01", Pnl: 16, RoiPercent: 14.95, CumulativeRoiPercent: 14.95, Trades: 9, WinningTrades: 4},
+				{Period: "2026-04-01", Pnl: 2.5, RoiPercent: 2, CumulativeRoiPercent: 17.25, Trades: 1, WinningTrades: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.granularity, func(t *testing.T) {
+			got := bucketSnapshots(snapshots, tt.granularity)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("bucketSnapshots() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := bucketSnapshots(nil, "day"); got == nil || len(got) != 0 {
+		t.Errorf("bucketSnapshots(nil) = %#v, want an empty slice so the JSON is []", got)
+	}
+}
+
+func TestPerformanceRange(t *testing.T) {
+	tests := []struct {
+		name        string
+		granularity string
+		from, to    string
+		wantFrom    string
+		wantTo      string
+		wantErr     bool
+	}{
+		{name: "day default window", granularity: "day", to: "2026-03-31", wantFrom: "2026-03-02", wantTo: "2026-03-31"},
+		{name: "week default window", granularity: "week", to: "2026-03-31", wantFrom: "2025-10-01", wantTo: "2026-03-31"},
+		{name: "month default window", granularity: "month", to: "2026-03-31", wantFrom: "2025-03-31", wantTo: "2026-03-31"},
+		{name: "explicit range", granularity: "day", from: "2026-01-01", to: "2026-01-31", wantFrom: "2026-01-01", wantTo: "2026-01-31"},
+		{name: "single day", granularity: "day", from: "2026-01-01", to: "2026-01-01", wantFrom: "2026-01-01", wantTo: "2026-01-01"},
+		{name: "from after to", granularity: "day", from: "2026-02-01", to: "2026-01-31", wantErr: true},
+		{name: "bad from", granularity: "day", from: "01/02/2026", to: "2026-01-31", wantErr: true},
+		{name: "bad to", granularity: "day", to: "2026-13-01", wantErr: true},
+		{name: "unknown granularity", granularity: "hour", to: "2026-03-31", wantErr: true},
+		{name: "longest day range", granularity: "day", from: "2025-01-01", to: "2026-01-01", wantFrom: "2025-01-01", wantTo: "2026-01-01"},
+		{name: "day range too long", granularity: "day", from: "2024-12-31", to: "2026-01-01", wantErr: true},
+		{name: "week range", granularity: "week", from: "2024-03-01", to: "2026-01-01", wantFrom: "2024-03-01", wantTo: "2026-01-01"},
+		{name: "week range too long", granularity: "week", from: "2023-01-01", to: "2026-01-01", wantErr: true},
+		{name: "month range too long", granularity: "month", from: "2016-01-01", to: "2026-01-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, err := performanceRange(tt.granularity, tt.from, tt.to)
+			if tt.wantErr {
+				var apiErr *model.APIError
+				if !errors.As(err, &apiErr) || apiErr.Code != model.ErrCodeInvalidPerformanceData {
+					t.Fatalf("performanceRange() error = %v, want code %s", err, model.ErrCodeInvalidPerformanceData)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("performanceRange() error = %v", err)
+			}
+			if !from.Equal(date(tt.wantFrom)) || !to.Equal(date(tt.wantTo)) {
+				t.Errorf("performanceRange() = %s..%s, want %s..%s", from.Format(dateLayout), to.Format(dateLayout), tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
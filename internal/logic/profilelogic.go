@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"wata-bot-BE/internal/i18n"
 	"wata-bot-BE/internal/model"
 	"wata-bot-BE/internal/svc"
 	"wata-bot-BE/internal/types"
@@ -27,16 +28,56 @@ func NewProfileLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ProfileLo
 
 func (l *ProfileLogic) GetProfile(req *types.GetProfileReq) (resp *types.ProfileResp, err error) {
 	// Find user by address - always from database, not from cache
-	user, err := l.svcCtx.UserModel.FindOneByAddressNoCache(req.Address)
+	user, err := l.svcCtx.UserModel.FindOneByAddressNoCache(l.ctx, req.Address)
 	if err != nil {
 		if err == model.ErrNotFound {
-			return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, "User not found")
+			return nil, model.NewAPIError(model.ErrCodeUserNotFound, model.ErrMsgUserNotFound)
 		}
 		l.logger.Errorf("Failed to find user by address: %v", err)
 		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
 	}
 
-	// Convert to API response
+	return &types.ProfileResp{
+		Message: "success",
+		Data:    profileToAPI(user),
+	}, nil
+}
+
+// UpdateLanguage sets the language the user's notifications are written in.
+// An empty language clears the preference.
+func (l *ProfileLogic) UpdateLanguage(req *types.UpdateLanguageReq) (resp *types.ProfileResp, err error) {
+	language := ""
+	if req.Language != "" {
+		lang, ok := i18n.Normalize(req.Language)
+		if !ok {
+			return nil, model.NewAPIError(model.ErrCodeInvalidLanguage, model.ErrMsgInvalidLanguage)
+		}
+		language = lang
+	}
+
+	user, err := l.svcCtx.UserModel.FindOneByAddressNoCache(l.ctx, req.Address)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeUserNotFound, model.ErrMsgUserNotFound)
+		}
+		l.logger.Errorf("Failed to find user by address: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
+	}
+
+	if err := l.svcCtx.UserModel.UpdateLanguage(l.ctx, user, language); err != nil {
+		l.logger.Errorf("Failed to update language of user %d: %v", user.Id, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	user.Language = language
+
+	return &types.ProfileResp{
+		Message: "success",
+		Data:    profileToAPI(user),
+	}, nil
+}
+
+// profileToAPI converts a user to its API profile
+func profileToAPI(user *model.User) types.UserProfileData {
 	// Ensure balance values are not empty (fallback to "0" if empty)
 	wataBalance := user.WataBalance
 	if wataBalance == "" {
@@ -47,20 +88,17 @@ func (l *ProfileLogic) GetProfile(req *types.GetProfileReq) (resp *types.Profile
 		usdtBalance = "0"
 	}
 
-	profileData := types.UserProfileData{
-		Address:      user.Address,
+	return types.UserProfileData{
+		Address:      user.Address.String(),
 		ReferralCode: user.ReferralCode,
 		InviteCode:   user.InviteCode,
 		WataReward:   user.WataReward,
 		WataBalance:  wataBalance,
 		UsdtBalance:  usdtBalance,
 		Role:         user.Role,
+		Language:     user.Language,
+		ChainId:      user.ChainId,
 		CreatedAt:    user.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:    user.UpdatedAt.Format(time.RFC3339),
 	}
-
-	return &types.ProfileResp{
-		Message: "success",
-		Data:    profileData,
-	}, nil
 }
@@ -0,0 +1,250 @@
+package logic
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"wata-bot-BE/internal/metrics"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/notify"
+	"wata-bot-BE/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// settlementBatchSize bounds how many subscriptions one settlement pass handles per step
+const settlementBatchSize = 200
+
+// SettlementLogic moves subscriptions through the end of their term:
+// active -> matured once the duration has elapsed, then matured -> settled with
+// the position value fixed, renewing auto-renew subscriptions into a new term. Transitions are conditional on the previous status,
+// so running it on several replicas at once is safe.
+type SettlementLogic struct {
+	logger logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func NewSettlementLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SettlementLogic {
+	return &SettlementLogic{
+		logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		done:   make(chan struct{}),
+	}
+}
+
+// Start runs a settlement pass every interval until Stop is called
+func (l *SettlementLogic) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		l.RunOnce()
+		select {
+		case <-ticker.C:
+		case <-l.done:
+			return
+		}
+	}
+}
+
+func (l *SettlementLogic) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.done)
+	})
+}
+
+// RunOnce matures due subscriptions, settles matured ones and offers freed
+// capacity to waitlisted users
+func (l *SettlementLogic) RunOnce() {
+	matured := l.matureDue(time.Now())
+	settled := l.settleMatured()
+	// Matured positions free capacity for waitlisted users
+	NewWaitlistLogic(l.ctx, l.svcCtx).OfferAllSlots()
+	if matured > 0 || settled > 0 {
+		l.logger.Infof("Settlement pass: %d matured, %d settled", matured, settled)
+	}
+}
+
+func (l *SettlementLogic) matureDue(now time.Time) int {
+	due, err := l.svcCtx.UserBotSubscriptionModel.FindDueForMaturity(l.ctx, now, settlementBatchSize)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find subscriptions due for maturity: %v", err)
+		return 0
+	}
+
+	count := 0
+	for _, sub := range due {
+		durationDay, _ := strconv.Atoi(sub.DurationDay)
+		maturesAt := truncateToDay(sub.StartedAt).AddDate(0, 0, durationDay)
+
+		ok, err := l.svcCtx.UserBotSubscriptionModel.Mature(l.ctx, sub.Id, maturesAt)
+		if err != nil {
+			l.logger.Errorf("Failed to mature subscription %d: %v", sub.Id, err)
+			continue
+		}
+		if ok {
+			count++
+		}
+	}
+	return count
+}
+
+func (l *SettlementLogic) settleMatured() int {
+	matured, err := l.svcCtx.UserBotSubscriptionModel.FindByStatus(l.ctx, model.SubscriptionStatusMatured, settlementBatchSize)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find matured subscriptions: %v", err)
+		return 0
+	}
+
+	subscriptionLogic := NewSubscriptionLogic(l.ctx, l.svcCtx)
+	count := 0
+	for _, sub := range matured {
+		position, err := subscriptionLogic.valuePosition(sub)
+		if err != nil {
+			l.logger.Errorf("Failed to value subscription %d: %v", sub.Id, err)
+			continue
+		}
+
+		renewal := l.renewal(sub, position)
+		payout, err := l.payout(sub, position, renewal)
+		if err != nil {
+			l.logger.Errorf("Failed to prepare payout of subscription %d: %v", sub.Id, err)
+			continue
+		}
+		ok, err := l.svcCtx.UserBotSubscriptionModel.Settle(l.ctx, sub.Id, formatDecimal(position.Value), time.Now(), renewal, payout)
+		if err != nil {
+			l.logger.Errorf("Failed to settle subscription %d: %v", sub.Id, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		count++
+		if renewal != nil && renewal.Id == 0 {
+			l.logger.Infof("Not renewing subscription %d: bot %s is full", sub.Id, sub.BotId)
+		}
+		if renewal != nil && renewal.Id != 0 {
+			metrics.Subscriptions.Inc(sub.BotId, metrics.ActionRenew)
+		}
+		l.notifySettled(sub, position, renewal)
+	}
+	return count
+}
+
+// payout returns the balance change that credits a settled position to the
+// user's USDT balance: its whole value, or what is left once the renewal has
+// taken its amount into the next term
+func (l *SettlementLogic) payout(sub *model.UserBotSubscription, position *positionValue, renewal *model.UserBotSubscription) (func(renewed bool) *model.BalanceChange, error) {
+	user, err := l.svcCtx.UserModel.FindOne(l.ctx, sub.UserId)
+	if err != nil {
+		return nil, err
+	}
+	// Balances are written against the stored value, so skip the cache
+	if user, err = l.svcCtx.UserModel.FindOneByAddressNoCache(l.ctx, user.Address); err != nil {
+		return nil, err
+	}
+
+	value, _ := strconv.ParseFloat(formatDecimal(position.Value), 64)
+	full, err := usdtChange(l.svcCtx, user, model.TransactionTypeSettle, value)
+	if err != nil {
+		return nil, err
+	}
+	remainder := full
+	if renewal != nil {
+		rolled, _ := strconv.ParseFloat(renewal.Amount, 64)
+		if remainder, err = usdtChange(l.svcCtx, user, model.TransactionTypeSettle, value-rolled); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(renewed bool) *model.BalanceChange {
+		if renewed {
+			return remainder
+		}
+		return full
+	}, nil
+}
+
+// notifySettled tells the user their position settled and, if it did, renewed
+func (l *SettlementLogic) notifySettled(sub *model.UserBotSubscription, position *positionValue, renewal *model.UserBotSubscription) {
+	botName := sub.BotId
+	if bot, err := l.svcCtx.BotModel.FindOne(l.ctx, sub.BotId); err == nil {
+		botName = bot.Name
+	}
+
+	notifications := NewNotificationLogic(l.ctx, l.svcCtx)
+	notifications.Notify(sub.UserId, notify.EventSubscriptionSettled, map[string]string{
+		"botName":      botName,
+		"amount":       formatDecimal(position.Amount),
+		"durationDays": sub.DurationDay,
+		"finalValue":   formatDecimal(position.Value),
+	})
+	if renewal != nil && renewal.Id != 0 {
+		notifications.Notify(sub.UserId, notify.EventSubscriptionRenewed, map[string]string{
+			"botName":      botName,
+			"amount":       renewal.Amount,
+			"durationDays": renewal.DurationDay,
+		})
+	}
+}
+
+// renewal builds the next term of an auto-renewing subscription, or returns nil
+// when it should not renew. Compounding rolls the settled value, payout rolls
+// the principal only, or the value after a loss; both are capped at the bot's
+// maximum investment.
+func (l *SettlementLogic) renewal(sub *model.UserBotSubscription, position *positionValue) *model.UserBotSubscription {
+	if !sub.AutoRenew {
+		return nil
+	}
+
+	bot, err := l.svcCtx.BotModel.FindOne(l.ctx, sub.BotId)
+	if err != nil {
+		l.logger.Errorf("Failed to find bot %s to renew subscription %d: %v", sub.BotId, sub.Id, err)
+		return nil
+	}
+	if !bot.IsActive {
+		l.logger.Infof("Not renewing subscription %d: bot %s is inactive", sub.Id, bot.Id)
+		return nil
+	}
+
+	durationDay := sub.DurationDay
+	if sub.RenewDurationDay != "" {
+		durationDay = sub.RenewDurationDay
+	}
+	days, _ := strconv.Atoi(durationDay)
+	if !isOfferedDuration(bot, days) {
+		l.logger.Infof("Not renewing subscription %d: bot %s no longer offers %s days", sub.Id, bot.Id, durationDay)
+		return nil
+	}
+
+	amount := position.Value
+	if sub.RenewMode == model.RenewModePayout && position.Amount < amount {
+		amount = position.Amount
+	}
+	if bot.MaxInvestment > 0 && amount > float64(bot.MaxInvestment) {
+		amount = float64(bot.MaxInvestment)
+	}
+	if amount <= 0 || amount < float64(bot.MinInvestment) {
+		l.logger.Infof("Not renewing subscription %d: amount %s is below the bot minimum", sub.Id, formatDecimal(amount))
+		return nil
+	}
+
+	// The next term starts where this one matured so no day is counted twice
+	return &model.UserBotSubscription{
+		UserId:           sub.UserId,
+		BotId:            sub.BotId,
+		DurationDay:      strconv.Itoa(days),
+		Amount:           formatDecimal(amount),
+		StartedAt:        position.MaturesAt,
+		AutoRenew:        true,
+		RenewDurationDay: sub.RenewDurationDay,
+		RenewMode:        sub.RenewMode,
+	}
+}
@@ -0,0 +1,106 @@
+package logic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/svc"
+)
+
+// fakeBotModel serves bots from memory; methods the tests do not use panic
+type fakeBotModel struct {
+	model.BotModel
+	bots map[string]*model.Bot
+}
+
+func (m *fakeBotModel) FindOne(ctx context.Context, id string) (*model.Bot, error) {
+	bot, ok := m.bots[id]
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+	return bot, nil
+}
+
+func TestSettlementRenewal(t *testing.T) {
+	maturesAt := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	bots := &fakeBotModel{bots: map[string]*model.Bot{
+		"grid":     {Id: "grid", IsActive: true, DurationDays: "[30,90]", MinInvestment: 100, MaxInvestment: 10000},
+		"uncapped": {Id: "uncapped", IsActive: true, DurationDays: "[30]", MinInvestment: 100},
+		"retired":  {Id: "retired", IsActive: false, DurationDays: "[30]", MinInvestment: 100},
+	}}
+	l := NewSettlementLogic(context.Background(), &svc.ServiceContext{BotModel: bots})
+
+	sub := func(botId, mode string, modify ...func(s *model.UserBotSubscription)) *model.UserBotSubscription {
+		s := &model.UserBotSubscription{
+			Id:          7,
+			UserId:      3,
+			BotId:       botId,
+			DurationDay: "30",
+			Amount:      "1000",
+			AutoRenew:   true,
+			RenewMode:   mode,
+		}
+		for _, m := range modify {
+			m(s)
+		}
+		return s
+	}
+	position := func(amount, value float64) *positionValue {
+		return &positionValue{Amount: amount, Value: value, MaturesAt: maturesAt}
+	}
+
+	tests := []struct {
+		name     string
+		sub      *model.UserBotSubscription
+		position *positionValue
+		want     string // Renewed amount, empty for no renewal
+		wantDays string
+	}{
+		{name: "compound rolls the value", sub: sub("grid", model.RenewModeCompound), position: position(1000, 1100), want: "1100", wantDays: "30"},
+		{name: "compound after a loss", sub: sub("grid", model.RenewModeCompound), position: position(1000, 900), want: "900", wantDays: "30"},
+		{name: "payout rolls the principal", sub: sub("grid", model.RenewModePayout), position: position(1000, 1100), want: "1000", wantDays: "30"},
+		{name: "payout after a loss rolls the value", sub: sub("grid", model.RenewModePayout), position: position(1000, 900), want: "900", wantDays: "30"},
+		{name: "compound capped at max investment", sub: sub("grid", model.RenewModeCompound), position: position(9800, 10400.5), want: "10000", wantDays: "30"},
+		{name: "payout capped at max investment", sub: sub("grid", model.RenewModePayout), position: position(12000, 12500), want: "10000", wantDays: "30"},
+		{name: "no max investment", sub: sub("uncapped", model.RenewModeCompound), position: position(50000, 51000.25), want: "51000.25", wantDays: "30"},
+		{name: "at the minimum", sub: sub("grid", model.RenewModeCompound), position: position(120, 100), want: "100", wantDays: "30"},
+		{name: "below the minimum", sub: sub("grid", model.RenewModeCompound), position: position(120, 99.99)},
+		{name: "payout below the minimum", sub: sub("grid", model.RenewModePayout), position: position(100, 60)},
+		{name: "nothing left", sub: sub("uncapped", model.RenewModeCompound), position: position(100, 0)},
+		{name: "renew duration", sub: sub("grid", model.RenewModeCompound, func(s *model.UserBotSubscription) { s.RenewDurationDay = "90" }), position: position(1000, 1000), want: "1000", wantDays: "90"},
+		{name: "duration no longer offered", sub: sub("grid", model.RenewModeCompound, func(s *model.UserBotSubscription) { s.DurationDay = "60" }), position: position(1000, 1000)},
+		{name: "renew duration not offered", sub: sub("grid", model.RenewModeCompound, func(s *model.UserBotSubscription) { s.RenewDurationDay = "7" }), position: position(1000, 1000)},
+		{name: "auto-renew off", sub: sub("grid", model.RenewModeCompound, func(s *model.UserBotSubscription) { s.AutoRenew = false }), position: position(1000, 1100)},
+		{name: "inactive bot", sub: sub("retired", model.RenewModeCompound), position: position(1000, 1100)},
+		{name: "unknown bot", sub: sub("gone", model.RenewModeCompound), position: position(1000, 1100)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := l.renewal(tt.sub, tt.position)
+			if tt.want == "" {
+				if got != nil {
+					t.Fatalf("renewal() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("renewal() = nil, want %s", tt.want)
+			}
+			if got.Amount != tt.want {
+				t.Errorf("Amount = %s, want %s", got.Amount, tt.want)
+			}
+			if got.DurationDay != tt.wantDays {
+				t.Errorf("DurationDay = %s, want %s", got.DurationDay, tt.wantDays)
+			}
+			if !got.StartedAt.Equal(maturesAt) {
+				t.Errorf("StartedAt = %v, want the maturity %v", got.StartedAt, maturesAt)
+			}
+			if got.UserId != tt.sub.UserId || got.BotId != tt.sub.BotId || !got.AutoRenew ||
+				got.RenewMode != tt.sub.RenewMode || got.RenewDurationDay != tt.sub.RenewDurationDay {
+				t.Errorf("renewal() = %+v, want the owner and renewal settings of %+v", got, tt.sub)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"wata-bot-BE/internal/address"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/stream"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Stream events besides the updates published on topics
+const (
+	streamEventConnected = "connected"
+	streamEventHeartbeat = "heartbeat"
+)
+
+// Update events published on topics
+const (
+	StreamEventBalances    = "balances"
+	StreamEventTransaction = "transaction"
+	StreamEventBotMetrics  = "metrics"
+)
+
+// StreamWriter writes one server-sent event and flushes it to the client
+type StreamWriter interface {
+	WriteEvent(event string, data []byte) error
+}
+
+type StreamLogic struct {
+	logger logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewStreamLogic(ctx context.Context, svcCtx *svc.ServiceContext) *StreamLogic {
+	return &StreamLogic{
+		logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Open registers a connection for the authenticated wallet. Errors are
+// returned before anything is written, so they can still be sent as JSON.
+func (l *StreamLogic) Open(req *types.StreamReq) (*stream.Conn, error) {
+	conn, err := l.svcCtx.Stream.Connect(req.Address, parseTopics(req.Topics))
+	if err != nil {
+		return nil, model.NewAPIError(model.ErrCodeInvalidStreamTopic, model.ErrMsgInvalidStreamTopic)
+	}
+	return conn, nil
+}
+
+// Serve writes the connection's updates until the client disconnects or the
+// hub drops the connection. A heartbeat is written every Stream.Heartbeat so
+// proxies keep the connection open and clients can detect stale streams.
+func (l *StreamLogic) Serve(conn *stream.Conn, w StreamWriter) error {
+	defer l.svcCtx.Stream.Disconnect(conn)
+
+	if err := writeStreamEvent(w, streamEventConnected, types.StreamConnected{
+		ConnectionId: conn.Id,
+		Topics:       conn.Topics(),
+	}); err != nil {
+		return err
+	}
+	// Send the current balances so clients need not fetch the profile first
+	for _, topic := range conn.Topics() {
+		if topic == stream.TopicBalances {
+			if err := l.writeBalances(conn.Address, w); err != nil {
+				return err
+			}
+		}
+	}
+
+	heartbeat := time.NewTicker(l.svcCtx.Config.Stream.Heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case msg, ok := <-conn.Send:
+			if !ok {
+				return nil
+			}
+			if err := writeStreamEvent(w, msg.Event, msg); err != nil {
+				return err
+			}
+		case <-heartbeat.C:
+			if err := writeStreamEvent(w, streamEventHeartbeat, map[string]string{
+				"at": time.Now().Format(time.RFC3339),
+			}); err != nil {
+				return err
+			}
+		case <-l.ctx.Done():
+			return nil
+		}
+	}
+}
+
+// UpdateSubscriptions adds and removes topics of one of the wallet's connections
+func (l *StreamLogic) UpdateSubscriptions(req *types.UpdateStreamSubscriptionsReq) (resp *types.UpdateStreamSubscriptionsResp, err error) {
+	if req.ConnectionId == "" {
+		return nil, model.NewAPIError(model.ErrCodeInvalidStreamTopic, "connectionId is required")
+	}
+	if err := l.svcCtx.Stream.Update(l.ctx, req.ConnectionId, req.Address, req.Subscribe, req.Unsubscribe); err != nil {
+		if err == stream.ErrInvalidTopic {
+			return nil, model.NewAPIError(model.ErrCodeInvalidStreamTopic, model.ErrMsgInvalidStreamTopic)
+		}
+		l.logger.Errorf("Failed to publish stream subscription update: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeInternalServerError, model.ErrMsgInternalServerError)
+	}
+	return &types.UpdateStreamSubscriptionsResp{
+		Message: "success",
+	}, nil
+}
+
+func (l *StreamLogic) writeBalances(addr address.Address, w StreamWriter) error {
+	user, err := l.svcCtx.UserModel.FindOneByAddress(l.ctx, addr)
+	if err != nil {
+		if err != model.ErrNotFound {
+			l.logger.Errorf("Failed to find user for stream balances: %v", err)
+		}
+		return nil
+	}
+	data, _ := json.Marshal(types.StreamBalances{
+		WataBalance: user.WataBalance,
+		UsdtBalance: user.UsdtBalance,
+	})
+	return writeStreamEvent(w, StreamEventBalances, stream.Message{
+		Topic: stream.TopicBalances,
+		Event: StreamEventBalances,
+		Data:  data,
+		At:    time.Now(),
+	})
+}
+
+// PublishBalanceChange pushes a deposit or withdrawal and the resulting
+// balances to the wallet's connections
+func (l *StreamLogic) PublishBalanceChange(evtType string, payload model.BalanceEvent) error {
+	txType := "deposit"
+	if evtType == model.EventWithdrawRequested {
+		txType = "withdraw"
+	}
+	if err := l.svcCtx.Stream.PublishJSON(l.ctx, stream.TopicTransactions, StreamEventTransaction, payload.Address, types.StreamTransaction{
+		TransactionId: payload.TransactionId,
+		Type:          txType,
+		ChainId:       payload.ChainId,
+		Currency:      payload.Currency,
+		Amount:        payload.Amount,
+		BalanceAfter:  payload.BalanceAfter,
+		Status:        payload.Status,
+		TxHash:        payload.TxHash,
+	}); err != nil {
+		return err
+	}
+
+	user, err := l.svcCtx.UserModel.FindOne(l.ctx, payload.UserId)
+	if err != nil {
+		return err
+	}
+	return l.svcCtx.Stream.PublishJSON(l.ctx, stream.TopicBalances, StreamEventBalances, user.Address, types.StreamBalances{
+		WataBalance: user.WataBalance,
+		UsdtBalance: user.UsdtBalance,
+	})
+}
+
+// PublishBotMetrics pushes recomputed metrics to the bot's subscribers.
+// Failures are logged; the stream is best effort.
+func (l *StreamLogic) PublishBotMetrics(botId string, metrics *model.BotMetrics) {
+	if err := l.svcCtx.Stream.PublishJSON(l.ctx, stream.BotTopic(botId), StreamEventBotMetrics, "", types.StreamBotMetrics{
+		BotId:       botId,
+		Roi30d:      metrics.Roi30d,
+		WinRate:     metrics.WinRate,
+		TotalTrades: metrics.TotalTrades,
+		Pnl30d:      metrics.Pnl30d,
+	}); err != nil {
+		l.logger.Errorf("Failed to publish metrics of bot %s: %v", botId, err)
+	}
+}
+
+func writeStreamEvent(w StreamWriter, event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return w.WriteEvent(event, data)
+}
+
+// parseTopics splits a comma separated topic list, defaulting to the wallet's
+// balances and transactions
+func parseTopics(s string) []string {
+	var topics []string
+	for _, t := range strings.Split(s, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			topics = append(topics, t)
+		}
+	}
+	if len(topics) == 0 {
+		return []string{stream.TopicBalances, stream.TopicTransactions}
+	}
+	return topics
+}
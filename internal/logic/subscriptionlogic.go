@@ -2,9 +2,15 @@ package logic
 
 import (
 	"context"
-	"encoding/json"
+	"database/sql"
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
 
+	"wata-bot-BE/internal/metrics"
 	"wata-bot-BE/internal/model"
 	"wata-bot-BE/internal/svc"
 	"wata-bot-BE/internal/types"
@@ -26,15 +32,16 @@ func NewSubscriptionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Subs
 	}
 }
 
-// GetUserBots returns all bots that a user has subscribed to
-func (l *SubscriptionLogic) GetUserBots(req *types.GetUserBotsReq) (resp *types.BotsResp, err error) {
+// GetUserBots returns the user's subscription history, optionally filtered by
+// status, with each position's share of the bot's performance
+func (l *SubscriptionLogic) GetUserBots(req *types.GetUserBotsReq) (resp *types.UserBotsResp, err error) {
 	// Find user by address
-	user, err := l.svcCtx.UserModel.FindOneByAddress(req.Address)
+	user, err := l.svcCtx.UserModel.FindOneByAddress(l.ctx, req.Address)
 	if err != nil {
 		if err == model.ErrNotFound {
-			return &types.BotsResp{
+			return &types.UserBotsResp{
 				Message: "success",
-				Data:    []types.Bot{},
+				Data:    []types.UserBot{},
 			}, nil
 		}
 		l.logger.Errorf("Failed to find user by address: %v", err)
@@ -42,22 +49,21 @@ func (l *SubscriptionLogic) GetUserBots(req *types.GetUserBotsReq) (resp *types.
 	}
 
 	// Get all subscriptions for this user
-	subscriptions, err := l.svcCtx.UserBotSubscriptionModel.FindByUserId(user.Id)
+	subscriptions, err := l.svcCtx.UserBotSubscriptionModel.FindByUserId(l.ctx, user.Id, req.Status)
 	if err != nil {
 		if err == model.ErrNotFound {
-			return &types.BotsResp{
+			return &types.UserBotsResp{
 				Message: "success",
-				Data:    []types.Bot{},
+				Data:    []types.UserBot{},
 			}, nil
 		}
 		l.logger.Errorf("Failed to find subscriptions: %v", err)
 		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
 	}
 
-	// Get bot details for each subscription
-	bots := make([]types.Bot, 0, len(subscriptions))
+	userBots := make([]types.UserBot, 0, len(subscriptions))
 	for _, sub := range subscriptions {
-		bot, err := l.svcCtx.BotModel.FindOne(sub.BotId)
+		bot, err := l.svcCtx.BotModel.FindOne(l.ctx, sub.BotId)
 		if err != nil {
 			if err == model.ErrNotFound {
 				l.logger.Errorf("Bot %s not found for subscription", sub.BotId)
@@ -68,120 +74,198 @@ func (l *SubscriptionLogic) GetUserBots(req *types.GetUserBotsReq) (resp *types.
 		}
 
 		// Parse duration_days from bot (not from subscription)
-		var durationDays []int
-		if bot.DurationDays != "" {
-			if err := json.Unmarshal([]byte(bot.DurationDays), &durationDays); err != nil {
-				l.logger.Errorf("Failed to parse duration_days for bot %s: %v", bot.Id, err)
-				// Default to [5, 15, 30, 60, 90, 180] if parsing fails
-				durationDays = []int{5, 15, 30, 60, 90, 180}
-			}
-		} else {
-			// Default to [5, 15, 30, 60, 90, 180] if empty
-			durationDays = []int{5, 15, 30, 60, 90, 180}
+		durationDays, err := parseDurationDays(bot.DurationDays)
+		if err != nil {
+			l.logger.Errorf("Failed to parse duration_days for bot %s: %v", bot.Id, err)
 		}
 
-		// Convert to API type
-		apiBot := types.Bot{
-			Id:                    bot.Id,
-			Name:                  bot.Name,
-			IconLetter:            bot.IconLetter,
-			RiskLevel:             bot.RiskLevel,
-			DurationDays:          durationDays,
-			ExpectedReturnPercent: bot.ExpectedReturnPercent,
-			AprDisplay:            bot.AprDisplay,
-			MinInvestment:         bot.MinInvestment,
-			MaxInvestment:         bot.MaxInvestment,
-			InvestmentRange:       bot.InvestmentRange,
-			Subscribers:           bot.Subscribers,
-			Author:                bot.Author,
-			Description:           bot.Description,
-			IsActive:              bot.IsActive,
-			Metrics: types.BotMetrics{
-				LockupPeriod:   bot.LockupPeriod,
-				ExpectedReturn: bot.ExpectedReturn,
-				MinInvestment:  bot.MinInvestmentDisplay,
-				MaxInvestment:  bot.MaxInvestmentDisplay,
-				Roi30d:         bot.Roi30d,
-				WinRate:        bot.WinRate,
-				TradingPair:    bot.TradingPair,
-				TotalTrades:    bot.TotalTrades,
-				Pnl30d:         bot.Pnl30d,
-			},
+		userBot, err := l.userBot(sub, bot, durationDays)
+		if err != nil {
+			return nil, err
 		}
-		bots = append(bots, apiBot)
+		userBots = append(userBots, userBot)
 	}
 
-	return &types.BotsResp{
+	return &types.UserBotsResp{
 		Message: "success",
-		Data:    bots,
+		Data:    userBots,
+	}, nil
+}
+
+// userBot converts a subscription and its bot to the user bots response entry
+func (l *SubscriptionLogic) userBot(sub *model.UserBotSubscription, bot *model.Bot, durationDays []int) (types.UserBot, error) {
+	position, err := l.valuePosition(sub)
+	if err != nil {
+		return types.UserBot{}, err
+	}
+
+	durationDay, _ := strconv.Atoi(sub.DurationDay)
+	return types.UserBot{
+		Bot: l.convertBotToAPI(bot, durationDays),
+		Subscription: types.UserBotSubscription{
+			Id:            sub.Id,
+			Status:        sub.Status,
+			DurationDays:  durationDay,
+			Amount:        formatDecimal(position.Amount),
+			StartedAt:     sub.StartedAt.Format(time.RFC3339),
+			MaturesAt:     position.MaturesAt.Format(time.RFC3339),
+			CancelledAt:   formatNullTime(sub.CancelledAt),
+			MaturedAt:     formatNullTime(sub.MaturedAt),
+			SettledAt:     formatNullTime(sub.SettledAt),
+			AutoRenew:     autoRenewToAPI(sub),
+			RenewedFromId: sub.RenewedFromId.Int64,
+			BotVersion:    sub.BotVersion,
+			TermsChanged:  sub.Status == model.SubscriptionStatusActive && bot.Version > sub.BotVersion,
+		},
+		Position: types.UserBotPosition{
+			CurrentValue:  formatDecimal(position.Value),
+			UnrealizedPnl: formatDecimal(position.Value - position.Amount),
+			RoiPercent:    roundPercent(position.RoiPercent),
+			ValuedAt:      position.ValuedAt.Format(dateLayout),
+		},
 	}, nil
 }
 
-// SubscribeBot subscribes a user to a bot
+// positionValue is a subscription's share of its bot's performance
+type positionValue struct {
+	Amount     float64
+	Value      float64
+	RoiPercent float64
+	MaturesAt  time.Time
+	ValuedAt   time.Time // Last snapshot day included
+}
+
+// valuePosition compounds the bot's daily returns from the day after the
+// subscription started until it was cancelled, matured or today, whichever
+// comes first. Settled subscriptions keep the value fixed at settlement.
+func (l *SubscriptionLogic) valuePosition(sub *model.UserBotSubscription) (*positionValue, error) {
+	durationDay, _ := strconv.Atoi(sub.DurationDay)
+	startDay := truncateToDay(sub.StartedAt)
+
+	amount, err := strconv.ParseFloat(sub.Amount, 64)
+	if err != nil {
+		amount = 0
+	}
+	position := &positionValue{
+		Amount:    amount,
+		Value:     amount,
+		MaturesAt: startDay.AddDate(0, 0, durationDay),
+		ValuedAt:  startDay,
+	}
+
+	valuedTo := truncateToDay(time.Now())
+	if sub.Status == model.SubscriptionStatusCancelled && sub.CancelledAt.Valid {
+		valuedTo = truncateToDay(sub.CancelledAt.Time)
+	}
+	if durationDay > 0 && position.MaturesAt.Before(valuedTo) {
+		valuedTo = position.MaturesAt
+	}
+
+	growth := 1.0
+	from := startDay.AddDate(0, 0, 1)
+	if !from.After(valuedTo) {
+		snapshots, err := l.svcCtx.BotPerformanceSnapshotModel.FindByBotIdBetween(l.ctx, sub.BotId, from, valuedTo)
+		if err != nil && err != model.ErrNotFound {
+			l.logger.Errorf("Failed to load performance snapshots for bot %s: %v", sub.BotId, err)
+			return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+		}
+		for _, s := range snapshots {
+			growth *= 1 + s.RoiPercent/100
+			position.ValuedAt = s.SnapshotDate
+		}
+	}
+	position.Value = amount * growth
+	position.RoiPercent = (growth - 1) * 100
+
+	if sub.Status == model.SubscriptionStatusSettled && sub.FinalValue.Valid {
+		if finalValue, err := strconv.ParseFloat(sub.FinalValue.String, 64); err == nil {
+			position.Value = finalValue
+			if amount > 0 {
+				position.RoiPercent = (finalValue/amount - 1) * 100
+			}
+		}
+	}
+
+	return position, nil
+}
+
+func formatNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return ""
+	}
+	return t.Time.Format(time.RFC3339)
+}
+
+// SubscribeBot subscribes a user to a bot, paying the amount from their USDT balance
 func (l *SubscriptionLogic) SubscribeBot(req *types.SubscribeBotReq) (resp *types.SubscribeResp, err error) {
-	// Find user by address
-	user, err := l.svcCtx.UserModel.FindOneByAddress(req.Address)
+	// Find user by address (no cache to get latest balance)
+	user, err := l.svcCtx.UserModel.FindOneByAddressNoCache(l.ctx, req.Address)
 	if err != nil {
 		if err == model.ErrNotFound {
-			return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, "User not found")
+			return nil, model.NewAPIError(model.ErrCodeUserNotFound, model.ErrMsgUserNotFound)
 		}
 		l.logger.Errorf("Failed to find user by address: %v", err)
 		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
 	}
 
 	// Check if bot exists
-	bot, err := l.svcCtx.BotModel.FindOne(req.BotId)
+	bot, err := l.svcCtx.BotModel.FindOne(l.ctx, req.BotId)
 	if err != nil {
 		if err == model.ErrNotFound {
-			return nil, model.NewAPIError("0300", "Bot not found")
+			return nil, model.NewAPIError(model.ErrCodeBotNotFound, model.ErrMsgBotNotFound)
 		}
 		l.logger.Errorf("Failed to find bot: %v", err)
 		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
 	}
 
-	// Validate durationDays
-	if req.DurationDays <= 0 {
-		return nil, model.NewAPIError(model.ErrCodeInvalidAmount, "duration_days must be greater than 0")
+	// Only the durations the bot offers can be subscribed to
+	if !isOfferedDuration(bot, req.DurationDays) {
+		return nil, model.NewAPIError(model.ErrCodeInvalidDuration, model.ErrMsgInvalidDuration)
 	}
 
-	// Check if already subscribed
-	existing, err := l.svcCtx.UserBotSubscriptionModel.FindByUserIdAndBotId(user.Id, req.BotId)
-	if err == nil && existing != nil {
-		// Already subscribed, return success with bot info
-		// Get duration_days from bot (not from subscription)
-		var durationDays []int
-		if bot.DurationDays != "" {
-			if err := json.Unmarshal([]byte(bot.DurationDays), &durationDays); err != nil {
-				durationDays = []int{5, 15, 30, 60, 90, 180}
-			}
-		} else {
-			durationDays = []int{5, 15, 30, 60, 90, 180}
-		}
-		apiBot := l.convertBotToAPI(bot, durationDays)
-		return &types.SubscribeResp{
-			Message: "Already subscribed",
-			Data:    &apiBot,
-		}, nil
+	// Validate amount against the bot's investment range
+	amount, err := strconv.ParseFloat(strings.TrimSpace(req.Amount), 64)
+	if err != nil || amount <= 0 {
+		return nil, model.NewAPIError(model.ErrCodeInvalidAmount, model.ErrMsgInvalidAmount)
+	}
+	if amount < float64(bot.MinInvestment) || (bot.MaxInvestment > 0 && amount > float64(bot.MaxInvestment)) {
+		return nil, model.NewAPIErrorf(model.ErrCodeInvalidAmount, "amount must be between %d and %d", bot.MinInvestment, bot.MaxInvestment)
 	}
 
-	// Default duration_days array: [5, 15, 30, 60, 90, 180] (from bot)
-	var durationDaysArray []int
-	if bot.DurationDays != "" {
-		if err := json.Unmarshal([]byte(bot.DurationDays), &durationDaysArray); err != nil {
-			durationDaysArray = []int{5, 15, 30, 60, 90, 180}
-		}
-	} else {
-		durationDaysArray = []int{5, 15, 30, 60, 90, 180}
+	renewDurationDay, renewMode, err := autoRenewSettings(bot, req.RenewDurationDays, req.RenewMode)
+	if err != nil {
+		return nil, err
+	}
+
+	debit, err := usdtChange(l.svcCtx, user, model.TransactionTypeSubscribe, -amount)
+	if err != nil {
+		return nil, err
+	}
+
+	durationDaysArray, err := parseDurationDays(bot.DurationDays)
+	if err != nil {
+		l.logger.Errorf("Failed to parse duration_days for bot %s: %v", bot.Id, err)
 	}
 
 	// Create subscription - only store duration_day
 	subscription := &model.UserBotSubscription{
-		UserId:      user.Id,
-		BotId:       req.BotId,
-		DurationDay: fmt.Sprintf("%d", req.DurationDays), // Store the selected duration day from API as string
+		UserId:           user.Id,
+		BotId:            req.BotId,
+		DurationDay:      fmt.Sprintf("%d", req.DurationDays), // Store the selected duration day from API as string
+		Amount:           formatDecimal(amount),
+		StartedAt:        time.Now(),
+		AutoRenew:        req.AutoRenew,
+		RenewDurationDay: renewDurationDay,
+		RenewMode:        renewMode,
 	}
 	l.logger.Infof("Creating subscription for user %d, bot %s with duration_day: %s", user.Id, req.BotId, subscription.DurationDay)
-	_, err = l.svcCtx.UserBotSubscriptionModel.Insert(subscription)
+	result, err := l.svcCtx.UserBotSubscriptionModel.Insert(l.ctx, subscription, debit)
+	if err == model.ErrBotFull {
+		return nil, model.NewAPIError(model.ErrCodeBotFull, model.ErrMsgBotFull)
+	}
+	if err == model.ErrBalanceChanged {
+		return nil, model.NewAPIError(model.ErrCodeFailedToUpdateBalance, model.ErrMsgFailedToUpdateBalance)
+	}
 	if err != nil {
 		l.logger.Errorf("Failed to create subscription: %v, error details: %+v, user_id: %d, bot_id: %s, duration_day: %s",
 			err, err, user.Id, req.BotId, subscription.DurationDay)
@@ -191,92 +275,257 @@ func (l *SubscriptionLogic) SubscribeBot(req *types.SubscribeBotReq) (resp *type
 		}
 		return nil, model.NewAPIError(model.ErrCodeDatabaseError, "Failed to subscribe to bot")
 	}
+	subscriptionId, _ := result.LastInsertId()
+	metrics.Subscriptions.Inc(req.BotId, metrics.ActionSubscribe)
 
-	// Update bot subscriber count
-	bot.Subscribers++
-	if err := l.svcCtx.BotModel.Update(bot); err != nil {
-		l.logger.Errorf("Failed to update bot subscriber count: %v", err)
+	// The insert updated the subscriber count; reload it for the response
+	if updated, err := l.svcCtx.BotModel.FindOne(l.ctx, req.BotId); err == nil {
+		bot = updated
 	}
 
 	apiBot := l.convertBotToAPI(bot, durationDaysArray)
 	return &types.SubscribeResp{
-		Message: "Subscribed successfully",
-		Data:    &apiBot,
+		Message:        "Subscribed successfully",
+		SubscriptionId: subscriptionId,
+		Data:           &apiBot,
 	}, nil
 }
 
-// UnsubscribeBot unsubscribes a user from a bot
+// UnsubscribeBot cancels one active position when subscription_id is given,
+// otherwise all of the user's active positions in the bot, and pays their
+// principal back to the USDT balance. Returns are only paid out when a position
+// is settled at maturity, so leaving early forfeits them. Cancelled
+// subscriptions are kept for history.
 func (l *SubscriptionLogic) UnsubscribeBot(req *types.UnsubscribeBotReq) (resp *types.SubscribeResp, err error) {
 	// Find user by address
-	user, err := l.svcCtx.UserModel.FindOneByAddress(req.Address)
+	user, err := l.svcCtx.UserModel.FindOneByAddress(l.ctx, req.Address)
 	if err != nil {
 		if err == model.ErrNotFound {
-			return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, "User not found")
+			return nil, model.NewAPIError(model.ErrCodeUserNotFound, model.ErrMsgUserNotFound)
 		}
 		l.logger.Errorf("Failed to find user by address: %v", err)
 		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
 	}
 
 	// Check if subscription exists
-	subscription, err := l.svcCtx.UserBotSubscriptionModel.FindByUserIdAndBotId(user.Id, req.BotId)
-	if err != nil {
-		if err == model.ErrNotFound {
-			return &types.SubscribeResp{
-				Message: "Not subscribed to this bot",
-			}, nil
-		}
+	active, err := l.svcCtx.UserBotSubscriptionModel.FindActiveByUserIdAndBotId(l.ctx, user.Id, req.BotId)
+	if err != nil && err != model.ErrNotFound {
 		l.logger.Errorf("Failed to find subscription: %v", err)
 		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
 	}
 
-	// Delete subscription
-	if err := l.svcCtx.UserBotSubscriptionModel.Delete(subscription.Id); err != nil {
-		l.logger.Errorf("Failed to delete subscription: %v", err)
-		return nil, model.NewAPIError(model.ErrCodeDatabaseError, "Failed to unsubscribe from bot")
+	toCancel := active
+	if req.SubscriptionId > 0 {
+		toCancel = nil
+		for _, sub := range active {
+			if sub.Id == req.SubscriptionId {
+				toCancel = append(toCancel, sub)
+			}
+		}
+	}
+	if len(toCancel) == 0 {
+		return &types.SubscribeResp{
+			Message: "Not subscribed to this bot",
+		}, nil
 	}
 
-	// Update bot subscriber count
-	bot, err := l.svcCtx.BotModel.FindOne(req.BotId)
-	if err == nil && bot != nil {
-		if bot.Subscribers > 0 {
-			bot.Subscribers--
+	// The positions are cancelled and refunded together, so either all of them
+	// are or none is. Cancelling the user's last active position also releases
+	// them from the bot's subscriber count.
+	now := time.Now()
+	var cancelled []*model.UserBotSubscription
+	for attempt := 1; ; attempt++ {
+		// Read the balance as it is now; the refund only applies on top of it
+		current, err := l.svcCtx.UserModel.FindOneByAddressNoCache(l.ctx, user.Address)
+		if err != nil {
+			l.logger.Errorf("Failed to find user by address: %v", err)
+			return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
 		}
-		if err := l.svcCtx.BotModel.Update(bot); err != nil {
-			l.logger.Errorf("Failed to update bot subscriber count: %v", err)
+		cancelled, err = l.svcCtx.UserBotSubscriptionModel.Cancel(l.ctx, toCancel, now, func(positions []*model.UserBotSubscription) (*model.BalanceChange, error) {
+			return l.refund(current, positions)
+		})
+		if err == nil {
+			break
+		}
+		if err != model.ErrBalanceChanged {
+			var apiErr *model.APIError
+			if errors.As(err, &apiErr) {
+				return nil, err
+			}
+			l.logger.Errorf("Failed to cancel subscriptions of user %d in bot %s: %v", user.Id, req.BotId, err)
+			return nil, model.NewAPIError(model.ErrCodeDatabaseError, "Failed to unsubscribe from bot")
+		}
+		// Another request changed the balance since it was read
+		if attempt == maxRefundAttempts {
+			return nil, model.NewAPIError(model.ErrCodeConflict, "Balance changed while unsubscribing, please retry")
 		}
 	}
+	for _, sub := range cancelled {
+		metrics.Subscriptions.Inc(sub.BotId, metrics.ActionUnsubscribe)
+	}
 
+	// The Unsubscribed events hand the freed capacity to the next users on the
+	// bot's waitlist, see RegisterEventHandlers
 	return &types.SubscribeResp{
-		Message: "Unsubscribed successfully",
+		Message:        "Unsubscribed successfully",
+		SubscriptionId: req.SubscriptionId,
 	}, nil
 }
 
-func (l *SubscriptionLogic) convertBotToAPI(bot *model.Bot, durationDays []int) types.Bot {
-	return types.Bot{
-		Id:                    bot.Id,
-		Name:                  bot.Name,
-		IconLetter:            bot.IconLetter,
-		RiskLevel:             bot.RiskLevel,
-		DurationDays:          durationDays,
-		ExpectedReturnPercent: bot.ExpectedReturnPercent,
-		AprDisplay:            bot.AprDisplay,
-		MinInvestment:         bot.MinInvestment,
-		MaxInvestment:         bot.MaxInvestment,
-		InvestmentRange:       bot.InvestmentRange,
-		Subscribers:           bot.Subscribers,
-		Author:                bot.Author,
-		Description:           bot.Description,
-		IsActive:              bot.IsActive,
-		Metrics: types.BotMetrics{
-			LockupPeriod:   bot.LockupPeriod,
-			ExpectedReturn: bot.ExpectedReturn,
-			MinInvestment:  bot.MinInvestmentDisplay,
-			MaxInvestment:  bot.MaxInvestmentDisplay,
-			Roi30d:         bot.Roi30d,
-			WinRate:        bot.WinRate,
-			TradingPair:    bot.TradingPair,
-			TotalTrades:    bot.TotalTrades,
-			Pnl30d:         bot.Pnl30d,
+// maxRefundAttempts bounds how often UnsubscribeBot re-reads a balance that
+// changed while it was being refunded
+const maxRefundAttempts = 3
+
+// refund returns the balance change that pays the principal of the cancelled
+// subscriptions back to the user
+func (l *SubscriptionLogic) refund(user *model.User, cancelled []*model.UserBotSubscription) (*model.BalanceChange, error) {
+	var principal float64
+	for _, sub := range cancelled {
+		amount, err := strconv.ParseFloat(sub.Amount, 64)
+		if err != nil {
+			l.logger.Errorf("Invalid amount %q of subscription %d: %v", sub.Amount, sub.Id, err)
+			return nil, model.NewAPIError(model.ErrCodeFailedToUpdateBalance, model.ErrMsgFailedToUpdateBalance)
+		}
+		principal += amount
+	}
+	return usdtChange(l.svcCtx, user, model.TransactionTypeRefund, principal)
+}
+
+// usdtChange builds the transaction that adds amount, or removes it when
+// negative, to the user's USDT balance on their network. It returns nil when
+// amount rounds to zero, and an insufficient balance error when the balance
+// does not cover a removal.
+func usdtChange(svcCtx *svc.ServiceContext, user *model.User, txType string, amount float64) (*model.BalanceChange, error) {
+	amountStr := formatDecimal(math.Abs(amount))
+	if amountStr == "0" {
+		return nil, nil
+	}
+
+	balanceBefore := user.UsdtBalance
+	if balanceBefore == "" {
+		balanceBefore = "0"
+	}
+	balance, err := strconv.ParseFloat(balanceBefore, 64)
+	if err != nil {
+		return nil, model.NewAPIError(model.ErrCodeFailedToUpdateBalance, model.ErrMsgFailedToUpdateBalance)
+	}
+	if amount < 0 && balance < -amount {
+		return nil, model.NewAPIError(model.ErrCodeInsufficientBalance, model.ErrMsgInsufficientBalance)
+	}
+	balanceAfter := formatDecimal(math.Max(balance+amount, 0))
+
+	// Without a chain id the user's network is used, so this cannot fail
+	network, _ := resolveChain(svcCtx.Chains, 0, user.ChainId)
+	updated := *user
+	updated.UsdtBalance = balanceAfter
+	return &model.BalanceChange{
+		User: &updated,
+		Transaction: &model.Transaction{
+			UserId:        user.Id,
+			ChainId:       network.Id,
+			Type:          txType,
+			Currency:      "usdt",
+			Amount:        amountStr,
+			BalanceBefore: balanceBefore,
+			BalanceAfter:  balanceAfter,
+			Status:        "completed",
 		},
+	}, nil
+}
+
+// UpdateAutoRenew changes the auto-renew settings of one of the user's active
+// subscriptions. Once a subscription has matured its settings are final.
+func (l *SubscriptionLogic) UpdateAutoRenew(req *types.UpdateAutoRenewReq) (resp *types.AutoRenewResp, err error) {
+	user, err := l.svcCtx.UserModel.FindOneByAddress(l.ctx, req.Address)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeUserNotFound, model.ErrMsgUserNotFound)
+		}
+		l.logger.Errorf("Failed to find user by address: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
+	}
+
+	sub, err := l.svcCtx.UserBotSubscriptionModel.FindOne(l.ctx, req.SubscriptionId)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find subscription %d: %v", req.SubscriptionId, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	if sub == nil || sub.UserId != user.Id || sub.Status != model.SubscriptionStatusActive {
+		return nil, model.NewAPIError(model.ErrCodeSubscriptionNotFound, model.ErrMsgSubscriptionNotFound)
+	}
+
+	bot, err := l.svcCtx.BotModel.FindOne(l.ctx, sub.BotId)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeBotNotFound, model.ErrMsgBotNotFound)
+		}
+		l.logger.Errorf("Failed to find bot: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	renewDurationDay, renewMode, err := autoRenewSettings(bot, req.RenewDurationDays, req.RenewMode)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := l.svcCtx.UserBotSubscriptionModel.UpdateAutoRenew(l.ctx, sub.Id, req.AutoRenew, renewDurationDay, renewMode)
+	if err != nil {
+		l.logger.Errorf("Failed to update auto-renew for subscription %d: %v", sub.Id, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	if !ok {
+		// Matured or cancelled since it was read
+		return nil, model.NewAPIError(model.ErrCodeSubscriptionNotFound, model.ErrMsgSubscriptionNotFound)
+	}
+
+	sub.AutoRenew = req.AutoRenew
+	sub.RenewDurationDay = renewDurationDay
+	sub.RenewMode = renewMode
+	return &types.AutoRenewResp{
+		Message:        "success",
+		SubscriptionId: sub.Id,
+		Data:           autoRenewToAPI(sub),
+	}, nil
+}
+
+// autoRenewSettings validates the requested renewal duration against the bot's
+// durationDays and defaults the mode to compounding
+func autoRenewSettings(bot *model.Bot, renewDurationDays int, renewMode string) (string, string, error) {
+	renewDurationDay := ""
+	if renewDurationDays != 0 {
+		if !isOfferedDuration(bot, renewDurationDays) {
+			return "", "", model.NewAPIError(model.ErrCodeInvalidAutoRenew, "renew_duration_days must be one of the bot's durationDays")
+		}
+		renewDurationDay = strconv.Itoa(renewDurationDays)
+	}
+
+	switch renewMode {
+	case "":
+		renewMode = model.RenewModeCompound
+	case model.RenewModeCompound, model.RenewModePayout:
+	default:
+		return "", "", model.NewAPIError(model.ErrCodeInvalidAutoRenew, "renew_mode must be 'compound' or 'payout'")
 	}
+	return renewDurationDay, renewMode, nil
+}
+
+func autoRenewToAPI(sub *model.UserBotSubscription) types.UserBotAutoRenew {
+	durationDay := sub.DurationDay
+	if sub.RenewDurationDay != "" {
+		durationDay = sub.RenewDurationDay
+	}
+	days, _ := strconv.Atoi(durationDay)
+	mode := sub.RenewMode
+	if mode == "" {
+		mode = model.RenewModeCompound
+	}
+	return types.UserBotAutoRenew{
+		Enabled:      sub.AutoRenew,
+		DurationDays: days,
+		Mode:         mode,
+	}
+}
+
+func (l *SubscriptionLogic) convertBotToAPI(bot *model.Bot, durationDays []int) types.Bot {
+	return botToAPI(bot, durationDays)
 }
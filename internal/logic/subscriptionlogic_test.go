@@ -0,0 +1,366 @@
+package logic
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"wata-bot-BE/internal/address"
+	"wata-bot-BE/internal/chain"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+)
+
+// fakeSnapshotModel serves a bot's daily returns from memory; methods the
+// tests do not use panic
+type fakeSnapshotModel struct {
+	model.BotPerformanceSnapshotModel
+	snapshots []*model.BotPerformanceSnapshot
+	err       error
+}
+
+func (m *fakeSnapshotModel) FindByBotIdBetween(ctx context.Context, botId string, from, to time.Time) ([]*model.BotPerformanceSnapshot, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	var resp []*model.BotPerformanceSnapshot
+	for _, s := range m.snapshots {
+		if s.BotId == botId && !s.SnapshotDate.Before(from) && !s.SnapshotDate.After(to) {
+			resp = append(resp, s)
+		}
+	}
+	if len(resp) == 0 {
+		return nil, model.ErrNotFound
+	}
+	return resp, nil
+}
+
+func TestValuePosition(t *testing.T) {
+	today := truncateToDay(time.Now())
+	day := func(offset int) time.Time {
+		return today.AddDate(0, 0, offset)
+	}
+	// grid returns 10% on each of the last 10 days up to today
+	var snapshots []*model.BotPerformanceSnapshot
+	for offset := -9; offset <= 0; offset++ {
+		snapshots = append(snapshots, &model.BotPerformanceSnapshot{BotId: "grid", SnapshotDate: day(offset), RoiPercent: 10})
+	}
+	snapshots = append(snapshots,
+		&model.BotPerformanceSnapshot{BotId: "swing", SnapshotDate: day(-1), RoiPercent: 20},
+		&model.BotPerformanceSnapshot{BotId: "swing", SnapshotDate: day(0), RoiPercent: -50},
+	)
+
+	sub := func(startOffset int, duration string, modify ...func(s *model.UserBotSubscription)) *model.UserBotSubscription {
+		s := &model.UserBotSubscription{
+			Id:          7,
+			BotId:       "grid",
+			DurationDay: duration,
+			Amount:      "1000",
+			StartedAt:   day(startOffset).Add(15 * time.Hour),
+			Status:      model.SubscriptionStatusActive,
+		}
+		for _, m := range modify {
+			m(s)
+		}
+		return s
+	}
+
+	tests := []struct {
+		name         string
+		sub          *model.UserBotSubscription
+		wantValue    float64
+		wantRoi      float64
+		wantValuedAt time.Time
+		wantMatures  time.Time
+	}{
+		{
+			name:         "compounds from the day after the start",
+			sub:          sub(-3, "30"),
+			wantValue:    1000 * 1.1 * 1.1 * 1.1,
+			wantRoi:      33.1,
+			wantValuedAt: day(0),
+			wantMatures:  day(27),
+		},
+		{
+			name:         "started today",
+			sub:          sub(0, "30"),
+			wantValue:    1000,
+			wantValuedAt: day(0),
+			wantMatures:  day(30),
+		},
+		{
+			name: "losses compound too",
+			sub: sub(-2, "30", func(s *model.UserBotSubscription) {
+				s.BotId = "swing"
+			}),
+			wantValue:    1000 * 1.2 * 0.5,
+			wantRoi:      -40,
+			wantValuedAt: day(0),
+			wantMatures:  day(28),
+		},
+		{
+			name:         "no snapshots",
+			sub:          sub(-3, "30", func(s *model.UserBotSubscription) { s.BotId = "idle" }),
+			wantValue:    1000,
+			wantValuedAt: day(-3),
+			wantMatures:  day(27),
+		},
+		{
+			name: "stops at cancellation",
+			sub: sub(-5, "30", func(s *model.UserBotSubscription) {
+				s.Status = model.SubscriptionStatusCancelled
+				s.CancelledAt = sql.NullTime{Time: day(-3).Add(10 * time.Hour), Valid: true}
+			}),
+			wantValue:    1000 * 1.1 * 1.1,
+			wantRoi:      21,
+			wantValuedAt: day(-3),
+			wantMatures:  day(25),
+		},
+		{
+			name:         "stops at maturity",
+			sub:          sub(-8, "2", func(s *model.UserBotSubscription) { s.Status = model.SubscriptionStatusMatured }),
+			wantValue:    1000 * 1.1 * 1.1,
+			wantRoi:      21,
+			wantValuedAt: day(-6),
+			wantMatures:  day(-6),
+		},
+		{
+			name: "settled keeps its final value",
+			sub: sub(-8, "2", func(s *model.UserBotSubscription) {
+				s.Status = model.SubscriptionStatusSettled
+				s.FinalValue = sql.NullString{String: "1250", Valid: true}
+			}),
+			wantValue:    1250,
+			wantRoi:      25,
+			wantValuedAt: day(-6),
+			wantMatures:  day(-6),
+		},
+	}
+
+	l := NewSubscriptionLogic(context.Background(), &svc.ServiceContext{
+		BotPerformanceSnapshotModel: &fakeSnapshotModel{snapshots: snapshots},
+	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := l.valuePosition(tt.sub)
+			if err != nil {
+				t.Fatalf("valuePosition() error = %v", err)
+			}
+			if got.Amount != 1000 {
+				t.Errorf("Amount = %v, want 1000", got.Amount)
+			}
+			if math.Abs(got.Value-tt.wantValue) > 1e-9 {
+				t.Errorf("Value = %v, want %v", got.Value, tt.wantValue)
+			}
+			if math.Abs(got.RoiPercent-tt.wantRoi) > 1e-9 {
+				t.Errorf("RoiPercent = %v, want %v", got.RoiPercent, tt.wantRoi)
+			}
+			if !got.ValuedAt.Equal(tt.wantValuedAt) {
+				t.Errorf("ValuedAt = %v, want %v", got.ValuedAt, tt.wantValuedAt)
+			}
+			if !got.MaturesAt.Equal(tt.wantMatures) {
+				t.Errorf("MaturesAt = %v, want %v", got.MaturesAt, tt.wantMatures)
+			}
+		})
+	}
+}
+
+func TestValuePositionSnapshotError(t *testing.T) {
+	l := NewSubscriptionLogic(context.Background(), &svc.ServiceContext{
+		BotPerformanceSnapshotModel: &fakeSnapshotModel{err: errors.New("connection refused")},
+	})
+	sub := &model.UserBotSubscription{
+		BotId:       "grid",
+		DurationDay: "30",
+		Amount:      "1000",
+		StartedAt:   time.Now().AddDate(0, 0, -3),
+		Status:      model.SubscriptionStatusActive,
+	}
+
+	_, err := l.valuePosition(sub)
+	var apiErr *model.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != model.ErrCodeDatabaseError {
+		t.Fatalf("valuePosition() error = %v, want code %s", err, model.ErrCodeDatabaseError)
+	}
+}
+
+func TestUsdtChange(t *testing.T) {
+	tests := []struct {
+		name       string
+		balance    string
+		amount     float64
+		wantAmount string // Empty when no change is needed
+		wantAfter  string
+		wantCode   string
+	}{
+		{name: "credit", balance: "100.5", amount: 1100.25, wantAmount: "1100.25", wantAfter: "1200.75"},
+		{name: "credit empty balance", balance: "", amount: 50, wantAmount: "50", wantAfter: "50"},
+		{name: "debit", balance: "1500", amount: -1000, wantAmount: "1000", wantAfter: "500"},
+		{name: "debit whole balance", balance: "1000", amount: -1000, wantAmount: "1000", wantAfter: "0"},
+		{name: "debit over balance", balance: "999.99", amount: -1000, wantCode: model.ErrCodeInsufficientBalance},
+		{name: "rounds to zero", balance: "10", amount: 0.000000001},
+		{name: "corrupt balance", balance: "abc", amount: 10, wantCode: model.ErrCodeFailedToUpdateBalance},
+	}
+	svcCtx := &svc.ServiceContext{Chains: chain.NewRegistry([]chain.Conf{{Id: 1, Name: "Ethereum"}, {Id: 56, Name: "BNB Chain"}})}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &model.User{Id: 3, ChainId: 56, UsdtBalance: tt.balance, WataBalance: "7"}
+			change, err := usdtChange(svcCtx, user, model.TransactionTypeSettle, tt.amount)
+			if tt.wantCode != "" {
+				var apiErr *model.APIError
+				if !errors.As(err, &apiErr) || apiErr.Code != tt.wantCode {
+					t.Fatalf("usdtChange() error = %v, want code %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("usdtChange() error = %v", err)
+			}
+			if tt.wantAmount == "" {
+				if change != nil {
+					t.Fatalf("usdtChange() = %+v, want nil", change)
+				}
+				return
+			}
+
+			tx := change.Transaction
+			if tx.Amount != tt.wantAmount || tx.BalanceAfter != tt.wantAfter || tx.Currency != "usdt" || tx.Type != model.TransactionTypeSettle || tx.ChainId != 56 {
+				t.Errorf("transaction = %+v, want %s usdt on chain 56 ending at %s", tx, tt.wantAmount, tt.wantAfter)
+			}
+			if change.User.UsdtBalance != tt.wantAfter || change.User.WataBalance != "7" {
+				t.Errorf("user balances = %s usdt, %s wata, want %s usdt and wata unchanged", change.User.UsdtBalance, change.User.WataBalance, tt.wantAfter)
+			}
+			if user.UsdtBalance != tt.balance {
+				t.Errorf("usdtChange() modified the user it was given")
+			}
+		})
+	}
+}
+
+// fakeUserModel serves one user whose balance reads return balances in turn,
+// repeating the last one
+type fakeUserModel struct {
+	model.UserModel
+	user     *model.User
+	balances []string
+	reads    int
+}
+
+func (m *fakeUserModel) FindOneByAddress(ctx context.Context, addr address.Address) (*model.User, error) {
+	return m.user, nil
+}
+
+func (m *fakeUserModel) FindOneByAddressNoCache(ctx context.Context, addr address.Address) (*model.User, error) {
+	user := *m.user
+	user.UsdtBalance = m.balances[min(m.reads, len(m.balances)-1)]
+	m.reads++
+	return &user, nil
+}
+
+// fakeSubscriptionModel cancels every position it is given once the balance
+// the refund was built on is current
+type fakeSubscriptionModel struct {
+	model.UserBotSubscriptionModel
+	active  []*model.UserBotSubscription
+	balance string // Current USDT balance
+	refunds []*model.BalanceChange
+}
+
+func (m *fakeSubscriptionModel) FindActiveByUserIdAndBotId(ctx context.Context, userId int64, botId string) ([]*model.UserBotSubscription, error) {
+	return m.active, nil
+}
+
+func (m *fakeSubscriptionModel) Cancel(ctx context.Context, subs []*model.UserBotSubscription, at time.Time, refund func(cancelled []*model.UserBotSubscription) (*model.BalanceChange, error)) ([]*model.UserBotSubscription, error) {
+	change, err := refund(subs)
+	if err != nil {
+		return nil, err
+	}
+	if change.Transaction.BalanceBefore != m.balance {
+		return nil, model.ErrBalanceChanged
+	}
+	m.refunds = append(m.refunds, change)
+	return subs, nil
+}
+
+func TestUnsubscribeBot(t *testing.T) {
+	tests := []struct {
+		name           string
+		subscriptionId int64
+		balances       []string // Balances read before each attempt
+		wantAmount     string   // Refunded principal, empty for none
+		wantCode       string
+	}{
+		{name: "all positions", balances: []string{"100"}, wantAmount: "1500"},
+		{name: "one position", subscriptionId: 8, balances: []string{"100"}, wantAmount: "500"},
+		{name: "balance changed once", balances: []string{"50", "100"}, wantAmount: "1500"},
+		{name: "balance keeps changing", balances: []string{"50", "60", "70", "80"}, wantCode: model.ErrCodeConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &fakeUserModel{user: &model.User{Id: 3, Address: "0xabc", ChainId: 56}, balances: tt.balances}
+			subs := &fakeSubscriptionModel{
+				active: []*model.UserBotSubscription{
+					{Id: 7, UserId: 3, BotId: "grid", Amount: "1000", Status: model.SubscriptionStatusActive},
+					{Id: 8, UserId: 3, BotId: "grid", Amount: "500", Status: model.SubscriptionStatusActive},
+				},
+				balance: "100",
+			}
+			svcCtx := &svc.ServiceContext{
+				Chains:                   chain.NewRegistry([]chain.Conf{{Id: 56, Name: "BNB Chain"}}),
+				UserModel:                users,
+				UserBotSubscriptionModel: subs,
+			}
+
+			_, err := NewSubscriptionLogic(context.Background(), svcCtx).UnsubscribeBot(&types.UnsubscribeBotReq{
+				Address:        "0xabc",
+				BotId:          "grid",
+				SubscriptionId: tt.subscriptionId,
+			})
+			if tt.wantCode != "" {
+				var apiErr *model.APIError
+				if !errors.As(err, &apiErr) || apiErr.Code != tt.wantCode {
+					t.Fatalf("UnsubscribeBot() error = %v, want code %s", err, tt.wantCode)
+				}
+				if len(subs.refunds) != 0 {
+					t.Errorf("refunds = %d, want none", len(subs.refunds))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnsubscribeBot() error = %v", err)
+			}
+			if len(subs.refunds) != 1 {
+				t.Fatalf("refunds = %d, want 1", len(subs.refunds))
+			}
+			if tx := subs.refunds[0].Transaction; tx.Amount != tt.wantAmount || tx.Type != model.TransactionTypeRefund {
+				t.Errorf("refund = %s %s, want %s refund", tx.Amount, tx.Type, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestSubscribeBotRejectsUnofferedDuration(t *testing.T) {
+	svcCtx := &svc.ServiceContext{
+		Chains:    chain.NewRegistry([]chain.Conf{{Id: 56, Name: "BNB Chain"}}),
+		UserModel: &fakeUserModel{user: &model.User{Id: 3, Address: "0xabc", ChainId: 56}, balances: []string{"5000"}},
+		BotModel: &fakeBotModel{bots: map[string]*model.Bot{
+			"grid": {Id: "grid", IsActive: true, DurationDays: "[30,90]", MinInvestment: 100, MaxInvestment: 10000},
+		}},
+	}
+	for _, days := range []int{0, 45, 180} {
+		_, err := NewSubscriptionLogic(context.Background(), svcCtx).SubscribeBot(&types.SubscribeBotReq{
+			Address:      "0xabc",
+			BotId:        "grid",
+			DurationDays: days,
+			Amount:       "1000",
+		})
+		var apiErr *model.APIError
+		if !errors.As(err, &apiErr) || apiErr.Code != model.ErrCodeInvalidDuration {
+			t.Errorf("SubscribeBot() with %d days error = %v, want code %s", days, err, model.ErrCodeInvalidDuration)
+		}
+	}
+}
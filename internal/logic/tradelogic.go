@@ -0,0 +1,216 @@
+package logic
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+const (
+	// maxTradesPerRequest bounds a single ingestion call
+	maxTradesPerRequest = 1000
+	// maxTradesPageSize bounds the public trade log page size
+	maxTradesPageSize = 100
+)
+
+type TradeLogic struct {
+	logger logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewTradeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TradeLogic {
+	return &TradeLogic{
+		logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// IngestTrades stores closed trades pushed by the trading engine and refreshes
+// the bot's derived win rate and total trades
+func (l *TradeLogic) IngestTrades(req *types.IngestTradesReq) (resp *types.IngestTradesResp, err error) {
+	if len(req.Trades) == 0 || len(req.Trades) > maxTradesPerRequest {
+		return nil, model.NewAPIErrorf(model.ErrCodeInvalidTradeData, "trades must contain between 1 and %d entries", maxTradesPerRequest)
+	}
+
+	if _, err := l.svcCtx.BotModel.FindOne(l.ctx, req.BotId); err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeBotNotFound, model.ErrMsgBotNotFound)
+		}
+		l.logger.Errorf("Failed to find bot %s: %v", req.BotId, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	// Validate everything before writing so a bad entry doesn't leave a partial import
+	trades := make([]*model.BotTrade, 0, len(req.Trades))
+	for _, t := range req.Trades {
+		trade, err := l.toModelTrade(req.BotId, t)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, trade)
+	}
+
+	inserted := 0
+	for _, trade := range trades {
+		ok, err := l.svcCtx.BotTradeModel.InsertIgnore(l.ctx, trade)
+		if err != nil {
+			l.logger.Errorf("Failed to store trade %s for bot %s: %v", trade.ExternalId, req.BotId, err)
+			return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+		}
+		if ok {
+			inserted++
+		}
+	}
+
+	metrics, err := NewPerformanceLogic(l.ctx, l.svcCtx).RecomputeMetrics(req.BotId)
+	if err != nil {
+		return nil, err
+	}
+
+	l.logger.Infof("Ingested %d trades for bot %s (%d duplicates)", inserted, req.BotId, len(trades)-inserted)
+
+	return &types.IngestTradesResp{
+		Message: "success",
+		Data: types.IngestTradesData{
+			Inserted:   inserted,
+			Duplicates: len(trades) - inserted,
+			Metrics: types.BotMetrics{
+				Roi30d:      metrics.Roi30d,
+				WinRate:     metrics.WinRate,
+				TotalTrades: metrics.TotalTrades,
+				Pnl30d:      metrics.Pnl30d,
+			},
+		},
+	}, nil
+}
+
+// BotTrades returns a page of the bot's public trade log
+func (l *TradeLogic) BotTrades(req *types.BotTradesReq) (resp *types.BotTradesResp, err error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > maxTradesPageSize {
+		return nil, model.NewAPIErrorf(model.ErrCodeInvalidTradeData, "pageSize must be between 1 and %d", maxTradesPageSize)
+	}
+
+	if _, err := l.svcCtx.BotModel.FindOne(l.ctx, req.BotId); err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeBotNotFound, model.ErrMsgBotNotFound)
+		}
+		l.logger.Errorf("Failed to find bot %s: %v", req.BotId, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	total, err := l.svcCtx.BotTradeModel.CountByBotId(l.ctx, req.BotId)
+	if err != nil {
+		l.logger.Errorf("Failed to count trades for bot %s: %v", req.BotId, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	trades, err := l.svcCtx.BotTradeModel.FindPageByBotId(l.ctx, req.BotId, req.Page, req.PageSize)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find trades for bot %s: %v", req.BotId, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	items := make([]types.BotTrade, 0, len(trades))
+	for _, t := range trades {
+		items = append(items, types.BotTrade{
+			ExternalId: t.ExternalId,
+			Pair:       t.Pair,
+			Side:       t.Side,
+			EntryPrice: t.EntryPrice,
+			ExitPrice:  t.ExitPrice,
+			Size:       t.Size,
+			Pnl:        t.Pnl,
+			OpenedAt:   t.OpenedAt.Format(time.RFC3339),
+			ClosedAt:   t.ClosedAt.Format(time.RFC3339),
+		})
+	}
+
+	return &types.BotTradesResp{
+		Message: "success",
+		Data: types.BotTradesData{
+			Items:    items,
+			Page:     req.Page,
+			PageSize: req.PageSize,
+			Total:    total,
+		},
+	}, nil
+}
+
+// toModelTrade validates an ingested trade and converts it to its row
+func (l *TradeLogic) toModelTrade(botId string, t types.BotTrade) (*model.BotTrade, error) {
+	externalId := strings.TrimSpace(t.ExternalId)
+	if externalId == "" || len(externalId) > 64 {
+		return nil, model.NewAPIError(model.ErrCodeInvalidTradeData, "externalId is required and must be at most 64 characters")
+	}
+
+	pair := strings.ToUpper(strings.TrimSpace(t.Pair))
+	if pair == "" || len(pair) > 20 {
+		return nil, model.NewAPIErrorf(model.ErrCodeInvalidTradeData, "invalid pair for trade %s", externalId)
+	}
+
+	side := strings.ToLower(strings.TrimSpace(t.Side))
+	if side != "long" && side != "short" {
+		return nil, model.NewAPIErrorf(model.ErrCodeInvalidTradeData, "side must be 'long' or 'short' for trade %s", externalId)
+	}
+
+	for _, v := range []string{t.EntryPrice, t.ExitPrice, t.Size} {
+		if !isPositiveDecimal(v) {
+			return nil, model.NewAPIErrorf(model.ErrCodeInvalidTradeData, "entryPrice, exitPrice and size must be positive numbers for trade %s", externalId)
+		}
+	}
+
+	if math.IsNaN(t.Pnl) || math.IsInf(t.Pnl, 0) {
+		return nil, model.NewAPIErrorf(model.ErrCodeInvalidTradeData, "invalid pnl for trade %s", externalId)
+	}
+
+	openedAt, err := time.Parse(time.RFC3339, t.OpenedAt)
+	if err != nil {
+		return nil, model.NewAPIErrorf(model.ErrCodeInvalidTradeData, "invalid openedAt for trade %s, expected RFC3339", externalId)
+	}
+	closedAt, err := time.Parse(time.RFC3339, t.ClosedAt)
+	if err != nil {
+		return nil, model.NewAPIErrorf(model.ErrCodeInvalidTradeData, "invalid closedAt for trade %s, expected RFC3339", externalId)
+	}
+	if closedAt.Before(openedAt) {
+		return nil, model.NewAPIErrorf(model.ErrCodeInvalidTradeData, "closedAt must not be before openedAt for trade %s", externalId)
+	}
+
+	return &model.BotTrade{
+		BotId:      botId,
+		ExternalId: externalId,
+		Pair:       pair,
+		Side:       side,
+		EntryPrice: strings.TrimSpace(t.EntryPrice),
+		ExitPrice:  strings.TrimSpace(t.ExitPrice),
+		Size:       strings.TrimSpace(t.Size),
+		Pnl:        t.Pnl,
+		OpenedAt:   openedAt.Local(),
+		ClosedAt:   closedAt.Local(),
+	}, nil
+}
+
+// isPositiveDecimal reports whether s is a plain, finite, positive decimal number
+func isPositiveDecimal(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.ContainsAny(s, "eExXpP_") {
+		return false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return false
+	}
+	return v > 0
+}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"wata-bot-BE/internal/chain"
+	"wata-bot-BE/internal/metrics"
 	"wata-bot-BE/internal/model"
 	"wata-bot-BE/internal/svc"
 	"wata-bot-BE/internal/types"
@@ -43,15 +45,21 @@ func (l *TransactionLogic) Deposit(req *types.DepositReq) (resp *types.Transacti
 	}
 
 	// Find user by address (no cache to get latest balance)
-	user, err := l.svcCtx.UserModel.FindOneByAddressNoCache(req.Address)
+	user, err := l.svcCtx.UserModel.FindOneByAddressNoCache(l.ctx, req.Address)
 	if err != nil {
 		if err == model.ErrNotFound {
-			return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, "User not found")
+			return nil, model.NewAPIError(model.ErrCodeUserNotFound, model.ErrMsgUserNotFound)
 		}
 		l.logger.Errorf("Failed to find user by address: %v", err)
 		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
 	}
 
+	// Validate chain
+	network, err := l.chainFor(req.ChainId, user, currency)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get current balance
 	var balanceBefore, balanceAfter string
 	if currency == "wata" {
@@ -70,16 +78,11 @@ func (l *TransactionLogic) Deposit(req *types.DepositReq) (resp *types.Transacti
 		user.UsdtBalance = balanceAfter
 	}
 
-	// Update user balance
-	if err := l.svcCtx.UserModel.Update(user); err != nil {
-		l.logger.Errorf("Failed to update user balance: %v", err)
-		return nil, model.NewAPIError(model.ErrCodeFailedToUpdateBalance, model.ErrMsgFailedToUpdateBalance)
-	}
-
-	// Create transaction record
+	// Update user balance and record the transaction together
 	transaction := &model.Transaction{
 		UserId:        user.Id,
-		Type:          "deposit",
+		ChainId:       network.Id,
+		Type:          model.TransactionTypeDeposit,
 		Currency:      currency,
 		Amount:        req.Amount,
 		BalanceBefore: balanceBefore,
@@ -87,19 +90,17 @@ func (l *TransactionLogic) Deposit(req *types.DepositReq) (resp *types.Transacti
 		Status:        "completed",
 		TxHash:        req.TxHash,
 	}
-
-	result, err := l.svcCtx.TransactionModel.Insert(transaction)
-	if err != nil {
-		l.logger.Errorf("Failed to create transaction record: %v", err)
-		// Don't fail the deposit if transaction record fails, but log it
-	} else {
-		transactionId, _ := result.LastInsertId()
-		transaction.Id = transactionId
+	if err := l.svcCtx.TransactionModel.InsertWithBalance(l.ctx, user, transaction); err != nil {
+		l.logger.Errorf("Failed to update user balance: %v", err)
+		l.recordTransaction(transaction.Type, currency, "failed", amount)
+		return nil, model.NewAPIError(model.ErrCodeFailedToUpdateBalance, model.ErrMsgFailedToUpdateBalance)
 	}
+	l.recordTransaction(transaction.Type, currency, transaction.Status, amount)
 
 	// Return response
 	transactionData := types.TransactionData{
 		Type:          transaction.Type,
+		ChainId:       transaction.ChainId,
 		Currency:      transaction.Currency,
 		Amount:        transaction.Amount,
 		BalanceBefore: transaction.BalanceBefore,
@@ -130,15 +131,21 @@ func (l *TransactionLogic) Withdraw(req *types.WithdrawReq) (resp *types.Transac
 	}
 
 	// Find user by address (no cache to get latest balance)
-	user, err := l.svcCtx.UserModel.FindOneByAddressNoCache(req.Address)
+	user, err := l.svcCtx.UserModel.FindOneByAddressNoCache(l.ctx, req.Address)
 	if err != nil {
 		if err == model.ErrNotFound {
-			return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, "User not found")
+			return nil, model.NewAPIError(model.ErrCodeUserNotFound, model.ErrMsgUserNotFound)
 		}
 		l.logger.Errorf("Failed to find user by address: %v", err)
 		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
 	}
 
+	// Validate chain
+	network, err := l.chainFor(req.ChainId, user, currency)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get current balance
 	var balanceBefore, balanceAfter string
 	if currency == "wata" {
@@ -148,6 +155,7 @@ func (l *TransactionLogic) Withdraw(req *types.WithdrawReq) (resp *types.Transac
 		}
 		// Check sufficient balance
 		if !l.hasSufficientBalance(balanceBefore, req.Amount) {
+			l.recordTransaction("withdraw", currency, "rejected", amount)
 			return nil, model.NewAPIError(model.ErrCodeInsufficientBalance, model.ErrMsgInsufficientBalance)
 		}
 		balanceAfter = l.subtractAmounts(balanceBefore, req.Amount)
@@ -159,22 +167,18 @@ func (l *TransactionLogic) Withdraw(req *types.WithdrawReq) (resp *types.Transac
 		}
 		// Check sufficient balance
 		if !l.hasSufficientBalance(balanceBefore, req.Amount) {
+			l.recordTransaction("withdraw", currency, "rejected", amount)
 			return nil, model.NewAPIError(model.ErrCodeInsufficientBalance, model.ErrMsgInsufficientBalance)
 		}
 		balanceAfter = l.subtractAmounts(balanceBefore, req.Amount)
 		user.UsdtBalance = balanceAfter
 	}
 
-	// Update user balance
-	if err := l.svcCtx.UserModel.Update(user); err != nil {
-		l.logger.Errorf("Failed to update user balance: %v", err)
-		return nil, model.NewAPIError(model.ErrCodeFailedToUpdateBalance, model.ErrMsgFailedToUpdateBalance)
-	}
-
-	// Create transaction record
+	// Update user balance and record the transaction together
 	transaction := &model.Transaction{
 		UserId:        user.Id,
-		Type:          "withdraw",
+		ChainId:       network.Id,
+		Type:          model.TransactionTypeWithdraw,
 		Currency:      currency,
 		Amount:        req.Amount,
 		BalanceBefore: balanceBefore,
@@ -182,19 +186,17 @@ func (l *TransactionLogic) Withdraw(req *types.WithdrawReq) (resp *types.Transac
 		Status:        "completed",
 		TxHash:        req.TxHash,
 	}
-
-	result, err := l.svcCtx.TransactionModel.Insert(transaction)
-	if err != nil {
-		l.logger.Errorf("Failed to create transaction record: %v", err)
-		// Don't fail the withdraw if transaction record fails, but log it
-	} else {
-		transactionId, _ := result.LastInsertId()
-		transaction.Id = transactionId
+	if err := l.svcCtx.TransactionModel.InsertWithBalance(l.ctx, user, transaction); err != nil {
+		l.logger.Errorf("Failed to update user balance: %v", err)
+		l.recordTransaction(transaction.Type, currency, "failed", amount)
+		return nil, model.NewAPIError(model.ErrCodeFailedToUpdateBalance, model.ErrMsgFailedToUpdateBalance)
 	}
+	l.recordTransaction(transaction.Type, currency, transaction.Status, amount)
 
 	// Return response
 	transactionData := types.TransactionData{
 		Type:          transaction.Type,
+		ChainId:       transaction.ChainId,
 		Currency:      transaction.Currency,
 		Amount:        transaction.Amount,
 		BalanceBefore: transaction.BalanceBefore,
@@ -210,6 +212,27 @@ func (l *TransactionLogic) Withdraw(req *types.WithdrawReq) (resp *types.Transac
 	}, nil
 }
 
+// chainFor returns the network a deposit or withdrawal of currency moves on.
+// Balances are held per currency across all networks, so funds deposited on
+// one network can be withdrawn on any other that has the currency's token.
+func (l *TransactionLogic) chainFor(chainId int64, user *model.User, currency string) (chain.Conf, error) {
+	network, err := resolveChain(l.svcCtx.Chains, chainId, user.ChainId)
+	if err != nil {
+		return chain.Conf{}, err
+	}
+	if _, ok := network.Token(currency); !ok {
+		return chain.Conf{}, model.NewAPIError(model.ErrCodeCurrencyNotOnChain, model.ErrMsgCurrencyNotOnChain)
+	}
+	return network, nil
+}
+
+// recordTransaction counts a deposit or withdrawal and adds its amount to the
+// volume, labelled by status: completed, rejected or failed
+func (l *TransactionLogic) recordTransaction(txType, currency, status string, amount float64) {
+	metrics.BalanceTransactions.Inc(txType, currency, status)
+	metrics.BalanceVolume.Add(amount, txType, currency, status)
+}
+
 // Helper functions for amount calculations
 func (l *TransactionLogic) parseAmount(amountStr string) (float64, error) {
 	amountStr = strings.TrimSpace(amountStr)
@@ -250,5 +273,10 @@ func (l *TransactionLogic) hasSufficientBalance(balanceStr, amountStr string) bo
 
 func (l *TransactionLogic) formatAmount(amount float64) string {
 	// Format to remove trailing zeros but keep up to 8 decimal places
+	return formatDecimal(amount)
+}
+
+// formatDecimal formats an amount with up to 8 decimal places and no trailing zeros
+func formatDecimal(amount float64) string {
 	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.8f", amount), "0"), ".")
 }
@@ -0,0 +1,245 @@
+package logic
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/notify"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type WaitlistLogic struct {
+	logger logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewWaitlistLogic(ctx context.Context, svcCtx *svc.ServiceContext) *WaitlistLogic {
+	return &WaitlistLogic{
+		logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// JoinWaitlist queues the user for a bot. Joining again returns the existing entry.
+func (l *WaitlistLogic) JoinWaitlist(req *types.JoinWaitlistReq) (resp *types.WaitlistResp, err error) {
+	user, err := l.svcCtx.UserModel.FindOneByAddress(l.ctx, req.Address)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeUserNotFound, model.ErrMsgUserNotFound)
+		}
+		l.logger.Errorf("Failed to find user by address: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
+	}
+
+	bot, err := l.svcCtx.BotModel.FindOne(l.ctx, req.BotId)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeBotNotFound, model.ErrMsgBotNotFound)
+		}
+		l.logger.Errorf("Failed to find bot: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	if req.DurationDays <= 0 {
+		return nil, model.NewAPIError(model.ErrCodeInvalidAmount, "duration_days must be greater than 0")
+	}
+	amount, err := strconv.ParseFloat(strings.TrimSpace(req.Amount), 64)
+	if err != nil || amount <= 0 {
+		return nil, model.NewAPIError(model.ErrCodeInvalidAmount, model.ErrMsgInvalidAmount)
+	}
+	if amount < float64(bot.MinInvestment) || (bot.MaxInvestment > 0 && amount > float64(bot.MaxInvestment)) {
+		return nil, model.NewAPIErrorf(model.ErrCodeInvalidAmount, "amount must be between %d and %d", bot.MinInvestment, bot.MaxInvestment)
+	}
+
+	entry, err := l.svcCtx.BotWaitlistModel.FindOpenByUserIdAndBotId(l.ctx, user.Id, bot.Id)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find waitlist entry: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	if entry == nil {
+		if _, err := l.svcCtx.BotWaitlistModel.Insert(l.ctx, &model.BotWaitlist{
+			BotId:       bot.Id,
+			UserId:      user.Id,
+			Amount:      formatDecimal(amount),
+			DurationDay: strconv.Itoa(req.DurationDays),
+		}); err != nil {
+			l.logger.Errorf("Failed to join waitlist for bot %s: %v", bot.Id, err)
+			return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+		}
+
+		// The bot may have room already, in which case the user is offered a slot right away
+		l.OfferSlots(bot.Id)
+
+		entry, err = l.svcCtx.BotWaitlistModel.FindOpenByUserIdAndBotId(l.ctx, user.Id, bot.Id)
+		if err != nil {
+			l.logger.Errorf("Failed to find waitlist entry: %v", err)
+			return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+		}
+	}
+
+	data, err := l.waitlistEntry(entry)
+	if err != nil {
+		return nil, err
+	}
+	return &types.WaitlistResp{
+		Message: "success",
+		Data:    data,
+	}, nil
+}
+
+// LeaveWaitlist removes the user from a bot's waitlist, releasing any slot offered to them
+func (l *WaitlistLogic) LeaveWaitlist(req *types.LeaveWaitlistReq) (resp *types.WaitlistResp, err error) {
+	user, err := l.svcCtx.UserModel.FindOneByAddress(l.ctx, req.Address)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeUserNotFound, model.ErrMsgUserNotFound)
+		}
+		l.logger.Errorf("Failed to find user by address: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
+	}
+
+	entry, err := l.svcCtx.BotWaitlistModel.FindOpenByUserIdAndBotId(l.ctx, user.Id, req.BotId)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return &types.WaitlistResp{
+				Message: "Not on the waitlist for this bot",
+			}, nil
+		}
+		l.logger.Errorf("Failed to find waitlist entry: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	if _, err := l.svcCtx.BotWaitlistModel.Cancel(l.ctx, entry.Id); err != nil {
+		l.logger.Errorf("Failed to leave waitlist %d: %v", entry.Id, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	if entry.Status == model.WaitlistStatusOffered {
+		l.OfferSlots(entry.BotId)
+	}
+
+	return &types.WaitlistResp{
+		Message: "Left waitlist successfully",
+	}, nil
+}
+
+// GetWaitlist returns the user's open waitlist entries
+func (l *WaitlistLogic) GetWaitlist(req *types.GetWaitlistReq) (resp *types.WaitlistsResp, err error) {
+	user, err := l.svcCtx.UserModel.FindOneByAddress(l.ctx, req.Address)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return &types.WaitlistsResp{
+				Message: "success",
+				Data:    []types.WaitlistEntry{},
+			}, nil
+		}
+		l.logger.Errorf("Failed to find user by address: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeFailedToFindUser, model.ErrMsgFailedToFindUser)
+	}
+
+	entries, err := l.svcCtx.BotWaitlistModel.FindOpenByUserId(l.ctx, user.Id)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find waitlist entries: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	data := make([]types.WaitlistEntry, 0, len(entries))
+	for _, entry := range entries {
+		item, err := l.waitlistEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, *item)
+	}
+
+	return &types.WaitlistsResp{
+		Message: "success",
+		Data:    data,
+	}, nil
+}
+
+// OfferSlots expires stale offers and offers the bot's free capacity to the
+// next users in line. Failures are logged; the settlement process retries.
+func (l *WaitlistLogic) OfferSlots(botId string) {
+	l.expireOffers()
+	l.offerNext(botId)
+}
+
+// OfferAllSlots runs OfferSlots for every bot with users waiting
+func (l *WaitlistLogic) OfferAllSlots() {
+	l.expireOffers()
+
+	botIds, err := l.svcCtx.BotWaitlistModel.FindBotIdsWithWaiting(l.ctx)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find bots with waitlists: %v", err)
+		return
+	}
+	for _, botId := range botIds {
+		l.offerNext(botId)
+	}
+}
+
+func (l *WaitlistLogic) expireOffers() {
+	if _, err := l.svcCtx.BotWaitlistModel.ExpireOffers(l.ctx, time.Now()); err != nil {
+		l.logger.Errorf("Failed to expire waitlist offers: %v", err)
+	}
+}
+
+func (l *WaitlistLogic) offerNext(botId string) {
+	offered, err := l.svcCtx.BotWaitlistModel.OfferNext(l.ctx, botId, time.Now(), l.svcCtx.Config.Waitlist.OfferTTL)
+	if err != nil {
+		l.logger.Errorf("Failed to offer waitlist slots for bot %s: %v", botId, err)
+		return
+	}
+	if len(offered) == 0 {
+		return
+	}
+
+	botName := botId
+	if bot, err := l.svcCtx.BotModel.FindOne(l.ctx, botId); err == nil {
+		botName = bot.Name
+	}
+	notifications := NewNotificationLogic(l.ctx, l.svcCtx)
+	for _, entry := range offered {
+		expiresAt := entry.OfferExpiresAt.Time.Format(time.RFC3339)
+		l.logger.Infof("Offered bot %s slot to user %d until %s", botId, entry.UserId, expiresAt)
+		amount, _ := strconv.ParseFloat(entry.Amount, 64)
+		notifications.Notify(entry.UserId, notify.EventWaitlistOffered, map[string]string{
+			"botName":   botName,
+			"amount":    formatDecimal(amount),
+			"expiresAt": expiresAt,
+		})
+	}
+}
+
+func (l *WaitlistLogic) waitlistEntry(entry *model.BotWaitlist) (*types.WaitlistEntry, error) {
+	durationDay, _ := strconv.Atoi(entry.DurationDay)
+	amount, _ := strconv.ParseFloat(entry.Amount, 64)
+	item := &types.WaitlistEntry{
+		Id:             entry.Id,
+		BotId:          entry.BotId,
+		Amount:         formatDecimal(amount),
+		DurationDays:   durationDay,
+		Status:         entry.Status,
+		JoinedAt:       entry.CreatedAt.Format(time.RFC3339),
+		OfferedAt:      formatNullTime(entry.OfferedAt),
+		OfferExpiresAt: formatNullTime(entry.OfferExpiresAt),
+	}
+
+	if entry.Status == model.WaitlistStatusWaiting {
+		position, err := l.svcCtx.BotWaitlistModel.QueuePosition(l.ctx, entry)
+		if err != nil {
+			l.logger.Errorf("Failed to find waitlist position: %v", err)
+			return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+		}
+		item.Position = position
+	}
+	return item, nil
+}
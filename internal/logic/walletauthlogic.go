@@ -9,10 +9,12 @@ import (
 	"strings"
 	"time"
 
+	"wata-bot-BE/internal/address"
+	"wata-bot-BE/internal/errorlog"
+	"wata-bot-BE/internal/metrics"
 	"wata-bot-BE/internal/model"
 	"wata-bot-BE/internal/svc"
 	"wata-bot-BE/internal/types"
-	"wata-bot-BE/internal/utils"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -36,28 +38,35 @@ func NewWalletAuthLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Wallet
 
 func (l *WalletAuthLogic) WalletAuth(req *types.WalletAuthReq) (resp *types.WalletAuthResp, err error) {
 	// Verify signature
-	address, err := l.verifySignature(req.Message, req.Signature)
+	signer, err := l.verifySignature(req.Message, req.Signature)
 	if err != nil {
 		l.logger.Errorf("Signature verification failed: %v", err)
-		utils.WriteErrorLog("Signature verification failed", err)
+		errorlog.Report(l.ctx, "Signature verification failed", err)
 		return nil, model.NewAPIError(model.ErrCodeInvalidSignature, model.ErrMsgInvalidSignature)
 	}
 
-	addressStr := address.String()
+	addr := address.FromCommon(signer)
+
+	// New users are registered on the requested chain, or the default one
+	network, err := resolveChain(l.svcCtx.Chains, req.ChainId, 0)
+	if err != nil {
+		return nil, err
+	}
 
 	// Get or create user (allow registration if not found)
-	user, err := l.getOrCreateUser(addressStr, req.InviteCode)
+	user, created, err := l.getOrCreateUser(addr, req.InviteCode, network.Id)
 	if err != nil {
 		return nil, err
 	}
 
 	// Generate JWT tokens
-	accessToken, refreshToken, expiresIn, err := l.generateTokens(addressStr, user.ReferralCode)
+	accessToken, refreshToken, expiresIn, err := l.generateTokens(addr.String(), user.ReferralCode)
 	if err != nil {
 		l.logger.Errorf("Token generation failed: %v", err)
-		utils.WriteErrorLog("Token generation failed", err)
+		errorlog.Report(l.ctx, "Token generation failed", err)
 		return nil, model.NewAPIError(model.ErrCodeTokenGenerationFailed, model.ErrMsgTokenGenerationFailed)
 	}
+	metrics.Logins.Inc(metrics.LoginSigned, metrics.UserKind(created))
 
 	return &types.WalletAuthResp{
 		Message: "success",
@@ -73,24 +82,31 @@ func (l *WalletAuthLogic) WalletAuth(req *types.WalletAuthReq) (resp *types.Wall
 
 func (l *WalletAuthLogic) WalletAuthNotSign(req *types.WalletAuthNotSignReq) (resp *types.WalletAuthResp, err error) {
 	// Validate and normalize address using go-ethereum
-	addressStr, err := l.validateAndNormalizeAddress(req.Address)
+	addr, err := l.validateAndNormalizeAddress(req.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// New users are registered on the requested chain, or the default one
+	network, err := resolveChain(l.svcCtx.Chains, req.ChainId, 0)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get or create user with referral_code from request (allow registration if not found)
-	user, err := l.getOrCreateUserWithReferralCode(addressStr, req.ReferralCode)
+	user, created, err := l.getOrCreateUserWithReferralCode(addr, req.ReferralCode, network.Id)
 	if err != nil {
 		return nil, err
 	}
 
 	// Generate JWT tokens
-	accessToken, refreshToken, expiresIn, err := l.generateTokens(addressStr, user.ReferralCode)
+	accessToken, refreshToken, expiresIn, err := l.generateTokens(addr.String(), user.ReferralCode)
 	if err != nil {
 		l.logger.Errorf("Token generation failed: %v", err)
-		utils.WriteErrorLog("Token generation failed", err)
+		errorlog.Report(l.ctx, "Token generation failed", err)
 		return nil, model.NewAPIError(model.ErrCodeTokenGenerationFailed, model.ErrMsgTokenGenerationFailed)
 	}
+	metrics.Logins.Inc(metrics.LoginUnsigned, metrics.UserKind(created))
 
 	return &types.WalletAuthResp{
 		Message: "success",
@@ -104,37 +120,41 @@ func (l *WalletAuthLogic) WalletAuthNotSign(req *types.WalletAuthNotSignReq) (re
 	}, nil
 }
 
-// getOrCreateUser gets existing user or creates new one if not found (allows registration)
-func (l *WalletAuthLogic) getOrCreateUser(addressStr, inviteCode string) (*model.User, error) {
+// getOrCreateUser gets existing user or creates new one on chainId if not found (allows registration)
+// created reports whether the user was registered by this call
+func (l *WalletAuthLogic) getOrCreateUser(addr address.Address, inviteCode string, chainId int64) (user *model.User, created bool, err error) {
+	addressStr := addr.String()
 	referralCode := strings.ToUpper(addressStr[len(addressStr)-8:])
 
 	// Check if user exists
-	user, err := l.svcCtx.UserModel.FindOneByAddress(addressStr)
+	user, err = l.svcCtx.UserModel.FindOneByAddress(l.ctx, addr)
 	if err != nil && err != model.ErrNotFound {
 		l.logger.Errorf("Database error: %v", err)
-		utils.WriteErrorLog("Database error when finding user by address", err)
-		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+		errorlog.Report(l.ctx, "Database error when finding user by address", err)
+		return nil, false, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
 	}
 
 	// If user not found, create new user (allow registration)
 	if err == model.ErrNotFound {
-		// New user registration - only save address and referral_code
+		// New user registration - only save address, referral_code and chain
 		newUser := &model.User{
-			Address:      addressStr,
+			Address:      addr,
 			ReferralCode: referralCode,
+			ChainId:      chainId,
 		}
-		_, err = l.svcCtx.UserModel.Insert(newUser)
+		_, err = l.svcCtx.UserModel.Insert(l.ctx, newUser)
 		if err != nil {
 			l.logger.Errorf("Failed to create user: %v", err)
-			utils.WriteErrorLog("Failed to create user", err)
-			return nil, model.NewAPIError(model.ErrCodeFailedToCreateUser, model.ErrMsgFailedToCreateUser)
+			errorlog.Report(l.ctx, "Failed to create user", err)
+			return nil, false, model.NewAPIError(model.ErrCodeFailedToCreateUser, model.ErrMsgFailedToCreateUser)
 		}
+		created = true
 
 		// Retrieve the newly created user with retry (for cache consistency)
 		// Retry up to 3 times with small delay between attempts
 		maxRetries := 3
 		for i := 0; i < maxRetries; i++ {
-			user, err = l.svcCtx.UserModel.FindOneByAddress(addressStr)
+			user, err = l.svcCtx.UserModel.FindOneByAddress(l.ctx, addr)
 			if err == nil {
 				break
 			}
@@ -148,14 +168,15 @@ func (l *WalletAuthLogic) getOrCreateUser(addressStr, inviteCode string) (*model
 		// Since Insert succeeded, we can construct user object to continue
 		if err != nil {
 			l.logger.Errorf("Failed to find created user after insert and retries (address: %s): %v", addressStr, err)
-			utils.WriteErrorLog("Failed to find created user after insert", err)
+			errorlog.Report(l.ctx, "Failed to find created user after insert", err)
 			// User was created successfully, so we construct user object to continue
 			// This handles edge case where cache hasn't updated yet but DB insert succeeded
 			user = &model.User{
-				Address:      addressStr,
+				Address:      addr,
 				ReferralCode: referralCode,
 				WataReward:   0,
 				Role:         "user",
+				ChainId:      chainId,
 			}
 			l.logger.Infof("Using constructed user object for address: %s (insert succeeded but query failed)", addressStr)
 		}
@@ -163,11 +184,13 @@ func (l *WalletAuthLogic) getOrCreateUser(addressStr, inviteCode string) (*model
 	}
 	// Note: No longer updating invite_code for existing users
 
-	return user, nil
+	return user, created, nil
 }
 
-// getOrCreateUserWithReferralCode gets existing user or creates new one with referral_code from request
-func (l *WalletAuthLogic) getOrCreateUserWithReferralCode(addressStr, referralCode string) (*model.User, error) {
+// getOrCreateUserWithReferralCode gets existing user or creates new one on chainId with referral_code from request
+// created reports whether the user was registered by this call
+func (l *WalletAuthLogic) getOrCreateUserWithReferralCode(addr address.Address, referralCode string, chainId int64) (user *model.User, created bool, err error) {
+	addressStr := addr.String()
 	// Validate and normalize referral_code
 	referralCode = strings.TrimSpace(referralCode)
 	referralCode = strings.ToUpper(referralCode)
@@ -178,32 +201,34 @@ func (l *WalletAuthLogic) getOrCreateUserWithReferralCode(addressStr, referralCo
 	}
 
 	// Check if user exists
-	user, err := l.svcCtx.UserModel.FindOneByAddress(addressStr)
+	user, err = l.svcCtx.UserModel.FindOneByAddress(l.ctx, addr)
 	if err != nil && err != model.ErrNotFound {
 		l.logger.Errorf("Database error: %v", err)
-		utils.WriteErrorLog("Database error when finding user by address", err)
-		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+		errorlog.Report(l.ctx, "Database error when finding user by address", err)
+		return nil, false, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
 	}
 
 	// If user not found, create new user (allow registration)
 	if err == model.ErrNotFound {
-		// New user registration - only save address and referral_code
+		// New user registration - only save address, referral_code and chain
 		newUser := &model.User{
-			Address:      addressStr,
+			Address:      addr,
 			ReferralCode: referralCode,
+			ChainId:      chainId,
 		}
-		_, err = l.svcCtx.UserModel.Insert(newUser)
+		_, err = l.svcCtx.UserModel.Insert(l.ctx, newUser)
 		if err != nil {
 			l.logger.Errorf("Failed to create user: %v", err)
-			utils.WriteErrorLog("Failed to create user", err)
-			return nil, model.NewAPIError(model.ErrCodeFailedToCreateUser, model.ErrMsgFailedToCreateUser)
+			errorlog.Report(l.ctx, "Failed to create user", err)
+			return nil, false, model.NewAPIError(model.ErrCodeFailedToCreateUser, model.ErrMsgFailedToCreateUser)
 		}
+		created = true
 
 		// Retrieve the newly created user with retry (for cache consistency)
 		// Retry up to 3 times with small delay between attempts
 		maxRetries := 3
 		for i := 0; i < maxRetries; i++ {
-			user, err = l.svcCtx.UserModel.FindOneByAddress(addressStr)
+			user, err = l.svcCtx.UserModel.FindOneByAddress(l.ctx, addr)
 			if err == nil {
 				break
 			}
@@ -217,76 +242,42 @@ func (l *WalletAuthLogic) getOrCreateUserWithReferralCode(addressStr, referralCo
 		// Since Insert succeeded, we can construct user object to continue
 		if err != nil {
 			l.logger.Errorf("Failed to find created user after insert and retries (address: %s): %v", addressStr, err)
-			utils.WriteErrorLog("Failed to find created user after insert", err)
+			errorlog.Report(l.ctx, "Failed to find created user after insert", err)
 			// User was created successfully, so we construct user object to continue
 			// This handles edge case where cache hasn't updated yet but DB insert succeeded
 			user = &model.User{
-				Address:      addressStr,
+				Address:      addr,
 				ReferralCode: referralCode,
 				WataReward:   0,
 				Role:         "user",
+				ChainId:      chainId,
 			}
 			l.logger.Infof("Using constructed user object for address: %s (insert succeeded but query failed)", addressStr)
 		}
 		l.logger.Infof("New user registered: %s with referral_code: %s", addressStr, referralCode)
 	}
 
-	return user, nil
+	return user, created, nil
 }
 
-// validateAndNormalizeAddress validates and normalizes Ethereum address using go-ethereum
-func (l *WalletAuthLogic) validateAndNormalizeAddress(addressInput string) (string, error) {
-	// Trim whitespace
-	addressInput = strings.TrimSpace(addressInput)
-	if addressInput == "" {
-		l.logger.Errorf("Empty address provided")
-		utils.WriteErrorLog("Empty address", fmt.Errorf("address is empty"))
-		return "", model.NewAPIError(model.ErrCodeInvalidAddressFormat, model.ErrMsgInvalidAddressFormat)
-	}
-
-	// Basic format validation: must start with 0x
-	if !strings.HasPrefix(strings.ToLower(addressInput), "0x") {
-		l.logger.Errorf("Invalid address format: %s (missing 0x prefix)", addressInput)
-		utils.WriteErrorLog("Invalid address format", fmt.Errorf("address missing 0x prefix: %s", addressInput))
+// validateAndNormalizeAddress returns the EIP-55 checksummed form of addr,
+// rejecting the zero address
+func (l *WalletAuthLogic) validateAndNormalizeAddress(addr address.Address) (address.Address, error) {
+	normalized, err := address.Parse(addr.String())
+	if err != nil {
+		l.logger.Errorf("Invalid address format: %s", addr)
+		errorlog.Report(l.ctx, "Invalid address format", fmt.Errorf("invalid address: %s", addr))
 		return "", model.NewAPIError(model.ErrCodeInvalidAddressFormat, model.ErrMsgInvalidAddressFormat)
 	}
 
-	// Normalize to lowercase for format check
-	addressLower := strings.ToLower(addressInput)
-
-	// Validate hex characters (after 0x prefix)
-	hexPart := addressLower[2:]
-	for _, char := range hexPart {
-		if !((char >= '0' && char <= '9') || (char >= 'a' && char <= 'f')) {
-			l.logger.Errorf("Invalid address format: %s (contains invalid hex characters)", addressInput)
-			utils.WriteErrorLog("Invalid address format", fmt.Errorf("address contains invalid hex characters"))
-			return "", model.NewAPIError(model.ErrCodeInvalidAddressFormat, model.ErrMsgInvalidAddressFormat)
-		}
-	}
-
-	// Use go-ethereum's HexToAddress to parse and validate
-	// HexToAddress accepts both checksum and lowercase addresses
-	address := common.HexToAddress(addressInput)
-
 	// Check if address is zero address (0x0000...)
-	zeroAddress := common.HexToAddress("0x0")
-	if address == zeroAddress {
-		l.logger.Errorf("Zero address not allowed: %s", addressInput)
-		utils.WriteErrorLog("Zero address", fmt.Errorf("zero address is not allowed"))
-		return "", model.NewAPIError(model.ErrCodeInvalidAddressFormat, model.ErrMsgInvalidAddressFormat)
-	}
-
-	// Return checksum address (Hex() returns EIP-55 checksummed address)
-	normalizedAddress := address.Hex()
-
-	// Verify the normalized address is valid (should always be 42 chars)
-	if len(normalizedAddress) != 42 {
-		l.logger.Errorf("Invalid address normalization: %s -> %s", addressInput, normalizedAddress)
-		utils.WriteErrorLog("Invalid address normalization", fmt.Errorf("normalized address has invalid length"))
+	if common.HexToAddress(normalized.String()) == (common.Address{}) {
+		l.logger.Errorf("Zero address not allowed: %s", addr)
+		errorlog.Report(l.ctx, "Zero address", fmt.Errorf("zero address is not allowed"))
 		return "", model.NewAPIError(model.ErrCodeInvalidAddressFormat, model.ErrMsgInvalidAddressFormat)
 	}
 
-	return normalizedAddress, nil
+	return normalized, nil
 }
 
 // verifySignature verifies the Ethereum signature
@@ -0,0 +1,140 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/utils"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// webhookLease is how long a claimed delivery is hidden from other dispatchers
+// while it is being sent
+const webhookLease = time.Minute
+
+// WebhookDispatchLogic sends queued partner webhook deliveries. Every attempt
+// is written to the delivery log; failures are retried with exponential
+// backoff until the attempt budget is used up. Deliveries are claimed before
+// sending, so running it on several replicas at once is safe.
+type WebhookDispatchLogic struct {
+	logger logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+func NewWebhookDispatchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *WebhookDispatchLogic {
+	return &WebhookDispatchLogic{
+		logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+		done:   make(chan struct{}),
+	}
+}
+
+// Start runs a dispatch pass every interval until Stop is called
+func (l *WebhookDispatchLogic) Start(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		l.RunOnce()
+		select {
+		case <-ticker.C:
+		case <-l.done:
+			return
+		}
+	}
+}
+
+func (l *WebhookDispatchLogic) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.done)
+	})
+}
+
+// RunOnce sends the deliveries that are due
+func (l *WebhookDispatchLogic) RunOnce() {
+	due, err := l.svcCtx.WebhookDeliveryModel.FindDue(l.ctx, time.Now(), l.svcCtx.Config.Webhooks.BatchSize)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find due webhook deliveries: %v", err)
+		return
+	}
+
+	delivered, failed := 0, 0
+	for _, d := range due {
+		ok, err := l.svcCtx.WebhookDeliveryModel.Claim(l.ctx, d.Id, time.Now(), webhookLease)
+		if err != nil {
+			l.logger.Errorf("Failed to claim webhook delivery %d: %v", d.Id, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if l.send(d) {
+			delivered++
+		} else {
+			failed++
+		}
+	}
+	if delivered > 0 || failed > 0 {
+		l.logger.Infof("Webhook dispatch pass: %d delivered, %d failed", delivered, failed)
+	}
+}
+
+func (l *WebhookDispatchLogic) send(d *model.WebhookDelivery) bool {
+	attempt := &model.WebhookDeliveryAttempt{DeliveryId: d.Id}
+
+	endpoint, err := l.svcCtx.WebhookEndpointModel.FindOne(l.ctx, d.EndpointId)
+	switch {
+	case err == model.ErrNotFound || (err == nil && !endpoint.Active):
+		// Nothing to send to; the delivery can be replayed once re-enabled
+		attempt.Error = "endpoint is disabled"
+		l.record(d, attempt, model.WebhookStatusFailed, time.Now())
+		return false
+	case err != nil:
+		l.logger.Errorf("Failed to find webhook endpoint %d: %v", d.EndpointId, err)
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(l.ctx, webhookLease/2)
+	defer cancel()
+	result, err := l.svcCtx.WebhookSender.Send(ctx, endpoint.Url, endpoint.Secret, d.Id, d.EventType, []byte(d.Payload))
+	attempt.StatusCode = result.StatusCode
+	attempt.DurationMs = result.Duration.Milliseconds()
+	if err == nil && result.OK() {
+		l.record(d, attempt, model.WebhookStatusDelivered, time.Now())
+		return true
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+	} else {
+		attempt.Error = fmt.Sprintf("unexpected status %d: %s", result.StatusCode, result.Response)
+	}
+
+	conf := l.svcCtx.Config.Webhooks
+	n := d.Attempts + 1
+	status := model.WebhookStatusPending
+	retryAt := time.Now().Add(utils.Backoff(n, conf.RetryBase, conf.RetryMax))
+	if n >= conf.MaxAttempts {
+		status = model.WebhookStatusFailed
+		l.logger.Errorf("Giving up on webhook delivery %d to endpoint %d after %d attempts: %s", d.Id, d.EndpointId, n, attempt.Error)
+	} else {
+		l.logger.Infof("Webhook delivery %d to endpoint %d failed, retrying at %s: %s", d.Id, d.EndpointId, retryAt.Format(time.RFC3339), attempt.Error)
+	}
+	l.record(d, attempt, status, retryAt)
+	return false
+}
+
+func (l *WebhookDispatchLogic) record(d *model.WebhookDelivery, attempt *model.WebhookDeliveryAttempt, status string, retryAt time.Time) {
+	if err := l.svcCtx.WebhookDeliveryModel.RecordAttempt(l.ctx, attempt, status, retryAt); err != nil {
+		l.logger.Errorf("Failed to record webhook delivery %d attempt: %v", d.Id, err)
+	}
+}
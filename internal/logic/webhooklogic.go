@@ -0,0 +1,357 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"wata-bot-BE/internal/event"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/svc"
+	"wata-bot-BE/internal/types"
+	"wata-bot-BE/internal/webhook"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxWebhookDeliveriesPageSize bounds the delivery log page size
+const maxWebhookDeliveriesPageSize = 100
+
+type WebhookLogic struct {
+	logger logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+func NewWebhookLogic(ctx context.Context, svcCtx *svc.ServiceContext) *WebhookLogic {
+	return &WebhookLogic{
+		logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// CreateWebhook registers a partner endpoint. The signing secret is only
+// returned here and when it is rotated.
+func (l *WebhookLogic) CreateWebhook(req *types.CreateWebhookReq) (resp *types.WebhookEndpointResp, err error) {
+	endpointUrl, events, err := validateWebhook(req.Url, req.Events)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		l.logger.Errorf("Failed to generate webhook secret: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeInternalServerError, model.ErrMsgInternalServerError)
+	}
+
+	endpoint := &model.WebhookEndpoint{
+		Url:         endpointUrl,
+		Secret:      secret,
+		Events:      events,
+		Description: strings.TrimSpace(req.Description),
+		Active:      true,
+	}
+	if err := l.svcCtx.WebhookEndpointModel.Insert(l.ctx, endpoint); err != nil {
+		l.logger.Errorf("Failed to create webhook endpoint: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	endpoint.CreatedAt = time.Now()
+	endpoint.UpdatedAt = endpoint.CreatedAt
+
+	data := webhookEndpointToAPI(endpoint)
+	data.Secret = secret
+	return &types.WebhookEndpointResp{
+		Message: "success",
+		Data:    data,
+	}, nil
+}
+
+// GetWebhooks lists every registered endpoint
+func (l *WebhookLogic) GetWebhooks() (resp *types.WebhookEndpointsResp, err error) {
+	endpoints, err := l.svcCtx.WebhookEndpointModel.FindAll(l.ctx)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find webhook endpoints: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	data := make([]types.WebhookEndpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		data = append(data, webhookEndpointToAPI(endpoint))
+	}
+	return &types.WebhookEndpointsResp{
+		Message: "success",
+		Events:  model.DomainEvents,
+		Data:    data,
+	}, nil
+}
+
+// UpdateWebhook replaces the endpoint's settings and optionally rotates its
+// secret. Deliveries already queued are sent to the new URL with the new secret.
+func (l *WebhookLogic) UpdateWebhook(req *types.UpdateWebhookReq) (resp *types.WebhookEndpointResp, err error) {
+	endpointUrl, events, err := validateWebhook(req.Url, req.Events)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := l.findEndpoint(req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.Url = endpointUrl
+	endpoint.Events = events
+	endpoint.Description = strings.TrimSpace(req.Description)
+	endpoint.Active = req.Active
+	if req.RotateSecret {
+		if endpoint.Secret, err = webhook.NewSecret(); err != nil {
+			l.logger.Errorf("Failed to generate webhook secret: %v", err)
+			return nil, model.NewAPIError(model.ErrCodeInternalServerError, model.ErrMsgInternalServerError)
+		}
+	}
+	if err := l.svcCtx.WebhookEndpointModel.Update(l.ctx, endpoint); err != nil {
+		l.logger.Errorf("Failed to update webhook endpoint %d: %v", endpoint.Id, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	endpoint.UpdatedAt = time.Now()
+
+	data := webhookEndpointToAPI(endpoint)
+	if req.RotateSecret {
+		data.Secret = endpoint.Secret
+	}
+	return &types.WebhookEndpointResp{
+		Message: "success",
+		Data:    data,
+	}, nil
+}
+
+// DeleteWebhook removes the endpoint and its delivery log
+func (l *WebhookLogic) DeleteWebhook(req *types.WebhookIdReq) (resp *types.DeleteWebhookResp, err error) {
+	if _, err := l.findEndpoint(req.Id); err != nil {
+		return nil, err
+	}
+	if err := l.svcCtx.WebhookEndpointModel.Delete(l.ctx, req.Id); err != nil {
+		l.logger.Errorf("Failed to delete webhook endpoint %d: %v", req.Id, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	return &types.DeleteWebhookResp{
+		Message: "success",
+	}, nil
+}
+
+// GetWebhookDeliveries pages through the endpoint's deliveries, newest first
+func (l *WebhookLogic) GetWebhookDeliveries(req *types.WebhookDeliveriesReq) (resp *types.WebhookDeliveriesResp, err error) {
+	switch req.Status {
+	case "", model.WebhookStatusPending, model.WebhookStatusDelivered, model.WebhookStatusFailed:
+	default:
+		return nil, model.NewAPIError(model.ErrCodeInvalidDeliveryQuery, "status must be 'pending', 'delivered' or 'failed'")
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize <= 0 || req.PageSize > maxWebhookDeliveriesPageSize {
+		return nil, model.NewAPIErrorf(model.ErrCodeInvalidDeliveryQuery, "pageSize must be between 1 and %d", maxWebhookDeliveriesPageSize)
+	}
+	if _, err := l.findEndpoint(req.Id); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := l.svcCtx.WebhookDeliveryModel.FindByEndpointId(l.ctx, req.Id, req.Status, (req.Page-1)*req.PageSize, req.PageSize)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find webhook deliveries: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	total, err := l.svcCtx.WebhookDeliveryModel.CountByEndpointId(l.ctx, req.Id, req.Status)
+	if err != nil {
+		l.logger.Errorf("Failed to count webhook deliveries: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	items := make([]types.WebhookDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		items = append(items, webhookDeliveryToAPI(d))
+	}
+	return &types.WebhookDeliveriesResp{
+		Message: "success",
+		Data: types.WebhookDeliveriesData{
+			Items:    items,
+			Page:     req.Page,
+			PageSize: req.PageSize,
+			Total:    total,
+		},
+	}, nil
+}
+
+// GetWebhookDelivery returns one delivery with its payload and attempt log
+func (l *WebhookLogic) GetWebhookDelivery(req *types.WebhookIdReq) (resp *types.WebhookDeliveryDetailResp, err error) {
+	delivery, err := l.findDelivery(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := l.svcCtx.WebhookDeliveryModel.FindAttempts(l.ctx, delivery.Id)
+	if err != nil && err != model.ErrNotFound {
+		l.logger.Errorf("Failed to find webhook delivery attempts: %v", err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+
+	data := types.WebhookDeliveryDetail{
+		Delivery: webhookDeliveryToAPI(delivery),
+		Payload:  delivery.Payload,
+		Attempts: make([]types.WebhookDeliveryAttempt, 0, len(attempts)),
+	}
+	for _, a := range attempts {
+		data.Attempts = append(data.Attempts, types.WebhookDeliveryAttempt{
+			StatusCode: a.StatusCode,
+			Error:      a.Error,
+			DurationMs: a.DurationMs,
+			CreatedAt:  a.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return &types.WebhookDeliveryDetailResp{
+		Message: "success",
+		Data:    data,
+	}, nil
+}
+
+// ReplayWebhookDelivery sends a delivered or failed delivery again. The
+// payload and X-Webhook-Delivery id are unchanged; the signature is fresh.
+func (l *WebhookLogic) ReplayWebhookDelivery(req *types.WebhookIdReq) (resp *types.WebhookDeliveryResp, err error) {
+	delivery, err := l.findDelivery(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := l.svcCtx.WebhookDeliveryModel.Replay(l.ctx, delivery.Id, time.Now())
+	if err != nil {
+		l.logger.Errorf("Failed to replay webhook delivery %d: %v", delivery.Id, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	if !ok {
+		return nil, model.NewAPIError(model.ErrCodeWebhookDeliveryPending, model.ErrMsgWebhookDeliveryPending)
+	}
+
+	delivery, err = l.findDelivery(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &types.WebhookDeliveryResp{
+		Message: "success",
+		Data:    webhookDeliveryToAPI(delivery),
+	}, nil
+}
+
+// QueueWebhookDeliveries queues the event for every active endpoint that
+// subscribed to its type. Already queued deliveries are skipped, so the event
+// bus may call it more than once for the same event.
+func (l *WebhookLogic) QueueWebhookDeliveries(evt event.Event) error {
+	endpoints, err := l.svcCtx.WebhookEndpointModel.FindActive(l.ctx)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+
+	var body []byte
+	for _, endpoint := range endpoints {
+		if !endpoint.Wants(evt.Type) {
+			continue
+		}
+		if body == nil {
+			body, err = json.Marshal(webhook.Payload{
+				Id:        evt.Id,
+				Type:      evt.Type,
+				CreatedAt: evt.OccurredAt,
+				Data:      evt.Payload,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if _, err := l.svcCtx.WebhookDeliveryModel.InsertIgnore(l.ctx, &model.WebhookDelivery{
+			EndpointId: endpoint.Id,
+			EventId:    evt.Id,
+			EventType:  evt.Type,
+			Payload:    string(body),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *WebhookLogic) findEndpoint(id int64) (*model.WebhookEndpoint, error) {
+	endpoint, err := l.svcCtx.WebhookEndpointModel.FindOne(l.ctx, id)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeWebhookNotFound, model.ErrMsgWebhookNotFound)
+		}
+		l.logger.Errorf("Failed to find webhook endpoint %d: %v", id, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	return endpoint, nil
+}
+
+func (l *WebhookLogic) findDelivery(id int64) (*model.WebhookDelivery, error) {
+	delivery, err := l.svcCtx.WebhookDeliveryModel.FindOne(l.ctx, id)
+	if err != nil {
+		if err == model.ErrNotFound {
+			return nil, model.NewAPIError(model.ErrCodeWebhookDeliveryNotFound, model.ErrMsgWebhookDeliveryNotFound)
+		}
+		l.logger.Errorf("Failed to find webhook delivery %d: %v", id, err)
+		return nil, model.NewAPIError(model.ErrCodeDatabaseError, model.ErrMsgDatabaseError)
+	}
+	return delivery, nil
+}
+
+// validateWebhook checks the endpoint URL and event types, returning them in
+// the form they are stored
+func validateWebhook(rawUrl string, events []string) (string, string, error) {
+	rawUrl = strings.TrimSpace(rawUrl)
+	u, err := url.Parse(rawUrl)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", "", model.NewAPIError(model.ErrCodeInvalidWebhookUrl, model.ErrMsgInvalidWebhookUrl)
+	}
+	for _, e := range events {
+		if !model.IsDomainEvent(e) {
+			return "", "", model.NewAPIErrorf(model.ErrCodeInvalidWebhookEvent, "invalid webhook event: %s", e)
+		}
+	}
+	return rawUrl, strings.Join(events, ","), nil
+}
+
+func webhookEndpointToAPI(endpoint *model.WebhookEndpoint) types.WebhookEndpoint {
+	events := endpoint.EventList()
+	if events == nil {
+		events = []string{}
+	}
+	return types.WebhookEndpoint{
+		Id:          endpoint.Id,
+		Url:         endpoint.Url,
+		Events:      events,
+		Description: endpoint.Description,
+		Active:      endpoint.Active,
+		CreatedAt:   endpoint.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   endpoint.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func webhookDeliveryToAPI(d *model.WebhookDelivery) types.WebhookDelivery {
+	data := types.WebhookDelivery{
+		Id:             d.Id,
+		EndpointId:     d.EndpointId,
+		EventId:        d.EventId,
+		EventType:      d.EventType,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		LastStatusCode: d.LastStatusCode,
+		LastError:      d.LastError,
+		CreatedAt:      d.CreatedAt.Format(time.RFC3339),
+	}
+	if d.Status == model.WebhookStatusPending {
+		data.NextAttemptAt = d.NextAttemptAt.Format(time.RFC3339)
+	}
+	if d.DeliveredAt.Valid {
+		data.DeliveredAt = d.DeliveredAt.Time.Format(time.RFC3339)
+	}
+	return data
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import "github.com/zeromicro/go-zero/core/metric"
+
+// Business metrics, exposed with go-zero's HTTP metrics on the Prometheus
+// endpoint. They are only recorded while Prometheus is enabled in the config.
+const namespace = "wata"
+
+// Login methods and user kinds
+const (
+	LoginSigned   = "signed"
+	LoginUnsigned = "unsigned"
+	UserNew       = "new"
+	UserReturning = "returning"
+)
+
+// Subscription actions
+const (
+	ActionSubscribe   = "subscribe"
+	ActionRenew       = "renew"
+	ActionUnsubscribe = "unsubscribe"
+)
+
+var (
+	// Logins counts successful wallet sign-ins
+	Logins = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "logins_total",
+		Help:      "wallet sign-ins by method (signed, unsigned) and user (new, returning).",
+		Labels:    []string{"method", "user"},
+	})
+
+	// BalanceTransactions counts deposits and withdrawals
+	BalanceTransactions = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "balance",
+		Name:      "transactions_total",
+		Help:      "deposits and withdrawals by type, currency and status.",
+		Labels:    []string{"type", "currency", "status"},
+	})
+
+	// BalanceVolume sums the amounts of deposits and withdrawals
+	BalanceVolume = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "balance",
+		Name:      "volume_total",
+		Help:      "deposit and withdrawal amounts by type, currency and status.",
+		Labels:    []string{"type", "currency", "status"},
+	})
+
+	// Subscriptions counts subscriptions started, renewed and cancelled
+	Subscriptions = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "bot",
+		Name:      "subscriptions_total",
+		Help:      "bot subscriptions by bot and action (subscribe, renew, unsubscribe).",
+		Labels:    []string{"bot_id", "action"},
+	})
+
+	// ApiErrors counts the error codes returned to clients
+	ApiErrors = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: namespace,
+		Subsystem: "api",
+		Name:      "errors_total",
+		Help:      "error responses by error code and http status.",
+		Labels:    []string{"code", "status"},
+	})
+
+	// DbDuration observes how long each model method takes
+	DbDuration = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "duration_ms",
+		Help:      "model method duration(ms).",
+		Labels:    []string{"method"},
+		Buckets:   []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500},
+	})
+)
+
+// UserKind returns the user label of a login
+func UserKind(created bool) string {
+	if created {
+		return UserNew
+	}
+	return UserReturning
+}
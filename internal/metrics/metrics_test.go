@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"testing"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/zeromicro/go-zero/core/prometheus"
+)
+
+// find returns the sample of the named metric with the given label values
+func find(t *testing.T, name string, labels map[string]string) *dto.Metric {
+	t.Helper()
+	families, err := prom.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			matched := 0
+			for _, pair := range m.GetLabel() {
+				if labels[pair.GetName()] == pair.GetValue() {
+					matched++
+				}
+			}
+			if matched == len(labels) {
+				return m
+			}
+		}
+	}
+	return nil
+}
+
+func TestUserKind(t *testing.T) {
+	if got := UserKind(true); got != UserNew {
+		t.Errorf("UserKind(true) = %q, want %q", got, UserNew)
+	}
+	if got := UserKind(false); got != UserReturning {
+		t.Errorf("UserKind(false) = %q, want %q", got, UserReturning)
+	}
+}
+
+func TestCounters(t *testing.T) {
+	prometheus.Enable()
+
+	Logins.Inc(LoginSigned, UserKind(true))
+	Logins.Inc(LoginSigned, UserKind(true))
+	BalanceVolume.Add(12.5, "deposit", "USDT", "completed")
+	Subscriptions.Inc("bot-1", ActionRenew)
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"wata_auth_logins_total", map[string]string{"method": "signed", "user": "new"}, 2},
+		{"wata_balance_volume_total", map[string]string{"type": "deposit", "currency": "USDT", "status": "completed"}, 12.5},
+		{"wata_bot_subscriptions_total", map[string]string{"bot_id": "bot-1", "action": "renew"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := find(t, tt.name, tt.labels)
+			if m == nil {
+				t.Fatalf("%s%v not exported", tt.name, tt.labels)
+			}
+			if got := m.GetCounter().GetValue(); got != tt.want {
+				t.Errorf("%s = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDbDuration(t *testing.T) {
+	prometheus.Enable()
+
+	DbDuration.ObserveFloat(3, "UserModel.FindOne")
+	DbDuration.ObserveFloat(40, "UserModel.FindOne")
+
+	m := find(t, "wata_db_duration_ms", map[string]string{"method": "UserModel.FindOne"})
+	if m == nil {
+		t.Fatal("wata_db_duration_ms not exported")
+	}
+	h := m.GetHistogram()
+	if h.GetSampleCount() != 2 || h.GetSampleSum() != 43 {
+		t.Errorf("count = %d, sum = %v, want 2 and 43", h.GetSampleCount(), h.GetSampleSum())
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/response"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// AdminKeyHeader carries the operator API key
+const AdminKeyHeader = "X-Admin-Key"
+
+// AdminAuthMiddleware protects operator endpoints such as partner webhook management
+type AdminAuthMiddleware struct {
+	apiKey string
+}
+
+func NewAdminAuthMiddleware(apiKey string) *AdminAuthMiddleware {
+	return &AdminAuthMiddleware{
+		apiKey: apiKey,
+	}
+}
+
+func (m *AdminAuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// An empty key disables the admin API rather than leaving it open
+		if m.apiKey == "" {
+			logx.WithContext(r.Context()).Errorf("Admin API is disabled, rejecting %s %s", r.Method, r.URL.Path)
+			response.Error(r.Context(), w, model.NewAPIErrorFromCode(model.ErrCodeForbidden))
+			return
+		}
+		key := r.Header.Get(AdminKeyHeader)
+		if subtle.ConstantTimeCompare([]byte(key), []byte(m.apiKey)) != 1 {
+			logx.WithContext(r.Context()).Errorf("Admin auth failed for %s %s", r.Method, r.URL.Path)
+			writeUnauthorized(w, r)
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		apiKey string
+		header string
+		want   int
+	}{
+		{name: "matching key", apiKey: "admin-key", header: "admin-key", want: http.StatusOK},
+		{name: "wrong key", apiKey: "admin-key", header: "admin-kez", want: http.StatusUnauthorized},
+		{name: "missing key", apiKey: "admin-key", want: http.StatusUnauthorized},
+		{name: "admin api disabled", apiKey: "", header: "", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := NewAdminAuthMiddleware(tt.apiKey).Handle(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/api/admin/webhooks", nil)
+			if tt.header != "" {
+				r.Header.Set(AdminKeyHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if called != (tt.want == http.StatusOK) {
+				t.Errorf("next called = %v, want %v", called, tt.want == http.StatusOK)
+			}
+		})
+	}
+}
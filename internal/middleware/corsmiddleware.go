@@ -2,136 +2,198 @@ package middleware
 
 import (
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
+// CorsConf configures which browser origins may call the API
+type CorsConf struct {
+	// Origins are exact origins ("https://app.example.com"), wildcard
+	// subdomains ("https://*.example.com") or "*" for any origin without
+	// credentials; cross-origin requests are refused when empty
+	Origins []string `json:",optional"`
+	Methods []string `json:",default=[GET,POST,PUT,DELETE,PATCH,OPTIONS]"`
+	Headers []string `json:",default=[Content-Type,Authorization,X-Requested-With,Accept,Origin,X-Request-ID]"`
+	// ExposeHeaders are the response headers browsers let scripts read
+	ExposeHeaders []string `json:",default=[Content-Length,Content-Type,X-Request-ID,RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,RateLimit-Policy,Retry-After]"`
+	// AllowCredentials lets browsers send cookies and Authorization headers.
+	// It is ignored when Origins contains "*".
+	AllowCredentials bool `json:",default=true"`
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration `json:",default=1h"`
+}
+
 type CorsMiddleware struct {
-	allowedOrigins []string
+	anyOrigin     bool
+	origins       []originPattern
+	methods       string
+	headers       string
+	exposeHeaders string
+	credentials   bool
+	maxAge        string
+}
+
+// originPattern is an allowed origin; a wildcard pattern matches any
+// subdomain of host, but not host itself
+type originPattern struct {
+	scheme   string
+	host     string
+	wildcard bool
 }
 
-func NewCorsMiddleware() *CorsMiddleware {
-	return &CorsMiddleware{
-		allowedOrigins: []string{}, // Empty means allow all origins
+func NewCorsMiddleware(c CorsConf) *CorsMiddleware {
+	m := &CorsMiddleware{
+		methods:       strings.Join(c.Methods, ", "),
+		headers:       strings.Join(c.Headers, ", "),
+		exposeHeaders: strings.Join(c.ExposeHeaders, ", "),
+		credentials:   c.AllowCredentials,
+		maxAge:        strconv.Itoa(int(c.MaxAge / time.Second)),
+	}
+	for _, origin := range c.Origins {
+		origin = strings.TrimSpace(origin)
+		if origin == "*" {
+			m.anyOrigin = true
+			continue
+		}
+		pattern, ok := parseOriginPattern(origin)
+		if !ok {
+			logx.Errorf("CORS: ignoring invalid origin %q", origin)
+			continue
+		}
+		m.origins = append(m.origins, pattern)
 	}
+	// Echoing any origin with credentials would let every site make
+	// authenticated requests with the user's cookies
+	if m.anyOrigin && m.credentials {
+		logx.Errorf("CORS: AllowCredentials is ignored because Origins contains \"*\"")
+		m.credentials = false
+	}
+	return m
 }
 
-// NewCorsMiddlewareWithOrigins creates CORS middleware with specific allowed origins
-func NewCorsMiddlewareWithOrigins(origins []string) *CorsMiddleware {
-	return &CorsMiddleware{
-		allowedOrigins: origins,
+func parseOriginPattern(origin string) (originPattern, bool) {
+	u, err := url.Parse(strings.TrimSuffix(origin, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+		return originPattern{}, false
+	}
+	pattern := originPattern{
+		scheme: strings.ToLower(u.Scheme),
+		host:   strings.ToLower(u.Host),
+	}
+	if rest, ok := strings.CutPrefix(pattern.host, "*."); ok {
+		if rest == "" || strings.Contains(rest, "*") {
+			return originPattern{}, false
+		}
+		pattern.host = rest
+		pattern.wildcard = true
+	} else if strings.Contains(pattern.host, "*") {
+		return originPattern{}, false
 	}
+	return pattern, true
+}
+
+func (p originPattern) matches(scheme, host string) bool {
+	if p.scheme != scheme {
+		return false
+	}
+	if !p.wildcard {
+		return p.host == host
+	}
+	return strings.HasSuffix(host, "."+p.host)
 }
 
 func (m *CorsMiddleware) isOriginAllowed(origin string) bool {
-	// If no origins specified, allow all
-	if len(m.allowedOrigins) == 0 {
+	if origin == "" {
+		return false
+	}
+	if m.anyOrigin {
 		return true
 	}
-
-	// Normalize origin (remove trailing slash for comparison)
-	normalizedOrigin := strings.TrimSuffix(origin, "/")
-
-	// Check if origin is in allowed list
-	for _, allowed := range m.allowedOrigins {
-		if allowed == "*" {
-			return true
-		}
-		// Normalize allowed origin too
-		normalizedAllowed := strings.TrimSuffix(allowed, "/")
-		if normalizedAllowed == normalizedOrigin {
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	scheme, host := strings.ToLower(u.Scheme), strings.ToLower(u.Host)
+	for _, pattern := range m.origins {
+		if pattern.matches(scheme, host) {
 			return true
 		}
 	}
 	return false
 }
 
+// setOriginHeaders allows the request's origin, if permitted, and reports
+// whether it was
+func (m *CorsMiddleware) setOriginHeaders(header http.Header, origin string) bool {
+	// The response depends on the Origin header even when it is refused, so
+	// shared caches must not serve it to other origins
+	addVary(header, "Origin")
+	if !m.isOriginAllowed(origin) {
+		return false
+	}
+	if m.anyOrigin {
+		header.Set("Access-Control-Allow-Origin", "*")
+		return true
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+	if m.credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	return true
+}
+
 func (m *CorsMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		origin := r.Header.Get("Origin")
-
-		// Debug logging
-		logx.Infof("CORS Request - Origin: %s, Method: %s, Path: %s", origin, r.Method, r.URL.Path)
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if m.setOriginHeaders(w.Header(), origin) && m.exposeHeaders != "" {
+				w.Header().Set("Access-Control-Expose-Headers", m.exposeHeaders)
+			}
+		}
+		next(w, r)
+	}
+}
 
-		// Create a response writer wrapper to ensure headers are set
-		corsWriter := &corsResponseWriter{
-			ResponseWriter: w,
-			allowedOrigins: m.allowedOrigins,
-			origin:         origin,
+// NotAllowedHandler answers requests whose path exists but not for their
+// method. Preflight OPTIONS requests always end up here because no route is
+// registered for OPTIONS, so this is where they are answered.
+func (m *CorsMiddleware) NotAllowedHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
 		}
 
-		// Handle preflight OPTIONS request
-		if r.Method == http.MethodOptions {
-			logx.Infof("CORS: Handling preflight OPTIONS request")
-			corsWriter.setCorsHeaders()
+		header := w.Header()
+		addVary(header, "Access-Control-Request-Method")
+		addVary(header, "Access-Control-Request-Headers")
+		origin := r.Header.Get("Origin")
+		if !m.setOriginHeaders(header, origin) {
+			if origin != "" {
+				logx.WithContext(r.Context()).Debugf("CORS: origin %s not allowed", origin)
+			}
 			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
-		// Continue with the next handler
-		next(corsWriter, r)
-	}
+		header.Set("Access-Control-Allow-Methods", m.methods)
+		header.Set("Access-Control-Allow-Headers", m.headers)
+		header.Set("Access-Control-Max-Age", m.maxAge)
+		w.WriteHeader(http.StatusNoContent)
+	})
 }
 
-// corsResponseWriter wraps http.ResponseWriter to ensure CORS headers are always set
-type corsResponseWriter struct {
-	http.ResponseWriter
-	allowedOrigins []string
-	origin         string
-	headersSet     bool
-}
-
-func (rw *corsResponseWriter) setCorsHeaders() {
-	if rw.headersSet {
-		return
-	}
-	rw.headersSet = true
-
-	// Always set CORS headers for all requests
-	rw.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS, PATCH")
-	rw.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, Accept, Origin")
-	rw.Header().Set("Access-Control-Max-Age", "3600")
-	rw.Header().Set("Access-Control-Expose-Headers", "Content-Length, Content-Type")
-
-	// Set Access-Control-Allow-Origin based on origin
-	if rw.origin != "" {
-		normalizedOrigin := strings.TrimSuffix(rw.origin, "/")
-		allowed := false
-
-		if len(rw.allowedOrigins) == 0 {
-			allowed = true
-		} else {
-			for _, allowedOrigin := range rw.allowedOrigins {
-				normalizedAllowed := strings.TrimSuffix(allowedOrigin, "/")
-				if normalizedAllowed == normalizedOrigin || allowedOrigin == "*" {
-					allowed = true
-					break
-				}
+// addVary adds value to the Vary header unless it is already listed
+func addVary(header http.Header, value string) {
+	for _, line := range header.Values("Vary") {
+		for _, v := range strings.Split(line, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), value) {
+				return
 			}
 		}
-
-		if allowed {
-			rw.Header().Set("Access-Control-Allow-Origin", rw.origin)
-			rw.Header().Set("Access-Control-Allow-Credentials", "true")
-			logx.Infof("CORS: Allowing origin %s", rw.origin)
-		} else {
-			logx.Errorf("CORS: Origin %s not allowed. Allowed origins: %v", rw.origin, rw.allowedOrigins)
-		}
-	} else {
-		// No origin header
-		if len(rw.allowedOrigins) == 0 {
-			rw.Header().Set("Access-Control-Allow-Origin", "*")
-		}
 	}
-}
-
-func (rw *corsResponseWriter) WriteHeader(code int) {
-	rw.setCorsHeaders()
-	rw.ResponseWriter.WriteHeader(code)
-}
-
-func (rw *corsResponseWriter) Write(b []byte) (int, error) {
-	rw.setCorsHeaders()
-	return rw.ResponseWriter.Write(b)
+	header.Add("Vary", value)
 }
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCorsOriginHeaders(t *testing.T) {
+	tests := []struct {
+		name            string
+		origins         []string
+		credentials     bool
+		origin          string
+		wantOrigin      string
+		wantCredentials string
+	}{
+		{name: "exact origin", origins: []string{"https://app.example.com"}, credentials: true,
+			origin: "https://app.example.com", wantOrigin: "https://app.example.com", wantCredentials: "true"},
+		{name: "wildcard subdomain", origins: []string{"https://*.example.com"}, credentials: true,
+			origin: "https://beta.example.com", wantOrigin: "https://beta.example.com", wantCredentials: "true"},
+		{name: "wildcard does not match apex", origins: []string{"https://*.example.com"}, credentials: true,
+			origin: "https://example.com"},
+		{name: "other scheme", origins: []string{"https://app.example.com"},
+			origin: "http://app.example.com"},
+		{name: "any origin without credentials", origins: []string{"*"},
+			origin: "https://evil.example.net", wantOrigin: "*"},
+		{name: "any origin ignores credentials", origins: []string{"*"}, credentials: true,
+			origin: "https://evil.example.net", wantOrigin: "*"},
+		{name: "no origins", credentials: true, origin: "https://app.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewCorsMiddleware(CorsConf{Origins: tt.origins, AllowCredentials: tt.credentials})
+			r := httptest.NewRequest(http.MethodGet, "/api/bots", nil)
+			r.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+			m.Handle(func(http.ResponseWriter, *http.Request) {})(w, r)
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Errorf("Access-Control-Allow-Credentials = %q, want %q", got, tt.wantCredentials)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// EngineKeyHeader carries the shared API key of the trading engine
+const EngineKeyHeader = "X-Engine-Key"
+
+// EngineAuthMiddleware protects ingestion endpoints that only the trading engine may call
+type EngineAuthMiddleware struct {
+	apiKey string
+}
+
+func NewEngineAuthMiddleware(apiKey string) *EngineAuthMiddleware {
+	return &EngineAuthMiddleware{
+		apiKey: apiKey,
+	}
+}
+
+func (m *EngineAuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// An empty key disables ingestion rather than leaving it open
+		key := r.Header.Get(EngineKeyHeader)
+		if m.apiKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(m.apiKey)) != 1 {
+			logx.WithContext(r.Context()).Errorf("Engine auth failed for %s %s", r.Method, r.URL.Path)
+			writeUnauthorized(w, r)
+			return
+		}
+
+		next(w, r)
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEngineAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		apiKey string
+		header string
+		want   int
+	}{
+		{name: "matching key", apiKey: "engine-key", header: "engine-key", want: http.StatusOK},
+		{name: "wrong key", apiKey: "engine-key", header: "engine-kez", want: http.StatusUnauthorized},
+		{name: "missing key", apiKey: "engine-key", want: http.StatusUnauthorized},
+		{name: "prefix of the key", apiKey: "engine-key", header: "engine", want: http.StatusUnauthorized},
+		{name: "ingestion disabled", apiKey: "", header: "", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			handler := NewEngineAuthMiddleware(tt.apiKey).Handle(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})
+
+			r := httptest.NewRequest(http.MethodPost, "/api/engine/performance", nil)
+			if tt.header != "" {
+				r.Header.Set(EngineKeyHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if called != (tt.want == http.StatusOK) {
+				t.Errorf("next called = %v, want %v", called, tt.want == http.StatusOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignEngineRequest(t *testing.T) {
+	// Computed independently with:
+	// printf '1700000000\nPOST\n/api/engine/trades\n{"a":1}' | openssl dgst -sha256 -hmac secret
+	const want = "6e6c787df8dfacc71e518cfade0300c0c31a33c04505a39ea3a3612eae15d85e"
+
+	got := hex.EncodeToString(SignEngineRequest([]byte("secret"), "1700000000", http.MethodPost, "/api/engine/trades", []byte(`{"a":1}`)))
+	if got != want {
+		t.Errorf("SignEngineRequest() = %s, want %s", got, want)
+	}
+}
+
+func TestEngineSignatureMiddleware(t *testing.T) {
+	const (
+		secret = "engine-secret"
+		path   = "/api/engine/trades"
+		body   = `{"botId":"grid","trades":[]}`
+	)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	sign := func(secret, timestamp, method, path, body string) string {
+		return hex.EncodeToString(SignEngineRequest([]byte(secret), timestamp, method, path, []byte(body)))
+	}
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      string
+		want      int
+	}{
+		{name: "valid", secret: secret, timestamp: now, signature: sign(secret, now, http.MethodPost, path, body), body: body, want: http.StatusOK},
+		{name: "no secret configured", secret: "", timestamp: now, signature: sign("", now, http.MethodPost, path, body), body: body, want: http.StatusUnauthorized},
+		{name: "wrong secret", secret: secret, timestamp: now, signature: sign("other", now, http.MethodPost, path, body), body: body, want: http.StatusUnauthorized},
+		{name: "tampered body", secret: secret, timestamp: now, signature: sign(secret, now, http.MethodPost, path, body), body: `{"botId":"grid","trades":[{}]}`, want: http.StatusUnauthorized},
+		{name: "other path", secret: secret, timestamp: now, signature: sign(secret, now, http.MethodPost, "/api/engine/performance", body), body: body, want: http.StatusUnauthorized},
+		{name: "other method", secret: secret, timestamp: now, signature: sign(secret, now, http.MethodPut, path, body), body: body, want: http.StatusUnauthorized},
+		{name: "signature not hex", secret: secret, timestamp: now, signature: "not-hex", body: body, want: http.StatusUnauthorized},
+		{name: "missing timestamp", secret: secret, signature: sign(secret, "", http.MethodPost, path, body), body: body, want: http.StatusUnauthorized},
+		{
+			name:      "stale timestamp",
+			secret:    secret,
+			timestamp: strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10),
+			body:      body,
+			want:      http.StatusUnauthorized,
+		},
+		{
+			name:      "future timestamp",
+			secret:    secret,
+			timestamp: strconv.FormatInt(time.Now().Add(10*time.Minute).Unix(), 10),
+			body:      body,
+			want:      http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature := tt.signature
+			if signature == "" {
+				// Correctly signed, so only the timestamp can be at fault
+				signature = sign(tt.secret, tt.timestamp, http.MethodPost, path, tt.body)
+			}
+
+			var received string
+			handler := NewEngineSignatureMiddleware(tt.secret).Handle(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				received = string(b)
+			})
+			r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(tt.body))
+			r.Header.Set(EngineTimestampHeader, tt.timestamp)
+			r.Header.Set(EngineSignatureHeader, signature)
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && received != tt.body {
+				t.Errorf("handler read body %q, want the signed body %q", received, tt.body)
+			}
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package middleware
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
-	"wata-bot-BE/internal/utils"
+	"wata-bot-BE/internal/errorlog"
+	"wata-bot-BE/internal/model"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -19,6 +22,7 @@ func NewErrorLogMiddleware() *ErrorLogMiddleware {
 func (m *ErrorLogMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		ctx := errorlog.NewContext(r.Context())
 
 		// Create a response writer wrapper to capture status code
 		rw := &responseWriter{
@@ -27,10 +31,11 @@ func (m *ErrorLogMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 		}
 
 		// Execute next handler
-		next(rw, r)
+		next(rw, r.WithContext(ctx))
 
-		// Log errors (status code >= 400)
-		if rw.statusCode >= 400 {
+		// Log server errors that ErrorHandler did not report, such as those
+		// written by middlewares; client errors are not reported
+		if rw.statusCode >= http.StatusInternalServerError && !errorlog.Reported(ctx) {
 			duration := time.Since(start)
 
 			context := map[string]interface{}{
@@ -43,20 +48,51 @@ func (m *ErrorLogMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 			}
 
 			errorMsg := fmt.Sprintf("HTTP Error: %s %s", r.Method, r.URL.Path)
-			utils.WriteErrorLogWithContext(errorMsg, nil, context)
+			errorlog.ReportWithFields(ctx, errorMsg, rw.apiError(), context)
 
 			// Also log to go-zero logger
-			logx.Errorf("HTTP Error [%d] %s %s - %v", rw.statusCode, r.Method, r.URL.Path, duration)
+			logx.WithContext(ctx).Errorf("HTTP Error [%d] %s %s - %v", rw.statusCode, r.Method, r.URL.Path, duration)
 		}
 	}
 }
 
+// maxErrorBodySize bounds how much of a server error response is kept to read
+// its error code
+const maxErrorBodySize = 4 << 10
+
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	// body holds the start of a server error response
+	body bytes.Buffer
 }
 
 func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if rw.statusCode >= http.StatusInternalServerError && rw.body.Len() < maxErrorBodySize {
+		rw.body.Write(b[:min(len(b), maxErrorBodySize-rw.body.Len())])
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+// apiError returns the error of a server error response, with the error code
+// of its ErrorResp body; responses without one are internal server errors
+func (rw *responseWriter) apiError() error {
+	var resp struct {
+		ErrorCode string `json:"error_code"`
+		Message   string `json:"message"`
+	}
+	if json.Unmarshal(rw.body.Bytes(), &resp) != nil || resp.ErrorCode == "" {
+		return model.NewAPIError(model.ErrCodeInternalServerError, http.StatusText(rw.statusCode))
+	}
+	return model.NewAPIError(resp.ErrorCode, resp.Message)
+}
+
+// Unwrap lets http.ResponseController flush streamed responses
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"wata-bot-BE/internal/errorlog"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/response"
+)
+
+// entrySink keeps the reports written to it
+type entrySink struct {
+	entries []*errorlog.Entry
+}
+
+func (s *entrySink) Write(entry *errorlog.Entry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *entrySink) Close() error {
+	return nil
+}
+
+func TestErrorLogMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		wantCode []string // Error codes of the reports, in order
+	}{
+		{
+			name:    "success",
+			handler: func(w http.ResponseWriter, r *http.Request) {},
+		},
+		{
+			name: "client error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				response.Error(r.Context(), w, model.NewAPIErrorFromCode(model.ErrCodeBotNotFound))
+			},
+		},
+		{
+			name: "reported by the handler",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				err := model.NewAPIErrorFromCode(model.ErrCodeDatabaseError)
+				errorlog.Report(r.Context(), "Handler error", err)
+				response.Error(r.Context(), w, err)
+			},
+			wantCode: []string{model.ErrCodeDatabaseError},
+		},
+		{
+			name: "written by a middleware",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				response.Error(r.Context(), w, model.NewAPIErrorFromCode(model.ErrCodeFailedToFindUser))
+			},
+			wantCode: []string{model.ErrCodeFailedToFindUser},
+		},
+		{
+			name: "without an error body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "upstream unavailable", http.StatusBadGateway)
+			},
+			wantCode: []string{model.ErrCodeInternalServerError},
+		},
+		{
+			name: "plain error hides its message",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				response.Error(r.Context(), w, errors.New("dial tcp: connection refused"))
+			},
+			wantCode: []string{model.ErrCodeInternalServerError},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &entrySink{}
+			errorlog.Setup(errorlog.NewReporter(false, sink))
+			defer errorlog.Setup(errorlog.NewReporter(false))
+
+			handler := NewErrorLogMiddleware().Handle(tt.handler)
+			handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/bots", nil))
+
+			var got []string
+			for _, entry := range sink.entries {
+				got = append(got, entry.ErrorCode)
+			}
+			if len(got) != len(tt.wantCode) {
+				t.Fatalf("reported codes %v, want %v", got, tt.wantCode)
+			}
+			for i := range got {
+				if got[i] != tt.wantCode[i] {
+					t.Errorf("reported codes %v, want %v", got, tt.wantCode)
+				}
+			}
+		})
+	}
+}
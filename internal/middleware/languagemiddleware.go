@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"net/http"
+
+	"wata-bot-BE/internal/i18n"
+)
+
+// LanguageMiddleware picks the response language from the Accept-Language
+// header, falling back to English, so that error messages are translated
+type LanguageMiddleware struct{}
+
+func NewLanguageMiddleware() *LanguageMiddleware {
+	return &LanguageMiddleware{}
+}
+
+func (m *LanguageMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lang := i18n.Match(r.Header.Get("Accept-Language"))
+		addVary(w.Header(), "Accept-Language")
+		next(w, r.WithContext(i18n.NewContext(r.Context(), lang)))
+	}
+}
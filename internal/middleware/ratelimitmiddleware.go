@@ -0,0 +1,231 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"wata-bot-BE/internal/address"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/ratelimit"
+	"wata-bot-BE/internal/response"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+	"github.com/zeromicro/go-zero/rest"
+)
+
+// Rate limit response headers (IETF RateLimit header fields draft)
+const (
+	RateLimitLimitHeader     = "RateLimit-Limit"
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	RateLimitResetHeader     = "RateLimit-Reset"
+	RateLimitPolicyHeader    = "RateLimit-Policy"
+)
+
+// maxAddressBodySize bounds how much of a request body is read to find the
+// wallet address it acts on
+const maxAddressBodySize = 64 << 10
+
+// AddressSource selects the wallet address a rate limit counts requests for
+type AddressSource int
+
+const (
+	// AddressFromToken counts requests with a valid access token per its wallet
+	AddressFromToken AddressSource = iota
+	// AddressFromBody counts requests per the "address" field of their JSON
+	// body, for routes that take the wallet from the body instead of a token
+	AddressFromBody
+)
+
+// RateLimitMiddleware limits a route group per client IP and per wallet
+// address. When several limits apply, the headers describe the one closest
+// to running out.
+type RateLimitMiddleware struct {
+	name          string
+	ip            *ratelimit.Limiter
+	address       *ratelimit.Limiter
+	addressSource AddressSource
+	policy        ratelimit.PolicyConf
+	proxyHops     int
+	secret        []byte
+}
+
+// NewRateLimitMiddleware counts in store, or in memory when store is nil.
+// proxyHops is the number of trusted reverse proxies that append to
+// X-Forwarded-For, 0 to use the connection's peer address.
+func NewRateLimitMiddleware(store *redis.Redis, keyPrefix, name string, policy ratelimit.PolicyConf,
+	addressSource AddressSource, proxyHops int, jwtSecret string) *RateLimitMiddleware {
+	m := &RateLimitMiddleware{
+		name:          name,
+		policy:        policy,
+		addressSource: addressSource,
+		proxyHops:     proxyHops,
+		secret:        []byte(jwtSecret),
+	}
+	prefix := keyPrefix + ":" + name + ":"
+	if policy.Ip > 0 {
+		m.ip = ratelimit.NewLimiter(store, prefix+"ip:", policy.Period, policy.Ip)
+	}
+	if policy.Address > 0 {
+		m.address = ratelimit.NewLimiter(store, prefix+"address:", policy.Period, policy.Address)
+	}
+	return m
+}
+
+func (m *RateLimitMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var results []ratelimit.Result
+		if m.ip != nil {
+			results = append(results, m.ip.Take(r.Context(), m.clientIP(r)))
+		}
+		if m.address != nil {
+			if addr, ok := m.walletAddress(r); ok {
+				results = append(results, m.address.Take(r.Context(), addr.Key()))
+			}
+		}
+		if len(results) == 0 {
+			next(w, r)
+			return
+		}
+
+		res := mostRestrictive(results)
+		m.writeHeaders(w, res)
+		if !res.Allowed {
+			logx.WithContext(r.Context()).Infof("Rate limit %s exceeded for %s %s from %s", m.name, r.Method, r.URL.Path, m.clientIP(r))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds(res.Reset)))
+			response.Error(r.Context(), w, model.NewAPIErrorFromCode(model.ErrCodeTooManyRequests))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// SkipPaths applies mw to every request except those for the given paths, such
+// as health probes that must keep answering however often they are polled
+func SkipPaths(mw rest.Middleware, paths ...string) rest.Middleware {
+	skip := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		skip[path] = true
+	}
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		limited := mw(next)
+		return func(w http.ResponseWriter, r *http.Request) {
+			if skip[r.URL.Path] {
+				next(w, r)
+				return
+			}
+			limited(w, r)
+		}
+	}
+}
+
+// writeHeaders reports res unless a limit applied earlier, such as the global
+// one, already reported fewer remaining requests
+func (m *RateLimitMiddleware) writeHeaders(w http.ResponseWriter, res ratelimit.Result) {
+	if prev := w.Header().Get(RateLimitRemainingHeader); prev != "" && res.Remaining >= 0 {
+		if n, err := strconv.Atoi(prev); err == nil && n < res.Remaining {
+			return
+		}
+	}
+	w.Header().Set(RateLimitLimitHeader, strconv.Itoa(res.Limit))
+	if res.Remaining >= 0 {
+		w.Header().Set(RateLimitRemainingHeader, strconv.Itoa(res.Remaining))
+	} else {
+		w.Header().Del(RateLimitRemainingHeader)
+	}
+	w.Header().Set(RateLimitResetHeader, strconv.Itoa(seconds(res.Reset)))
+	w.Header().Set(RateLimitPolicyHeader, strconv.Itoa(res.Limit)+";w="+strconv.Itoa(seconds(m.policy.Period)))
+}
+
+// walletAddress returns the wallet the request is counted for, if any
+func (m *RateLimitMiddleware) walletAddress(r *http.Request) (address.Address, bool) {
+	if m.addressSource == AddressFromBody {
+		return bodyAddress(r)
+	}
+	addr, err := accessTokenAddress(r, m.secret)
+	return addr, err == nil
+}
+
+// bodyAddress reads the "address" field of a JSON request body and restores
+// the body for the handler
+func bodyAddress(r *http.Request) (address.Address, bool) {
+	if r.Body == nil || !strings.Contains(r.Header.Get("Content-Type"), "json") {
+		return "", false
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxAddressBodySize))
+	// Put back what was read ahead of whatever is left, so the handler still
+	// sees the whole body and applies its own size limit
+	r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil {
+		return "", false
+	}
+
+	var req struct {
+		Address string `json:"address"`
+	}
+	if json.Unmarshal(body, &req) != nil {
+		return "", false
+	}
+	addr, err := address.Parse(req.Address)
+	return addr, err == nil
+}
+
+// readCloser reads from one reader and closes another
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// clientIP is the connection's peer address or, behind proxyHops trusted
+// reverse proxies, the X-Forwarded-For entry the outermost of them appended.
+// Entries to its left come from the client and cannot be trusted.
+func (m *RateLimitMiddleware) clientIP(r *http.Request) string {
+	if m.proxyHops > 0 {
+		var hops []string
+		for _, line := range r.Header.Values("X-Forwarded-For") {
+			for _, ip := range strings.Split(line, ",") {
+				if ip = strings.TrimSpace(ip); ip != "" {
+					hops = append(hops, ip)
+				}
+			}
+		}
+		if len(hops) > 0 {
+			// A shorter chain reached an inner proxy directly; its leftmost
+			// entry was still appended by a trusted proxy
+			return hops[max(len(hops)-m.proxyHops, 0)]
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// mostRestrictive picks a rejected result, else the one with the fewest
+// remaining requests
+func mostRestrictive(results []ratelimit.Result) ratelimit.Result {
+	best := results[0]
+	for _, res := range results[1:] {
+		switch {
+		case !res.Allowed && best.Allowed:
+			best = res
+		case res.Allowed != best.Allowed:
+		case res.Remaining >= 0 && (best.Remaining < 0 || res.Remaining < best.Remaining):
+			best = res
+		}
+	}
+	return best
+}
+
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
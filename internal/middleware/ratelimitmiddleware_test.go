@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"wata-bot-BE/internal/ratelimit"
+)
+
+func TestRateLimitClientIP(t *testing.T) {
+	tests := []struct {
+		name      string
+		proxyHops int
+		forwarded []string
+		want      string
+	}{
+		{name: "no proxy ignores header", forwarded: []string{"203.0.113.7"}, want: "192.0.2.1"},
+		{name: "one proxy", proxyHops: 1, forwarded: []string{"203.0.113.7"}, want: "203.0.113.7"},
+		{name: "one proxy ignores spoofed entries", proxyHops: 1, forwarded: []string{"198.51.100.9, 203.0.113.7"}, want: "203.0.113.7"},
+		{name: "two proxies", proxyHops: 2, forwarded: []string{"198.51.100.9, 203.0.113.7, 10.0.0.2"}, want: "203.0.113.7"},
+		{name: "header lines are joined", proxyHops: 2, forwarded: []string{"198.51.100.9, 203.0.113.7", "10.0.0.2"}, want: "203.0.113.7"},
+		{name: "chain shorter than hops", proxyHops: 3, forwarded: []string{"203.0.113.7, 10.0.0.2"}, want: "203.0.113.7"},
+		{name: "no header", proxyHops: 1, want: "192.0.2.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewRateLimitMiddleware(nil, "test", "api", ratelimit.PolicyConf{Period: time.Minute, Ip: 1}, AddressFromToken, tt.proxyHops, "secret")
+			r := httptest.NewRequest(http.MethodGet, "/api/bots", nil)
+			r.RemoteAddr = "192.0.2.1:4321"
+			for _, v := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if got := m.clientIP(r); got != tt.want {
+				t.Errorf("clientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitBodyAddress(t *testing.T) {
+	m := NewRateLimitMiddleware(nil, "test", "balance", ratelimit.PolicyConf{Period: time.Minute, Address: 1}, AddressFromBody, 0, "secret")
+	var seen string
+	handler := m.Handle(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		seen = string(body)
+	})
+
+	send := func(body string) int {
+		r := httptest.NewRequest(http.MethodPost, "/api/user/withdraw", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w.Code
+	}
+
+	first := `{"address": "0x742d35cc6634c0532925a3b844bc9e7595f0beb0", "amount": "1"}`
+	if code := send(first); code != http.StatusOK {
+		t.Fatalf("first request status = %d", code)
+	}
+	if seen != first {
+		t.Fatalf("handler read body %q, want %q", seen, first)
+	}
+	// The same wallet in another letter case shares the quota
+	if code := send(`{"address": "0x742D35CC6634C0532925A3B844BC9E7595F0BEB0", "amount": "1"}`); code != http.StatusTooManyRequests {
+		t.Errorf("second request for the wallet status = %d, want 429", code)
+	}
+	if code := send(`{"address": "0x0000000000000000000000000000000000000001", "amount": "1"}`); code != http.StatusOK {
+		t.Errorf("request for another wallet status = %d, want 200", code)
+	}
+	// Requests without a valid address are left to the handler's validation
+	if code := send(`{"amount": "1"}`); code != http.StatusOK {
+		t.Errorf("request without address status = %d, want 200", code)
+	}
+}
+
+func TestSkipPaths(t *testing.T) {
+	limit := NewRateLimitMiddleware(nil, "test", "api", ratelimit.PolicyConf{Period: time.Minute, Ip: 1}, AddressFromToken, 0, "secret")
+	handler := SkipPaths(limit.Handle, "/livez", "/healthz", "/readyz")(func(w http.ResponseWriter, r *http.Request) {})
+
+	send := func(path string) int {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code
+	}
+
+	for i := 0; i < 3; i++ {
+		for _, path := range []string{"/livez", "/healthz", "/readyz"} {
+			if code := send(path); code != http.StatusOK {
+				t.Fatalf("GET %s #%d = %d, want 200", path, i+1, code)
+			}
+		}
+	}
+	if code := send("/api/bots"); code != http.StatusOK {
+		t.Errorf("first GET /api/bots = %d, want 200", code)
+	}
+	if code := send("/api/bots"); code != http.StatusTooManyRequests {
+		t.Errorf("second GET /api/bots = %d, want 429", code)
+	}
+}
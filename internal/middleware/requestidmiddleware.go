@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+
+	"wata-bot-BE/internal/requestid"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIdMiddleware reuses the client's X-Request-ID or assigns a new one,
+// returns it in the response and adds it to every log written with the
+// request context
+type RequestIdMiddleware struct{}
+
+func NewRequestIdMiddleware() *RequestIdMiddleware {
+	return &RequestIdMiddleware{}
+}
+
+func (m *RequestIdMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		w.Header().Set(requestid.Header, id)
+
+		ctx := r.Context()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request_id", id))
+		ctx = requestid.NewContext(ctx, id)
+		ctx = logx.ContextWithFields(ctx, logx.Field("requestId", id))
+
+		next(w, r.WithContext(ctx))
+	}
+}
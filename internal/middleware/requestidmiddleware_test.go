@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"wata-bot-BE/internal/requestid"
+)
+
+func TestRequestIdMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		wantSame bool // Whether the client's id is reused
+	}{
+		{name: "client id reused", header: "client-123", wantSame: true},
+		{name: "missing id assigned"},
+		{name: "invalid id replaced", header: "has space"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := NewRequestIdMiddleware().Handle(func(w http.ResponseWriter, r *http.Request) {
+				seen = requestid.FromContext(r.Context())
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/api/bots", nil)
+			if tt.header != "" {
+				r.Header.Set(requestid.Header, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler(w, r)
+
+			got := w.Header().Get(requestid.Header)
+			if !requestid.Valid(got) {
+				t.Fatalf("response %s = %q, want a valid id", requestid.Header, got)
+			}
+			if seen != got {
+				t.Errorf("context id = %q, response id = %q, want the same", seen, got)
+			}
+			if (got == tt.header) != tt.wantSame {
+				t.Errorf("response id = %q, client sent %q, want reused %v", got, tt.header, tt.wantSame)
+			}
+		})
+	}
+}
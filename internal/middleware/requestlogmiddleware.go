@@ -2,94 +2,278 @@ package middleware
 
 import (
 	"bytes"
-	"fmt"
+	"context"
+	"encoding/json"
 	"io"
+	"math/rand/v2"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
-type RequestLogMiddleware struct{}
+const redacted = "[REDACTED]"
 
-func NewRequestLogMiddleware() *RequestLogMiddleware {
-	return &RequestLogMiddleware{}
+// maxCapturedBody bounds how much of a body is read for the debug log; bodies
+// are redacted before they are truncated to MaxBodySize
+const maxCapturedBody = 64 << 10
+
+// RequestLogConf configures the access log written for every request
+type RequestLogConf struct {
+	// RedactKeys are JSON body fields and query parameters whose values are
+	// never logged, matched case-insensitively at any depth
+	RedactKeys []string `json:",default=[signature,access_token,accessToken,refresh_token,refreshToken,token,password,secret,apiKey,api_key]"`
+	// RedactHeaders are request headers whose values are never logged
+	RedactHeaders []string `json:",default=[Authorization,Cookie,Set-Cookie,X-Admin-Key,X-Engine-Key,X-Engine-Signature]"`
+	// MaxBodySize bounds how much of each body is logged at debug level
+	MaxBodySize int `json:",default=2048"`
+	// Sampling logs only a fraction of successful requests to high-volume
+	// routes; errors and slow requests are always logged
+	Sampling []SampleConf `json:",optional"`
+	// SlowThreshold marks requests that took longer as slow
+	SlowThreshold time.Duration `json:",default=1s"`
+}
+
+// SampleConf logs Rate (0 to 1) of the requests whose path starts with Path
+type SampleConf struct {
+	Path string
+	Rate float64
+}
+
+type RequestLogMiddleware struct {
+	redactKeys    map[string]bool
+	redactHeaders map[string]bool
+	maxBodySize   int
+	sampling      []SampleConf
+	slowThreshold time.Duration
+	// debug also logs headers and bodies
+	debug bool
+}
+
+func NewRequestLogMiddleware(c RequestLogConf, debug bool) *RequestLogMiddleware {
+	m := &RequestLogMiddleware{
+		redactKeys:    make(map[string]bool, len(c.RedactKeys)),
+		redactHeaders: make(map[string]bool, len(c.RedactHeaders)),
+		maxBodySize:   c.MaxBodySize,
+		sampling:      c.Sampling,
+		slowThreshold: c.SlowThreshold,
+		debug:         debug,
+	}
+	for _, key := range c.RedactKeys {
+		m.redactKeys[strings.ToLower(key)] = true
+	}
+	for _, header := range c.RedactHeaders {
+		m.redactHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+	return m
+}
+
+// requestLogFields collects fields known only inside the route chain, such as
+// the wallet address authenticated by UserAuthMiddleware or parsed from the
+// request body by LogAddressValidator
+type requestLogFields struct {
+	address string
+}
+
+type requestLogKey struct{}
+
+// setLogAddress records the authenticated wallet address for the access log
+func setLogAddress(ctx context.Context, address string) {
+	if fields, ok := ctx.Value(requestLogKey{}).(*requestLogFields); ok {
+		fields.address = address
+	}
+}
+
+// LogAddressValidator wraps the request validator so the access log shows
+// the wallet address of requests that carry it in their body: once httpx.Parse
+// has parsed a request, its Address field, if set, is recorded.
+type LogAddressValidator struct {
+	httpx.Validator
+}
+
+func (v LogAddressValidator) Validate(r *http.Request, data any) error {
+	err := v.Validator.Validate(r, data)
+	// Validation normalizes the address, so record it afterwards, and also
+	// when the request is rejected
+	if addr := requestAddress(data); addr != "" {
+		setLogAddress(r.Context(), addr)
+	}
+	return err
+}
+
+// requestAddress returns the Address field of a parsed request, or ""
+func requestAddress(data any) string {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	if f := v.FieldByName("Address"); f.IsValid() && f.Kind() == reflect.String {
+		return f.String()
+	}
+	return ""
 }
 
 func (m *RequestLogMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
-		// Log request to terminal (using fmt.Printf to ensure it shows in console)
-		fmt.Printf("\n━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n")
-		fmt.Printf("📥 REQUEST [%s] %s", r.Method, r.URL.Path)
-		if r.URL.RawQuery != "" {
-			fmt.Printf("?%s", r.URL.RawQuery)
-		}
-		fmt.Printf("\n")
-		fmt.Printf("   Origin: %s\n", r.Header.Get("Origin"))
-		fmt.Printf("   User-Agent: %s\n", r.UserAgent())
-		fmt.Printf("   Remote: %s\n", r.RemoteAddr)
-
-		// Also log to logx for file logging
-		logx.Infof("REQUEST [%s] %s %s - Origin: %s", r.Method, r.URL.Path, r.URL.RawQuery, r.Header.Get("Origin"))
-
-		// Read and log request body if exists
-		if r.Body != nil {
-			requestBody, err := io.ReadAll(r.Body)
-			if err == nil && len(requestBody) > 0 {
-				// Restore body for handler to read
-				r.Body = io.NopCloser(bytes.NewBuffer(requestBody))
-				// Limit body size for logging (first 500 chars)
-				bodyStr := string(requestBody)
-				if len(bodyStr) > 500 {
-					bodyStr = bodyStr[:500] + "... (truncated)"
-				}
-				fmt.Printf("   Body: %s\n", bodyStr)
-				logx.Infof("Request Body: %s", bodyStr)
-			} else if err == nil {
-				// Body is empty, restore it anyway
-				r.Body = io.NopCloser(bytes.NewBuffer(requestBody))
+		var requestBody []byte
+		if m.debug && r.Body != nil {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxCapturedBody))
+			if err == nil {
+				requestBody = body
 			}
+			// Restore the body for the handler
+			r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
 		}
 
-		// Create response writer wrapper to capture response
+		fields := &requestLogFields{}
 		rw := &responseLogWriter{
 			ResponseWriter: w,
 			statusCode:     http.StatusOK,
-			body:           &bytes.Buffer{},
+		}
+		if m.debug {
+			rw.body = &bytes.Buffer{}
+			rw.limit = maxCapturedBody
 		}
 
-		// Execute next handler
-		next(rw, r)
+		next(rw, r.WithContext(context.WithValue(r.Context(), requestLogKey{}, fields)))
 
-		// Calculate duration
 		duration := time.Since(start)
+		slow := duration > m.slowThreshold
+		if rw.statusCode < http.StatusBadRequest && !slow && !m.sampled(r.URL.Path) {
+			return
+		}
+
+		address := fields.address
+		if address == "" {
+			address = r.URL.Query().Get("address")
+		}
+		logFields := []logx.LogField{
+			logx.Field("method", r.Method),
+			logx.Field("path", r.URL.Path),
+			logx.Field("status", rw.statusCode),
+			logx.Field("remoteAddr", httpx.GetRemoteAddr(r)),
+		}
+		if address != "" {
+			logFields = append(logFields, logx.Field("address", address))
+		}
+		if slow {
+			logFields = append(logFields, logx.Field("slow", true))
+		}
+		if m.debug {
+			logFields = append(logFields,
+				logx.Field("query", m.redactQuery(r.URL.RawQuery)),
+				logx.Field("headers", m.redactHeaderValues(r.Header)),
+				logx.Field("requestBody", m.redactBody(requestBody)),
+				logx.Field("responseBody", m.redactBody(rw.body.Bytes())),
+			)
+		}
 
-		// Log response to terminal
-		fmt.Printf("📤 RESPONSE [%d] %s %s - %v\n", rw.statusCode, r.Method, r.URL.Path, duration)
+		// The request id and trace ids come from the context
+		logger := logx.WithContext(r.Context()).WithDuration(duration)
+		if rw.statusCode >= http.StatusInternalServerError {
+			logger.Errorw("request", logFields...)
+		} else {
+			logger.Infow("request", logFields...)
+		}
+	}
+}
 
-		// Log response body if exists and not too large
-		if rw.body.Len() > 0 {
-			bodyStr := rw.body.String()
-			if len(bodyStr) > 500 {
-				bodyStr = bodyStr[:500] + "... (truncated)"
-			}
-			fmt.Printf("   Body: %s\n", bodyStr)
-			logx.Infof("Response Body: %s", bodyStr)
+// sampled reports whether a successful request to path should be logged
+func (m *RequestLogMiddleware) sampled(path string) bool {
+	for _, rule := range m.sampling {
+		if strings.HasPrefix(path, rule.Path) {
+			return rand.Float64() < rule.Rate
 		}
+	}
+	return true
+}
 
-		fmt.Printf("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━\n\n")
+func (m *RequestLogMiddleware) redactQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return redacted
+	}
+	for key := range query {
+		if m.redactKeys[strings.ToLower(key)] {
+			query[key] = []string{redacted}
+		}
+	}
+	return query.Encode()
+}
 
-		// Also log to logx for file logging
-		logx.Infof("RESPONSE [%d] %s %s - %v", rw.statusCode, r.Method, r.URL.Path, duration)
+func (m *RequestLogMiddleware) redactHeaderValues(header http.Header) map[string]string {
+	values := make(map[string]string, len(header))
+	for key := range header {
+		if m.redactHeaders[key] {
+			values[key] = redacted
+		} else {
+			values[key] = header.Get(key)
+		}
 	}
+	return values
 }
 
+// redactBody replaces sensitive fields of a JSON body; other bodies are only
+// described by their size since they cannot be redacted
+func (m *RequestLogMiddleware) redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return "[" + http.DetectContentType(body) + ", " + strconv.Itoa(len(body)) + " bytes]"
+	}
+	out, err := json.Marshal(m.redactValue(value))
+	if err != nil {
+		return redacted
+	}
+	if len(out) > m.maxBodySize {
+		return string(out[:m.maxBodySize]) + "...(truncated)"
+	}
+	return string(out)
+}
+
+func (m *RequestLogMiddleware) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if m.redactKeys[strings.ToLower(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = m.redactValue(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = m.redactValue(item)
+		}
+	}
+	return value
+}
+
+// responseLogWriter records the status code and, at debug level, the start of
+// the response body
 type responseLogWriter struct {
 	http.ResponseWriter
 	statusCode int
 	body       *bytes.Buffer
+	limit      int
 }
 
 func (rw *responseLogWriter) WriteHeader(code int) {
@@ -98,7 +282,13 @@ func (rw *responseLogWriter) WriteHeader(code int) {
 }
 
 func (rw *responseLogWriter) Write(b []byte) (int, error) {
-	// Write to both the actual response and our buffer for logging
-	rw.body.Write(b)
+	if rw.body != nil && rw.body.Len() < rw.limit {
+		rw.body.Write(b[:min(len(b), rw.limit-rw.body.Len())])
+	}
 	return rw.ResponseWriter.Write(b)
 }
+
+// Unwrap lets http.ResponseController flush streamed responses
+func (rw *responseLogWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"wata-bot-BE/internal/address"
+	"wata-bot-BE/internal/validate"
+
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/logx/logtest"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+func TestRequestLogAddressFromBody(t *testing.T) {
+	httpx.SetValidator(LogAddressValidator{Validator: validate.Validator{}})
+	defer httpx.SetValidator(nil)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "checksummed", body: `{"address": "0x742d35cc6634c0532925a3b844bc9e7595f0beb0"}`,
+			want: `"address":"0x742D35CC6634c0532925A3b844BC9E7595F0BEb0"`},
+		{name: "rejected request", body: `{"address": "0x742d35cc"}`, want: `"address":"0x742d35cc"`},
+		{name: "no address", body: `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := logtest.NewCollector(t)
+			m := NewRequestLogMiddleware(RequestLogConf{}, false)
+			handler := m.Handle(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Address address.Address `json:"address" validate:"address"`
+				}
+				if err := httpx.Parse(r, &req); err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+				}
+			})
+
+			r := httptest.NewRequest(http.MethodPost, "/api/user/bots", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			handler(httptest.NewRecorder(), r)
+
+			got := logs.String()
+			if tt.want == "" {
+				if strings.Contains(got, `"address"`) {
+					t.Errorf("log %s has an address", got)
+				}
+			} else if !strings.Contains(got, tt.want) {
+				t.Errorf("log %s does not contain %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestLogRedactBody(t *testing.T) {
+	var c RequestLogConf
+	if err := conf.FillDefault(&c); err != nil {
+		t.Fatal(err)
+	}
+	m := NewRequestLogMiddleware(c, true)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "auth response",
+			body: `{"code":0,"data":{"access_token":"eyJ.a.b","refresh_token":"eyJ.c.d","expires_in":86400,"role":"user"}}`,
+			want: `{"code":0,"data":{"access_token":"[REDACTED]","expires_in":86400,"refresh_token":"[REDACTED]","role":"user"}}`,
+		},
+		{
+			name: "camel case refresh request",
+			body: `{"refreshToken":"eyJ.c.d"}`,
+			want: `{"refreshToken":"[REDACTED]"}`,
+		},
+		{
+			name: "nested in arrays",
+			body: `[{"Signature":"0xabc","address":"0x1"}]`,
+			want: `[{"Signature":"[REDACTED]","address":"0x1"}]`,
+		},
+		{name: "not json", body: `access_token=eyJ.a.b`, want: "[text/plain; charset=utf-8, 20 bytes]"},
+		{name: "empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.redactBody([]byte(tt.body))
+			if got != tt.want {
+				t.Errorf("redactBody() = %s, want %s", got, tt.want)
+			}
+			for _, secret := range []string{"eyJ.a.b", "eyJ.c.d", "0xabc"} {
+				if strings.Contains(got, secret) {
+					t.Errorf("redactBody() leaks %s", secret)
+				}
+			}
+		})
+	}
+}
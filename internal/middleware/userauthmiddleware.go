@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"wata-bot-BE/internal/address"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// TokenQueryParam carries the access token for clients that cannot set
+// headers, such as the browser EventSource API
+const TokenQueryParam = "access_token"
+
+type addressKey struct{}
+
+// AddressFromContext returns the wallet address authenticated by UserAuthMiddleware
+func AddressFromContext(ctx context.Context) address.Address {
+	addr, _ := ctx.Value(addressKey{}).(address.Address)
+	return addr
+}
+
+// UserAuthMiddleware requires the access token issued at wallet sign-in, sent
+// as a Bearer token or in the access_token query parameter
+type UserAuthMiddleware struct {
+	secret []byte
+}
+
+func NewUserAuthMiddleware(secret string) *UserAuthMiddleware {
+	return &UserAuthMiddleware{
+		secret: []byte(secret),
+	}
+}
+
+func (m *UserAuthMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		addr, err := accessTokenAddress(r, m.secret)
+		if err != nil {
+			logx.WithContext(r.Context()).Errorf("User auth failed for %s %s: %v", r.Method, r.URL.Path, err)
+			writeUnauthorized(w, r)
+			return
+		}
+
+		setLogAddress(r.Context(), addr.String())
+		next(w, r.WithContext(context.WithValue(r.Context(), addressKey{}, addr)))
+	}
+}
+
+// accessTokenAddress checks the request's access token signature and expiry
+// and returns its wallet address in checksummed form
+func accessTokenAddress(r *http.Request, secret []byte) (address.Address, error) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.URL.Query().Get(TokenQueryParam)
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return "", err
+	}
+	claim, _ := claims["address"].(string)
+	addr, err := address.Parse(claim)
+	if err != nil {
+		return "", jwt.ErrTokenInvalidClaims
+	}
+	return addr, nil
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlc"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+type (
+	BotChangeNoticeModel interface {
+		// FindByUserId returns the user's notices, newest first
+		FindByUserId(ctx context.Context, userId int64, unreadOnly bool, limit int) ([]*BotChangeNotice, error)
+		// MarkRead marks the given notices as read; no ids marks all of them
+		MarkRead(ctx context.Context, userId int64, ids []int64, at time.Time) (int64, error)
+	}
+
+	defaultBotChangeNoticeModel struct {
+		sqlc.CachedConn
+		table string
+	}
+
+	// BotChangeNotice tells a subscriber that the terms of a bot they hold an
+	// active position in changed
+	BotChangeNotice struct {
+		Id          int64        `db:"id"`
+		UserId      int64        `db:"user_id"`
+		BotId       string       `db:"bot_id"`
+		FromVersion int          `db:"from_version"`
+		ToVersion   int          `db:"to_version"`
+		ReadAt      sql.NullTime `db:"read_at"`
+		CreatedAt   time.Time    `db:"created_at"`
+	}
+)
+
+func NewBotChangeNoticeModel(conn sqlx.SqlConn, c cache.CacheConf) BotChangeNoticeModel {
+	cachedConn := sqlc.NewConn(conn, c)
+
+	return &defaultBotChangeNoticeModel{
+		CachedConn: cachedConn,
+		table:      "`bot_change_notice`",
+	}
+}
+
+func (m *defaultBotChangeNoticeModel) FindByUserId(ctx context.Context, userId int64, unreadOnly bool, limit int) ([]*BotChangeNotice, error) {
+	ctx, span := startSpan(ctx, "BotChangeNoticeModel.FindByUserId")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `user_id` = ? order by `id` desc limit ?", m.table)
+	if unreadOnly {
+		query = fmt.Sprintf("select * from %s where `user_id` = ? and `read_at` is null order by `id` desc limit ?", m.table)
+	}
+	var resp []*BotChangeNotice
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, userId, limit)
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultBotChangeNoticeModel) MarkRead(ctx context.Context, userId int64, ids []int64, at time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "BotChangeNoticeModel.MarkRead")
+	defer span.End()
+	query := fmt.Sprintf("update %s set `read_at` = ? where `user_id` = ? and `read_at` is null", m.table)
+	args := []interface{}{at, userId}
+	if len(ids) > 0 {
+		query += " and `id` in (" + placeholders(len(ids)) + ")"
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	ret, err := m.ExecNoCacheCtx(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return ret.RowsAffected()
+}
+
+// insertChangeNotices notifies every user holding an active position pinned to
+// an older version of the bot
+func insertChangeNotices(ctx context.Context, session sqlx.Session, botId string, fromVersion, toVersion int) error {
+	query := "insert into `bot_change_notice` (`user_id`, `bot_id`, `from_version`, `to_version`) " +
+		"select distinct `user_id`, `bot_id`, ?, ? from `user_bot_subscription` where `bot_id` = ? and `status` = ? and `bot_version` < ?"
+	_, err := session.ExecCtx(ctx, query, fromVersion, toVersion, botId, SubscriptionStatusActive, toVersion)
+	return err
+}
+
+// placeholders returns n comma separated query placeholders
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	s := "?"
+	for i := 1; i < n; i++ {
+		s += ", ?"
+	}
+	return s
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -15,12 +16,15 @@ var (
 
 type (
 	BotModel interface {
-		Insert(data *Bot) (sql.Result, error)
-		FindOne(id string) (*Bot, error)
-		FindAll() ([]*Bot, error)
-		FindAllActive() ([]*Bot, error)
-		Update(data *Bot) error
-		Delete(id string) error
+		Insert(ctx context.Context, data *Bot) (sql.Result, error)
+		FindOne(ctx context.Context, id string) (*Bot, error)
+		FindAll(ctx context.Context) ([]*Bot, error)
+		FindAllActive(ctx context.Context) ([]*Bot, error)
+		Update(ctx context.Context, data *Bot) error
+		UpdateMetrics(ctx context.Context, id string, metrics BotMetrics) error
+		// RecountSubscribers recomputes the subscriber count from active subscriptions
+		RecountSubscribers(ctx context.Context, id string) (int, error)
+		Delete(ctx context.Context, id string) error
 	}
 
 	defaultBotModel struct {
@@ -52,6 +56,18 @@ type (
 		TradingPair          string  `db:"trading_pair"`
 		TotalTrades          int     `db:"total_trades"`
 		Pnl30d               float64 `db:"pnl30d"`
+		Version              int     `db:"version"` // Current definition version, see BotVersion
+		// Capacity limits, 0 means unlimited
+		MaxAllocation  float64 `db:"max_allocation"`  // Cap on the total amount of active positions (USDT)
+		MaxSubscribers int     `db:"max_subscribers"` // Cap on users with an active position
+	}
+
+	// BotMetrics holds the performance columns derived from trading engine data
+	BotMetrics struct {
+		Roi30d      string
+		WinRate     string
+		TotalTrades int
+		Pnl30d      float64
 	}
 )
 
@@ -64,25 +80,40 @@ func NewBotModel(conn sqlx.SqlConn, c cache.CacheConf) BotModel {
 	}
 }
 
-func (m *defaultBotModel) Insert(data *Bot) (sql.Result, error) {
-	query := fmt.Sprintf("insert into %s (`id`, `name`, `icon_letter`, `risk_level`, `duration_days`, `expected_return_percent`, `apr_display`, `min_investment`, `max_investment`, `investment_range`, `subscribers`, `author`, `description`, `is_active`, `lockup_period`, `expected_return`, `min_investment_display`, `max_investment_display`, `roi30d`, `win_rate`, `trading_pair`, `total_trades`, `pnl30d`) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", m.table)
-	
-	ret, err := m.ExecNoCache(query,
-		data.Id, data.Name, data.IconLetter, data.RiskLevel, data.DurationDays,
-		data.ExpectedReturnPercent, data.AprDisplay, data.MinInvestment, data.MaxInvestment,
-		data.InvestmentRange, data.Subscribers, data.Author, data.Description, data.IsActive,
-		data.LockupPeriod, data.ExpectedReturn, data.MinInvestmentDisplay, data.MaxInvestmentDisplay,
-		data.Roi30d, data.WinRate, data.TradingPair, data.TotalTrades, data.Pnl30d,
-	)
+// Insert creates the bot and records its terms as version 1
+func (m *defaultBotModel) Insert(ctx context.Context, data *Bot) (sql.Result, error) {
+	ctx, span := startSpan(ctx, "BotModel.Insert")
+	defer span.End()
+	query := fmt.Sprintf("insert into %s (`id`, `name`, `icon_letter`, `risk_level`, `duration_days`, `expected_return_percent`, `apr_display`, `min_investment`, `max_investment`, `investment_range`, `subscribers`, `author`, `description`, `is_active`, `lockup_period`, `expected_return`, `min_investment_display`, `max_investment_display`, `roi30d`, `win_rate`, `trading_pair`, `total_trades`, `pnl30d`, `max_allocation`, `max_subscribers`, `version`) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", m.table)
+
+	var ret sql.Result
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		var err error
+		ret, err = session.ExecCtx(ctx, query,
+			data.Id, data.Name, data.IconLetter, data.RiskLevel, data.DurationDays,
+			data.ExpectedReturnPercent, data.AprDisplay, data.MinInvestment, data.MaxInvestment,
+			data.InvestmentRange, data.Subscribers, data.Author, data.Description, data.IsActive,
+			data.LockupPeriod, data.ExpectedReturn, data.MinInvestmentDisplay, data.MaxInvestmentDisplay,
+			data.Roi30d, data.WinRate, data.TradingPair, data.TotalTrades, data.Pnl30d,
+			data.MaxAllocation, data.MaxSubscribers, 1,
+		)
+		if err != nil {
+			return err
+		}
+		data.Version = 1
+		return insertBotVersion(ctx, session, data, data.Version, nil)
+	})
 	return ret, err
 }
 
-func (m *defaultBotModel) FindOne(id string) (*Bot, error) {
+func (m *defaultBotModel) FindOne(ctx context.Context, id string) (*Bot, error) {
+	ctx, span := startSpan(ctx, "BotModel.FindOne")
+	defer span.End()
 	botIdKey := fmt.Sprintf("%s%v", cacheBotIdPrefix, id)
 	var resp Bot
-	err := m.QueryRow(&resp, botIdKey, func(conn sqlx.SqlConn, v interface{}) error {
+	err := m.QueryRowCtx(ctx, &resp, botIdKey, func(ctx context.Context, conn sqlx.SqlConn, v interface{}) error {
 		query := fmt.Sprintf("select * from %s where `id` = ? limit 1", m.table)
-		return conn.QueryRow(v, query, id)
+		return conn.QueryRowCtx(ctx, v, query, id)
 	})
 	switch err {
 	case nil:
@@ -94,10 +125,12 @@ func (m *defaultBotModel) FindOne(id string) (*Bot, error) {
 	}
 }
 
-func (m *defaultBotModel) FindAll() ([]*Bot, error) {
+func (m *defaultBotModel) FindAll(ctx context.Context) ([]*Bot, error) {
+	ctx, span := startSpan(ctx, "BotModel.FindAll")
+	defer span.End()
 	query := fmt.Sprintf("select * from %s order by `id`", m.table)
 	var resp []*Bot
-	err := m.QueryRowsNoCache(&resp, query)
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query)
 	switch err {
 	case nil:
 		return resp, nil
@@ -108,10 +141,12 @@ func (m *defaultBotModel) FindAll() ([]*Bot, error) {
 	}
 }
 
-func (m *defaultBotModel) FindAllActive() ([]*Bot, error) {
+func (m *defaultBotModel) FindAllActive(ctx context.Context) ([]*Bot, error) {
+	ctx, span := startSpan(ctx, "BotModel.FindAllActive")
+	defer span.End()
 	query := fmt.Sprintf("select * from %s where `is_active` = 1 order by `id`", m.table)
 	var resp []*Bot
-	err := m.QueryRowsNoCache(&resp, query)
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query)
 	switch err {
 	case nil:
 		return resp, nil
@@ -122,26 +157,134 @@ func (m *defaultBotModel) FindAllActive() ([]*Bot, error) {
 	}
 }
 
-func (m *defaultBotModel) Update(data *Bot) error {
+// Update writes every column except subscribers, which is only changed together
+// with the subscriptions it counts. When any versioned term changes, the bot
+// moves to a new version and subscribers on older versions get a change notice,
+// all in the same transaction.
+func (m *defaultBotModel) Update(ctx context.Context, data *Bot) error {
+	ctx, span := startSpan(ctx, "BotModel.Update")
+	defer span.End()
 	botIdKey := fmt.Sprintf("%s%v", cacheBotIdPrefix, data.Id)
-	_, err := m.Exec(func(conn sqlx.SqlConn) (result sql.Result, err error) {
-		query := fmt.Sprintf("update %s set `name`=?, `icon_letter`=?, `risk_level`=?, `duration_days`=?, `expected_return_percent`=?, `apr_display`=?, `min_investment`=?, `max_investment`=?, `investment_range`=?, `subscribers`=?, `author`=?, `description`=?, `is_active`=?, `lockup_period`=?, `expected_return`=?, `min_investment_display`=?, `max_investment_display`=?, `roi30d`=?, `win_rate`=?, `trading_pair`=?, `total_trades`=?, `pnl30d`=? where `id` = ?", m.table)
-		return conn.Exec(query,
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		var current Bot
+		query := fmt.Sprintf("select * from %s where `id` = ? limit 1 for update", m.table)
+		if err := session.QueryRowCtx(ctx, &current, query, data.Id); err != nil {
+			if err == sqlc.ErrNotFound {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		changes := botTermChanges(&current, data)
+		data.Version = current.Version
+		if len(changes) > 0 {
+			data.Version++
+		}
+
+		query = fmt.Sprintf("update %s set `name`=?, `icon_letter`=?, `risk_level`=?, `duration_days`=?, `expected_return_percent`=?, `apr_display`=?, `min_investment`=?, `max_investment`=?, `investment_range`=?, `author`=?, `description`=?, `is_active`=?, `lockup_period`=?, `expected_return`=?, `min_investment_display`=?, `max_investment_display`=?, `roi30d`=?, `win_rate`=?, `trading_pair`=?, `total_trades`=?, `pnl30d`=?, `max_allocation`=?, `max_subscribers`=?, `version`=? where `id` = ?", m.table)
+		if _, err := session.ExecCtx(ctx, query,
 			data.Name, data.IconLetter, data.RiskLevel, data.DurationDays, data.ExpectedReturnPercent,
-			data.AprDisplay, data.MinInvestment, data.MaxInvestment, data.InvestmentRange, data.Subscribers,
+			data.AprDisplay, data.MinInvestment, data.MaxInvestment, data.InvestmentRange,
 			data.Author, data.Description, data.IsActive, data.LockupPeriod, data.ExpectedReturn,
 			data.MinInvestmentDisplay, data.MaxInvestmentDisplay, data.Roi30d, data.WinRate,
-			data.TradingPair, data.TotalTrades, data.Pnl30d, data.Id,
-		)
+			data.TradingPair, data.TotalTrades, data.Pnl30d, data.MaxAllocation, data.MaxSubscribers, data.Version, data.Id,
+		); err != nil {
+			return err
+		}
+
+		if len(changes) == 0 {
+			return nil
+		}
+		if err := insertBotVersion(ctx, session, data, data.Version, changes); err != nil {
+			return err
+		}
+		return insertChangeNotices(ctx, session, data.Id, current.Version, data.Version)
+	})
+	if err != nil {
+		return err
+	}
+	return m.DelCacheCtx(ctx, botIdKey)
+}
+
+// UpdateMetrics only touches the performance columns so it cannot overwrite
+// concurrent changes to the rest of the bot row
+func (m *defaultBotModel) UpdateMetrics(ctx context.Context, id string, metrics BotMetrics) error {
+	ctx, span := startSpan(ctx, "BotModel.UpdateMetrics")
+	defer span.End()
+	botIdKey := fmt.Sprintf("%s%v", cacheBotIdPrefix, id)
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
+		query := fmt.Sprintf("update %s set `roi30d`=?, `win_rate`=?, `total_trades`=?, `pnl30d`=? where `id` = ?", m.table)
+		return conn.ExecCtx(ctx, query, metrics.Roi30d, metrics.WinRate, metrics.TotalTrades, metrics.Pnl30d, id)
 	}, botIdKey)
 	return err
 }
 
-func (m *defaultBotModel) Delete(id string) error {
+func (m *defaultBotModel) RecountSubscribers(ctx context.Context, id string) (int, error) {
+	ctx, span := startSpan(ctx, "BotModel.RecountSubscribers")
+	defer span.End()
+	botIdKey := fmt.Sprintf("%s%v", cacheBotIdPrefix, id)
+	var count int
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		if _, err := lockBot(ctx, session, id); err != nil {
+			return err
+		}
+		query := "select count(distinct `user_id`) from `user_bot_subscription` where `bot_id` = ? and `status` = ?"
+		if err := session.QueryRowCtx(ctx, &count, query, id, SubscriptionStatusActive); err != nil {
+			return err
+		}
+		_, err := session.ExecCtx(ctx, fmt.Sprintf("update %s set `subscribers`=? where `id` = ?", m.table), count, id)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, m.DelCacheCtx(ctx, botIdKey)
+}
+
+// lockedBot holds the bot columns subscription changes depend on, read under
+// its row lock
+type lockedBot struct {
+	Version        int     `db:"version"`
+	MaxAllocation  float64 `db:"max_allocation"`
+	MaxSubscribers int     `db:"max_subscribers"`
+}
+
+func (c *lockedBot) limited() bool {
+	return c.MaxAllocation > 0 || c.MaxSubscribers > 0
+}
+
+// lockBot takes a row lock on the bot so subscription changes to it are
+// serialized within their transactions
+func lockBot(ctx context.Context, session sqlx.Session, id string) (*lockedBot, error) {
+	var locked lockedBot
+	err := session.QueryRowCtx(ctx, &locked, "select `version`, `max_allocation`, `max_subscribers` from `bot` where `id` = ? for update", id)
+	switch err {
+	case nil:
+		return &locked, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+// adjustSubscribers changes the bot's subscriber count by delta, never below zero
+func adjustSubscribers(ctx context.Context, session sqlx.Session, id string, delta int) error {
+	_, err := session.ExecCtx(ctx, "update `bot` set `subscribers` = greatest(cast(`subscribers` as signed) + ?, 0) where `id` = ?", delta, id)
+	return err
+}
+
+func botCacheKey(id string) string {
+	return fmt.Sprintf("%s%v", cacheBotIdPrefix, id)
+}
+
+func (m *defaultBotModel) Delete(ctx context.Context, id string) error {
+	ctx, span := startSpan(ctx, "BotModel.Delete")
+	defer span.End()
 	botIdKey := fmt.Sprintf("%s%v", cacheBotIdPrefix, id)
-	_, err := m.Exec(func(conn sqlx.SqlConn) (result sql.Result, err error) {
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
 		query := fmt.Sprintf("delete from %s where `id` = ?", m.table)
-		return conn.Exec(query, id)
+		return conn.ExecCtx(ctx, query, id)
 	}, botIdKey)
 	return err
 }
@@ -0,0 +1,81 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlc"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+type (
+	BotPerformanceSnapshotModel interface {
+		Upsert(ctx context.Context, data *BotPerformanceSnapshot) (sql.Result, error)
+		FindByBotIdBetween(ctx context.Context, botId string, from, to time.Time) ([]*BotPerformanceSnapshot, error)
+		SumTradesByBotId(ctx context.Context, botId string) (int64, error)
+	}
+
+	defaultBotPerformanceSnapshotModel struct {
+		sqlc.CachedConn
+		table string
+	}
+
+	BotPerformanceSnapshot struct {
+		Id            int64     `db:"id"`
+		BotId         string    `db:"bot_id"`
+		SnapshotDate  time.Time `db:"snapshot_date"`
+		Pnl           float64   `db:"pnl"`
+		RoiPercent    float64   `db:"roi_percent"` // Daily return in percent
+		Trades        int       `db:"trades"`
+		WinningTrades int       `db:"winning_trades"`
+		CreatedAt     time.Time `db:"created_at"`
+		UpdatedAt     time.Time `db:"updated_at"`
+	}
+)
+
+func NewBotPerformanceSnapshotModel(conn sqlx.SqlConn, c cache.CacheConf) BotPerformanceSnapshotModel {
+	cachedConn := sqlc.NewConn(conn, c)
+
+	return &defaultBotPerformanceSnapshotModel{
+		CachedConn: cachedConn,
+		table:      "`bot_performance_snapshot`",
+	}
+}
+
+// Upsert inserts a daily snapshot or replaces the existing one for the same bot and day,
+// so the trading engine can safely re-send a day
+func (m *defaultBotPerformanceSnapshotModel) Upsert(ctx context.Context, data *BotPerformanceSnapshot) (sql.Result, error) {
+	ctx, span := startSpan(ctx, "BotPerformanceSnapshotModel.Upsert")
+	defer span.End()
+	query := fmt.Sprintf("insert into %s (`bot_id`, `snapshot_date`, `pnl`, `roi_percent`, `trades`, `winning_trades`) values (?, ?, ?, ?, ?, ?) on duplicate key update `pnl`=values(`pnl`), `roi_percent`=values(`roi_percent`), `trades`=values(`trades`), `winning_trades`=values(`winning_trades`)", m.table)
+	return m.ExecNoCacheCtx(ctx, query, data.BotId, data.SnapshotDate.Format("2006-01-02"), data.Pnl, data.RoiPercent, data.Trades, data.WinningTrades)
+}
+
+// FindByBotIdBetween returns snapshots for a bot with from <= snapshot_date <= to, oldest first
+func (m *defaultBotPerformanceSnapshotModel) FindByBotIdBetween(ctx context.Context, botId string, from, to time.Time) ([]*BotPerformanceSnapshot, error) {
+	ctx, span := startSpan(ctx, "BotPerformanceSnapshotModel.FindByBotIdBetween")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `bot_id` = ? and `snapshot_date` >= ? and `snapshot_date` <= ? order by `snapshot_date`", m.table)
+	var resp []*BotPerformanceSnapshot
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, botId, from.Format("2006-01-02"), to.Format("2006-01-02"))
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultBotPerformanceSnapshotModel) SumTradesByBotId(ctx context.Context, botId string) (int64, error) {
+	ctx, span := startSpan(ctx, "BotPerformanceSnapshotModel.SumTradesByBotId")
+	defer span.End()
+	var total int64
+	query := fmt.Sprintf("select coalesce(sum(`trades`), 0) from %s where `bot_id` = ?", m.table)
+	err := m.QueryRowNoCacheCtx(ctx, &total, query, botId)
+	return total, err
+}
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlc"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+type (
+	BotTradeModel interface {
+		// InsertIgnore skips trades whose (bot_id, external_id) is already stored
+		InsertIgnore(ctx context.Context, data *BotTrade) (bool, error)
+		FindPageByBotId(ctx context.Context, botId string, page, pageSize int) ([]*BotTrade, error)
+		CountByBotId(ctx context.Context, botId string) (int64, error)
+		StatsByBotId(ctx context.Context, botId string, since time.Time) (*BotTradeStats, error)
+	}
+
+	defaultBotTradeModel struct {
+		sqlc.CachedConn
+		table string
+	}
+
+	BotTrade struct {
+		Id         int64     `db:"id"`
+		BotId      string    `db:"bot_id"`
+		ExternalId string    `db:"external_id"` // Trade ID assigned by the trading engine
+		Pair       string    `db:"pair"`
+		Side       string    `db:"side"` // long, short
+		EntryPrice string    `db:"entry_price"`
+		ExitPrice  string    `db:"exit_price"`
+		Size       string    `db:"size"`
+		Pnl        float64   `db:"pnl"`
+		OpenedAt   time.Time `db:"opened_at"`
+		ClosedAt   time.Time `db:"closed_at"`
+		CreatedAt  time.Time `db:"created_at"`
+	}
+
+	BotTradeStats struct {
+		Trades        int64 `db:"trades"`
+		WinningTrades int64 `db:"winning_trades"`
+	}
+)
+
+func NewBotTradeModel(conn sqlx.SqlConn, c cache.CacheConf) BotTradeModel {
+	cachedConn := sqlc.NewConn(conn, c)
+
+	return &defaultBotTradeModel{
+		CachedConn: cachedConn,
+		table:      "`bot_trade`",
+	}
+}
+
+func (m *defaultBotTradeModel) InsertIgnore(ctx context.Context, data *BotTrade) (bool, error) {
+	ctx, span := startSpan(ctx, "BotTradeModel.InsertIgnore")
+	defer span.End()
+	query := fmt.Sprintf("insert ignore into %s (`bot_id`, `external_id`, `pair`, `side`, `entry_price`, `exit_price`, `size`, `pnl`, `opened_at`, `closed_at`) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", m.table)
+	ret, err := m.ExecNoCacheCtx(ctx, query, data.BotId, data.ExternalId, data.Pair, data.Side, data.EntryPrice, data.ExitPrice, data.Size, data.Pnl, data.OpenedAt, data.ClosedAt)
+	if err != nil {
+		return false, err
+	}
+	affected, err := ret.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// FindPageByBotId returns a page of trades for a bot, most recently closed first
+func (m *defaultBotTradeModel) FindPageByBotId(ctx context.Context, botId string, page, pageSize int) ([]*BotTrade, error) {
+	ctx, span := startSpan(ctx, "BotTradeModel.FindPageByBotId")
+	defer span.End()
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	query := fmt.Sprintf("select * from %s where `bot_id` = ? order by `closed_at` desc, `id` desc limit ? offset ?", m.table)
+	var resp []*BotTrade
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, botId, pageSize, (page-1)*pageSize)
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultBotTradeModel) CountByBotId(ctx context.Context, botId string) (int64, error) {
+	ctx, span := startSpan(ctx, "BotTradeModel.CountByBotId")
+	defer span.End()
+	var count int64
+	query := fmt.Sprintf("select count(*) from %s where `bot_id` = ?", m.table)
+	err := m.QueryRowNoCacheCtx(ctx, &count, query, botId)
+	return count, err
+}
+
+// StatsByBotId counts trades and winning trades closed at or after since
+func (m *defaultBotTradeModel) StatsByBotId(ctx context.Context, botId string, since time.Time) (*BotTradeStats, error) {
+	ctx, span := startSpan(ctx, "BotTradeModel.StatsByBotId")
+	defer span.End()
+	var resp BotTradeStats
+	query := fmt.Sprintf("select count(*) as `trades`, coalesce(sum(`pnl` > 0), 0) as `winning_trades` from %s where `bot_id` = ? and `closed_at` >= ?", m.table)
+	err := m.QueryRowNoCacheCtx(ctx, &resp, query, botId, since)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
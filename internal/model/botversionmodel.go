@@ -0,0 +1,155 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlc"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+type (
+	BotVersionModel interface {
+		// FindByBotId returns the bot's versions, newest first
+		FindByBotId(ctx context.Context, botId string) ([]*BotVersion, error)
+		FindOneByBotIdAndVersion(ctx context.Context, botId string, version int) (*BotVersion, error)
+	}
+
+	defaultBotVersionModel struct {
+		sqlc.CachedConn
+		table string
+	}
+
+	// BotVersion is a snapshot of the terms subscribers join a bot under. A new
+	// version is recorded whenever BotModel.Update changes any of them.
+	BotVersion struct {
+		Id                    int64     `db:"id"`
+		BotId                 string    `db:"bot_id"`
+		Version               int       `db:"version"`
+		RiskLevel             string    `db:"risk_level"`
+		DurationDays          string    `db:"duration_days"` // JSON array stored as string
+		ExpectedReturnPercent int       `db:"expected_return_percent"`
+		AprDisplay            string    `db:"apr_display"`
+		MinInvestment         int       `db:"min_investment"`
+		MaxInvestment         int       `db:"max_investment"`
+		LockupPeriod          string    `db:"lockup_period"`
+		ExpectedReturn        string    `db:"expected_return"`
+		TradingPair           string    `db:"trading_pair"`
+		Changes               string    `db:"changes"` // JSON array of BotTermChange from the previous version
+		CreatedAt             time.Time `db:"created_at"`
+	}
+
+	// BotTermChange describes one term that changed between two bot versions
+	BotTermChange struct {
+		Field string `json:"field"`
+		From  string `json:"from"`
+		To    string `json:"to"`
+	}
+)
+
+func NewBotVersionModel(conn sqlx.SqlConn, c cache.CacheConf) BotVersionModel {
+	cachedConn := sqlc.NewConn(conn, c)
+
+	return &defaultBotVersionModel{
+		CachedConn: cachedConn,
+		table:      "`bot_version`",
+	}
+}
+
+func (m *defaultBotVersionModel) FindByBotId(ctx context.Context, botId string) ([]*BotVersion, error) {
+	ctx, span := startSpan(ctx, "BotVersionModel.FindByBotId")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `bot_id` = ? order by `version` desc", m.table)
+	var resp []*BotVersion
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, botId)
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultBotVersionModel) FindOneByBotIdAndVersion(ctx context.Context, botId string, version int) (*BotVersion, error) {
+	ctx, span := startSpan(ctx, "BotVersionModel.FindOneByBotIdAndVersion")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `bot_id` = ? and `version` = ? limit 1", m.table)
+	var resp BotVersion
+	err := m.QueryRowNoCacheCtx(ctx, &resp, query, botId, version)
+	switch err {
+	case nil:
+		return &resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+// ParseChanges decodes the changes recorded with the version
+func (v *BotVersion) ParseChanges() []BotTermChange {
+	var changes []BotTermChange
+	if v.Changes != "" {
+		json.Unmarshal([]byte(v.Changes), &changes)
+	}
+	return changes
+}
+
+// botTermChanges lists the versioned terms that differ between two bot rows
+func botTermChanges(from, to *Bot) []BotTermChange {
+	terms := []struct {
+		field    string
+		from, to string
+	}{
+		{"riskLevel", from.RiskLevel, to.RiskLevel},
+		{"durationDays", normalizeDurationDays(from.DurationDays), normalizeDurationDays(to.DurationDays)},
+		{"expectedReturnPercent", strconv.Itoa(from.ExpectedReturnPercent), strconv.Itoa(to.ExpectedReturnPercent)},
+		{"aprDisplay", from.AprDisplay, to.AprDisplay},
+		{"minInvestment", strconv.Itoa(from.MinInvestment), strconv.Itoa(to.MinInvestment)},
+		{"maxInvestment", strconv.Itoa(from.MaxInvestment), strconv.Itoa(to.MaxInvestment)},
+		{"lockupPeriod", from.LockupPeriod, to.LockupPeriod},
+		{"expectedReturn", from.ExpectedReturn, to.ExpectedReturn},
+		{"tradingPair", from.TradingPair, to.TradingPair},
+	}
+
+	var changes []BotTermChange
+	for _, t := range terms {
+		if t.from != t.to {
+			changes = append(changes, BotTermChange{Field: t.field, From: t.from, To: t.to})
+		}
+	}
+	return changes
+}
+
+// normalizeDurationDays re-encodes a duration_days JSON array so values read
+// back from MySQL compare equal to the same array marshalled in Go
+func normalizeDurationDays(s string) string {
+	var days []int
+	if err := json.Unmarshal([]byte(s), &days); err != nil {
+		return s
+	}
+	b, _ := json.Marshal(days)
+	return string(b)
+}
+
+// insertBotVersion records the bot's current terms as the given version
+func insertBotVersion(ctx context.Context, session sqlx.Session, bot *Bot, version int, changes []BotTermChange) error {
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	if changes == nil {
+		changesJSON = []byte("[]")
+	}
+
+	query := "insert into `bot_version` (`bot_id`, `version`, `risk_level`, `duration_days`, `expected_return_percent`, `apr_display`, `min_investment`, `max_investment`, `lockup_period`, `expected_return`, `trading_pair`, `changes`) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	_, err = session.ExecCtx(ctx, query, bot.Id, version, bot.RiskLevel, bot.DurationDays, bot.ExpectedReturnPercent, bot.AprDisplay,
+		bot.MinInvestment, bot.MaxInvestment, bot.LockupPeriod, bot.ExpectedReturn, bot.TradingPair, string(changesJSON))
+	return err
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestBotTermChanges(t *testing.T) {
+	base := Bot{
+		Id: "grid", Name: "Grid", RiskLevel: "low", DurationDays: "[30,60]", ExpectedReturnPercent: 12,
+		AprDisplay: "12%", MinInvestment: 100, MaxInvestment: 10000, LockupPeriod: "30 days",
+		ExpectedReturn: "12%", TradingPair: "BTC/USDT", Roi30d: "4.2",
+	}
+
+	tests := []struct {
+		name   string
+		update func(b *Bot)
+		want   []BotTermChange
+	}{
+		{name: "nothing changed", update: func(b *Bot) {}},
+		{name: "only display and metrics changed", update: func(b *Bot) { b.Name = "Grid v2"; b.Roi30d = "5"; b.Subscribers = 9 }},
+		{name: "duration days reformatted", update: func(b *Bot) { b.DurationDays = "[30, 60]" }},
+		{
+			name:   "duration days changed",
+			update: func(b *Bot) { b.DurationDays = "[30, 90]" },
+			want:   []BotTermChange{{Field: "durationDays", From: "[30,60]", To: "[30,90]"}},
+		},
+		{
+			name:   "several terms changed",
+			update: func(b *Bot) { b.RiskLevel = "high"; b.MinInvestment = 500; b.TradingPair = "ETH/USDT" },
+			want: []BotTermChange{
+				{Field: "riskLevel", From: "low", To: "high"},
+				{Field: "minInvestment", From: "100", To: "500"},
+				{Field: "tradingPair", From: "BTC/USDT", To: "ETH/USDT"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := base
+			tt.update(&updated)
+			if got := botTermChanges(&base, &updated); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("botTermChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBotModelUpdateVersions(t *testing.T) {
+	current := &Bot{Id: "grid", Name: "Grid", RiskLevel: "low", DurationDays: "[30]", MinInvestment: 100, Version: 2}
+
+	tests := []struct {
+		name        string
+		riskLevel   string
+		wantVersion int
+	}{
+		{name: "terms unchanged", riskLevel: "low", wantVersion: 2},
+		{name: "terms changed", riskLevel: "high", wantVersion: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, c := newMockConn(t)
+			m := NewBotModel(conn, c)
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(regexp.QuoteMeta("select * from `bot` where `id` = ? limit 1 for update")).WithArgs("grid").
+				WillReturnRows(mockRows(current))
+			mock.ExpectExec(regexp.QuoteMeta("update `bot` set `name`=?")).WillReturnResult(sqlmock.NewResult(0, 1))
+			if tt.wantVersion != current.Version {
+				mock.ExpectExec(regexp.QuoteMeta("insert into `bot_version`")).
+					WithArgs("grid", tt.wantVersion, tt.riskLevel, "[30]", 0, "", 100, 0, "", "", "",
+						`[{"field":"riskLevel","from":"low","to":"high"}]`).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(regexp.QuoteMeta("insert into `bot_change_notice`")).
+					WithArgs(current.Version, tt.wantVersion, "grid", SubscriptionStatusActive, tt.wantVersion).
+					WillReturnResult(sqlmock.NewResult(1, 2))
+			}
+			mock.ExpectCommit()
+
+			data := *current
+			data.Name = "Grid renamed"
+			data.RiskLevel = tt.riskLevel
+			if err := m.Update(context.Background(), &data); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if data.Version != tt.wantVersion {
+				t.Errorf("Version = %d, want %d", data.Version, tt.wantVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlc"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+// Waitlist entry lifecycle: waiting -> offered -> accepted or expired; waiting
+// and offered entries can also be cancelled by the user
+const (
+	WaitlistStatusWaiting   = "waiting"
+	WaitlistStatusOffered   = "offered"
+	WaitlistStatusAccepted  = "accepted"
+	WaitlistStatusExpired   = "expired"
+	WaitlistStatusCancelled = "cancelled"
+)
+
+// maxOffersPerPass bounds how many entries one OfferNext call offers
+const maxOffersPerPass = 100
+
+type (
+	BotWaitlistModel interface {
+		Insert(ctx context.Context, data *BotWaitlist) (sql.Result, error)
+		// FindOpenByUserIdAndBotId returns the user's waiting or offered entry for the bot
+		FindOpenByUserIdAndBotId(ctx context.Context, userId int64, botId string) (*BotWaitlist, error)
+		FindOpenByUserId(ctx context.Context, userId int64) ([]*BotWaitlist, error)
+		// QueuePosition returns the 1-based position of a waiting entry in its bot's queue
+		QueuePosition(ctx context.Context, data *BotWaitlist) (int64, error)
+		CountWaitingByBotId(ctx context.Context, botId string) (int64, error)
+		FindBotIdsWithWaiting(ctx context.Context) ([]string, error)
+		Cancel(ctx context.Context, id int64) (bool, error)
+		ExpireOffers(ctx context.Context, now time.Time) (int64, error)
+		// OfferNext offers freed capacity to waiting entries in FIFO order, stopping
+		// at the first entry that does not fit, and returns the entries it offered
+		OfferNext(ctx context.Context, botId string, now time.Time, ttl time.Duration) ([]*BotWaitlist, error)
+	}
+
+	defaultBotWaitlistModel struct {
+		sqlc.CachedConn
+		table string
+	}
+
+	BotWaitlist struct {
+		Id             int64        `db:"id"`
+		BotId          string       `db:"bot_id"`
+		UserId         int64        `db:"user_id"`
+		Amount         string       `db:"amount"`       // Amount the user wants to invest (USDT)
+		DurationDay    string       `db:"duration_day"` // Requested duration day (stored as string)
+		Status         string       `db:"status"`
+		OfferedAt      sql.NullTime `db:"offered_at"`
+		OfferExpiresAt sql.NullTime `db:"offer_expires_at"` // Reserved capacity is released after this
+		CreatedAt      time.Time    `db:"created_at"`
+		UpdatedAt      time.Time    `db:"updated_at"`
+	}
+
+	// botUsage is the capacity taken in a bot by active positions or open offers
+	botUsage struct {
+		Allocated   float64 `db:"allocated"`
+		Subscribers int64   `db:"subscribers"`
+	}
+)
+
+func NewBotWaitlistModel(conn sqlx.SqlConn, c cache.CacheConf) BotWaitlistModel {
+	cachedConn := sqlc.NewConn(conn, c)
+
+	return &defaultBotWaitlistModel{
+		CachedConn: cachedConn,
+		table:      "`bot_waitlist`",
+	}
+}
+
+func (m *defaultBotWaitlistModel) Insert(ctx context.Context, data *BotWaitlist) (sql.Result, error) {
+	ctx, span := startSpan(ctx, "BotWaitlistModel.Insert")
+	defer span.End()
+	query := fmt.Sprintf("insert into %s (`bot_id`, `user_id`, `amount`, `duration_day`, `status`) values (?, ?, ?, ?, ?)", m.table)
+	return m.ExecNoCacheCtx(ctx, query, data.BotId, data.UserId, data.Amount, data.DurationDay, WaitlistStatusWaiting)
+}
+
+func (m *defaultBotWaitlistModel) FindOpenByUserIdAndBotId(ctx context.Context, userId int64, botId string) (*BotWaitlist, error) {
+	ctx, span := startSpan(ctx, "BotWaitlistModel.FindOpenByUserIdAndBotId")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `user_id` = ? and `bot_id` = ? and `status` in (?, ?) order by `id` limit 1", m.table)
+	var resp BotWaitlist
+	err := m.QueryRowNoCacheCtx(ctx, &resp, query, userId, botId, WaitlistStatusWaiting, WaitlistStatusOffered)
+	switch err {
+	case nil:
+		return &resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultBotWaitlistModel) FindOpenByUserId(ctx context.Context, userId int64) ([]*BotWaitlist, error) {
+	ctx, span := startSpan(ctx, "BotWaitlistModel.FindOpenByUserId")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `user_id` = ? and `status` in (?, ?) order by `id`", m.table)
+	var resp []*BotWaitlist
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, userId, WaitlistStatusWaiting, WaitlistStatusOffered)
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultBotWaitlistModel) QueuePosition(ctx context.Context, data *BotWaitlist) (int64, error) {
+	ctx, span := startSpan(ctx, "BotWaitlistModel.QueuePosition")
+	defer span.End()
+	var position int64
+	query := fmt.Sprintf("select count(*) from %s where `bot_id` = ? and `status` = ? and `id` <= ?", m.table)
+	err := m.QueryRowNoCacheCtx(ctx, &position, query, data.BotId, WaitlistStatusWaiting, data.Id)
+	return position, err
+}
+
+func (m *defaultBotWaitlistModel) CountWaitingByBotId(ctx context.Context, botId string) (int64, error) {
+	ctx, span := startSpan(ctx, "BotWaitlistModel.CountWaitingByBotId")
+	defer span.End()
+	var count int64
+	query := fmt.Sprintf("select count(*) from %s where `bot_id` = ? and `status` = ?", m.table)
+	err := m.QueryRowNoCacheCtx(ctx, &count, query, botId, WaitlistStatusWaiting)
+	return count, err
+}
+
+func (m *defaultBotWaitlistModel) FindBotIdsWithWaiting(ctx context.Context) ([]string, error) {
+	ctx, span := startSpan(ctx, "BotWaitlistModel.FindBotIdsWithWaiting")
+	defer span.End()
+	query := fmt.Sprintf("select distinct `bot_id` from %s where `status` = ? order by `bot_id`", m.table)
+	var resp []string
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, WaitlistStatusWaiting)
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultBotWaitlistModel) Cancel(ctx context.Context, id int64) (bool, error) {
+	ctx, span := startSpan(ctx, "BotWaitlistModel.Cancel")
+	defer span.End()
+	query := fmt.Sprintf("update %s set `status` = ? where `id` = ? and `status` in (?, ?)", m.table)
+	ret, err := m.ExecNoCacheCtx(ctx, query, WaitlistStatusCancelled, id, WaitlistStatusWaiting, WaitlistStatusOffered)
+	if err != nil {
+		return false, err
+	}
+	affected, err := ret.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (m *defaultBotWaitlistModel) ExpireOffers(ctx context.Context, now time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "BotWaitlistModel.ExpireOffers")
+	defer span.End()
+	query := fmt.Sprintf("update %s set `status` = ? where `status` = ? and `offer_expires_at` <= ?", m.table)
+	ret, err := m.ExecNoCacheCtx(ctx, query, WaitlistStatusExpired, WaitlistStatusOffered, now)
+	if err != nil {
+		return 0, err
+	}
+	return ret.RowsAffected()
+}
+
+func (m *defaultBotWaitlistModel) OfferNext(ctx context.Context, botId string, now time.Time, ttl time.Duration) ([]*BotWaitlist, error) {
+	ctx, span := startSpan(ctx, "BotWaitlistModel.OfferNext")
+	defer span.End()
+	var offered []*BotWaitlist
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		capacity, err := lockBot(ctx, session, botId)
+		if err != nil {
+			return err
+		}
+
+		for len(offered) < maxOffersPerPass {
+			var next BotWaitlist
+			query := fmt.Sprintf("select * from %s where `bot_id` = ? and `status` = ? order by `id` limit 1", m.table)
+			err := session.QueryRowCtx(ctx, &next, query, botId, WaitlistStatusWaiting)
+			if err == sqlc.ErrNotFound {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			active, err := countActiveSubscriptions(ctx, session, next.UserId, botId)
+			if err != nil {
+				return err
+			}
+			err = checkCapacity(ctx, session, capacity, botId, next.UserId, next.Amount, active == 0, now)
+			if err == ErrBotFull {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			next.Status = WaitlistStatusOffered
+			next.OfferedAt = sql.NullTime{Time: now, Valid: true}
+			next.OfferExpiresAt = sql.NullTime{Time: now.Add(ttl), Valid: true}
+			query = fmt.Sprintf("update %s set `status` = ?, `offered_at` = ?, `offer_expires_at` = ? where `id` = ?", m.table)
+			if _, err := session.ExecCtx(ctx, query, next.Status, next.OfferedAt, next.OfferExpiresAt, next.Id); err != nil {
+				return err
+			}
+			offered = append(offered, &next)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return offered, nil
+}
+
+// checkCapacity returns ErrBotFull when adding amount to the bot, and one more
+// subscriber for a new subscriber, would exceed its limits. Capacity offered to
+// other waitlisted users counts as taken until their offers expire. It must run
+// in the transaction holding the bot's row lock.
+func checkCapacity(ctx context.Context, session sqlx.Session, capacity *lockedBot, botId string, userId int64, amount string, newSubscriber bool, now time.Time) error {
+	if !capacity.limited() {
+		return nil
+	}
+
+	var active botUsage
+	query := "select cast(coalesce(sum(`amount`), 0) as char) as `allocated`, count(distinct `user_id`) as `subscribers` from `user_bot_subscription` where `bot_id` = ? and `status` = ?"
+	if err := session.QueryRowCtx(ctx, &active, query, botId, SubscriptionStatusActive); err != nil {
+		return err
+	}
+
+	// Offers held by other users; users who already hold an active position do
+	// not take a new subscriber slot
+	var reserved botUsage
+	query = "select cast(coalesce(sum(`amount`), 0) as char) as `allocated`, count(distinct case when `user_id` not in (select `user_id` from `user_bot_subscription` where `bot_id` = ? and `status` = ?) then `user_id` end) as `subscribers` " +
+		"from `bot_waitlist` where `bot_id` = ? and `status` = ? and `offer_expires_at` > ? and `user_id` <> ?"
+	if err := session.QueryRowCtx(ctx, &reserved, query, botId, SubscriptionStatusActive, botId, WaitlistStatusOffered, now, userId); err != nil {
+		return err
+	}
+
+	requested, _ := strconv.ParseFloat(amount, 64)
+	if capacity.MaxAllocation > 0 && active.Allocated+reserved.Allocated+requested > capacity.MaxAllocation {
+		return ErrBotFull
+	}
+	if newSubscriber && capacity.MaxSubscribers > 0 && active.Subscribers+reserved.Subscribers+1 > int64(capacity.MaxSubscribers) {
+		return ErrBotFull
+	}
+	return nil
+}
+
+// hasWaitingAhead reports whether other users joined the bot's waitlist before
+// this user; freed capacity goes to them first
+func hasWaitingAhead(ctx context.Context, session sqlx.Session, botId string, userId int64) (bool, error) {
+	var count int64
+	query := "select count(*) from `bot_waitlist` where `bot_id` = ? and `status` = ? and `user_id` <> ? and `id` < " +
+		"(select coalesce(min(`id`), 18446744073709551615) from `bot_waitlist` where `bot_id` = ? and `user_id` = ? and `status` in (?, ?))"
+	err := session.QueryRowCtx(ctx, &count, query, botId, WaitlistStatusWaiting, userId, botId, userId, WaitlistStatusWaiting, WaitlistStatusOffered)
+	return count > 0, err
+}
+
+// acceptWaitlist closes the user's open waitlist entry for the bot once they
+// subscribe, releasing any capacity offered to them
+func acceptWaitlist(ctx context.Context, session sqlx.Session, botId string, userId int64) error {
+	_, err := session.ExecCtx(ctx, "update `bot_waitlist` set `status` = ? where `bot_id` = ? and `user_id` = ? and `status` in (?, ?)",
+		WaitlistStatusAccepted, botId, userId, WaitlistStatusWaiting, WaitlistStatusOffered)
+	return err
+}
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var (
+	waitingAheadQuery  = regexp.QuoteMeta("select count(*) from `bot_waitlist` where `bot_id` = ? and `status` = ? and `user_id` <> ? and `id` <")
+	activeUsageQuery   = regexp.QuoteMeta("count(distinct `user_id`) as `subscribers` from `user_bot_subscription` where `bot_id` = ? and `status` = ?")
+	reservedUsageQuery = regexp.QuoteMeta("from `bot_waitlist` where `bot_id` = ? and `status` = ? and `offer_expires_at` > ? and `user_id` <> ?")
+	nextWaitingQuery   = regexp.QuoteMeta("select * from `bot_waitlist` where `bot_id` = ? and `status` = ? order by `id` limit 1")
+	offerQuery         = regexp.QuoteMeta("update `bot_waitlist` set `status` = ?, `offered_at` = ?, `offer_expires_at` = ? where `id` = ?")
+)
+
+// usageRows returns one capacity usage row as checkCapacity reads it
+func usageRows(allocated float64, subscribers int64) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"allocated", "subscribers"}).AddRow(allocated, subscribers)
+}
+
+func TestUserBotSubscriptionModelInsertCapacity(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxAllocation  float64
+		maxSubscribers int64
+		active         int64   // User's active positions in the bot
+		ahead          int64   // Other users waiting ahead of this one
+		allocated      float64 // USDT held by active positions
+		subscribers    int64   // Users with an active position
+		reserved       float64 // USDT held by other users' open offers
+		offers         int64   // New subscriber slots held by open offers
+		wantErr        error
+	}{
+		{name: "room left", maxAllocation: 10000, maxSubscribers: 10, allocated: 5000, subscribers: 5},
+		{name: "fills allocation exactly", maxAllocation: 10000, allocated: 9000},
+		{name: "allocation exceeded", maxAllocation: 10000, allocated: 9500, wantErr: ErrBotFull},
+		{name: "allocation reserved by offers", maxAllocation: 10000, allocated: 8000, reserved: 1500, wantErr: ErrBotFull},
+		{name: "subscribers full", maxSubscribers: 5, subscribers: 5, wantErr: ErrBotFull},
+		{name: "subscriber slot reserved by offer", maxSubscribers: 5, subscribers: 4, offers: 1, wantErr: ErrBotFull},
+		{name: "existing subscriber adds a position", maxSubscribers: 5, active: 1, subscribers: 5},
+		{name: "others waiting ahead", maxSubscribers: 5, ahead: 1, wantErr: ErrBotFull},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, c := newMockConn(t)
+			m := NewUserBotSubscriptionModel(conn, c)
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(lockBotQuery).WithArgs("grid").WillReturnRows(capacityRows(tt.maxAllocation, tt.maxSubscribers))
+			mock.ExpectQuery(countActiveQuery).WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(tt.active))
+			mock.ExpectQuery(waitingAheadQuery).WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(tt.ahead))
+			if tt.ahead == 0 {
+				mock.ExpectQuery(activeUsageQuery).WillReturnRows(usageRows(tt.allocated, tt.subscribers))
+				mock.ExpectQuery(reservedUsageQuery).WillReturnRows(usageRows(tt.reserved, tt.offers))
+			}
+			if tt.wantErr != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectExec("insert into `user_bot_subscription`").WillReturnResult(sqlmock.NewResult(11, 1))
+				if tt.active == 0 {
+					mock.ExpectExec(adjustSubscribersQuery).WithArgs(1, "grid").WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectExec(acceptWaitlistQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(insertOutboxQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			}
+
+			_, err := m.Insert(context.Background(), &UserBotSubscription{UserId: 3, BotId: "grid", DurationDay: "30", Amount: "1000"}, nil)
+			if err != tt.wantErr {
+				t.Fatalf("Insert() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBotWaitlistModelOfferNext(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	ttl := 24 * time.Hour
+	first := &BotWaitlist{Id: 1, BotId: "grid", UserId: 3, Amount: "1000", DurationDay: "30", Status: WaitlistStatusWaiting}
+	second := &BotWaitlist{Id: 2, BotId: "grid", UserId: 4, Amount: "5000", DurationDay: "30", Status: WaitlistStatusWaiting}
+
+	conn, mock, c := newMockConn(t)
+	m := NewBotWaitlistModel(conn, c)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockBotQuery).WithArgs("grid").WillReturnRows(capacityRows(10000, 0))
+	// The first entry fits in the 2000 USDT left
+	mock.ExpectQuery(nextWaitingQuery).WithArgs("grid", WaitlistStatusWaiting).WillReturnRows(mockRows(first))
+	mock.ExpectQuery(countActiveQuery).WithArgs(int64(3), "grid", SubscriptionStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(0))
+	mock.ExpectQuery(activeUsageQuery).WillReturnRows(usageRows(8000, 4))
+	mock.ExpectQuery(reservedUsageQuery).WillReturnRows(usageRows(0, 0))
+	mock.ExpectExec(offerQuery).
+		WithArgs(WaitlistStatusOffered, sql.NullTime{Time: now, Valid: true}, sql.NullTime{Time: now.Add(ttl), Valid: true}, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	// The second does not fit once the first offer is reserved, so the pass stops
+	mock.ExpectQuery(nextWaitingQuery).WillReturnRows(mockRows(second))
+	mock.ExpectQuery(countActiveQuery).WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(0))
+	mock.ExpectQuery(activeUsageQuery).WillReturnRows(usageRows(8000, 4))
+	mock.ExpectQuery(reservedUsageQuery).WillReturnRows(usageRows(1000, 1))
+	mock.ExpectCommit()
+
+	offered, err := m.OfferNext(context.Background(), "grid", now, ttl)
+	if err != nil {
+		t.Fatalf("OfferNext() error = %v", err)
+	}
+	if len(offered) != 1 || offered[0].Id != 1 {
+		t.Fatalf("OfferNext() = %v, want entry 1 only", offered)
+	}
+	if offered[0].Status != WaitlistStatusOffered || !offered[0].OfferExpiresAt.Time.Equal(now.Add(ttl)) {
+		t.Errorf("offered entry = %+v, want offered until %v", offered[0], now.Add(ttl))
+	}
+}
+
+func TestBotWaitlistModelOfferNextEmptyQueue(t *testing.T) {
+	conn, mock, c := newMockConn(t)
+	m := NewBotWaitlistModel(conn, c)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockBotQuery).WithArgs("grid").WillReturnRows(capacityRows(10000, 0))
+	mock.ExpectQuery(nextWaitingQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	offered, err := m.OfferNext(context.Background(), "grid", time.Now(), time.Hour)
+	if err != nil {
+		t.Fatalf("OfferNext() error = %v", err)
+	}
+	if len(offered) != 0 {
+		t.Errorf("OfferNext() = %v, want none", offered)
+	}
+}
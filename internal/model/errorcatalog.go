@@ -0,0 +1,121 @@
+package model
+
+import (
+	"net/http"
+	"sort"
+)
+
+// ErrorSpec describes how an error code is returned to clients
+type ErrorSpec struct {
+	Code string
+	// Status is the HTTP status of responses carrying the code
+	Status int
+	// Message is the default English message
+	Message string
+	// Key identifies the message in translations
+	Key string
+}
+
+// errorCatalog lists every error code the API returns
+var errorCatalog = map[string]ErrorSpec{}
+
+func init() {
+	for _, spec := range []ErrorSpec{
+		{ErrCodeInvalidAddressFormat, http.StatusBadRequest, ErrMsgInvalidAddressFormat, "error.invalid_address_format"},
+		{ErrCodeInvalidSignature, http.StatusBadRequest, ErrMsgInvalidSignature, "error.invalid_signature"},
+		{ErrCodeInvalidMessage, http.StatusBadRequest, ErrMsgInvalidMessage, "error.invalid_message"},
+		{ErrCodeInvalidRequest, http.StatusBadRequest, ErrMsgInvalidRequest, "error.invalid_request"},
+
+		{ErrCodeTokenGenerationFailed, http.StatusInternalServerError, ErrMsgTokenGenerationFailed, "error.token_generation_failed"},
+		{ErrCodeUnauthorized, http.StatusUnauthorized, ErrMsgUnauthorized, "error.unauthorized"},
+		{ErrCodeForbidden, http.StatusForbidden, ErrMsgForbidden, "error.forbidden"},
+
+		{ErrCodeDatabaseError, http.StatusInternalServerError, ErrMsgDatabaseError, "error.database_error"},
+		{ErrCodeFailedToCreateUser, http.StatusInternalServerError, ErrMsgFailedToCreateUser, "error.failed_to_create_user"},
+		{ErrCodeFailedToFindUser, http.StatusInternalServerError, ErrMsgFailedToFindUser, "error.failed_to_find_user"},
+
+		{ErrCodeInvalidCurrency, http.StatusBadRequest, ErrMsgInvalidCurrency, "error.invalid_currency"},
+		{ErrCodeInvalidAmount, http.StatusBadRequest, ErrMsgInvalidAmount, "error.invalid_amount"},
+		{ErrCodeInsufficientBalance, http.StatusUnprocessableEntity, ErrMsgInsufficientBalance, "error.insufficient_balance"},
+		{ErrCodeFailedToUpdateBalance, http.StatusInternalServerError, ErrMsgFailedToUpdateBalance, "error.failed_to_update_balance"},
+
+		{ErrCodeBotNotFound, http.StatusNotFound, ErrMsgBotNotFound, "error.bot_not_found"},
+		{ErrCodeInvalidPerformanceData, http.StatusBadRequest, ErrMsgInvalidPerformanceData, "error.invalid_performance_data"},
+		{ErrCodeInvalidTradeData, http.StatusBadRequest, ErrMsgInvalidTradeData, "error.invalid_trade_data"},
+		{ErrCodeSubscriptionNotFound, http.StatusNotFound, ErrMsgSubscriptionNotFound, "error.subscription_not_found"},
+		{ErrCodeInvalidAutoRenew, http.StatusBadRequest, ErrMsgInvalidAutoRenew, "error.invalid_auto_renew"},
+		{ErrCodeBotFull, http.StatusConflict, ErrMsgBotFull, "error.bot_full"},
+		{ErrCodeInvalidDuration, http.StatusBadRequest, ErrMsgInvalidDuration, "error.invalid_duration"},
+
+		{ErrCodeInternalServerError, http.StatusInternalServerError, ErrMsgInternalServerError, "error.internal_server_error"},
+
+		{ErrCodeInvalidNotificationChannel, http.StatusBadRequest, ErrMsgInvalidNotificationChannel, "error.invalid_notification_channel"},
+		{ErrCodeInvalidNotificationTarget, http.StatusBadRequest, ErrMsgInvalidNotificationTarget, "error.invalid_notification_target"},
+		{ErrCodeInvalidNotificationEvent, http.StatusBadRequest, ErrMsgInvalidNotificationEvent, "error.invalid_notification_event"},
+
+		{ErrCodeWebhookNotFound, http.StatusNotFound, ErrMsgWebhookNotFound, "error.webhook_not_found"},
+		{ErrCodeInvalidWebhookUrl, http.StatusBadRequest, ErrMsgInvalidWebhookUrl, "error.invalid_webhook_url"},
+		{ErrCodeInvalidWebhookEvent, http.StatusBadRequest, ErrMsgInvalidWebhookEvent, "error.invalid_webhook_event"},
+		{ErrCodeWebhookDeliveryNotFound, http.StatusNotFound, ErrMsgWebhookDeliveryNotFound, "error.webhook_delivery_not_found"},
+		{ErrCodeWebhookDeliveryPending, http.StatusConflict, ErrMsgWebhookDeliveryPending, "error.webhook_delivery_pending"},
+		{ErrCodeInvalidDeliveryQuery, http.StatusBadRequest, ErrMsgInvalidDeliveryQuery, "error.invalid_delivery_query"},
+
+		{ErrCodeInvalidStreamTopic, http.StatusBadRequest, ErrMsgInvalidStreamTopic, "error.invalid_stream_topic"},
+
+		{ErrCodeTooManyRequests, http.StatusTooManyRequests, ErrMsgTooManyRequests, "error.too_many_requests"},
+
+		{ErrCodeNotFound, http.StatusNotFound, ErrMsgNotFound, "error.not_found"},
+		{ErrCodeConflict, http.StatusConflict, ErrMsgConflict, "error.conflict"},
+		{ErrCodeUserNotFound, http.StatusNotFound, ErrMsgUserNotFound, "error.user_not_found"},
+
+		{ErrCodeInvalidLanguage, http.StatusBadRequest, ErrMsgInvalidLanguage, "error.invalid_language"},
+
+		{ErrCodeUnsupportedChain, http.StatusBadRequest, ErrMsgUnsupportedChain, "error.unsupported_chain"},
+		{ErrCodeCurrencyNotOnChain, http.StatusBadRequest, ErrMsgCurrencyNotOnChain, "error.currency_not_on_chain"},
+	} {
+		errorCatalog[spec.Code] = spec
+	}
+}
+
+// LookupError returns the catalog entry of code. Codes missing from the
+// catalog are reported as internal server errors.
+func LookupError(code string) (ErrorSpec, bool) {
+	spec, ok := errorCatalog[code]
+	if !ok {
+		return errorCatalog[ErrCodeInternalServerError], false
+	}
+	return spec, true
+}
+
+// ErrorSpecs returns every entry of the catalog, ordered by code
+func ErrorSpecs() []ErrorSpec {
+	specs := make([]ErrorSpec, 0, len(errorCatalog))
+	for _, spec := range errorCatalog {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].Code < specs[j].Code
+	})
+	return specs
+}
+
+// HTTPStatus returns the HTTP status of responses carrying the error's code
+func (e *APIError) HTTPStatus() int {
+	spec, _ := LookupError(e.Code)
+	return spec.Status
+}
+
+// Key returns the translation key of the error's code
+func (e *APIError) Key() string {
+	spec, _ := LookupError(e.Code)
+	return spec.Key
+}
+
+// NewAPIErrorFromCode creates an APIError with the catalog message of code
+func NewAPIErrorFromCode(code string) *APIError {
+	spec, _ := LookupError(code)
+	return &APIError{
+		Code:    spec.Code,
+		Message: spec.Message,
+	}
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestLookupError(t *testing.T) {
+	tests := []struct {
+		code   string
+		status int
+		found  bool
+	}{
+		{ErrCodeInvalidAddressFormat, http.StatusBadRequest, true},
+		{ErrCodeInvalidRequest, http.StatusBadRequest, true},
+		{ErrCodeUnauthorized, http.StatusUnauthorized, true},
+		{ErrCodeForbidden, http.StatusForbidden, true},
+		{ErrCodeDatabaseError, http.StatusInternalServerError, true},
+		{ErrCodeInsufficientBalance, http.StatusUnprocessableEntity, true},
+		{ErrCodeBotNotFound, http.StatusNotFound, true},
+		{ErrCodeBotFull, http.StatusConflict, true},
+		{ErrCodeWebhookDeliveryPending, http.StatusConflict, true},
+		{ErrCodeTooManyRequests, http.StatusTooManyRequests, true},
+		{ErrCodeNotFound, http.StatusNotFound, true},
+		{"9999", http.StatusInternalServerError, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			spec, found := LookupError(tt.code)
+			if found != tt.found || spec.Status != tt.status {
+				t.Errorf("LookupError(%s) = %d, %v, want %d, %v", tt.code, spec.Status, found, tt.status, tt.found)
+			}
+			if got := NewAPIError(tt.code, "msg").HTTPStatus(); got != tt.status {
+				t.Errorf("HTTPStatus() = %d, want %d", got, tt.status)
+			}
+		})
+	}
+}
+
+func TestErrorSpecs(t *testing.T) {
+	specs := ErrorSpecs()
+	if len(specs) != len(errorCatalog) {
+		t.Fatalf("got %d specs, want %d", len(specs), len(errorCatalog))
+	}
+	keys := make(map[string]string)
+	for i, spec := range specs {
+		if i > 0 && specs[i-1].Code >= spec.Code {
+			t.Errorf("specs not ordered by code: %s before %s", specs[i-1].Code, spec.Code)
+		}
+		if spec.Status < 400 || spec.Message == "" || spec.Key == "" {
+			t.Errorf("incomplete spec %+v", spec)
+		}
+		if other, ok := keys[spec.Key]; ok {
+			t.Errorf("codes %s and %s share key %s", other, spec.Code, spec.Key)
+		}
+		keys[spec.Key] = spec.Code
+	}
+}
+
+func TestNewAPIErrorFromCode(t *testing.T) {
+	err := NewAPIErrorFromCode(ErrCodeBotFull)
+	if err.Code != ErrCodeBotFull || err.Message != ErrMsgBotFull || err.Key() != "error.bot_full" {
+		t.Errorf("NewAPIErrorFromCode(%s) = %+v, key %s", ErrCodeBotFull, err, err.Key())
+	}
+
+	err = NewAPIErrorFromCode("9999")
+	if err.Code != ErrCodeInternalServerError || err.Message != ErrMsgInternalServerError {
+		t.Errorf("unknown code = %+v, want internal server error", err)
+	}
+}
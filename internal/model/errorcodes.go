@@ -1,14 +1,18 @@
 package model
 
-// Error codes for API responses
+// Error codes for API responses; see errorcatalog.go for the HTTP status of
+// every code
 const (
 	// Validation errors (0001-0099)
 	ErrCodeInvalidAddressFormat = "0001"
 	ErrCodeInvalidSignature     = "0002"
 	ErrCodeInvalidMessage       = "0003"
+	ErrCodeInvalidRequest       = "0004"
 
 	// Authentication errors (0100-0199)
 	ErrCodeTokenGenerationFailed = "0100"
+	ErrCodeUnauthorized          = "0101"
+	ErrCodeForbidden             = "0102"
 
 	// Database errors (0200-0299)
 	ErrCodeDatabaseError      = "0200"
@@ -21,21 +25,96 @@ const (
 	ErrCodeInsufficientBalance   = "0302"
 	ErrCodeFailedToUpdateBalance = "0303"
 
+	// Bot errors (0400-0499)
+	ErrCodeBotNotFound            = "0400"
+	ErrCodeInvalidPerformanceData = "0401"
+	ErrCodeInvalidTradeData       = "0402"
+	ErrCodeSubscriptionNotFound   = "0403"
+	ErrCodeInvalidAutoRenew       = "0404"
+	ErrCodeBotFull                = "0405"
+	ErrCodeInvalidDuration        = "0406"
+
 	// Server errors (0500-0599)
 	ErrCodeInternalServerError = "0500"
+
+	// Notification errors (0600-0699)
+	ErrCodeInvalidNotificationChannel = "0600"
+	ErrCodeInvalidNotificationTarget  = "0601"
+	ErrCodeInvalidNotificationEvent   = "0602"
+
+	// Webhook errors (0700-0799)
+	ErrCodeWebhookNotFound         = "0700"
+	ErrCodeInvalidWebhookUrl       = "0701"
+	ErrCodeInvalidWebhookEvent     = "0702"
+	ErrCodeWebhookDeliveryNotFound = "0703"
+	ErrCodeWebhookDeliveryPending  = "0704"
+	ErrCodeInvalidDeliveryQuery    = "0705"
+
+	// Stream errors (0800-0899)
+	ErrCodeInvalidStreamTopic = "0800"
+
+	// Rate limit errors (0900-0999), returned with HTTP 429
+	ErrCodeTooManyRequests = "0900"
+
+	// Resource errors (1000-1099)
+	ErrCodeNotFound     = "1000"
+	ErrCodeConflict     = "1001"
+	ErrCodeUserNotFound = "1002"
+
+	// Localization errors (1100-1199)
+	ErrCodeInvalidLanguage = "1100"
+
+	// Chain errors (1200-1299)
+	ErrCodeUnsupportedChain   = "1200"
+	ErrCodeCurrencyNotOnChain = "1201"
 )
 
 // Error messages
 const (
-	ErrMsgInvalidAddressFormat  = "invalid address format"
-	ErrMsgInvalidSignature      = "invalid signature"
-	ErrMsgTokenGenerationFailed = "failed to generate tokens"
-	ErrMsgDatabaseError         = "database error"
-	ErrMsgFailedToCreateUser    = "failed to create user"
-	ErrMsgFailedToFindUser      = "failed to find user"
-	ErrMsgInvalidCurrency       = "invalid currency. Must be 'wata' or 'usdt'"
-	ErrMsgInvalidAmount         = "invalid amount"
-	ErrMsgInsufficientBalance   = "insufficient balance"
-	ErrMsgFailedToUpdateBalance = "failed to update balance"
-	ErrMsgInternalServerError   = "internal server error"
+	ErrMsgInvalidAddressFormat   = "invalid address format"
+	ErrMsgInvalidSignature       = "invalid signature"
+	ErrMsgInvalidMessage         = "invalid message"
+	ErrMsgInvalidRequest         = "invalid request"
+	ErrMsgTokenGenerationFailed  = "failed to generate tokens"
+	ErrMsgUnauthorized           = "unauthorized"
+	ErrMsgForbidden              = "forbidden"
+	ErrMsgDatabaseError          = "database error"
+	ErrMsgFailedToCreateUser     = "failed to create user"
+	ErrMsgFailedToFindUser       = "failed to find user"
+	ErrMsgInvalidCurrency        = "invalid currency. Must be 'wata' or 'usdt'"
+	ErrMsgInvalidAmount          = "invalid amount"
+	ErrMsgInsufficientBalance    = "insufficient balance"
+	ErrMsgFailedToUpdateBalance  = "failed to update balance"
+	ErrMsgBotNotFound            = "bot not found"
+	ErrMsgInvalidPerformanceData = "invalid performance data"
+	ErrMsgInvalidTradeData       = "invalid trade data"
+	ErrMsgSubscriptionNotFound   = "subscription not found"
+	ErrMsgInvalidAutoRenew       = "invalid auto-renew settings"
+	ErrMsgBotFull                = "bot is full, join the waitlist to be offered the next free slot"
+	ErrMsgInvalidDuration        = "invalid duration, must be one of the bot's durationDays"
+	ErrMsgInternalServerError    = "internal server error"
+
+	ErrMsgInvalidNotificationChannel = "invalid notification channel. Must be 'email', 'telegram' or 'webhook'"
+	ErrMsgInvalidNotificationTarget  = "invalid notification target"
+	ErrMsgInvalidNotificationEvent   = "invalid notification event"
+
+	ErrMsgWebhookNotFound         = "webhook endpoint not found"
+	ErrMsgInvalidWebhookUrl       = "invalid webhook url. Must be an http(s) URL"
+	ErrMsgInvalidWebhookEvent     = "invalid webhook event"
+	ErrMsgWebhookDeliveryNotFound = "webhook delivery not found"
+	ErrMsgWebhookDeliveryPending  = "webhook delivery is still pending"
+	ErrMsgInvalidDeliveryQuery    = "invalid webhook delivery query"
+
+	ErrMsgInvalidStreamTopic = "invalid stream topic. Must be 'balances', 'transactions' or 'bot:<id>'"
+
+	ErrMsgTooManyRequests = "too many requests, retry after the time in the Retry-After header"
+
+	ErrMsgNotFound     = "not found"
+	ErrMsgConflict     = "the request conflicts with the current state of the resource"
+	ErrMsgUserNotFound = "user not found"
+
+	ErrMsgInvalidLanguage = "invalid language. Must be 'en' or 'vi'"
+
+	ErrMsgUnsupportedChain   = "unsupported chain, see /api/chains"
+	ErrMsgCurrencyNotOnChain = "currency is not available on this chain"
 )
@@ -3,14 +3,48 @@ package model
 import (
 	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
 )
 
+// mysqlErrDupEntry is MySQL's error number for a duplicate unique key
+const mysqlErrDupEntry = 1062
+
 var ErrNotFound = errors.New("not found")
 
+// ErrBotFull is returned when a subscription would exceed a bot's capacity limits
+var ErrBotFull = errors.New("bot is at capacity")
+
+// ErrBalanceChanged is returned when a user's balance changed between being
+// read and being written, so the write would have lost the other change
+var ErrBalanceChanged = errors.New("balance changed concurrently")
+
+// isDuplicateKey reports whether err is a MySQL duplicate entry error for the
+// unique key named key
+func isDuplicateKey(err error, key string) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != mysqlErrDupEntry {
+		return false
+	}
+	// MySQL 8 names the key as 'table.key', earlier versions as 'key'
+	return strings.HasSuffix(mysqlErr.Message, "'"+key+"'") || strings.HasSuffix(mysqlErr.Message, "."+key+"'")
+}
+
 // APIError represents an API error with error code
 type APIError struct {
 	Code    string
 	Message string
+	// Fields lists the request fields that failed validation, if any
+	Fields []FieldError
+}
+
+// FieldError describes a request field that failed a validation rule
+type FieldError struct {
+	Field   string // Client-facing name, such as "amount" or "trades[2].side"
+	Rule    string // Rule that failed, such as "address"
+	Param   string // Rule parameter, such as the limit of "max"
+	Message string
 }
 
 func (e *APIError) Error() string {
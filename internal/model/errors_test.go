@@ -0,0 +1,33 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestIsDuplicateKey(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "mysql 8", err: &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'deposit:42' for key 'notification.uk_dedupe_key'"}, want: true},
+		{name: "mysql 5.7", err: &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'deposit:42' for key 'uk_dedupe_key'"}, want: true},
+		{name: "wrapped", err: fmt.Errorf("insert: %w", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'uk_dedupe_key'"}), want: true},
+		{name: "other key", err: &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '1' for key 'notification.PRIMARY'"}},
+		{name: "key with the name as suffix", err: &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'other_uk_dedupe_key'"}},
+		{name: "other error", err: &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row"}},
+		{name: "not mysql", err: errors.New("Duplicate entry for key 'uk_dedupe_key'")},
+		{name: "nil"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDuplicateKey(tt.err, "uk_dedupe_key"); got != tt.want {
+				t.Errorf("isDuplicateKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
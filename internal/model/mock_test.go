@@ -0,0 +1,60 @@
+package model
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+// newMockConn returns a connection backed by sqlmock and a cache backed by an
+// in-process redis. Unmet expectations fail the test when it ends.
+func newMockConn(t *testing.T) (sqlx.SqlConn, sqlmock.Sqlmock, cache.CacheConf) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+
+	rds := miniredis.RunT(t)
+	c := cache.CacheConf{{
+		RedisConf: redis.RedisConf{Host: rds.Addr(), Type: redis.NodeType},
+		Weight:    100,
+	}}
+	return sqlx.NewSqlConnFromDB(db), mock, c
+}
+
+// mockRows returns rows holding the given model structs, one column per db tag
+func mockRows(items ...interface{}) *sqlmock.Rows {
+	typ := reflect.TypeOf(items[0]).Elem()
+	var columns []string
+	for i := 0; i < typ.NumField(); i++ {
+		columns = append(columns, typ.Field(i).Tag.Get("db"))
+	}
+
+	rows := sqlmock.NewRows(columns)
+	for _, item := range items {
+		v := reflect.ValueOf(item).Elem()
+		values := make([]driver.Value, v.NumField())
+		for i := range values {
+			field := v.Field(i).Interface()
+			if valuer, ok := field.(driver.Valuer); ok {
+				field, _ = valuer.Value()
+			}
+			values[i] = field
+		}
+		rows.AddRow(values...)
+	}
+	return rows
+}
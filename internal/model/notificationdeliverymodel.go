@@ -0,0 +1,134 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlc"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+// Delivery lifecycle: pending -> sent, or pending -> failed once every attempt
+// has been used
+const (
+	DeliveryStatusPending = "pending"
+	DeliveryStatusSent    = "sent"
+	DeliveryStatusFailed  = "failed"
+)
+
+// maxDeliveryErrorLength bounds the stored error of a failed attempt
+const maxDeliveryErrorLength = 500
+
+type (
+	NotificationDeliveryModel interface {
+		// FindDue returns pending deliveries whose next attempt is due, oldest first
+		FindDue(ctx context.Context, now time.Time, limit int) ([]*NotificationDelivery, error)
+		// Claim pushes a due delivery's next attempt out by lease so other
+		// dispatchers skip it while it is being sent; false means another
+		// dispatcher claimed it first
+		Claim(ctx context.Context, id int64, now time.Time, lease time.Duration) (bool, error)
+		MarkSent(ctx context.Context, id int64, at time.Time) error
+		// MarkFailed records a failed attempt and schedules the next one at
+		// retryAt, or gives up when final is set
+		MarkFailed(ctx context.Context, id int64, lastError string, retryAt time.Time, final bool) error
+	}
+
+	defaultNotificationDeliveryModel struct {
+		sqlc.CachedConn
+		table string
+	}
+
+	// NotificationDelivery is one attempt queue entry for sending a
+	// notification over an external channel
+	NotificationDelivery struct {
+		Id             int64        `db:"id"`
+		NotificationId int64        `db:"notification_id"`
+		UserId         int64        `db:"user_id"`
+		Channel        string       `db:"channel"`
+		Target         string       `db:"target"` // Email address, Telegram chat id or webhook URL
+		Status         string       `db:"status"`
+		Attempts       int          `db:"attempts"`
+		NextAttemptAt  time.Time    `db:"next_attempt_at"`
+		LastError      string       `db:"last_error"`
+		SentAt         sql.NullTime `db:"sent_at"`
+		CreatedAt      time.Time    `db:"created_at"`
+		UpdatedAt      time.Time    `db:"updated_at"`
+	}
+)
+
+func NewNotificationDeliveryModel(conn sqlx.SqlConn, c cache.CacheConf) NotificationDeliveryModel {
+	cachedConn := sqlc.NewConn(conn, c)
+
+	return &defaultNotificationDeliveryModel{
+		CachedConn: cachedConn,
+		table:      "`notification_delivery`",
+	}
+}
+
+func (m *defaultNotificationDeliveryModel) FindDue(ctx context.Context, now time.Time, limit int) ([]*NotificationDelivery, error) {
+	ctx, span := startSpan(ctx, "NotificationDeliveryModel.FindDue")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `status` = ? and `next_attempt_at` <= ? order by `next_attempt_at`, `id` limit ?", m.table)
+	var resp []*NotificationDelivery
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, DeliveryStatusPending, now, limit)
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultNotificationDeliveryModel) Claim(ctx context.Context, id int64, now time.Time, lease time.Duration) (bool, error) {
+	ctx, span := startSpan(ctx, "NotificationDeliveryModel.Claim")
+	defer span.End()
+	query := fmt.Sprintf("update %s set `next_attempt_at` = ? where `id` = ? and `status` = ? and `next_attempt_at` <= ?", m.table)
+	ret, err := m.ExecNoCacheCtx(ctx, query, now.Add(lease), id, DeliveryStatusPending, now)
+	if err != nil {
+		return false, err
+	}
+	affected, err := ret.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (m *defaultNotificationDeliveryModel) MarkSent(ctx context.Context, id int64, at time.Time) error {
+	ctx, span := startSpan(ctx, "NotificationDeliveryModel.MarkSent")
+	defer span.End()
+	query := fmt.Sprintf("update %s set `status` = ?, `attempts` = `attempts` + 1, `sent_at` = ?, `last_error` = '' where `id` = ? and `status` = ?", m.table)
+	_, err := m.ExecNoCacheCtx(ctx, query, DeliveryStatusSent, at, id, DeliveryStatusPending)
+	return err
+}
+
+func (m *defaultNotificationDeliveryModel) MarkFailed(ctx context.Context, id int64, lastError string, retryAt time.Time, final bool) error {
+	ctx, span := startSpan(ctx, "NotificationDeliveryModel.MarkFailed")
+	defer span.End()
+	if len(lastError) > maxDeliveryErrorLength {
+		lastError = lastError[:maxDeliveryErrorLength]
+	}
+	status := DeliveryStatusPending
+	if final {
+		status = DeliveryStatusFailed
+	}
+	query := fmt.Sprintf("update %s set `status` = ?, `attempts` = `attempts` + 1, `next_attempt_at` = ?, `last_error` = ? where `id` = ? and `status` = ?", m.table)
+	_, err := m.ExecNoCacheCtx(ctx, query, status, retryAt, lastError, id, DeliveryStatusPending)
+	return err
+}
+
+// insertDelivery queues a delivery for immediate sending
+func insertDelivery(ctx context.Context, session sqlx.Session, data *NotificationDelivery) error {
+	query := "insert into `notification_delivery` (`notification_id`, `user_id`, `channel`, `target`, `status`, `next_attempt_at`) values (?, ?, ?, ?, ?, ?)"
+	ret, err := session.ExecCtx(ctx, query, data.NotificationId, data.UserId, data.Channel, data.Target, DeliveryStatusPending, time.Now())
+	if err != nil {
+		return err
+	}
+	data.Id, err = ret.LastInsertId()
+	return err
+}
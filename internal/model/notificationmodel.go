@@ -0,0 +1,145 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlc"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+type (
+	NotificationModel interface {
+		// Insert stores the notification in the user's inbox and queues its
+		// deliveries in the same transaction. A notification whose DedupeKey was
+		// already used is skipped and 0 is returned.
+		Insert(ctx context.Context, data *Notification, deliveries []*NotificationDelivery) (int64, error)
+		FindOne(ctx context.Context, id int64) (*Notification, error)
+		// FindByUserId returns the user's notifications, newest first
+		FindByUserId(ctx context.Context, userId int64, unreadOnly bool, limit int) ([]*Notification, error)
+		CountUnread(ctx context.Context, userId int64) (int64, error)
+		// MarkRead marks the given notifications as read; no ids marks all of them
+		MarkRead(ctx context.Context, userId int64, ids []int64, at time.Time) (int64, error)
+	}
+
+	defaultNotificationModel struct {
+		sqlc.CachedConn
+		table string
+	}
+
+	// Notification is an entry in a user's in-app inbox
+	Notification struct {
+		Id        int64          `db:"id"`
+		UserId    int64          `db:"user_id"`
+		Event     string         `db:"event"`
+		Title     string         `db:"title"`
+		Body      string         `db:"body"`
+		Data      string         `db:"data"`       // JSON object with the template fields
+		DedupeKey sql.NullString `db:"dedupe_key"` // Set when the notification must only be created once
+		ReadAt    sql.NullTime   `db:"read_at"`
+		CreatedAt time.Time      `db:"created_at"`
+	}
+)
+
+func NewNotificationModel(conn sqlx.SqlConn, c cache.CacheConf) NotificationModel {
+	cachedConn := sqlc.NewConn(conn, c)
+
+	return &defaultNotificationModel{
+		CachedConn: cachedConn,
+		table:      "`notification`",
+	}
+}
+
+func (m *defaultNotificationModel) Insert(ctx context.Context, data *Notification, deliveries []*NotificationDelivery) (int64, error) {
+	ctx, span := startSpan(ctx, "NotificationModel.Insert")
+	defer span.End()
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		query := fmt.Sprintf("insert into %s (`user_id`, `event`, `title`, `body`, `data`, `dedupe_key`) values (?, ?, ?, ?, ?, ?)", m.table)
+		ret, err := session.ExecCtx(ctx, query, data.UserId, data.Event, data.Title, data.Body, data.Data, data.DedupeKey)
+		if isDuplicateKey(err, "uk_dedupe_key") {
+			// Already created for this occurrence
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if data.Id, err = ret.LastInsertId(); err != nil {
+			return err
+		}
+
+		for _, d := range deliveries {
+			d.NotificationId = data.Id
+			d.UserId = data.UserId
+			if err := insertDelivery(ctx, session, d); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return data.Id, err
+}
+
+func (m *defaultNotificationModel) FindOne(ctx context.Context, id int64) (*Notification, error) {
+	ctx, span := startSpan(ctx, "NotificationModel.FindOne")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `id` = ? limit 1", m.table)
+	var resp Notification
+	err := m.QueryRowNoCacheCtx(ctx, &resp, query, id)
+	switch err {
+	case nil:
+		return &resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultNotificationModel) FindByUserId(ctx context.Context, userId int64, unreadOnly bool, limit int) ([]*Notification, error) {
+	ctx, span := startSpan(ctx, "NotificationModel.FindByUserId")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `user_id` = ? order by `id` desc limit ?", m.table)
+	if unreadOnly {
+		query = fmt.Sprintf("select * from %s where `user_id` = ? and `read_at` is null order by `id` desc limit ?", m.table)
+	}
+	var resp []*Notification
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, userId, limit)
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultNotificationModel) CountUnread(ctx context.Context, userId int64) (int64, error) {
+	ctx, span := startSpan(ctx, "NotificationModel.CountUnread")
+	defer span.End()
+	var count int64
+	query := fmt.Sprintf("select count(*) from %s where `user_id` = ? and `read_at` is null", m.table)
+	err := m.QueryRowNoCacheCtx(ctx, &count, query, userId)
+	return count, err
+}
+
+func (m *defaultNotificationModel) MarkRead(ctx context.Context, userId int64, ids []int64, at time.Time) (int64, error) {
+	ctx, span := startSpan(ctx, "NotificationModel.MarkRead")
+	defer span.End()
+	query := fmt.Sprintf("update %s set `read_at` = ? where `user_id` = ? and `read_at` is null", m.table)
+	args := []interface{}{at, userId}
+	if len(ids) > 0 {
+		query += " and `id` in (" + placeholders(len(ids)) + ")"
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	ret, err := m.ExecNoCacheCtx(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return ret.RowsAffected()
+}
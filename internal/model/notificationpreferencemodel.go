@@ -0,0 +1,114 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlc"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+type (
+	NotificationPreferenceModel interface {
+		FindByUserId(ctx context.Context, userId int64) ([]*NotificationPreference, error)
+		FindOneByUserIdAndChannel(ctx context.Context, userId int64, channel string) (*NotificationPreference, error)
+		// Upsert creates or replaces the user's preference for the channel
+		Upsert(ctx context.Context, data *NotificationPreference) error
+	}
+
+	defaultNotificationPreferenceModel struct {
+		sqlc.CachedConn
+		table string
+	}
+
+	// NotificationPreference is a user's opt-in to an external channel. Users
+	// without one only get the in-app inbox.
+	NotificationPreference struct {
+		Id        int64     `db:"id"`
+		UserId    int64     `db:"user_id"`
+		Channel   string    `db:"channel"`
+		Target    string    `db:"target"` // Email address, Telegram chat id or webhook URL
+		Enabled   bool      `db:"enabled"`
+		Events    string    `db:"events"` // Comma separated events to send, empty for all
+		CreatedAt time.Time `db:"created_at"`
+		UpdatedAt time.Time `db:"updated_at"`
+	}
+)
+
+func NewNotificationPreferenceModel(conn sqlx.SqlConn, c cache.CacheConf) NotificationPreferenceModel {
+	cachedConn := sqlc.NewConn(conn, c)
+
+	return &defaultNotificationPreferenceModel{
+		CachedConn: cachedConn,
+		table:      "`notification_preference`",
+	}
+}
+
+func (m *defaultNotificationPreferenceModel) FindByUserId(ctx context.Context, userId int64) ([]*NotificationPreference, error) {
+	ctx, span := startSpan(ctx, "NotificationPreferenceModel.FindByUserId")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `user_id` = ? order by `channel`", m.table)
+	var resp []*NotificationPreference
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, userId)
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultNotificationPreferenceModel) FindOneByUserIdAndChannel(ctx context.Context, userId int64, channel string) (*NotificationPreference, error) {
+	ctx, span := startSpan(ctx, "NotificationPreferenceModel.FindOneByUserIdAndChannel")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `user_id` = ? and `channel` = ? limit 1", m.table)
+	var resp NotificationPreference
+	err := m.QueryRowNoCacheCtx(ctx, &resp, query, userId, channel)
+	switch err {
+	case nil:
+		return &resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultNotificationPreferenceModel) Upsert(ctx context.Context, data *NotificationPreference) error {
+	ctx, span := startSpan(ctx, "NotificationPreferenceModel.Upsert")
+	defer span.End()
+	query := fmt.Sprintf("insert into %s (`user_id`, `channel`, `target`, `enabled`, `events`) values (?, ?, ?, ?, ?) "+
+		"on duplicate key update `target` = values(`target`), `enabled` = values(`enabled`), `events` = values(`events`)", m.table)
+	_, err := m.ExecNoCacheCtx(ctx, query, data.UserId, data.Channel, data.Target, data.Enabled, data.Events)
+	return err
+}
+
+// EventList returns the events the preference is limited to, nil for all
+func (p *NotificationPreference) EventList() []string {
+	if p.Events == "" {
+		return nil
+	}
+	return strings.Split(p.Events, ",")
+}
+
+// Wants reports whether event should be sent over the preference's channel
+func (p *NotificationPreference) Wants(event string) bool {
+	if !p.Enabled || p.Target == "" {
+		return false
+	}
+	events := p.EventList()
+	if len(events) == 0 {
+		return true
+	}
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
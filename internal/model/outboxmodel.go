@@ -0,0 +1,188 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"wata-bot-BE/internal/address"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlc"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+// Domain events. They are written to the outbox in the same transaction as the
+// change they describe and dispatched afterwards.
+const (
+	EventUserRegistered    = "UserRegistered"
+	EventDepositCompleted  = "DepositCompleted"
+	EventWithdrawRequested = "WithdrawRequested"
+	EventSubscribed        = "Subscribed"
+	EventUnsubscribed      = "Unsubscribed"
+)
+
+// Outbox event lifecycle: pending -> dispatched, or pending -> failed once
+// every attempt has been used
+const (
+	OutboxStatusPending    = "pending"
+	OutboxStatusDispatched = "dispatched"
+	OutboxStatusFailed     = "failed"
+)
+
+// maxOutboxErrorLength bounds the stored error of a failed dispatch
+const maxOutboxErrorLength = 1000
+
+type (
+	// UserRegisteredEvent is emitted when a wallet signs in for the first time
+	UserRegisteredEvent struct {
+		UserId       int64           `json:"userId"`
+		Address      address.Address `json:"address"`
+		ChainId      int64           `json:"chainId"`
+		ReferralCode string          `json:"referralCode"`
+	}
+
+	// BalanceEvent is the payload of DepositCompleted and WithdrawRequested
+	BalanceEvent struct {
+		TransactionId int64           `json:"transactionId"`
+		UserId        int64           `json:"userId"`
+		Address       address.Address `json:"address"`
+		ChainId       int64           `json:"chainId"`
+		Currency      string          `json:"currency"`
+		Amount        string          `json:"amount"`
+		BalanceAfter  string          `json:"balanceAfter"`
+		Status        string          `json:"status"`
+		TxHash        string          `json:"txHash,omitempty"`
+	}
+
+	// SubscribedEvent is emitted for every new position, including renewals
+	SubscribedEvent struct {
+		SubscriptionId int64  `json:"subscriptionId"`
+		UserId         int64  `json:"userId"`
+		BotId          string `json:"botId"`
+		BotVersion     int    `json:"botVersion"`
+		Amount         string `json:"amount"`
+		DurationDay    string `json:"durationDay"`
+		RenewedFromId  int64  `json:"renewedFromId,omitempty"`
+	}
+
+	// UnsubscribedEvent is emitted when a user cancels an active position
+	UnsubscribedEvent struct {
+		SubscriptionId int64  `json:"subscriptionId"`
+		UserId         int64  `json:"userId"`
+		BotId          string `json:"botId"`
+		Amount         string `json:"amount"`
+	}
+)
+
+type (
+	OutboxModel interface {
+		// FindDue returns pending events whose next attempt is due, in the order
+		// they were written
+		FindDue(ctx context.Context, now time.Time, limit int) ([]*OutboxEvent, error)
+		// Claim hides a due event from other dispatchers for lease; false means
+		// another dispatcher claimed it first
+		Claim(ctx context.Context, id int64, now time.Time, lease time.Duration) (bool, error)
+		MarkDispatched(ctx context.Context, id int64, at time.Time) error
+		// MarkFailed records a failed dispatch and schedules the next one at
+		// retryAt, or gives up when final is set
+		MarkFailed(ctx context.Context, id int64, lastError string, retryAt time.Time, final bool) error
+	}
+
+	defaultOutboxModel struct {
+		sqlc.CachedConn
+		table string
+	}
+
+	OutboxEvent struct {
+		Id            int64        `db:"id"`
+		EventType     string       `db:"event_type"`
+		AggregateId   string       `db:"aggregate_id"`
+		Payload       string       `db:"payload"` // JSON encoded event
+		Status        string       `db:"status"`
+		Attempts      int          `db:"attempts"`
+		NextAttemptAt time.Time    `db:"next_attempt_at"`
+		LastError     string       `db:"last_error"`
+		DispatchedAt  sql.NullTime `db:"dispatched_at"`
+		CreatedAt     time.Time    `db:"created_at"`
+		UpdatedAt     time.Time    `db:"updated_at"`
+	}
+)
+
+func NewOutboxModel(conn sqlx.SqlConn, c cache.CacheConf) OutboxModel {
+	cachedConn := sqlc.NewConn(conn, c)
+
+	return &defaultOutboxModel{
+		CachedConn: cachedConn,
+		table:      "`outbox_event`",
+	}
+}
+
+func (m *defaultOutboxModel) FindDue(ctx context.Context, now time.Time, limit int) ([]*OutboxEvent, error) {
+	ctx, span := startSpan(ctx, "OutboxModel.FindDue")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `status` = ? and `next_attempt_at` <= ? order by `id` limit ?", m.table)
+	var resp []*OutboxEvent
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, OutboxStatusPending, now, limit)
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultOutboxModel) Claim(ctx context.Context, id int64, now time.Time, lease time.Duration) (bool, error) {
+	ctx, span := startSpan(ctx, "OutboxModel.Claim")
+	defer span.End()
+	query := fmt.Sprintf("update %s set `next_attempt_at` = ? where `id` = ? and `status` = ? and `next_attempt_at` <= ?", m.table)
+	ret, err := m.ExecNoCacheCtx(ctx, query, now.Add(lease), id, OutboxStatusPending, now)
+	if err != nil {
+		return false, err
+	}
+	affected, err := ret.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (m *defaultOutboxModel) MarkDispatched(ctx context.Context, id int64, at time.Time) error {
+	ctx, span := startSpan(ctx, "OutboxModel.MarkDispatched")
+	defer span.End()
+	query := fmt.Sprintf("update %s set `status` = ?, `attempts` = `attempts` + 1, `dispatched_at` = ?, `last_error` = '' where `id` = ? and `status` = ?", m.table)
+	_, err := m.ExecNoCacheCtx(ctx, query, OutboxStatusDispatched, at, id, OutboxStatusPending)
+	return err
+}
+
+func (m *defaultOutboxModel) MarkFailed(ctx context.Context, id int64, lastError string, retryAt time.Time, final bool) error {
+	ctx, span := startSpan(ctx, "OutboxModel.MarkFailed")
+	defer span.End()
+	if len(lastError) > maxOutboxErrorLength {
+		lastError = lastError[:maxOutboxErrorLength]
+	}
+	status := OutboxStatusPending
+	if final {
+		status = OutboxStatusFailed
+	}
+	query := fmt.Sprintf("update %s set `status` = ?, `attempts` = `attempts` + 1, `next_attempt_at` = ?, `last_error` = ? where `id` = ? and `status` = ?", m.table)
+	_, err := m.ExecNoCacheCtx(ctx, query, status, retryAt, lastError, id, OutboxStatusPending)
+	return err
+}
+
+// insertOutboxEvent records a domain event. It must run in the transaction
+// that makes the change the event describes.
+func insertOutboxEvent(ctx context.Context, session sqlx.Session, eventType string, aggregateId int64, payload interface{}) error {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	query := "insert into `outbox_event` (`event_type`, `aggregate_id`, `payload`, `status`, `next_attempt_at`) values (?, ?, ?, ?, ?)"
+	_, err = session.ExecCtx(ctx, query, eventType, strconv.FormatInt(aggregateId, 10), string(payloadJSON), OutboxStatusPending, time.Now())
+	return err
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestOutboxModelClaim(t *testing.T) {
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	lease := 2 * time.Minute
+
+	tests := []struct {
+		name    string
+		updated int64 // Rows the conditional update changed
+		want    bool
+	}{
+		{name: "claimed", updated: 1, want: true},
+		{name: "claimed by another dispatcher", updated: 0, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, c := newMockConn(t)
+			m := NewOutboxModel(conn, c)
+
+			// The lease moves next_attempt_at forward so FindDue skips the event
+			// until it runs out
+			mock.ExpectExec(regexp.QuoteMeta("update `outbox_event` set `next_attempt_at` = ? where `id` = ? and `status` = ? and `next_attempt_at` <= ?")).
+				WithArgs(now.Add(lease), int64(5), OutboxStatusPending, now).
+				WillReturnResult(sqlmock.NewResult(0, tt.updated))
+
+			got, err := m.Claim(context.Background(), 5, now, lease)
+			if err != nil {
+				t.Fatalf("Claim() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Claim() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOutboxModelMarkFailed(t *testing.T) {
+	retryAt := time.Date(2026, 3, 1, 9, 5, 0, 0, time.UTC)
+	long := strings.Repeat("x", maxOutboxErrorLength+10)
+
+	tests := []struct {
+		name       string
+		lastError  string
+		final      bool
+		wantStatus string
+		wantError  string
+	}{
+		{name: "retry", lastError: "sink down", wantStatus: OutboxStatusPending, wantError: "sink down"},
+		{name: "give up", lastError: "sink down", final: true, wantStatus: OutboxStatusFailed, wantError: "sink down"},
+		{name: "long error truncated", lastError: long, wantStatus: OutboxStatusPending, wantError: long[:maxOutboxErrorLength]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, c := newMockConn(t)
+			m := NewOutboxModel(conn, c)
+
+			mock.ExpectExec(regexp.QuoteMeta("update `outbox_event` set `status` = ?, `attempts` = `attempts` + 1, `next_attempt_at` = ?, `last_error` = ? where `id` = ? and `status` = ?")).
+				WithArgs(tt.wantStatus, retryAt, tt.wantError, int64(5), OutboxStatusPending).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+
+			if err := m.MarkFailed(context.Background(), 5, tt.lastError, retryAt, tt.final); err != nil {
+				t.Fatalf("MarkFailed() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestInsertWithBalanceOutboxEvent(t *testing.T) {
+	tests := []struct {
+		txType    string
+		wantEvent string // Outbox event written with the transaction, empty for none
+	}{
+		{txType: TransactionTypeDeposit, wantEvent: EventDepositCompleted},
+		{txType: TransactionTypeWithdraw, wantEvent: EventWithdrawRequested},
+		{txType: TransactionTypeSubscribe},
+	}
+	for _, tt := range tests {
+		t.Run(tt.txType, func(t *testing.T) {
+			conn, mock, c := newMockConn(t)
+			m := NewTransactionModel(conn, c)
+			change := usdtChange(tt.txType, "100", "150")
+
+			mock.ExpectBegin()
+			mock.ExpectExec(updateUsdtQuery).WithArgs("150", int64(3), "100").WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(insertTxQuery).WillReturnResult(sqlmock.NewResult(21, 1))
+			if tt.wantEvent != "" {
+				mock.ExpectExec(insertOutboxQuery).
+					WithArgs(tt.wantEvent, "21", sqlmock.AnyArg(), OutboxStatusPending, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+			mock.ExpectCommit()
+
+			if err := m.InsertWithBalance(context.Background(), change.User, change.Transaction); err != nil {
+				t.Fatalf("InsertWithBalance() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"wata-bot-BE/internal/metrics"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer names the spans that wrap model methods; the queries they run are
+// traced by go-zero as child spans
+var tracer = otel.Tracer("wata-bot-BE/model")
+
+// methodSpan is the span of a model method; ending it also records the
+// method's latency
+type methodSpan struct {
+	trace.Span
+	method string
+	start  time.Time
+}
+
+// startSpan starts the span for a model method, such as "UserModel.FindOne"
+func startSpan(ctx context.Context, method string) (context.Context, *methodSpan) {
+	ctx, span := tracer.Start(ctx, method)
+	return ctx, &methodSpan{
+		Span:   span,
+		method: method,
+		start:  time.Now(),
+	}
+}
+
+func (s *methodSpan) End(options ...trace.SpanEndOption) {
+	metrics.DbDuration.ObserveFloat(float64(time.Since(s.start))/float64(time.Millisecond), s.method)
+	s.Span.End(options...)
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -14,11 +15,30 @@ var (
 	cacheTransactionIdPrefix = "cache:transaction:id:"
 )
 
+// Transaction types
+const (
+	TransactionTypeDeposit   = "deposit"
+	TransactionTypeWithdraw  = "withdraw"
+	TransactionTypeSubscribe = "subscribe" // USDT moved from the balance into a bot position
+	TransactionTypeSettle    = "settle"    // Position value paid back when it matures
+	TransactionTypeRefund    = "refund"    // Position value paid back when it is cancelled
+)
+
+// balanceColumns maps each currency to the user column holding its balance
+var balanceColumns = map[string]string{
+	"wata": "`wata_balance`",
+	"usdt": "`usdt_balance`",
+}
+
 type (
 	TransactionModel interface {
-		Insert(data *Transaction) (sql.Result, error)
-		FindOne(id int64) (*Transaction, error)
-		FindByUserId(userId int64, limit int) ([]*Transaction, error)
+		Insert(ctx context.Context, data *Transaction) (sql.Result, error)
+		// InsertWithBalance stores the user's new balance, the transaction record
+		// and its domain event in one database transaction. It returns
+		// ErrBalanceChanged if the balance is no longer data.BalanceBefore.
+		InsertWithBalance(ctx context.Context, user *User, data *Transaction) error
+		FindOne(ctx context.Context, id int64) (*Transaction, error)
+		FindByUserId(ctx context.Context, userId int64, limit int) ([]*Transaction, error)
 	}
 
 	defaultTransactionModel struct {
@@ -26,9 +46,18 @@ type (
 		table string
 	}
 
+	// BalanceChange is a transaction and the user whose balance it changes, for
+	// writes that move funds together with another change, such as a
+	// subscription's status
+	BalanceChange struct {
+		User        *User
+		Transaction *Transaction
+	}
+
 	Transaction struct {
 		Id            int64     `db:"id"`
 		UserId        int64     `db:"user_id"`
+		ChainId       int64     `db:"chain_id"` // Network the funds moved on
 		Type          string    `db:"type"`
 		Currency      string    `db:"currency"`
 		Amount        string    `db:"amount"`
@@ -50,18 +79,100 @@ func NewTransactionModel(conn sqlx.SqlConn, c cache.CacheConf) TransactionModel
 	}
 }
 
-func (m *defaultTransactionModel) Insert(data *Transaction) (sql.Result, error) {
-	query := fmt.Sprintf("insert into %s (`user_id`, `type`, `currency`, `amount`, `balance_before`, `balance_after`, `status`, `tx_hash`) values (?, ?, ?, ?, ?, ?, ?, ?)", m.table)
-	ret, err := m.ExecNoCache(query, data.UserId, data.Type, data.Currency, data.Amount, data.BalanceBefore, data.BalanceAfter, data.Status, data.TxHash)
+func (m *defaultTransactionModel) Insert(ctx context.Context, data *Transaction) (sql.Result, error) {
+	ctx, span := startSpan(ctx, "TransactionModel.Insert")
+	defer span.End()
+	query := fmt.Sprintf("insert into %s (`user_id`, `chain_id`, `type`, `currency`, `amount`, `balance_before`, `balance_after`, `status`, `tx_hash`) values (?, ?, ?, ?, ?, ?, ?, ?, ?)", m.table)
+	ret, err := m.ExecNoCacheCtx(ctx, query, data.UserId, data.ChainId, data.Type, data.Currency, data.Amount, data.BalanceBefore, data.BalanceAfter, data.Status, data.TxHash)
 	return ret, err
 }
 
-func (m *defaultTransactionModel) FindOne(id int64) (*Transaction, error) {
+func (m *defaultTransactionModel) InsertWithBalance(ctx context.Context, user *User, data *Transaction) error {
+	ctx, span := startSpan(ctx, "TransactionModel.InsertWithBalance")
+	defer span.End()
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		return insertWithBalance(ctx, session, user, data)
+	})
+	if err != nil {
+		return err
+	}
+	return m.DelCacheCtx(ctx, userCacheKeys(user)...)
+}
+
+// insertWithBalance writes the user's new balance in data's currency, the
+// transaction record and, for deposits and withdrawals, their domain event
+// within a transaction. The balance is only written while it still equals
+// data.BalanceBefore, otherwise ErrBalanceChanged is returned.
+func insertWithBalance(ctx context.Context, session sqlx.Session, user *User, data *Transaction) error {
+	column, ok := balanceColumns[data.Currency]
+	if !ok {
+		return fmt.Errorf("unknown currency %q", data.Currency)
+	}
+	balance := user.UsdtBalance
+	if data.Currency == "wata" {
+		balance = user.WataBalance
+	}
+	query := fmt.Sprintf("update `user` set %s = ? where `id` = ? and %s = ?", column, column)
+	ret, err := session.ExecCtx(ctx, query, balance, user.Id, data.BalanceBefore)
+	if err != nil {
+		return err
+	}
+	affected, err := ret.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrBalanceChanged
+	}
+
+	query = "insert into `transaction` (`user_id`, `chain_id`, `type`, `currency`, `amount`, `balance_before`, `balance_after`, `status`, `tx_hash`) values (?, ?, ?, ?, ?, ?, ?, ?, ?)"
+	ret, err = session.ExecCtx(ctx, query, data.UserId, data.ChainId, data.Type, data.Currency, data.Amount, data.BalanceBefore, data.BalanceAfter, data.Status, data.TxHash)
+	if err != nil {
+		return err
+	}
+	if data.Id, err = ret.LastInsertId(); err != nil {
+		return err
+	}
+
+	// Subscription funds are covered by the subscription events
+	var eventType string
+	switch data.Type {
+	case TransactionTypeDeposit:
+		eventType = EventDepositCompleted
+	case TransactionTypeWithdraw:
+		eventType = EventWithdrawRequested
+	default:
+		return nil
+	}
+	return insertOutboxEvent(ctx, session, eventType, data.Id, BalanceEvent{
+		TransactionId: data.Id,
+		UserId:        user.Id,
+		Address:       user.Address,
+		ChainId:       data.ChainId,
+		Currency:      data.Currency,
+		Amount:        data.Amount,
+		BalanceAfter:  data.BalanceAfter,
+		Status:        data.Status,
+		TxHash:        data.TxHash,
+	})
+}
+
+// userCacheKeys returns the cache keys of user's row
+func userCacheKeys(user *User) []string {
+	return []string{
+		fmt.Sprintf("%s%v", cacheUserIdPrefix, user.Id),
+		cacheUserAddressPrefix + user.Address.Key(),
+	}
+}
+
+func (m *defaultTransactionModel) FindOne(ctx context.Context, id int64) (*Transaction, error) {
+	ctx, span := startSpan(ctx, "TransactionModel.FindOne")
+	defer span.End()
 	transactionIdKey := fmt.Sprintf("%s%v", cacheTransactionIdPrefix, id)
 	var resp Transaction
-	err := m.QueryRow(&resp, transactionIdKey, func(conn sqlx.SqlConn, v interface{}) error {
+	err := m.QueryRowCtx(ctx, &resp, transactionIdKey, func(ctx context.Context, conn sqlx.SqlConn, v interface{}) error {
 		query := fmt.Sprintf("select * from %s where `id` = ? limit 1", m.table)
-		return conn.QueryRow(v, query, id)
+		return conn.QueryRowCtx(ctx, v, query, id)
 	})
 	switch err {
 	case nil:
@@ -73,13 +184,15 @@ func (m *defaultTransactionModel) FindOne(id int64) (*Transaction, error) {
 	}
 }
 
-func (m *defaultTransactionModel) FindByUserId(userId int64, limit int) ([]*Transaction, error) {
+func (m *defaultTransactionModel) FindByUserId(ctx context.Context, userId int64, limit int) ([]*Transaction, error) {
+	ctx, span := startSpan(ctx, "TransactionModel.FindByUserId")
+	defer span.End()
 	if limit <= 0 {
 		limit = 50
 	}
 	query := fmt.Sprintf("select * from %s where `user_id` = ? order by `created_at` desc limit ?", m.table)
 	var resp []*Transaction
-	err := m.QueryRowsNoCache(&resp, query, userId, limit)
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, userId, limit)
 	switch err {
 	case nil:
 		return resp, nil
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+func TestInsertWithBalanceInvalidatesUserCache(t *testing.T) {
+	conn, mock, c := newMockConn(t)
+	m := NewTransactionModel(conn, c)
+	rds := redis.MustNewRedis(c[0].RedisConf)
+
+	change := usdtChange(TransactionTypeDeposit, "100", "150")
+	change.User.Address = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	// The row was cached under the case-insensitive address key
+	keys := []string{
+		"cache:user:id:3",
+		"cache:user:address:0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+	}
+	for _, key := range keys {
+		if err := rds.Set(key, `{"id":3}`); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(updateUsdtQuery).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(insertTxQuery).WillReturnResult(sqlmock.NewResult(21, 1))
+	mock.ExpectExec(insertOutboxQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := m.InsertWithBalance(context.Background(), change.User, change.Transaction); err != nil {
+		t.Fatalf("InsertWithBalance() error = %v", err)
+	}
+	for _, key := range keys {
+		if ok, _ := rds.Exists(key); ok {
+			t.Errorf("%s still cached", key)
+		}
+	}
+}
@@ -1,9 +1,9 @@
 package model
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/zeromicro/go-zero/core/stores/cache"
@@ -16,15 +16,47 @@ var (
 	cacheSubscriptionUserPrefix = "cache:subscription:user:"
 )
 
+// Subscription lifecycle: active -> cancelled, or active -> matured -> settled
+const (
+	SubscriptionStatusActive    = "active"
+	SubscriptionStatusCancelled = "cancelled"
+	SubscriptionStatusMatured   = "matured"
+	SubscriptionStatusSettled   = "settled"
+)
+
+// What an auto-renewed position carries into its next term
+const (
+	RenewModeCompound = "compound" // Roll principal and returns
+	RenewModePayout   = "payout"   // Roll principal only, returns stay with the settled position
+)
+
 type (
 	UserBotSubscriptionModel interface {
-		Insert(data *UserBotSubscription) (sql.Result, error)
-		FindOne(id int64) (*UserBotSubscription, error)
-		FindByUserIdAndBotId(userId int64, botId string) (*UserBotSubscription, error)
-		FindByUserId(userId int64) ([]*UserBotSubscription, error)
-		Delete(id int64) error
-		DeleteByUserIdAndBotId(userId int64, botId string) error
-		CountByBotId(botId string) (int64, error)
+		// Insert creates an active subscription and applies debit, which moves its
+		// amount out of the user's balance, in the same transaction
+		Insert(ctx context.Context, data *UserBotSubscription, debit *BalanceChange) (sql.Result, error)
+		FindOne(ctx context.Context, id int64) (*UserBotSubscription, error)
+		FindActiveByUserIdAndBotId(ctx context.Context, userId int64, botId string) ([]*UserBotSubscription, error)
+		FindByUserId(ctx context.Context, userId int64, status string) ([]*UserBotSubscription, error)
+		FindDueForMaturity(ctx context.Context, now time.Time, limit int) ([]*UserBotSubscription, error)
+		FindByStatus(ctx context.Context, status string, limit int) ([]*UserBotSubscription, error)
+		// Cancel cancels those of subs that are still active in one transaction
+		// and returns them. The balance change refund returns for the cancelled
+		// positions, if refund is not nil, is applied in the same transaction.
+		Cancel(ctx context.Context, subs []*UserBotSubscription, at time.Time, refund func(cancelled []*UserBotSubscription) (*BalanceChange, error)) ([]*UserBotSubscription, error)
+		// Mature and Settle only apply when the subscription is still in the
+		// expected previous status and report whether the transition happened.
+		Mature(ctx context.Context, id int64, at time.Time) (bool, error)
+		// Settle fixes the final value of a matured subscription. A non-nil renewal
+		// is inserted as its next term in the same transaction, unless the bot is
+		// full, in which case renewal.Id is left 0. The balance change payout
+		// returns for whether the renewal was inserted, if not nil, is applied in
+		// the same transaction too.
+		Settle(ctx context.Context, id int64, finalValue string, at time.Time, renewal *UserBotSubscription, payout func(renewed bool) *BalanceChange) (bool, error)
+		// UpdateAutoRenew changes the renewal settings of an active subscription
+		UpdateAutoRenew(ctx context.Context, id int64, autoRenew bool, renewDurationDay, renewMode string) (bool, error)
+		CountByBotId(ctx context.Context, botId string) (int64, error)
+		SumAmountByBotId(ctx context.Context, botId string) (string, error)
 	}
 
 	defaultUserBotSubscriptionModel struct {
@@ -33,12 +65,25 @@ type (
 	}
 
 	UserBotSubscription struct {
-		Id          int64     `db:"id"`
-		UserId      int64     `db:"user_id"`
-		BotId       string    `db:"bot_id"`
-		DurationDay string    `db:"duration_day"` // Selected duration day from API (stored as string)
-		CreatedAt   time.Time `db:"created_at"`
-		UpdatedAt   time.Time `db:"updated_at"`
+		Id          int64          `db:"id"`
+		UserId      int64          `db:"user_id"`
+		BotId       string         `db:"bot_id"`
+		BotVersion  int            `db:"bot_version"`  // Bot definition version the subscription joined under
+		DurationDay string         `db:"duration_day"` // Selected duration day from API (stored as string)
+		Amount      string         `db:"amount"`       // Invested amount in USDT
+		StartedAt   time.Time      `db:"started_at"`   // Start of the position, used for PnL attribution
+		Status      string         `db:"status"`
+		FinalValue  sql.NullString `db:"final_value"` // Position value fixed at settlement
+		CancelledAt sql.NullTime   `db:"cancelled_at"`
+		MaturedAt   sql.NullTime   `db:"matured_at"`
+		SettledAt   sql.NullTime   `db:"settled_at"`
+		// Auto-renewal settings applied at settlement
+		AutoRenew        bool          `db:"auto_renew"`
+		RenewDurationDay string        `db:"renew_duration_day"` // Empty renews with the same duration
+		RenewMode        string        `db:"renew_mode"`
+		RenewedFromId    sql.NullInt64 `db:"renewed_from_id"` // Subscription this one renewed
+		CreatedAt        time.Time     `db:"created_at"`
+		UpdatedAt        time.Time     `db:"updated_at"`
 	}
 )
 
@@ -51,27 +96,95 @@ func NewUserBotSubscriptionModel(conn sqlx.SqlConn, c cache.CacheConf) UserBotSu
 	}
 }
 
-func (m *defaultUserBotSubscriptionModel) Insert(data *UserBotSubscription) (sql.Result, error) {
-	// Insert with duration_day column
-	query := fmt.Sprintf("insert into %s (`user_id`, `bot_id`, `duration_day`) values (?, ?, ?)", m.table)
-	ret, err := m.ExecNoCache(query, data.UserId, data.BotId, data.DurationDay)
+// Insert creates an active subscription. When it is the user's first active
+// position in the bot, the bot's subscriber count is incremented in the same
+// transaction.
+func (m *defaultUserBotSubscriptionModel) Insert(ctx context.Context, data *UserBotSubscription, debit *BalanceChange) (sql.Result, error) {
+	ctx, span := startSpan(ctx, "UserBotSubscriptionModel.Insert")
+	defer span.End()
+	var ret sql.Result
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		var err error
+		if ret, err = m.insertActive(ctx, session, data); err != nil {
+			return err
+		}
+		return applyBalanceChange(ctx, session, debit)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, m.DelCacheCtx(ctx, append(balanceChangeCacheKeys(debit), botCacheKey(data.BotId))...)
+}
+
+// insertActive enforces the bot's capacity limits under its row lock, inserts
+// the subscription and keeps the subscriber count and waitlist in step. It
+// returns ErrBotFull before writing anything when the bot has no room.
+func (m *defaultUserBotSubscriptionModel) insertActive(ctx context.Context, session sqlx.Session, data *UserBotSubscription) (sql.Result, error) {
+	bot, err := lockBot(ctx, session, data.BotId)
+	if err != nil {
+		return nil, err
+	}
+	active, err := countActiveSubscriptions(ctx, session, data.UserId, data.BotId)
 	if err != nil {
-		// Fallback: if duration_day column doesn't exist, try insert without it
-		errStr := err.Error()
-		if strings.Contains(errStr, "Unknown column") && strings.Contains(errStr, "duration_day") {
-			fallbackQuery := fmt.Sprintf("insert into %s (`user_id`, `bot_id`) values (?, ?)", m.table)
-			ret, err = m.ExecNoCache(fallbackQuery, data.UserId, data.BotId)
+		return nil, err
+	}
+	if bot.limited() {
+		ahead, err := hasWaitingAhead(ctx, session, data.BotId, data.UserId)
+		if err != nil {
+			return nil, err
 		}
+		if ahead {
+			return nil, ErrBotFull
+		}
+	}
+	if err := checkCapacity(ctx, session, bot, data.BotId, data.UserId, data.Amount, active == 0, time.Now()); err != nil {
+		return nil, err
+	}
+
+	renewMode := data.RenewMode
+	if renewMode == "" {
+		renewMode = RenewModeCompound
+	}
+	// Pin the subscription to the bot definition it joins under
+	data.BotVersion = bot.Version
+	query := fmt.Sprintf("insert into %s (`user_id`, `bot_id`, `bot_version`, `duration_day`, `amount`, `started_at`, `status`, `auto_renew`, `renew_duration_day`, `renew_mode`, `renewed_from_id`) values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)", m.table)
+	ret, err := session.ExecCtx(ctx, query, data.UserId, data.BotId, data.BotVersion, data.DurationDay, data.Amount, data.StartedAt, SubscriptionStatusActive,
+		data.AutoRenew, data.RenewDurationDay, renewMode, data.RenewedFromId)
+	if err != nil {
+		return nil, err
+	}
+	data.Id, _ = ret.LastInsertId()
+
+	if active == 0 {
+		if err := adjustSubscribers(ctx, session, data.BotId, 1); err != nil {
+			return nil, err
+		}
+	}
+	if err := acceptWaitlist(ctx, session, data.BotId, data.UserId); err != nil {
+		return nil, err
+	}
+	if err := insertOutboxEvent(ctx, session, EventSubscribed, data.Id, SubscribedEvent{
+		SubscriptionId: data.Id,
+		UserId:         data.UserId,
+		BotId:          data.BotId,
+		BotVersion:     data.BotVersion,
+		Amount:         data.Amount,
+		DurationDay:    data.DurationDay,
+		RenewedFromId:  data.RenewedFromId.Int64,
+	}); err != nil {
+		return nil, err
 	}
-	return ret, err
+	return ret, nil
 }
 
-func (m *defaultUserBotSubscriptionModel) FindOne(id int64) (*UserBotSubscription, error) {
+func (m *defaultUserBotSubscriptionModel) FindOne(ctx context.Context, id int64) (*UserBotSubscription, error) {
+	ctx, span := startSpan(ctx, "UserBotSubscriptionModel.FindOne")
+	defer span.End()
 	subscriptionIdKey := fmt.Sprintf("%s%v", cacheSubscriptionIdPrefix, id)
 	var resp UserBotSubscription
-	err := m.QueryRow(&resp, subscriptionIdKey, func(conn sqlx.SqlConn, v interface{}) error {
+	err := m.QueryRowCtx(ctx, &resp, subscriptionIdKey, func(ctx context.Context, conn sqlx.SqlConn, v interface{}) error {
 		query := fmt.Sprintf("select * from %s where `id` = ? limit 1", m.table)
-		return conn.QueryRow(v, query, id)
+		return conn.QueryRowCtx(ctx, v, query, id)
 	})
 	switch err {
 	case nil:
@@ -83,13 +196,40 @@ func (m *defaultUserBotSubscriptionModel) FindOne(id int64) (*UserBotSubscriptio
 	}
 }
 
-func (m *defaultUserBotSubscriptionModel) FindByUserIdAndBotId(userId int64, botId string) (*UserBotSubscription, error) {
-	var resp UserBotSubscription
-	query := fmt.Sprintf("select * from %s where `user_id` = ? and `bot_id` = ? limit 1", m.table)
-	err := m.QueryRowNoCache(&resp, query, userId, botId)
+// FindActiveByUserIdAndBotId returns the user's active positions in a bot, oldest first
+func (m *defaultUserBotSubscriptionModel) FindActiveByUserIdAndBotId(ctx context.Context, userId int64, botId string) ([]*UserBotSubscription, error) {
+	ctx, span := startSpan(ctx, "UserBotSubscriptionModel.FindActiveByUserIdAndBotId")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `user_id` = ? and `bot_id` = ? and `status` = ? order by `started_at`, `id`", m.table)
+	var resp []*UserBotSubscription
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, userId, botId, SubscriptionStatusActive)
 	switch err {
 	case nil:
-		return &resp, nil
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+// FindByUserId returns the user's subscription history, newest first; an empty
+// status returns subscriptions in every status
+func (m *defaultUserBotSubscriptionModel) FindByUserId(ctx context.Context, userId int64, status string) ([]*UserBotSubscription, error) {
+	ctx, span := startSpan(ctx, "UserBotSubscriptionModel.FindByUserId")
+	defer span.End()
+	var resp []*UserBotSubscription
+	var err error
+	if status == "" {
+		query := fmt.Sprintf("select * from %s where `user_id` = ? order by `created_at` desc, `id` desc", m.table)
+		err = m.QueryRowsNoCacheCtx(ctx, &resp, query, userId)
+	} else {
+		query := fmt.Sprintf("select * from %s where `user_id` = ? and `status` = ? order by `created_at` desc, `id` desc", m.table)
+		err = m.QueryRowsNoCacheCtx(ctx, &resp, query, userId, status)
+	}
+	switch err {
+	case nil:
+		return resp, nil
 	case sqlc.ErrNotFound:
 		return nil, ErrNotFound
 	default:
@@ -97,10 +237,13 @@ func (m *defaultUserBotSubscriptionModel) FindByUserIdAndBotId(userId int64, bot
 	}
 }
 
-func (m *defaultUserBotSubscriptionModel) FindByUserId(userId int64) ([]*UserBotSubscription, error) {
-	query := fmt.Sprintf("select * from %s where `user_id` = ? order by `created_at` desc", m.table)
+// FindDueForMaturity returns active subscriptions whose term has ended at now
+func (m *defaultUserBotSubscriptionModel) FindDueForMaturity(ctx context.Context, now time.Time, limit int) ([]*UserBotSubscription, error) {
+	ctx, span := startSpan(ctx, "UserBotSubscriptionModel.FindDueForMaturity")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `status` = ? and date_add(date(`started_at`), interval cast(`duration_day` as unsigned) day) <= ? order by `id` limit ?", m.table)
 	var resp []*UserBotSubscription
-	err := m.QueryRowsNoCache(&resp, query, userId)
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, SubscriptionStatusActive, now, limit)
 	switch err {
 	case nil:
 		return resp, nil
@@ -111,24 +254,276 @@ func (m *defaultUserBotSubscriptionModel) FindByUserId(userId int64) ([]*UserBot
 	}
 }
 
-func (m *defaultUserBotSubscriptionModel) Delete(id int64) error {
+func (m *defaultUserBotSubscriptionModel) FindByStatus(ctx context.Context, status string, limit int) ([]*UserBotSubscription, error) {
+	ctx, span := startSpan(ctx, "UserBotSubscriptionModel.FindByStatus")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `status` = ? order by `id` limit ?", m.table)
+	var resp []*UserBotSubscription
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, status, limit)
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+// Cancel, like transition, releases the user from a bot's subscriber count once
+// they hold no other active position in it
+func (m *defaultUserBotSubscriptionModel) Cancel(ctx context.Context, subs []*UserBotSubscription, at time.Time, refund func(cancelled []*UserBotSubscription) (*BalanceChange, error)) ([]*UserBotSubscription, error) {
+	ctx, span := startSpan(ctx, "UserBotSubscriptionModel.Cancel")
+	defer span.End()
+	var cancelled []*UserBotSubscription
+	var change *BalanceChange
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		cancelled, change = nil, nil
+		locked := make(map[string]bool)
+		for _, sub := range subs {
+			if !locked[sub.BotId] {
+				if _, err := lockBot(ctx, session, sub.BotId); err != nil {
+					return err
+				}
+				locked[sub.BotId] = true
+			}
+
+			query := fmt.Sprintf("update %s set `status`=?, `cancelled_at`=? where `id` = ? and `status` = ?", m.table)
+			ret, err := session.ExecCtx(ctx, query, SubscriptionStatusCancelled, at, sub.Id, SubscriptionStatusActive)
+			if err != nil {
+				return err
+			}
+			affected, err := ret.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if affected == 0 {
+				continue
+			}
+			if err := insertOutboxEvent(ctx, session, EventUnsubscribed, sub.Id, UnsubscribedEvent{
+				SubscriptionId: sub.Id,
+				UserId:         sub.UserId,
+				BotId:          sub.BotId,
+				Amount:         sub.Amount,
+			}); err != nil {
+				return err
+			}
+			cancelled = append(cancelled, sub)
+		}
+		if len(cancelled) == 0 {
+			return nil
+		}
+
+		if refund != nil {
+			var err error
+			if change, err = refund(cancelled); err != nil {
+				return err
+			}
+		}
+		if err := applyBalanceChange(ctx, session, change); err != nil {
+			return err
+		}
+
+		type holding struct {
+			userId int64
+			botId  string
+		}
+		released := make(map[holding]bool)
+		for _, sub := range cancelled {
+			h := holding{userId: sub.UserId, botId: sub.BotId}
+			if released[h] {
+				continue
+			}
+			released[h] = true
+			remaining, err := countActiveSubscriptions(ctx, session, sub.UserId, sub.BotId)
+			if err != nil {
+				return err
+			}
+			if remaining == 0 {
+				if err := adjustSubscribers(ctx, session, sub.BotId, -1); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := balanceChangeCacheKeys(change)
+	for _, sub := range subs {
+		keys = append(keys, fmt.Sprintf("%s%v", cacheSubscriptionIdPrefix, sub.Id), botCacheKey(sub.BotId))
+	}
+	return cancelled, m.DelCacheCtx(ctx, keys...)
+}
+
+func (m *defaultUserBotSubscriptionModel) Mature(ctx context.Context, id int64, at time.Time) (bool, error) {
+	ctx, span := startSpan(ctx, "UserBotSubscriptionModel.Mature")
+	defer span.End()
+	return m.transition(ctx, id, SubscriptionStatusActive, SubscriptionStatusMatured, "`matured_at`=?", at)
+}
+
+func (m *defaultUserBotSubscriptionModel) Settle(ctx context.Context, id int64, finalValue string, at time.Time, renewal *UserBotSubscription, payout func(renewed bool) *BalanceChange) (bool, error) {
+	ctx, span := startSpan(ctx, "UserBotSubscriptionModel.Settle")
+	defer span.End()
+	settled := false
+	var change *BalanceChange
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		query := fmt.Sprintf("update %s set `status`=?, `final_value`=?, `settled_at`=? where `id` = ? and `status` = ?", m.table)
+		ret, err := session.ExecCtx(ctx, query, SubscriptionStatusSettled, finalValue, at, id, SubscriptionStatusMatured)
+		if err != nil {
+			return err
+		}
+		affected, err := ret.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if settled = affected > 0; !settled {
+			return nil
+		}
+
+		renewed := false
+		if renewal != nil {
+			renewal.RenewedFromId = sql.NullInt64{Int64: id, Valid: true}
+			_, err = m.insertActive(ctx, session, renewal)
+			if err != nil && err != ErrBotFull {
+				return err
+			}
+			renewed = err == nil
+		}
+		if payout != nil {
+			change = payout(renewed)
+		}
+		return applyBalanceChange(ctx, session, change)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	keys := append(balanceChangeCacheKeys(change), fmt.Sprintf("%s%v", cacheSubscriptionIdPrefix, id))
+	if renewal != nil {
+		keys = append(keys, botCacheKey(renewal.BotId))
+	}
+	return settled, m.DelCacheCtx(ctx, keys...)
+}
+
+func (m *defaultUserBotSubscriptionModel) UpdateAutoRenew(ctx context.Context, id int64, autoRenew bool, renewDurationDay, renewMode string) (bool, error) {
+	ctx, span := startSpan(ctx, "UserBotSubscriptionModel.UpdateAutoRenew")
+	defer span.End()
 	subscriptionIdKey := fmt.Sprintf("%s%v", cacheSubscriptionIdPrefix, id)
-	_, err := m.Exec(func(conn sqlx.SqlConn) (result sql.Result, err error) {
-		query := fmt.Sprintf("delete from %s where `id` = ?", m.table)
-		return conn.Exec(query, id)
+	ret, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
+		query := fmt.Sprintf("update %s set `auto_renew`=?, `renew_duration_day`=?, `renew_mode`=? where `id` = ? and `status` = ?", m.table)
+		return conn.ExecCtx(ctx, query, autoRenew, renewDurationDay, renewMode, id, SubscriptionStatusActive)
 	}, subscriptionIdKey)
-	return err
+	if err != nil {
+		return false, err
+	}
+	affected, err := ret.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// transition moves a subscription from one status to another, setting the
+// given columns, only if it is still in the from status.
+// Leaving the active status releases the user from the bot's subscriber count,
+// in the same transaction, once they hold no other active position in it.
+func (m *defaultUserBotSubscriptionModel) transition(ctx context.Context, id int64, from, to string, set string, args ...interface{}) (bool, error) {
+	var sub UserBotSubscription
+	query := fmt.Sprintf("select * from %s where `id` = ? limit 1", m.table)
+	if err := m.QueryRowNoCacheCtx(ctx, &sub, query, id); err != nil {
+		if err == sqlc.ErrNotFound {
+			return false, ErrNotFound
+		}
+		return false, err
+	}
+
+	changed := false
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		if from == SubscriptionStatusActive {
+			if _, err := lockBot(ctx, session, sub.BotId); err != nil {
+				return err
+			}
+		}
+
+		query := fmt.Sprintf("update %s set `status`=?, %s where `id` = ? and `status` = ?", m.table, set)
+		queryArgs := append([]interface{}{to}, args...)
+		queryArgs = append(queryArgs, id, from)
+		ret, err := session.ExecCtx(ctx, query, queryArgs...)
+		if err != nil {
+			return err
+		}
+		affected, err := ret.RowsAffected()
+		if err != nil {
+			return err
+		}
+		changed = affected > 0
+		if !changed {
+			return nil
+		}
+		if from != SubscriptionStatusActive {
+			return nil
+		}
+
+		remaining, err := countActiveSubscriptions(ctx, session, sub.UserId, sub.BotId)
+		if err != nil {
+			return err
+		}
+		if remaining == 0 {
+			return adjustSubscribers(ctx, session, sub.BotId, -1)
+		}
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	subscriptionIdKey := fmt.Sprintf("%s%v", cacheSubscriptionIdPrefix, id)
+	return changed, m.DelCacheCtx(ctx, subscriptionIdKey, botCacheKey(sub.BotId))
 }
 
-func (m *defaultUserBotSubscriptionModel) DeleteByUserIdAndBotId(userId int64, botId string) error {
-	query := fmt.Sprintf("delete from %s where `user_id` = ? and `bot_id` = ?", m.table)
-	_, err := m.ExecNoCache(query, userId, botId)
-	return err
+// applyBalanceChange writes change, if not nil, within a transaction
+func applyBalanceChange(ctx context.Context, session sqlx.Session, change *BalanceChange) error {
+	if change == nil {
+		return nil
+	}
+	return insertWithBalance(ctx, session, change.User, change.Transaction)
 }
 
-func (m *defaultUserBotSubscriptionModel) CountByBotId(botId string) (int64, error) {
+// balanceChangeCacheKeys returns the cache keys change invalidates
+func balanceChangeCacheKeys(change *BalanceChange) []string {
+	if change == nil {
+		return nil
+	}
+	return userCacheKeys(change.User)
+}
+
+// countActiveSubscriptions counts the user's active positions in the bot within a transaction
+func countActiveSubscriptions(ctx context.Context, session sqlx.Session, userId int64, botId string) (int64, error) {
 	var count int64
-	query := fmt.Sprintf("select count(*) from %s where `bot_id` = ?", m.table)
-	err := m.QueryRowNoCache(&count, query, botId)
+	query := "select count(*) from `user_bot_subscription` where `user_id` = ? and `bot_id` = ? and `status` = ?"
+	err := session.QueryRowCtx(ctx, &count, query, userId, botId, SubscriptionStatusActive)
 	return count, err
 }
+
+// CountByBotId counts distinct users holding at least one active position in the bot
+func (m *defaultUserBotSubscriptionModel) CountByBotId(ctx context.Context, botId string) (int64, error) {
+	ctx, span := startSpan(ctx, "UserBotSubscriptionModel.CountByBotId")
+	defer span.End()
+	var count int64
+	query := fmt.Sprintf("select count(distinct `user_id`) from %s where `bot_id` = ? and `status` = ?", m.table)
+	err := m.QueryRowNoCacheCtx(ctx, &count, query, botId, SubscriptionStatusActive)
+	return count, err
+}
+
+// SumAmountByBotId sums the amounts of active positions in the bot
+func (m *defaultUserBotSubscriptionModel) SumAmountByBotId(ctx context.Context, botId string) (string, error) {
+	ctx, span := startSpan(ctx, "UserBotSubscriptionModel.SumAmountByBotId")
+	defer span.End()
+	var total string
+	query := fmt.Sprintf("select cast(coalesce(sum(`amount`), 0) as char) from %s where `bot_id` = ? and `status` = ?", m.table)
+	err := m.QueryRowNoCacheCtx(ctx, &total, query, botId, SubscriptionStatusActive)
+	return total, err
+}
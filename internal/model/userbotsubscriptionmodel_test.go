@@ -0,0 +1,339 @@
+package model
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var (
+	lockBotQuery           = regexp.QuoteMeta("select `version`, `max_allocation`, `max_subscribers` from `bot` where `id` = ? for update")
+	countActiveQuery       = regexp.QuoteMeta("select count(*) from `user_bot_subscription` where `user_id` = ? and `bot_id` = ? and `status` = ?")
+	adjustSubscribersQuery = regexp.QuoteMeta("update `bot` set `subscribers` = greatest(cast(`subscribers` as signed) + ?, 0) where `id` = ?")
+	insertOutboxQuery      = regexp.QuoteMeta("insert into `outbox_event`")
+	acceptWaitlistQuery    = regexp.QuoteMeta("update `bot_waitlist` set `status` = ? where `bot_id` = ? and `user_id` = ? and `status` in (?, ?)")
+)
+
+// capacityRows returns the locked bot row at version 1 with the given limits,
+// 0 for none
+func capacityRows(maxAllocation float64, maxSubscribers int64) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"version", "max_allocation", "max_subscribers"}).AddRow(1, maxAllocation, maxSubscribers)
+}
+
+func TestUserBotSubscriptionModelInsertCountsSubscribers(t *testing.T) {
+	tests := []struct {
+		name        string
+		active      int64 // User's active positions in the bot before the insert
+		wantCounted bool
+	}{
+		{name: "first position", active: 0, wantCounted: true},
+		{name: "another position", active: 2, wantCounted: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, c := newMockConn(t)
+			m := NewUserBotSubscriptionModel(conn, c)
+			started := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(lockBotQuery).WithArgs("grid").
+				WillReturnRows(sqlmock.NewRows([]string{"version", "max_allocation", "max_subscribers"}).AddRow(4, 0, 0))
+			mock.ExpectQuery(countActiveQuery).WithArgs(int64(3), "grid", SubscriptionStatusActive).
+				WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(tt.active))
+			mock.ExpectExec("insert into `user_bot_subscription`").WithArgs(int64(3), "grid", 4, "30", "1000", started, SubscriptionStatusActive,
+				false, sqlmock.AnyArg(), RenewModeCompound, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(11, 1))
+			if tt.wantCounted {
+				mock.ExpectExec(adjustSubscribersQuery).WithArgs(1, "grid").WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectExec(acceptWaitlistQuery).WithArgs(WaitlistStatusAccepted, "grid", int64(3), WaitlistStatusWaiting, WaitlistStatusOffered).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(insertOutboxQuery).WithArgs(EventSubscribed, "11", sqlmock.AnyArg(), OutboxStatusPending, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
+
+			data := &UserBotSubscription{UserId: 3, BotId: "grid", DurationDay: "30", Amount: "1000", StartedAt: started}
+			ret, err := m.Insert(context.Background(), data, nil)
+			if err != nil {
+				t.Fatalf("Insert() error = %v", err)
+			}
+			if data.BotVersion != 4 {
+				t.Errorf("BotVersion = %d, want the locked bot's version 4", data.BotVersion)
+			}
+			if id, _ := ret.LastInsertId(); id != 11 {
+				t.Errorf("LastInsertId() = %d, want 11", id)
+			}
+		})
+	}
+}
+
+func TestUserBotSubscriptionModelInsertUnknownBot(t *testing.T) {
+	conn, mock, c := newMockConn(t)
+	m := NewUserBotSubscriptionModel(conn, c)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(lockBotQuery).WithArgs("gone").WillReturnRows(sqlmock.NewRows([]string{"version", "max_allocation", "max_subscribers"}))
+	mock.ExpectRollback()
+
+	if _, err := m.Insert(context.Background(), &UserBotSubscription{UserId: 3, BotId: "gone", DurationDay: "30", Amount: "1000"}, nil); err != ErrNotFound {
+		t.Fatalf("Insert() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestUserBotSubscriptionModelMatureReleasesSubscriber(t *testing.T) {
+	at := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		updated      int64 // Rows the conditional update changed
+		remaining    int64 // User's active positions left in the bot
+		wantChanged  bool
+		wantReleased bool
+	}{
+		{name: "last position", updated: 1, remaining: 0, wantChanged: true, wantReleased: true},
+		{name: "positions left", updated: 1, remaining: 1, wantChanged: true},
+		{name: "already matured", updated: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, c := newMockConn(t)
+			m := NewUserBotSubscriptionModel(conn, c)
+
+			mock.ExpectQuery(regexp.QuoteMeta("select * from `user_bot_subscription` where `id` = ? limit 1")).WithArgs(int64(7)).
+				WillReturnRows(mockRows(&UserBotSubscription{Id: 7, UserId: 3, BotId: "grid", DurationDay: "30", Amount: "1000", Status: SubscriptionStatusActive}))
+			mock.ExpectBegin()
+			mock.ExpectQuery(lockBotQuery).WithArgs("grid").WillReturnRows(capacityRows(0, 0))
+			mock.ExpectExec("update `user_bot_subscription` set `status`=\\?").WillReturnResult(sqlmock.NewResult(0, tt.updated))
+			if tt.updated > 0 {
+				mock.ExpectQuery(countActiveQuery).WithArgs(int64(3), "grid", SubscriptionStatusActive).
+					WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(tt.remaining))
+			}
+			if tt.wantReleased {
+				mock.ExpectExec(adjustSubscribersQuery).WithArgs(-1, "grid").WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectCommit()
+
+			changed, err := m.Mature(context.Background(), 7, at)
+			if err != nil {
+				t.Fatalf("Mature() error = %v", err)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+		})
+	}
+}
+
+var (
+	updateUsdtQuery = regexp.QuoteMeta("update `user` set `usdt_balance` = ? where `id` = ? and `usdt_balance` = ?")
+	insertTxQuery   = regexp.QuoteMeta("insert into `transaction`")
+)
+
+func usdtChange(txType, before, after string) *BalanceChange {
+	return &BalanceChange{
+		User: &User{Id: 3, Address: "0xabc", UsdtBalance: after},
+		Transaction: &Transaction{
+			UserId: 3, ChainId: 56, Type: txType, Currency: "usdt", Status: "completed",
+			BalanceBefore: before, BalanceAfter: after,
+		},
+	}
+}
+
+func TestUserBotSubscriptionModelInsertDebitsBalance(t *testing.T) {
+	tests := []struct {
+		name    string
+		updated int64 // Rows the conditional balance update changed
+		wantErr error
+	}{
+		{name: "balance unchanged", updated: 1},
+		{name: "balance changed since it was read", updated: 0, wantErr: ErrBalanceChanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, c := newMockConn(t)
+			m := NewUserBotSubscriptionModel(conn, c)
+			debit := usdtChange(TransactionTypeSubscribe, "1500", "500")
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(lockBotQuery).WithArgs("grid").
+				WillReturnRows(capacityRows(0, 0))
+			mock.ExpectQuery(countActiveQuery).WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(1))
+			mock.ExpectExec("insert into `user_bot_subscription`").WillReturnResult(sqlmock.NewResult(11, 1))
+			mock.ExpectExec(acceptWaitlistQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(insertOutboxQuery).WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectExec(updateUsdtQuery).WithArgs("500", int64(3), "1500").WillReturnResult(sqlmock.NewResult(0, tt.updated))
+			if tt.wantErr != nil {
+				mock.ExpectRollback()
+			} else {
+				mock.ExpectExec(insertTxQuery).
+					WithArgs(int64(3), int64(56), TransactionTypeSubscribe, "usdt", "", "1500", "500", "completed", "").
+					WillReturnResult(sqlmock.NewResult(21, 1))
+				mock.ExpectCommit()
+			}
+
+			_, err := m.Insert(context.Background(), &UserBotSubscription{UserId: 3, BotId: "grid", DurationDay: "30", Amount: "1000"}, debit)
+			if err != tt.wantErr {
+				t.Fatalf("Insert() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && debit.Transaction.Id != 21 {
+				t.Errorf("transaction id = %d, want 21", debit.Transaction.Id)
+			}
+		})
+	}
+}
+
+func TestUserBotSubscriptionModelCancel(t *testing.T) {
+	at := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	cancelQuery := regexp.QuoteMeta("update `user_bot_subscription` set `status`=?, `cancelled_at`=? where `id` = ? and `status` = ?")
+
+	tests := []struct {
+		name          string
+		updated       []int64 // Rows each position's status update changed
+		remaining     int64   // User's active positions left in the bot
+		refundUpdated int64   // Rows the conditional balance update changed
+		wantCancelled []int64
+		wantReleased  bool
+		wantErr       error
+	}{
+		{name: "last position", updated: []int64{1}, remaining: 0, refundUpdated: 1, wantCancelled: []int64{7}, wantReleased: true},
+		{name: "positions left", updated: []int64{1}, remaining: 1, refundUpdated: 1, wantCancelled: []int64{7}},
+		{name: "all positions", updated: []int64{1, 1}, remaining: 0, refundUpdated: 1, wantCancelled: []int64{7, 8}, wantReleased: true},
+		{name: "one no longer active", updated: []int64{0, 1}, remaining: 0, refundUpdated: 1, wantCancelled: []int64{8}, wantReleased: true},
+		{name: "none active", updated: []int64{0}},
+		{name: "balance changed since it was read", updated: []int64{1, 1}, wantErr: ErrBalanceChanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, c := newMockConn(t)
+			m := NewUserBotSubscriptionModel(conn, c)
+			var subs []*UserBotSubscription
+			for i := range tt.updated {
+				subs = append(subs, &UserBotSubscription{Id: int64(7 + i), UserId: 3, BotId: "grid", Amount: "500", Status: SubscriptionStatusActive})
+			}
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(lockBotQuery).WithArgs("grid").WillReturnRows(capacityRows(0, 0))
+			for i, updated := range tt.updated {
+				id := int64(7 + i)
+				mock.ExpectExec(cancelQuery).WithArgs(SubscriptionStatusCancelled, at, id, SubscriptionStatusActive).
+					WillReturnResult(sqlmock.NewResult(0, updated))
+				if updated > 0 {
+					mock.ExpectExec(insertOutboxQuery).WithArgs(EventUnsubscribed, strconv.FormatInt(id, 10), sqlmock.AnyArg(), OutboxStatusPending, sqlmock.AnyArg()).
+						WillReturnResult(sqlmock.NewResult(1, 1))
+				}
+			}
+			refunded := 0
+			if len(tt.wantCancelled) > 0 || tt.wantErr != nil {
+				mock.ExpectExec(updateUsdtQuery).WithArgs("1000", int64(3), "0").WillReturnResult(sqlmock.NewResult(0, tt.refundUpdated))
+			}
+			if tt.wantErr != nil {
+				mock.ExpectRollback()
+			} else {
+				if len(tt.wantCancelled) > 0 {
+					mock.ExpectExec(insertTxQuery).WillReturnResult(sqlmock.NewResult(21, 1))
+					mock.ExpectQuery(countActiveQuery).WithArgs(int64(3), "grid", SubscriptionStatusActive).
+						WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(tt.remaining))
+				}
+				if tt.wantReleased {
+					mock.ExpectExec(adjustSubscribersQuery).WithArgs(-1, "grid").WillReturnResult(sqlmock.NewResult(0, 1))
+				}
+				mock.ExpectCommit()
+			}
+
+			cancelled, err := m.Cancel(context.Background(), subs, at, func(cancelled []*UserBotSubscription) (*BalanceChange, error) {
+				refunded++
+				return usdtChange(TransactionTypeRefund, "0", "1000"), nil
+			})
+			if err != tt.wantErr {
+				t.Fatalf("Cancel() error = %v, want %v", err, tt.wantErr)
+			}
+			var ids []int64
+			for _, sub := range cancelled {
+				ids = append(ids, sub.Id)
+			}
+			if !reflect.DeepEqual(ids, tt.wantCancelled) {
+				t.Errorf("cancelled = %v, want %v", ids, tt.wantCancelled)
+			}
+			if wantRefunded := min(len(tt.wantCancelled), 1); tt.wantErr == nil && refunded != wantRefunded {
+				t.Errorf("refund called %d times, want %d", refunded, wantRefunded)
+			}
+		})
+	}
+}
+
+func TestUserBotSubscriptionModelSettle(t *testing.T) {
+	at := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	full := usdtChange(TransactionTypeSettle, "0", "1100")
+	remainder := usdtChange(TransactionTypeSettle, "0", "100")
+	payout := func(renewed bool) *BalanceChange {
+		if renewed {
+			return remainder
+		}
+		return full
+	}
+
+	tests := []struct {
+		name        string
+		updated     int64 // Rows the status update changed
+		renewal     *UserBotSubscription
+		wantSettled bool
+		wantCredit  string // USDT balance after the payout, empty for none
+	}{
+		{name: "no renewal", updated: 1, wantSettled: true, wantCredit: "1100"},
+		{name: "renewed", updated: 1, renewal: &UserBotSubscription{UserId: 3, BotId: "grid", DurationDay: "30", Amount: "1000", StartedAt: at}, wantSettled: true, wantCredit: "100"},
+		{name: "renewal bot full", updated: 1, renewal: &UserBotSubscription{UserId: 3, BotId: "grid", DurationDay: "30", Amount: "1000", StartedAt: at}, wantSettled: true, wantCredit: "1100"},
+		{name: "already settled", updated: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, mock, c := newMockConn(t)
+			m := NewUserBotSubscriptionModel(conn, c)
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("update `user_bot_subscription` set `status`=?, `final_value`=?, `settled_at`=? where `id` = ? and `status` = ?")).
+				WithArgs(SubscriptionStatusSettled, "1100", at, int64(7), SubscriptionStatusMatured).
+				WillReturnResult(sqlmock.NewResult(0, tt.updated))
+			full := tt.renewal != nil && tt.wantCredit == "1100"
+			if full {
+				mock.ExpectQuery(lockBotQuery).WillReturnRows(capacityRows(0, 1))
+				mock.ExpectQuery(countActiveQuery).WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(0))
+				mock.ExpectQuery(waitingAheadQuery).WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(0))
+				mock.ExpectQuery(activeUsageQuery).WillReturnRows(usageRows(5000, 1))
+				mock.ExpectQuery(reservedUsageQuery).WillReturnRows(usageRows(0, 0))
+			}
+			if tt.renewal != nil && tt.updated > 0 && !full {
+				mock.ExpectQuery(lockBotQuery).WillReturnRows(capacityRows(0, 0))
+				mock.ExpectQuery(countActiveQuery).WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow(0))
+				mock.ExpectExec("insert into `user_bot_subscription`").WillReturnResult(sqlmock.NewResult(12, 1))
+				mock.ExpectExec(adjustSubscribersQuery).WithArgs(1, "grid").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(acceptWaitlistQuery).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(insertOutboxQuery).WithArgs(EventSubscribed, "12", sqlmock.AnyArg(), OutboxStatusPending, sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			}
+			if tt.wantCredit != "" {
+				mock.ExpectExec(updateUsdtQuery).WithArgs(tt.wantCredit, int64(3), "0").WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(insertTxQuery).WillReturnResult(sqlmock.NewResult(21, 1))
+			}
+			mock.ExpectCommit()
+
+			settled, err := m.Settle(context.Background(), 7, "1100", at, tt.renewal, payout)
+			if err != nil {
+				t.Fatalf("Settle() error = %v", err)
+			}
+			if settled != tt.wantSettled {
+				t.Errorf("settled = %v, want %v", settled, tt.wantSettled)
+			}
+			if full && tt.renewal.Id != 0 {
+				t.Errorf("renewal.Id = %d, want 0 when the bot is full", tt.renewal.Id)
+			}
+			if tt.renewal != nil && tt.wantSettled && tt.renewal.RenewedFromId.Int64 != 7 {
+				t.Errorf("renewal.RenewedFromId = %v, want 7", tt.renewal.RenewedFromId)
+			}
+		})
+	}
+}
@@ -1,10 +1,13 @@
 package model
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
 
+	"wata-bot-BE/internal/address"
+
 	"github.com/zeromicro/go-zero/core/stores/cache"
 	"github.com/zeromicro/go-zero/core/stores/sqlc"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
@@ -15,14 +18,29 @@ var (
 	cacheUserAddressPrefix = "cache:user:address:"
 )
 
+// userOwnedTables lists the tables whose rows belong to a user by user_id
+// without a per-user unique key
+var userOwnedTables = []string{
+	"`user_bot_subscription`",
+	"`transaction`",
+	"`bot_waitlist`",
+	"`bot_change_notice`",
+	"`notification`",
+	"`notification_delivery`",
+}
+
 type (
 	UserModel interface {
-		Insert(data *User) (sql.Result, error)
-		FindOne(id int64) (*User, error)
-		FindOneByAddress(address string) (*User, error)
-		FindOneByAddressNoCache(address string) (*User, error)
-		Update(data *User) error
-		Delete(id int64) error
+		Insert(ctx context.Context, data *User) (sql.Result, error)
+		FindOne(ctx context.Context, id int64) (*User, error)
+		FindOneByAddress(ctx context.Context, addr address.Address) (*User, error)
+		FindOneByAddressNoCache(ctx context.Context, addr address.Address) (*User, error)
+		Update(ctx context.Context, data *User) error
+		UpdateLanguage(ctx context.Context, data *User, language string) error
+		FindAll(ctx context.Context) ([]*User, error)
+		Merge(ctx context.Context, into, from *User) error
+		UpdateAddress(ctx context.Context, data *User, addr address.Address) error
+		Delete(ctx context.Context, id int64) error
 	}
 
 	defaultUserModel struct {
@@ -31,16 +49,18 @@ type (
 	}
 
 	User struct {
-		Id           int64     `db:"id"`
-		Address      string    `db:"address"`
-		ReferralCode string    `db:"referral_code"`
-		InviteCode   string    `db:"invite_code"` // Can be NULL in DB, use COALESCE in queries
-		WataReward   int       `db:"wata_reward"`
-		WataBalance  string    `db:"wata_balance"`
-		UsdtBalance  string    `db:"usdt_balance"`
-		Role         string    `db:"role"`
-		CreatedAt    time.Time `db:"created_at"`
-		UpdatedAt    time.Time `db:"updated_at"`
+		Id           int64           `db:"id"`
+		Address      address.Address `db:"address"`
+		ReferralCode string          `db:"referral_code"`
+		InviteCode   string          `db:"invite_code"` // Can be NULL in DB, use COALESCE in queries
+		WataReward   int             `db:"wata_reward"`
+		WataBalance  string          `db:"wata_balance"`
+		UsdtBalance  string          `db:"usdt_balance"`
+		Role         string          `db:"role"`
+		Language     string          `db:"language"` // Preferred notification language, empty for the default
+		ChainId      int64           `db:"chain_id"` // Network the user signed up on, the default for deposits and withdrawals
+		CreatedAt    time.Time       `db:"created_at"`
+		UpdatedAt    time.Time       `db:"updated_at"`
 	}
 )
 
@@ -55,18 +75,39 @@ func NewUserModel(conn sqlx.SqlConn, c cache.CacheConf) UserModel {
 	}
 }
 
-func (m *defaultUserModel) Insert(data *User) (sql.Result, error) {
-	query := fmt.Sprintf("insert into %s (`address`, `referral_code`) values (?, ?)", m.table)
-	ret, err := m.ExecNoCache(query, data.Address, data.ReferralCode)
+// Insert registers the user and records a UserRegistered event
+func (m *defaultUserModel) Insert(ctx context.Context, data *User) (sql.Result, error) {
+	ctx, span := startSpan(ctx, "UserModel.Insert")
+	defer span.End()
+	query := fmt.Sprintf("insert into %s (`address`, `referral_code`, `chain_id`) values (?, ?, ?)", m.table)
+	var ret sql.Result
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		var err error
+		ret, err = session.ExecCtx(ctx, query, data.Address, data.ReferralCode, data.ChainId)
+		if err != nil {
+			return err
+		}
+		if data.Id, err = ret.LastInsertId(); err != nil {
+			return err
+		}
+		return insertOutboxEvent(ctx, session, EventUserRegistered, data.Id, UserRegisteredEvent{
+			UserId:       data.Id,
+			Address:      data.Address,
+			ChainId:      data.ChainId,
+			ReferralCode: data.ReferralCode,
+		})
+	})
 	return ret, err
 }
 
-func (m *defaultUserModel) FindOne(id int64) (*User, error) {
+func (m *defaultUserModel) FindOne(ctx context.Context, id int64) (*User, error) {
+	ctx, span := startSpan(ctx, "UserModel.FindOne")
+	defer span.End()
 	userIdKey := fmt.Sprintf("%s%v", cacheUserIdPrefix, id)
 	var resp User
-	err := m.QueryRow(&resp, userIdKey, func(conn sqlx.SqlConn, v interface{}) error {
-		query := fmt.Sprintf("select `id`, `address`, `referral_code`, COALESCE(`invite_code`, '') as `invite_code`, `wata_reward`, COALESCE(NULLIF(`wata_balance`, ''), '0') as `wata_balance`, COALESCE(NULLIF(`usdt_balance`, ''), '0') as `usdt_balance`, `role`, `created_at`, `updated_at` from %s where `id` = ? limit 1", m.table)
-		return conn.QueryRow(v, query, id)
+	err := m.QueryRowCtx(ctx, &resp, userIdKey, func(ctx context.Context, conn sqlx.SqlConn, v interface{}) error {
+		query := fmt.Sprintf("select `id`, `address`, `referral_code`, COALESCE(`invite_code`, '') as `invite_code`, `wata_reward`, COALESCE(NULLIF(`wata_balance`, ''), '0') as `wata_balance`, COALESCE(NULLIF(`usdt_balance`, ''), '0') as `usdt_balance`, `role`, `language`, `chain_id`, `created_at`, `updated_at` from %s where `id` = ? limit 1", m.table)
+		return conn.QueryRowCtx(ctx, v, query, id)
 	})
 	switch err {
 	case nil:
@@ -78,12 +119,16 @@ func (m *defaultUserModel) FindOne(id int64) (*User, error) {
 	}
 }
 
-func (m *defaultUserModel) FindOneByAddress(address string) (*User, error) {
-	userAddressKey := fmt.Sprintf("%s%v", cacheUserAddressPrefix, address)
+// FindOneByAddress finds the user of a wallet whatever the case of addr: the
+// address column compares case-insensitively and so does the cache key
+func (m *defaultUserModel) FindOneByAddress(ctx context.Context, addr address.Address) (*User, error) {
+	ctx, span := startSpan(ctx, "UserModel.FindOneByAddress")
+	defer span.End()
+	userAddressKey := m.formatAddress(addr)
 	var resp User
-	err := m.QueryRowIndex(&resp, userAddressKey, m.formatPrimary, func(conn sqlx.SqlConn, v interface{}) (i interface{}, e error) {
-		query := fmt.Sprintf("select `id`, `address`, `referral_code`, COALESCE(`invite_code`, '') as `invite_code`, `wata_reward`, COALESCE(NULLIF(`wata_balance`, ''), '0') as `wata_balance`, COALESCE(NULLIF(`usdt_balance`, ''), '0') as `usdt_balance`, `role`, `created_at`, `updated_at` from %s where `address` = ? limit 1", m.table)
-		if err := conn.QueryRow(&resp, query, address); err != nil {
+	err := m.QueryRowIndexCtx(ctx, &resp, userAddressKey, m.formatPrimary, func(ctx context.Context, conn sqlx.SqlConn, v interface{}) (i interface{}, e error) {
+		query := fmt.Sprintf("select `id`, `address`, `referral_code`, COALESCE(`invite_code`, '') as `invite_code`, `wata_reward`, COALESCE(NULLIF(`wata_balance`, ''), '0') as `wata_balance`, COALESCE(NULLIF(`usdt_balance`, ''), '0') as `usdt_balance`, `role`, `language`, `chain_id`, `created_at`, `updated_at` from %s where `address` = ? limit 1", m.table)
+		if err := conn.QueryRowCtx(ctx, &resp, query, addr); err != nil {
 			return nil, err
 		}
 		return resp.Id, nil
@@ -98,27 +143,157 @@ func (m *defaultUserModel) FindOneByAddress(address string) (*User, error) {
 	}
 }
 
-func (m *defaultUserModel) Update(data *User) error {
+func (m *defaultUserModel) Update(ctx context.Context, data *User) error {
+	ctx, span := startSpan(ctx, "UserModel.Update")
+	defer span.End()
 	userIdKey := fmt.Sprintf("%s%v", cacheUserIdPrefix, data.Id)
-	userAddressKey := fmt.Sprintf("%s%v", cacheUserAddressPrefix, data.Address)
-	_, err := m.Exec(func(conn sqlx.SqlConn) (result sql.Result, err error) {
+	userAddressKey := m.formatAddress(data.Address)
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
 		query := fmt.Sprintf("update %s set `address`=?, `referral_code`=?, `invite_code`=?, `wata_reward`=?, `wata_balance`=?, `usdt_balance`=?, `role`=? where `id` = ?", m.table)
-		return conn.Exec(query, data.Address, data.ReferralCode, data.InviteCode, data.WataReward, data.WataBalance, data.UsdtBalance, data.Role, data.Id)
+		return conn.ExecCtx(ctx, query, data.Address, data.ReferralCode, data.InviteCode, data.WataReward, data.WataBalance, data.UsdtBalance, data.Role, data.Id)
 	}, userIdKey, userAddressKey)
 	return err
 }
 
-func (m *defaultUserModel) Delete(id int64) error {
-	data, err := m.FindOne(id)
+// UpdateLanguage sets only the user's preferred language, so that it cannot
+// overwrite balances changed since data was read
+func (m *defaultUserModel) UpdateLanguage(ctx context.Context, data *User, language string) error {
+	ctx, span := startSpan(ctx, "UserModel.UpdateLanguage")
+	defer span.End()
+	userIdKey := fmt.Sprintf("%s%v", cacheUserIdPrefix, data.Id)
+	userAddressKey := m.formatAddress(data.Address)
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
+		query := fmt.Sprintf("update %s set `language`=? where `id` = ?", m.table)
+		return conn.ExecCtx(ctx, query, language, data.Id)
+	}, userIdKey, userAddressKey)
+	return err
+}
+
+// FindAll returns every user ordered by id, oldest first
+func (m *defaultUserModel) FindAll(ctx context.Context) ([]*User, error) {
+	ctx, span := startSpan(ctx, "UserModel.FindAll")
+	defer span.End()
+	var resp []*User
+	query := fmt.Sprintf("select `id`, `address`, `referral_code`, COALESCE(`invite_code`, '') as `invite_code`, `wata_reward`, COALESCE(NULLIF(`wata_balance`, ''), '0') as `wata_balance`, COALESCE(NULLIF(`usdt_balance`, ''), '0') as `usdt_balance`, `role`, `language`, `chain_id`, `created_at`, `updated_at` from %s order by `id`", m.table)
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query)
+	return resp, err
+}
+
+// Merge folds the duplicate user from into into: subscriptions, transactions,
+// waitlist entries, notices and notifications move to into, notification
+// preferences move for the channels into has not configured, and users
+// invited with from's referral code are credited to into's. The subscriber
+// counts of the bots into now holds positions in are recounted, since both
+// users may have been counted, and only into's oldest open waitlist entry per
+// bot is kept. from is then deleted and into's address, balances, reward and
+// invite code are saved as the caller combined them, in one transaction.
+func (m *defaultUserModel) Merge(ctx context.Context, into, from *User) error {
+	ctx, span := startSpan(ctx, "UserModel.Merge")
+	defer span.End()
+	keys := []string{
+		fmt.Sprintf("%s%v", cacheUserIdPrefix, into.Id),
+		fmt.Sprintf("%s%v", cacheUserIdPrefix, from.Id),
+		m.formatAddress(into.Address),
+		m.formatAddress(from.Address),
+	}
+	var botIds []string
+	err := m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		for _, table := range userOwnedTables {
+			query := fmt.Sprintf("update %s set `user_id` = ? where `user_id` = ?", table)
+			if _, err := session.ExecCtx(ctx, query, into.Id, from.Id); err != nil {
+				return err
+			}
+		}
+		// Preferences for channels into already has stay behind and are
+		// deleted with from
+		if _, err := session.ExecCtx(ctx, "update ignore `notification_preference` set `user_id` = ? where `user_id` = ?", into.Id, from.Id); err != nil {
+			return err
+		}
+		var err error
+		if botIds, err = recountSubscribers(ctx, session, into.Id); err != nil {
+			return err
+		}
+		if err := dedupeWaitlist(ctx, session, into.Id); err != nil {
+			return err
+		}
+		if from.ReferralCode != "" && from.ReferralCode != into.ReferralCode {
+			query := fmt.Sprintf("update %s set `invite_code` = ? where `invite_code` = ?", m.table)
+			if _, err := session.ExecCtx(ctx, query, into.ReferralCode, from.ReferralCode); err != nil {
+				return err
+			}
+		}
+
+		query := fmt.Sprintf("delete from %s where `id` = ?", m.table)
+		if _, err := session.ExecCtx(ctx, query, from.Id); err != nil {
+			return err
+		}
+		query = fmt.Sprintf("update %s set `address`=?, `invite_code`=NULLIF(?, ''), `wata_reward`=?, `wata_balance`=?, `usdt_balance`=? where `id` = ?", m.table)
+		_, err = session.ExecCtx(ctx, query, into.Address, into.InviteCode, into.WataReward, into.WataBalance, into.UsdtBalance, into.Id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	for _, botId := range botIds {
+		keys = append(keys, botCacheKey(botId))
+	}
+	return m.DelCacheCtx(ctx, keys...)
+}
+
+// recountSubscribers recounts, within a transaction, the subscribers of the
+// bots the user holds active positions in and returns their ids
+func recountSubscribers(ctx context.Context, session sqlx.Session, userId int64) ([]string, error) {
+	var botIds []string
+	query := "select distinct `bot_id` from `user_bot_subscription` where `user_id` = ? and `status` = ? order by `bot_id`"
+	if err := session.QueryRowsCtx(ctx, &botIds, query, userId, SubscriptionStatusActive); err != nil {
+		return nil, err
+	}
+	for _, botId := range botIds {
+		query := "update `bot` set `subscribers` = (select count(distinct `user_id`) from `user_bot_subscription` where `bot_id` = ? and `status` = ?) where `id` = ?"
+		if _, err := session.ExecCtx(ctx, query, botId, SubscriptionStatusActive, botId); err != nil {
+			return nil, err
+		}
+	}
+	return botIds, nil
+}
+
+// dedupeWaitlist cancels, within a transaction, all but the oldest of the
+// user's open waitlist entries in each bot. The oldest is the one the FIFO
+// order offers first.
+func dedupeWaitlist(ctx context.Context, session sqlx.Session, userId int64) error {
+	query := "update `bot_waitlist` w join (select `bot_id`, min(`id`) as `keep_id` from `bot_waitlist` where `user_id` = ? and `status` in (?, ?) group by `bot_id`) k on w.`bot_id` = k.`bot_id` " +
+		"set w.`status` = ? where w.`user_id` = ? and w.`status` in (?, ?) and w.`id` <> k.`keep_id`"
+	_, err := session.ExecCtx(ctx, query, userId, WaitlistStatusWaiting, WaitlistStatusOffered,
+		WaitlistStatusCancelled, userId, WaitlistStatusWaiting, WaitlistStatusOffered)
+	return err
+}
+
+// UpdateAddress rewrites the stored form of the user's address, such as to its
+// checksummed form
+func (m *defaultUserModel) UpdateAddress(ctx context.Context, data *User, addr address.Address) error {
+	ctx, span := startSpan(ctx, "UserModel.UpdateAddress")
+	defer span.End()
+	userIdKey := fmt.Sprintf("%s%v", cacheUserIdPrefix, data.Id)
+	_, err := m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
+		query := fmt.Sprintf("update %s set `address`=? where `id` = ?", m.table)
+		return conn.ExecCtx(ctx, query, addr, data.Id)
+	}, userIdKey, m.formatAddress(data.Address), m.formatAddress(addr))
+	return err
+}
+
+func (m *defaultUserModel) Delete(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "UserModel.Delete")
+	defer span.End()
+	data, err := m.FindOne(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	userIdKey := fmt.Sprintf("%s%v", cacheUserIdPrefix, id)
-	userAddressKey := fmt.Sprintf("%s%v", cacheUserAddressPrefix, data.Address)
-	_, err = m.Exec(func(conn sqlx.SqlConn) (result sql.Result, err error) {
+	userAddressKey := m.formatAddress(data.Address)
+	_, err = m.ExecCtx(ctx, func(ctx context.Context, conn sqlx.SqlConn) (result sql.Result, err error) {
 		query := fmt.Sprintf("delete from %s where `id` = ?", m.table)
-		return conn.Exec(query, id)
+		return conn.ExecCtx(ctx, query, id)
 	}, userIdKey, userAddressKey)
 	return err
 }
@@ -127,10 +302,16 @@ func (m *defaultUserModel) formatPrimary(primary interface{}) string {
 	return fmt.Sprintf("%s%v", cacheUserIdPrefix, primary)
 }
 
-func (m *defaultUserModel) FindOneByAddressNoCache(address string) (*User, error) {
+func (m *defaultUserModel) formatAddress(addr address.Address) string {
+	return cacheUserAddressPrefix + addr.Key()
+}
+
+func (m *defaultUserModel) FindOneByAddressNoCache(ctx context.Context, addr address.Address) (*User, error) {
+	ctx, span := startSpan(ctx, "UserModel.FindOneByAddressNoCache")
+	defer span.End()
 	var resp User
-	query := fmt.Sprintf("select `id`, `address`, `referral_code`, COALESCE(`invite_code`, '') as `invite_code`, `wata_reward`, COALESCE(NULLIF(`wata_balance`, ''), '0') as `wata_balance`, COALESCE(NULLIF(`usdt_balance`, ''), '0') as `usdt_balance`, `role`, `created_at`, `updated_at` from %s where `address` = ? limit 1", m.table)
-	err := m.QueryRowNoCache(&resp, query, address)
+	query := fmt.Sprintf("select `id`, `address`, `referral_code`, COALESCE(`invite_code`, '') as `invite_code`, `wata_reward`, COALESCE(NULLIF(`wata_balance`, ''), '0') as `wata_balance`, COALESCE(NULLIF(`usdt_balance`, ''), '0') as `usdt_balance`, `role`, `language`, `chain_id`, `created_at`, `updated_at` from %s where `address` = ? limit 1", m.table)
+	err := m.QueryRowNoCacheCtx(ctx, &resp, query, addr)
 	switch err {
 	case nil:
 		return &resp, nil
@@ -141,7 +322,7 @@ func (m *defaultUserModel) FindOneByAddressNoCache(address string) (*User, error
 	}
 }
 
-func (m *defaultUserModel) queryPrimary(conn sqlx.SqlConn, v, primary interface{}) error {
-	query := fmt.Sprintf("select `id`, `address`, `referral_code`, COALESCE(`invite_code`, '') as `invite_code`, `wata_reward`, COALESCE(NULLIF(`wata_balance`, ''), '0') as `wata_balance`, COALESCE(NULLIF(`usdt_balance`, ''), '0') as `usdt_balance`, `role`, `created_at`, `updated_at` from %s where `id` = ? limit 1", m.table)
-	return conn.QueryRow(v, query, primary)
+func (m *defaultUserModel) queryPrimary(ctx context.Context, conn sqlx.SqlConn, v, primary interface{}) error {
+	query := fmt.Sprintf("select `id`, `address`, `referral_code`, COALESCE(`invite_code`, '') as `invite_code`, `wata_reward`, COALESCE(NULLIF(`wata_balance`, ''), '0') as `wata_balance`, COALESCE(NULLIF(`usdt_balance`, ''), '0') as `usdt_balance`, `role`, `language`, `chain_id`, `created_at`, `updated_at` from %s where `id` = ? limit 1", m.table)
+	return conn.QueryRowCtx(ctx, v, query, primary)
 }
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestUserModelMerge(t *testing.T) {
+	conn, mock, c := newMockConn(t)
+	m := NewUserModel(conn, c)
+	into := &User{Id: 3, Address: "0x0742D35CC6634c0532925A3b844bc9E7595f0Beb", ReferralCode: "7595F0BE", WataBalance: "30", UsdtBalance: "1500"}
+	from := &User{Id: 9, Address: "0x0742d35cc6634c0532925a3b844bc9e7595f0beb", ReferralCode: "7595F0BX"}
+
+	mock.ExpectBegin()
+	for _, table := range userOwnedTables {
+		mock.ExpectExec(regexp.QuoteMeta("update "+table+" set `user_id` = ? where `user_id` = ?")).WithArgs(int64(3), int64(9)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(regexp.QuoteMeta("update ignore `notification_preference`")).WithArgs(int64(3), int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	// Both users held positions in grid, which counted them twice
+	mock.ExpectQuery(regexp.QuoteMeta("select distinct `bot_id` from `user_bot_subscription` where `user_id` = ? and `status` = ?")).
+		WithArgs(int64(3), SubscriptionStatusActive).
+		WillReturnRows(sqlmock.NewRows([]string{"bot_id"}).AddRow("grid").AddRow("scalp"))
+	for _, botId := range []string{"grid", "scalp"} {
+		mock.ExpectExec(regexp.QuoteMeta("update `bot` set `subscribers` = (select count(distinct `user_id`) from `user_bot_subscription` where `bot_id` = ? and `status` = ?) where `id` = ?")).
+			WithArgs(botId, SubscriptionStatusActive, botId).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectExec(regexp.QuoteMeta("update `bot_waitlist` w join (select `bot_id`, min(`id`) as `keep_id` from `bot_waitlist` where `user_id` = ? and `status` in (?, ?) group by `bot_id`) k")).
+		WithArgs(int64(3), WaitlistStatusWaiting, WaitlistStatusOffered, WaitlistStatusCancelled, int64(3), WaitlistStatusWaiting, WaitlistStatusOffered).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("update `user` set `invite_code` = ? where `invite_code` = ?")).WithArgs("7595F0BE", "7595F0BX").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta("delete from `user` where `id` = ?")).WithArgs(int64(9)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("update `user` set `address`=?")).
+		WithArgs(into.Address, "", 0, "30", "1500", int64(3)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	if err := m.Merge(context.Background(), into, from); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+}
@@ -0,0 +1,242 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlc"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+// Webhook delivery lifecycle: pending -> delivered, or pending -> failed once
+// every attempt has been used. Replaying a delivery puts it back to pending.
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusDelivered = "delivered"
+	WebhookStatusFailed    = "failed"
+)
+
+// maxWebhookErrorLength bounds the stored error of a failed attempt
+const maxWebhookErrorLength = 500
+
+type (
+	WebhookDeliveryModel interface {
+		// InsertIgnore queues a delivery of the event to the endpoint; false
+		// means it was already queued
+		InsertIgnore(ctx context.Context, data *WebhookDelivery) (bool, error)
+		FindOne(ctx context.Context, id int64) (*WebhookDelivery, error)
+		// FindByEndpointId returns the endpoint's deliveries, newest first,
+		// optionally limited to one status
+		FindByEndpointId(ctx context.Context, endpointId int64, status string, offset, limit int) ([]*WebhookDelivery, error)
+		CountByEndpointId(ctx context.Context, endpointId int64, status string) (int64, error)
+		// FindDue returns pending deliveries whose next attempt is due, oldest first
+		FindDue(ctx context.Context, now time.Time, limit int) ([]*WebhookDelivery, error)
+		// Claim hides a due delivery from other dispatchers for lease; false
+		// means another dispatcher claimed it first
+		Claim(ctx context.Context, id int64, now time.Time, lease time.Duration) (bool, error)
+		// RecordAttempt logs an attempt and moves the delivery to delivered,
+		// back to pending with the next attempt at retryAt, or to failed
+		RecordAttempt(ctx context.Context, attempt *WebhookDeliveryAttempt, status string, retryAt time.Time) error
+		// FindAttempts returns the delivery log of one delivery, oldest first
+		FindAttempts(ctx context.Context, deliveryId int64) ([]*WebhookDeliveryAttempt, error)
+		// Replay queues a delivered or failed delivery for immediate sending
+		// again with a fresh attempt budget; false means it is still pending
+		Replay(ctx context.Context, id int64, now time.Time) (bool, error)
+	}
+
+	defaultWebhookDeliveryModel struct {
+		sqlc.CachedConn
+		table string
+	}
+
+	// WebhookDelivery is one domain event queued for one partner endpoint
+	WebhookDelivery struct {
+		Id             int64        `db:"id"`
+		EndpointId     int64        `db:"endpoint_id"`
+		EventId        int64        `db:"event_id"` // Outbox event ID
+		EventType      string       `db:"event_type"`
+		Payload        string       `db:"payload"` // JSON body that is signed and sent
+		Status         string       `db:"status"`
+		Attempts       int          `db:"attempts"`
+		NextAttemptAt  time.Time    `db:"next_attempt_at"`
+		LastStatusCode int          `db:"last_status_code"`
+		LastError      string       `db:"last_error"`
+		DeliveredAt    sql.NullTime `db:"delivered_at"`
+		CreatedAt      time.Time    `db:"created_at"`
+		UpdatedAt      time.Time    `db:"updated_at"`
+	}
+
+	// WebhookDeliveryAttempt is one entry of the delivery log
+	WebhookDeliveryAttempt struct {
+		Id         int64     `db:"id"`
+		DeliveryId int64     `db:"delivery_id"`
+		StatusCode int       `db:"status_code"` // 0 when no response was received
+		Error      string    `db:"error"`
+		DurationMs int64     `db:"duration_ms"`
+		CreatedAt  time.Time `db:"created_at"`
+	}
+)
+
+func NewWebhookDeliveryModel(conn sqlx.SqlConn, c cache.CacheConf) WebhookDeliveryModel {
+	cachedConn := sqlc.NewConn(conn, c)
+
+	return &defaultWebhookDeliveryModel{
+		CachedConn: cachedConn,
+		table:      "`webhook_delivery`",
+	}
+}
+
+func (m *defaultWebhookDeliveryModel) InsertIgnore(ctx context.Context, data *WebhookDelivery) (bool, error) {
+	ctx, span := startSpan(ctx, "WebhookDeliveryModel.InsertIgnore")
+	defer span.End()
+	query := fmt.Sprintf("insert ignore into %s (`endpoint_id`, `event_id`, `event_type`, `payload`, `status`, `next_attempt_at`) values (?, ?, ?, ?, ?, ?)", m.table)
+	ret, err := m.ExecNoCacheCtx(ctx, query, data.EndpointId, data.EventId, data.EventType, data.Payload, WebhookStatusPending, time.Now())
+	if err != nil {
+		return false, err
+	}
+	affected, err := ret.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+	data.Id, err = ret.LastInsertId()
+	return true, err
+}
+
+func (m *defaultWebhookDeliveryModel) FindOne(ctx context.Context, id int64) (*WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, "WebhookDeliveryModel.FindOne")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `id` = ? limit 1", m.table)
+	var resp WebhookDelivery
+	err := m.QueryRowNoCacheCtx(ctx, &resp, query, id)
+	switch err {
+	case nil:
+		return &resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultWebhookDeliveryModel) FindByEndpointId(ctx context.Context, endpointId int64, status string, offset, limit int) ([]*WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, "WebhookDeliveryModel.FindByEndpointId")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `endpoint_id` = ? order by `id` desc limit ?, ?", m.table)
+	args := []interface{}{endpointId, offset, limit}
+	if status != "" {
+		query = fmt.Sprintf("select * from %s where `endpoint_id` = ? and `status` = ? order by `id` desc limit ?, ?", m.table)
+		args = []interface{}{endpointId, status, offset, limit}
+	}
+	var resp []*WebhookDelivery
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, args...)
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultWebhookDeliveryModel) CountByEndpointId(ctx context.Context, endpointId int64, status string) (int64, error) {
+	ctx, span := startSpan(ctx, "WebhookDeliveryModel.CountByEndpointId")
+	defer span.End()
+	query := fmt.Sprintf("select count(*) from %s where `endpoint_id` = ?", m.table)
+	args := []interface{}{endpointId}
+	if status != "" {
+		query += " and `status` = ?"
+		args = append(args, status)
+	}
+	var count int64
+	err := m.QueryRowNoCacheCtx(ctx, &count, query, args...)
+	return count, err
+}
+
+func (m *defaultWebhookDeliveryModel) FindDue(ctx context.Context, now time.Time, limit int) ([]*WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, "WebhookDeliveryModel.FindDue")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `status` = ? and `next_attempt_at` <= ? order by `next_attempt_at`, `id` limit ?", m.table)
+	var resp []*WebhookDelivery
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, WebhookStatusPending, now, limit)
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultWebhookDeliveryModel) Claim(ctx context.Context, id int64, now time.Time, lease time.Duration) (bool, error) {
+	ctx, span := startSpan(ctx, "WebhookDeliveryModel.Claim")
+	defer span.End()
+	query := fmt.Sprintf("update %s set `next_attempt_at` = ? where `id` = ? and `status` = ? and `next_attempt_at` <= ?", m.table)
+	ret, err := m.ExecNoCacheCtx(ctx, query, now.Add(lease), id, WebhookStatusPending, now)
+	if err != nil {
+		return false, err
+	}
+	affected, err := ret.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (m *defaultWebhookDeliveryModel) RecordAttempt(ctx context.Context, attempt *WebhookDeliveryAttempt, status string, retryAt time.Time) error {
+	ctx, span := startSpan(ctx, "WebhookDeliveryModel.RecordAttempt")
+	defer span.End()
+	if len(attempt.Error) > maxWebhookErrorLength {
+		attempt.Error = attempt.Error[:maxWebhookErrorLength]
+	}
+	return m.TransactCtx(ctx, func(ctx context.Context, session sqlx.Session) error {
+		query := "insert into `webhook_delivery_attempt` (`delivery_id`, `status_code`, `error`, `duration_ms`) values (?, ?, ?, ?)"
+		if _, err := session.ExecCtx(ctx, query, attempt.DeliveryId, attempt.StatusCode, attempt.Error, attempt.DurationMs); err != nil {
+			return err
+		}
+
+		var deliveredAt sql.NullTime
+		if status == WebhookStatusDelivered {
+			deliveredAt = sql.NullTime{Time: time.Now(), Valid: true}
+		}
+		query = fmt.Sprintf("update %s set `status` = ?, `attempts` = `attempts` + 1, `next_attempt_at` = ?, `last_status_code` = ?, `last_error` = ?, `delivered_at` = ? "+
+			"where `id` = ? and `status` = ?", m.table)
+		_, err := session.ExecCtx(ctx, query, status, retryAt, attempt.StatusCode, attempt.Error, deliveredAt, attempt.DeliveryId, WebhookStatusPending)
+		return err
+	})
+}
+
+func (m *defaultWebhookDeliveryModel) FindAttempts(ctx context.Context, deliveryId int64) ([]*WebhookDeliveryAttempt, error) {
+	ctx, span := startSpan(ctx, "WebhookDeliveryModel.FindAttempts")
+	defer span.End()
+	query := "select * from `webhook_delivery_attempt` where `delivery_id` = ? order by `id`"
+	var resp []*WebhookDeliveryAttempt
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, deliveryId)
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultWebhookDeliveryModel) Replay(ctx context.Context, id int64, now time.Time) (bool, error) {
+	ctx, span := startSpan(ctx, "WebhookDeliveryModel.Replay")
+	defer span.End()
+	query := fmt.Sprintf("update %s set `status` = ?, `attempts` = 0, `next_attempt_at` = ?, `delivered_at` = null where `id` = ? and `status` <> ?", m.table)
+	ret, err := m.ExecNoCacheCtx(ctx, query, WebhookStatusPending, now, id, WebhookStatusPending)
+	if err != nil {
+		return false, err
+	}
+	affected, err := ret.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
@@ -0,0 +1,166 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlc"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+// DomainEvents lists the event types partner webhooks can subscribe to
+var DomainEvents = []string{
+	EventUserRegistered,
+	EventDepositCompleted,
+	EventWithdrawRequested,
+	EventSubscribed,
+	EventUnsubscribed,
+}
+
+// IsDomainEvent reports whether eventType is a known domain event
+func IsDomainEvent(eventType string) bool {
+	for _, e := range DomainEvents {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type (
+	WebhookEndpointModel interface {
+		Insert(ctx context.Context, data *WebhookEndpoint) error
+		FindOne(ctx context.Context, id int64) (*WebhookEndpoint, error)
+		FindAll(ctx context.Context) ([]*WebhookEndpoint, error)
+		// FindActive returns the endpoints that receive new deliveries
+		FindActive(ctx context.Context) ([]*WebhookEndpoint, error)
+		Update(ctx context.Context, data *WebhookEndpoint) error
+		// Delete removes the endpoint together with its delivery log
+		Delete(ctx context.Context, id int64) error
+	}
+
+	defaultWebhookEndpointModel struct {
+		sqlc.CachedConn
+		table string
+	}
+
+	// WebhookEndpoint is a partner URL that receives signed domain events
+	WebhookEndpoint struct {
+		Id          int64     `db:"id"`
+		Url         string    `db:"url"`
+		Secret      string    `db:"secret"`
+		Events      string    `db:"events"` // Comma separated event types, empty for all
+		Description string    `db:"description"`
+		Active      bool      `db:"active"`
+		CreatedAt   time.Time `db:"created_at"`
+		UpdatedAt   time.Time `db:"updated_at"`
+	}
+)
+
+func NewWebhookEndpointModel(conn sqlx.SqlConn, c cache.CacheConf) WebhookEndpointModel {
+	cachedConn := sqlc.NewConn(conn, c)
+
+	return &defaultWebhookEndpointModel{
+		CachedConn: cachedConn,
+		table:      "`webhook_endpoint`",
+	}
+}
+
+func (m *defaultWebhookEndpointModel) Insert(ctx context.Context, data *WebhookEndpoint) error {
+	ctx, span := startSpan(ctx, "WebhookEndpointModel.Insert")
+	defer span.End()
+	query := fmt.Sprintf("insert into %s (`url`, `secret`, `events`, `description`, `active`) values (?, ?, ?, ?, ?)", m.table)
+	ret, err := m.ExecNoCacheCtx(ctx, query, data.Url, data.Secret, data.Events, data.Description, data.Active)
+	if err != nil {
+		return err
+	}
+	data.Id, err = ret.LastInsertId()
+	return err
+}
+
+func (m *defaultWebhookEndpointModel) FindOne(ctx context.Context, id int64) (*WebhookEndpoint, error) {
+	ctx, span := startSpan(ctx, "WebhookEndpointModel.FindOne")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `id` = ? limit 1", m.table)
+	var resp WebhookEndpoint
+	err := m.QueryRowNoCacheCtx(ctx, &resp, query, id)
+	switch err {
+	case nil:
+		return &resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultWebhookEndpointModel) FindAll(ctx context.Context) ([]*WebhookEndpoint, error) {
+	ctx, span := startSpan(ctx, "WebhookEndpointModel.FindAll")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s order by `id`", m.table)
+	return m.findRows(ctx, query)
+}
+
+func (m *defaultWebhookEndpointModel) FindActive(ctx context.Context) ([]*WebhookEndpoint, error) {
+	ctx, span := startSpan(ctx, "WebhookEndpointModel.FindActive")
+	defer span.End()
+	query := fmt.Sprintf("select * from %s where `active` = 1 order by `id`", m.table)
+	return m.findRows(ctx, query)
+}
+
+func (m *defaultWebhookEndpointModel) findRows(ctx context.Context, query string, args ...interface{}) ([]*WebhookEndpoint, error) {
+	var resp []*WebhookEndpoint
+	err := m.QueryRowsNoCacheCtx(ctx, &resp, query, args...)
+	switch err {
+	case nil:
+		return resp, nil
+	case sqlc.ErrNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, err
+	}
+}
+
+func (m *defaultWebhookEndpointModel) Update(ctx context.Context, data *WebhookEndpoint) error {
+	ctx, span := startSpan(ctx, "WebhookEndpointModel.Update")
+	defer span.End()
+	query := fmt.Sprintf("update %s set `url` = ?, `secret` = ?, `events` = ?, `description` = ?, `active` = ? where `id` = ?", m.table)
+	_, err := m.ExecNoCacheCtx(ctx, query, data.Url, data.Secret, data.Events, data.Description, data.Active, data.Id)
+	return err
+}
+
+func (m *defaultWebhookEndpointModel) Delete(ctx context.Context, id int64) error {
+	ctx, span := startSpan(ctx, "WebhookEndpointModel.Delete")
+	defer span.End()
+	query := fmt.Sprintf("delete from %s where `id` = ?", m.table)
+	_, err := m.ExecNoCacheCtx(ctx, query, id)
+	return err
+}
+
+// EventList returns the event types the endpoint is limited to, nil for all
+func (e *WebhookEndpoint) EventList() []string {
+	if e.Events == "" {
+		return nil
+	}
+	return strings.Split(e.Events, ",")
+}
+
+// Wants reports whether the endpoint should receive eventType
+func (e *WebhookEndpoint) Wants(eventType string) bool {
+	if !e.Active {
+		return false
+	}
+	events := e.EventList()
+	if len(events) == 0 {
+		return true
+	}
+	for _, ev := range events {
+		if ev == eventType {
+			return true
+		}
+	}
+	return false
+}
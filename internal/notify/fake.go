@@ -0,0 +1,53 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// Delivery is a message recorded by FakeSender
+type Delivery struct {
+	Recipient string
+	Message   Message
+}
+
+// FakeSender records messages instead of sending them. It stands in for every
+// channel in local development and tests; Fail makes the next sends return an
+// error to exercise retries.
+type FakeSender struct {
+	mu        sync.Mutex
+	sent      []Delivery
+	failures  int
+	failError error
+}
+
+func NewFakeSender() *FakeSender {
+	return &FakeSender{}
+}
+
+func (s *FakeSender) Send(ctx context.Context, recipient string, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.failures > 0 {
+		s.failures--
+		return s.failError
+	}
+	s.sent = append(s.sent, Delivery{Recipient: recipient, Message: msg})
+	return nil
+}
+
+// Fail makes the next n sends return err
+func (s *FakeSender) Fail(n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = n
+	s.failError = err
+}
+
+// Sent returns the messages recorded so far
+func (s *FakeSender) Sent() []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Delivery(nil), s.sent...)
+}
@@ -0,0 +1,47 @@
+// Package notify renders notification templates and delivers them over
+// external channels. Delivery state and retries live in the database; a
+// Sender only makes a single attempt.
+package notify
+
+import (
+	"context"
+	"errors"
+)
+
+// External delivery channels. Every notification is also stored in the
+// user's in-app inbox, which needs no channel.
+const (
+	ChannelEmail    = "email"
+	ChannelTelegram = "telegram"
+	ChannelWebhook  = "webhook"
+)
+
+// Channels lists the supported external channels
+var Channels = []string{ChannelEmail, ChannelTelegram, ChannelWebhook}
+
+// ErrNotConfigured is returned by senders whose channel has no credentials
+var ErrNotConfigured = errors.New("notification channel is not configured")
+
+// Message is a rendered notification
+type Message struct {
+	Event   string            `json:"event"`
+	Subject string            `json:"subject"`
+	Body    string            `json:"body"`
+	Data    map[string]string `json:"data,omitempty"`
+}
+
+// Sender delivers a message to one recipient over its channel. The recipient
+// is channel specific: an email address, a Telegram chat id or a webhook URL.
+type Sender interface {
+	Send(ctx context.Context, recipient string, msg Message) error
+}
+
+// IsChannel reports whether channel is a supported external channel
+func IsChannel(channel string) bool {
+	for _, c := range Channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+)
+
+// SMTPConf configures the email channel
+type SMTPConf struct {
+	Host     string `json:",optional"`
+	Port     int    `json:",default=587"`
+	Username string `json:",optional"`
+	Password string `json:",optional"`
+	From     string `json:",optional"`
+}
+
+// SMTPSender sends plain text email through an SMTP relay
+type SMTPSender struct {
+	conf SMTPConf
+	send func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+func NewSMTPSender(conf SMTPConf) *SMTPSender {
+	return &SMTPSender{
+		conf: conf,
+		send: smtp.SendMail,
+	}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, recipient string, msg Message) error {
+	if s.conf.Host == "" || s.conf.From == "" {
+		return ErrNotConfigured
+	}
+
+	var auth smtp.Auth
+	if s.conf.Username != "" {
+		auth = smtp.PlainAuth("", s.conf.Username, s.conf.Password, s.conf.Host)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.conf.From)
+	fmt.Fprintf(&b, "To: %s\r\n", recipient)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	b.WriteString("\r\n")
+
+	addr := net.JoinHostPort(s.conf.Host, strconv.Itoa(s.conf.Port))
+	return s.send(addr, auth, s.conf.From, []string{recipient}, []byte(b.String()))
+}
@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// TelegramConf configures the Telegram channel
+type TelegramConf struct {
+	BotToken string `json:",optional"`
+	ApiBase  string `json:",default=https://api.telegram.org"`
+}
+
+// TelegramSender posts messages through the Telegram Bot API
+type TelegramSender struct {
+	conf   TelegramConf
+	client *http.Client
+}
+
+func NewTelegramSender(conf TelegramConf) *TelegramSender {
+	return &TelegramSender{
+		conf:   conf,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *TelegramSender) Send(ctx context.Context, recipient string, msg Message) error {
+	if s.conf.BotToken == "" {
+		return ErrNotConfigured
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"chat_id": recipient,
+		"text":    msg.Subject + "\n\n" + msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimRight(s.conf.ApiBase, "/"), s.conf.BotToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// The request URL contains the bot token, keep it out of stored errors
+		return fmt.Errorf("telegram request failed: %w", unwrapURLError(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("telegram returned status %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
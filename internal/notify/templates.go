@@ -0,0 +1,107 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+
+	"wata-bot-BE/internal/i18n"
+)
+
+// Notification events
+const (
+	EventDepositCompleted    = "deposit_completed"
+	EventWithdrawCompleted   = "withdraw_completed"
+	EventSubscriptionSettled = "subscription_settled"
+	EventSubscriptionRenewed = "subscription_renewed"
+	EventWaitlistOffered     = "waitlist_offered"
+)
+
+// Events lists every event a user can be notified about
+var Events = []string{
+	EventDepositCompleted,
+	EventWithdrawCompleted,
+	EventSubscriptionSettled,
+	EventSubscriptionRenewed,
+	EventWaitlistOffered,
+}
+
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// templates maps each language to the subject and body of every event, parsed
+// from the i18n messages "notification.<event>.subject" and ".body". Fields
+// come from the data passed to Render; a missing field renders as an empty
+// string.
+var templates = map[string]map[string]messageTemplate{}
+
+func init() {
+	for _, lang := range i18n.Languages {
+		templates[lang] = make(map[string]messageTemplate, len(Events))
+		for _, event := range Events {
+			subject, _ := i18n.T(lang, subjectKey(event))
+			body, _ := i18n.T(lang, bodyKey(event))
+			templates[lang][event] = newTemplate(subject, body)
+		}
+	}
+}
+
+func newTemplate(subject, body string) messageTemplate {
+	return messageTemplate{
+		subject: template.Must(template.New("subject").Option("missingkey=zero").Parse(subject)),
+		body:    template.Must(template.New("body").Option("missingkey=zero").Parse(body)),
+	}
+}
+
+func subjectKey(event string) string {
+	return "notification." + event + ".subject"
+}
+
+func bodyKey(event string) string {
+	return "notification." + event + ".body"
+}
+
+// MessageKeys returns the i18n keys of every template, for checking that each
+// is translated
+func MessageKeys() []string {
+	keys := make([]string, 0, 2*len(Events))
+	for _, event := range Events {
+		keys = append(keys, subjectKey(event), bodyKey(event))
+	}
+	return keys
+}
+
+// Render builds the message for event from data in lang, or in the default
+// language when lang is not supported
+func Render(event, lang string, data map[string]string) (Message, error) {
+	byEvent, ok := templates[lang]
+	if !ok {
+		byEvent = templates[i18n.Default]
+	}
+	tmpl, ok := byEvent[event]
+	if !ok {
+		return Message{}, fmt.Errorf("no template for notification event %q", event)
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return Message{}, err
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Event:   event,
+		Subject: subject.String(),
+		Body:    body.String(),
+		Data:    data,
+	}, nil
+}
+
+// IsEvent reports whether event has a template
+func IsEvent(event string) bool {
+	_, ok := templates[i18n.Default][event]
+	return ok
+}
@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned for webhook targets on loopback, private or
+// link-local networks, so users cannot make the server call internal hosts
+var ErrPrivateAddress = errors.New("webhook target is not a public address")
+
+// WebhookConf configures the generic webhook channel
+type WebhookConf struct {
+	Timeout time.Duration `json:",default=10s"`
+}
+
+// WebhookSender posts the message as JSON to a user supplied URL. It only
+// connects to public addresses, whatever the target's host name resolves to.
+type WebhookSender struct {
+	client *http.Client
+}
+
+func NewWebhookSender(conf WebhookConf) *WebhookSender {
+	dialer := &net.Dialer{
+		Timeout: conf.Timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+				return ErrPrivateAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the target and bypass the check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &WebhookSender{
+		client: &http.Client{Timeout: conf.Timeout, Transport: transport},
+	}
+}
+
+// ValidateWebhookURL checks that target is an https URL whose host is not
+// localhost or a loopback, private or link-local address. Host names are
+// checked again on delivery against the addresses they resolve to.
+func ValidateWebhookURL(target string) error {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return errors.New("webhook target must be an https URL")
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && !publicIP(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// publicIP reports whether ip is routable on the internet
+func publicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+func (s *WebhookSender) Send(ctx context.Context, recipient string, msg Message) error {
+	// Targets saved before the https and public host rules are not sent
+	if err := ValidateWebhookURL(recipient); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, recipient, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, respBody)
+	}
+	return nil
+}
+
+// unwrapURLError drops the request URL from transport errors
+func unwrapURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		private bool
+		wantErr bool
+	}{
+		{name: "public https", target: "https://hooks.example.com/wata"},
+		{name: "public ip", target: "https://8.8.8.8/hook"},
+		{name: "http", target: "http://hooks.example.com/wata", wantErr: true},
+		{name: "no host", target: "https:///wata", wantErr: true},
+		{name: "not a url", target: "hooks.example.com", wantErr: true},
+		{name: "localhost", target: "https://localhost/hook", private: true},
+		{name: "localhost subdomain", target: "https://api.localhost./hook", private: true},
+		{name: "loopback", target: "https://127.0.0.1:8443/hook", private: true},
+		{name: "ipv6 loopback", target: "https://[::1]/hook", private: true},
+		{name: "private", target: "https://10.0.0.5/hook", private: true},
+		{name: "link-local metadata", target: "https://169.254.169.254/latest", private: true},
+		{name: "unspecified", target: "https://0.0.0.0/hook", private: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWebhookURL(tt.target)
+			if tt.private {
+				if !errors.Is(err, ErrPrivateAddress) {
+					t.Fatalf("ValidateWebhookURL(%q) = %v, want ErrPrivateAddress", tt.target, err)
+				}
+				return
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateWebhookURL(%q) = %v, wantErr %v", tt.target, err, tt.wantErr)
+			}
+		})
+	}
+}
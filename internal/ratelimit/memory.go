@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepSize is the number of keys above which expired windows are removed
+const sweepSize = 10000
+
+// memoryWindows counts requests per key in this process only
+type memoryWindows struct {
+	mu      sync.Mutex
+	windows map[string]*memoryWindow
+}
+
+type memoryWindow struct {
+	count int
+	ends  time.Time
+}
+
+func newMemoryWindows() *memoryWindows {
+	return &memoryWindows{
+		windows: make(map[string]*memoryWindow),
+	}
+}
+
+// incr counts a request in the key's window ending at ends and returns the count
+func (m *memoryWindows) incr(key string, ends time.Time) int {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.windows) >= sweepSize {
+		for k, w := range m.windows {
+			if !now.Before(w.ends) {
+				delete(m.windows, k)
+			}
+		}
+	}
+
+	w, ok := m.windows[key]
+	if !ok || !now.Before(w.ends) {
+		w = &memoryWindow{ends: ends}
+		m.windows[key] = w
+	}
+	w.count++
+	return w.count
+}
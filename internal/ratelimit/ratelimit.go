@@ -0,0 +1,115 @@
+// Package ratelimit counts requests per key in fixed windows, in Redis when
+// available so that the quota is shared by every replica
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/limit"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// PolicyConf limits one route group. A zero quota disables that dimension.
+type PolicyConf struct {
+	Period time.Duration `json:",default=1m"`
+	// Ip is the quota per client IP and period
+	Ip int `json:",optional"`
+	// Address is the quota per wallet and period
+	Address int `json:",optional"`
+}
+
+// Result is the state of a key's quota after a request was counted
+type Result struct {
+	Allowed bool
+	Limit   int
+	// Remaining is -1 when the count could not be read back
+	Remaining int
+	// Reset is the time until the window ends and the quota is restored
+	Reset time.Duration
+}
+
+// Limiter allows quota requests per key and period. Windows are aligned to
+// the period so every replica agrees on when they reset.
+type Limiter struct {
+	quota  int
+	period time.Duration
+	store  *redis.Redis
+	prefix string
+	redis  *limit.PeriodLimit
+	memory *memoryWindows
+}
+
+// NewLimiter counts in store, or in memory only when store is nil. Requests
+// are also counted in memory while Redis is unavailable.
+func NewLimiter(store *redis.Redis, prefix string, period time.Duration, quota int) *Limiter {
+	// Redis windows are counted in whole seconds
+	period = max(period.Truncate(time.Second), time.Second)
+	l := &Limiter{
+		quota:  quota,
+		period: period,
+		store:  store,
+		prefix: prefix,
+		memory: newMemoryWindows(),
+	}
+	if store != nil {
+		l.redis = limit.NewPeriodLimit(int(period/time.Second), quota, store, prefix, limit.Align())
+	}
+	return l
+}
+
+// Take counts one request for key
+func (l *Limiter) Take(ctx context.Context, key string) Result {
+	reset := untilWindowEnd(time.Now(), l.period)
+	if l.redis != nil {
+		code, err := l.redis.TakeCtx(ctx, key)
+		if err == nil {
+			res := Result{
+				Allowed:   code != limit.OverQuota,
+				Limit:     l.quota,
+				Remaining: 0,
+				Reset:     reset,
+			}
+			if code == limit.Allowed {
+				res.Remaining = l.remaining(ctx, key)
+			}
+			return res
+		}
+		logx.WithContext(ctx).Errorf("Rate limit store unavailable, counting in memory: %v", err)
+	}
+
+	count := l.memory.incr(key, time.Now().Add(reset))
+	return Result{
+		Allowed:   count <= l.quota,
+		Limit:     l.quota,
+		Remaining: max(l.quota-count, 0),
+		Reset:     reset,
+	}
+}
+
+// remaining reads back the key's count; PeriodLimit only reports whether the
+// quota was reached
+func (l *Limiter) remaining(ctx context.Context, key string) int {
+	val, err := l.store.GetCtx(ctx, l.prefix+key)
+	if err != nil {
+		return -1
+	}
+	count, err := strconv.Atoi(val)
+	if err != nil {
+		return -1
+	}
+	return max(l.quota-count, 0)
+}
+
+// untilWindowEnd mirrors the window alignment of limit.Align
+func untilWindowEnd(now time.Time, period time.Duration) time.Duration {
+	seconds := int64(period / time.Second)
+	if seconds <= 0 {
+		return period
+	}
+	_, offset := now.Zone()
+	unix := now.Unix() + int64(offset)
+	return time.Duration(seconds-unix%seconds) * time.Second
+}
@@ -0,0 +1,45 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Header carries the request id from clients and proxies and back in responses
+const Header = "X-Request-ID"
+
+// maxLength bounds ids accepted from clients so they stay log-friendly
+const maxLength = 128
+
+type requestIdKey struct{}
+
+// New returns a random request id
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid reports whether an id sent by a client may be reused: printable ASCII
+// without spaces, up to 128 characters
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext returns a copy of ctx carrying the request id
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdKey{}, id)
+}
+
+// FromContext returns the request id carried by ctx, if any
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIdKey{}).(string)
+	return id
+}
@@ -0,0 +1,47 @@
+package requestid
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		want bool
+	}{
+		{name: "uuid", id: "3f1c2a9e-6b7d-4c1e-9a2b-5d8e7f6a1b2c", want: true},
+		{name: "proxy style", id: "req_01HZX:abc/def", want: true},
+		{name: "max length", id: strings.Repeat("a", maxLength), want: true},
+		{name: "empty", id: ""},
+		{name: "too long", id: strings.Repeat("a", maxLength+1)},
+		{name: "space", id: "abc def"},
+		{name: "newline", id: "abc\ndef"},
+		{name: "non ascii", id: "abcé"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Valid(tt.id); got != tt.want {
+				t.Errorf("Valid(%q) = %v, want %v", tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	a, b := New(), New()
+	if !Valid(a) || a == b {
+		t.Errorf("New() = %q, %q, want two distinct valid ids", a, b)
+	}
+}
+
+func TestContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != "" {
+		t.Errorf("FromContext(empty) = %q, want empty", got)
+	}
+	if got := FromContext(NewContext(context.Background(), "abc")); got != "abc" {
+		t.Errorf("FromContext() = %q, want abc", got)
+	}
+}
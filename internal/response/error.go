@@ -0,0 +1,75 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"wata-bot-BE/internal/i18n"
+	"wata-bot-BE/internal/metrics"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/requestid"
+	"wata-bot-BE/internal/types"
+	"wata-bot-BE/internal/validate"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// Error writes err as an ErrorResp with the HTTP status the catalog gives its
+// code. Errors other than *model.APIError are returned as internal server
+// errors, without their message, which may carry SQL or other internals.
+// The message is translated into the request language.
+func Error(ctx context.Context, w http.ResponseWriter, err error) {
+	apiErr := AsAPIError(err)
+	status := apiErr.HTTPStatus()
+	lang := i18n.FromContext(ctx)
+	metrics.ApiErrors.Inc(apiErr.Code, strconv.Itoa(status))
+	w.Header().Set("Content-Language", lang)
+	httpx.WriteJsonCtx(ctx, w, status, types.ErrorResp{
+		ErrorCode: apiErr.Code,
+		Message:   Message(lang, apiErr),
+		RequestId: requestid.FromContext(ctx),
+		Details:   fieldErrors(lang, apiErr.Fields),
+	})
+}
+
+// Message returns the message of apiErr in lang. English keeps the error's own
+// message, which may carry details; other languages get the translated
+// catalog message of its code.
+func Message(lang string, apiErr *model.APIError) string {
+	if lang == i18n.Default {
+		return apiErr.Message
+	}
+	if msg, ok := i18n.T(lang, apiErr.Key()); ok {
+		return msg
+	}
+	return apiErr.Message
+}
+
+// AsAPIError returns the *model.APIError in err's chain, or an internal server
+// error when there is none
+func AsAPIError(err error) *model.APIError {
+	var apiErr *model.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return model.NewAPIErrorFromCode(model.ErrCodeInternalServerError)
+}
+
+// fieldErrors converts the fields that failed validation, with their messages
+// in lang
+func fieldErrors(lang string, fields []model.FieldError) []types.FieldError {
+	if len(fields) == 0 {
+		return nil
+	}
+	details := make([]types.FieldError, 0, len(fields))
+	for _, field := range fields {
+		details = append(details, types.FieldError{
+			Field:   field.Field,
+			Rule:    field.Rule,
+			Message: validate.Message(lang, field),
+		})
+	}
+	return details
+}
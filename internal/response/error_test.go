@@ -0,0 +1,84 @@
+package response
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"wata-bot-BE/internal/i18n"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/requestid"
+	"wata-bot-BE/internal/types"
+)
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{
+			name:    "api error",
+			err:     model.NewAPIError(model.ErrCodeInsufficientBalance, "Insufficient USDT"),
+			status:  http.StatusUnprocessableEntity,
+			code:    model.ErrCodeInsufficientBalance,
+			message: "Insufficient USDT",
+		},
+		{
+			name:    "wrapped api error",
+			err:     fmt.Errorf("subscribe: %w", model.NewAPIErrorFromCode(model.ErrCodeBotNotFound)),
+			status:  http.StatusNotFound,
+			code:    model.ErrCodeBotNotFound,
+			message: model.ErrMsgBotNotFound,
+		},
+		{
+			name:    "plain error hides its message",
+			err:     errors.New("Error 1146: Table 'wata.bot' doesn't exist"),
+			status:  http.StatusInternalServerError,
+			code:    model.ErrCodeInternalServerError,
+			message: model.ErrMsgInternalServerError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := requestid.NewContext(context.Background(), "req-1")
+			w := httptest.NewRecorder()
+			Error(ctx, w, tt.err)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			var resp types.ErrorResp
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.ErrorCode != tt.code || resp.Message != tt.message || resp.RequestId != "req-1" {
+				t.Errorf("body = %+v, want %s %q req-1", resp, tt.code, tt.message)
+			}
+		})
+	}
+}
+
+func TestErrorTranslated(t *testing.T) {
+	ctx := i18n.NewContext(context.Background(), i18n.Vi)
+	w := httptest.NewRecorder()
+	Error(ctx, w, model.NewAPIError(model.ErrCodeBotFull, "Bot bot-1 is full"))
+
+	want, _ := i18n.T(i18n.Vi, "error.bot_full")
+	var resp types.ErrorResp
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Message != want {
+		t.Errorf("message = %q, want %q", resp.Message, want)
+	}
+	if got := w.Header().Get("Content-Language"); got != i18n.Vi {
+		t.Errorf("Content-Language = %q, want %q", got, i18n.Vi)
+	}
+}
@@ -0,0 +1,89 @@
+package stream
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/zeromicro/go-zero/core/logx"
+	zredis "github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// LocalBroker delivers within this process only. It is used when no Redis is
+// configured, which is fine for a single replica.
+type LocalBroker struct {
+	ch chan []byte
+}
+
+func NewLocalBroker() *LocalBroker {
+	return &LocalBroker{ch: make(chan []byte, 1024)}
+}
+
+func (b *LocalBroker) Publish(ctx context.Context, payload []byte) error {
+	select {
+	case b.ch <- payload:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (b *LocalBroker) Run(ctx context.Context, deliver func(payload []byte)) {
+	for {
+		select {
+		case payload := <-b.ch:
+			deliver(payload)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RedisBroker fans out over a Redis pub/sub channel so that every replica
+// receives every message
+type RedisBroker struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+func NewRedisBroker(c zredis.RedisConf, channel string) *RedisBroker {
+	opts := &redis.UniversalOptions{
+		Addrs:         strings.Split(c.Host, ","),
+		Username:      c.User,
+		Password:      c.Pass,
+		IsClusterMode: c.Type == zredis.ClusterType,
+	}
+	if c.Tls {
+		opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return &RedisBroker{
+		client:  redis.NewUniversalClient(opts),
+		channel: channel,
+	}
+}
+
+func (b *RedisBroker) Publish(ctx context.Context, payload []byte) error {
+	return b.client.Publish(ctx, b.channel, payload).Err()
+}
+
+// Run subscribes to the channel; the client reconnects and resubscribes on
+// connection errors
+func (b *RedisBroker) Run(ctx context.Context, deliver func(payload []byte)) {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case msg, ok := <-ch:
+			if !ok {
+				logx.Errorf("Stream subscription to %s closed", b.channel)
+				return
+			}
+			deliver([]byte(msg.Payload))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,316 @@
+// Package stream fans real-time updates out to connected clients. Updates are
+// published through a Broker so that every replica delivers them to the
+// clients connected to it.
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"wata-bot-BE/internal/address"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// Topics. Balance and transaction updates only reach connections of the wallet
+// they belong to; bot topics are public.
+const (
+	TopicBalances     = "balances"
+	TopicTransactions = "transactions"
+	// TopicBotPrefix followed by a bot id carries that bot's metric updates
+	TopicBotPrefix = "bot:"
+)
+
+// EventSubscriptions confirms a connection's topics after they changed
+const EventSubscriptions = "subscriptions"
+
+// ErrInvalidTopic is returned for topic names clients cannot subscribe to
+var ErrInvalidTopic = errors.New("invalid topic")
+
+// BotTopic returns the topic carrying a bot's metric updates
+func BotTopic(botId string) string {
+	return TopicBotPrefix + botId
+}
+
+// ValidTopic reports whether clients can subscribe to topic
+func ValidTopic(topic string) bool {
+	switch {
+	case topic == TopicBalances, topic == TopicTransactions:
+		return true
+	case strings.HasPrefix(topic, TopicBotPrefix):
+		return len(topic) > len(TopicBotPrefix)
+	}
+	return false
+}
+
+// userScoped reports whether topic carries updates for a single wallet
+func userScoped(topic string) bool {
+	return topic == TopicBalances || topic == TopicTransactions
+}
+
+// Message is one update pushed to clients
+type Message struct {
+	Topic string `json:"topic,omitempty"`
+	Event string `json:"event"`
+	// Address limits a user scoped message to that wallet's connections
+	Address address.Address `json:"address,omitempty"`
+	Data    json.RawMessage `json:"data"`
+	At      time.Time       `json:"at"`
+}
+
+// control changes the subscriptions of one connection, wherever it is connected
+type control struct {
+	ConnectionId string          `json:"connectionId"`
+	Address      address.Address `json:"address"`
+	Subscribe    []string        `json:"subscribe,omitempty"`
+	Unsubscribe  []string        `json:"unsubscribe,omitempty"`
+}
+
+// envelope is what travels through the broker
+type envelope struct {
+	Message *Message `json:"message,omitempty"`
+	Control *control `json:"control,omitempty"`
+}
+
+// Broker carries envelopes to every replica, including the publishing one
+type Broker interface {
+	Publish(ctx context.Context, payload []byte) error
+	// Run delivers received payloads until ctx is cancelled
+	Run(ctx context.Context, deliver func(payload []byte))
+}
+
+// Conn is one connected client
+type Conn struct {
+	Id      string
+	Address address.Address
+
+	// Send receives the connection's messages; it is closed when the hub drops
+	// the connection
+	Send chan Message
+
+	mu     sync.Mutex
+	topics map[string]bool
+	closed bool
+}
+
+// Topics returns the connection's current subscriptions
+func (c *Conn) Topics() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	topics := make([]string, 0, len(c.topics))
+	for t := range c.topics {
+		topics = append(topics, t)
+	}
+	return topics
+}
+
+// offer queues msg if the connection subscribed to it. It reports false when
+// the buffer is full.
+func (c *Conn) offer(msg *Message) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed || !c.topics[msg.Topic] {
+		return true
+	}
+	if userScoped(msg.Topic) && !c.Address.Equal(msg.Address) {
+		return true
+	}
+	out := *msg
+	out.Address = ""
+	select {
+	case c.Send <- out:
+		return true
+	default:
+		return false
+	}
+}
+
+// Hub tracks the connections of this replica
+type Hub struct {
+	broker     Broker
+	bufferSize int
+	maxTopics  int
+
+	mu     sync.RWMutex
+	conns  map[string]*Conn
+	nextId atomic.Int64
+	prefix string
+}
+
+func NewHub(broker Broker, bufferSize, maxTopics int) *Hub {
+	return &Hub{
+		broker:     broker,
+		bufferSize: bufferSize,
+		maxTopics:  maxTopics,
+		conns:      make(map[string]*Conn),
+		prefix:     strconv.FormatInt(time.Now().UnixNano(), 36) + "-",
+	}
+}
+
+// Run receives published envelopes until ctx is cancelled
+func (h *Hub) Run(ctx context.Context) {
+	h.broker.Run(ctx, h.receive)
+}
+
+// Publish sends msg to the subscribed connections on every replica
+func (h *Hub) Publish(ctx context.Context, msg Message) error {
+	if msg.At.IsZero() {
+		msg.At = time.Now()
+	}
+	return h.publish(ctx, envelope{Message: &msg})
+}
+
+// PublishJSON encodes data and publishes it as a message
+func (h *Hub) PublishJSON(ctx context.Context, topic, event string, addr address.Address, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return h.Publish(ctx, Message{Topic: topic, Event: event, Address: addr, Data: b})
+}
+
+// Connect registers a connection for the wallet with the given topics
+func (h *Hub) Connect(addr address.Address, topics []string) (*Conn, error) {
+	if err := h.checkTopics(topics); err != nil {
+		return nil, err
+	}
+	conn := &Conn{
+		Id:      h.prefix + strconv.FormatInt(h.nextId.Add(1), 10),
+		Address: addr,
+		Send:    make(chan Message, h.bufferSize),
+		topics:  make(map[string]bool, len(topics)),
+	}
+	for _, t := range topics {
+		conn.topics[t] = true
+	}
+
+	h.mu.Lock()
+	h.conns[conn.Id] = conn
+	h.mu.Unlock()
+	return conn, nil
+}
+
+// Disconnect removes the connection and closes its Send channel
+func (h *Hub) Disconnect(conn *Conn) {
+	h.mu.Lock()
+	delete(h.conns, conn.Id)
+	h.mu.Unlock()
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	if !conn.closed {
+		conn.closed = true
+		close(conn.Send)
+	}
+}
+
+// Update changes the subscriptions of a connection owned by the wallet. The
+// connection may live on another replica, so the change is published.
+func (h *Hub) Update(ctx context.Context, connectionId string, addr address.Address, subscribe, unsubscribe []string) error {
+	if err := h.checkTopics(subscribe); err != nil {
+		return err
+	}
+	return h.publish(ctx, envelope{Control: &control{
+		ConnectionId: connectionId,
+		Address:      addr,
+		Subscribe:    subscribe,
+		Unsubscribe:  unsubscribe,
+	}})
+}
+
+// Connections returns how many clients are connected to this replica
+func (h *Hub) Connections() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns)
+}
+
+func (h *Hub) checkTopics(topics []string) error {
+	if len(topics) > h.maxTopics {
+		return ErrInvalidTopic
+	}
+	for _, t := range topics {
+		if !ValidTopic(t) {
+			return ErrInvalidTopic
+		}
+	}
+	return nil
+}
+
+func (h *Hub) publish(ctx context.Context, env envelope) error {
+	b, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	return h.broker.Publish(ctx, b)
+}
+
+func (h *Hub) receive(payload []byte) {
+	var env envelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		logx.Errorf("Failed to decode stream message: %v", err)
+		return
+	}
+	switch {
+	case env.Message != nil:
+		h.deliver(env.Message)
+	case env.Control != nil:
+		h.apply(env.Control)
+	}
+}
+
+// deliver queues msg on every subscribed connection. A connection whose buffer
+// is full is too slow to keep up and is dropped; the client reconnects.
+func (h *Hub) deliver(msg *Message) {
+	h.mu.RLock()
+	var slow []*Conn
+	for _, conn := range h.conns {
+		if !conn.offer(msg) {
+			slow = append(slow, conn)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, conn := range slow {
+		logx.Infof("Dropping slow stream connection %s", conn.Id)
+		h.Disconnect(conn)
+	}
+}
+
+func (h *Hub) apply(c *control) {
+	h.mu.RLock()
+	conn := h.conns[c.ConnectionId]
+	h.mu.RUnlock()
+	if conn == nil || !conn.Address.Equal(c.Address) {
+		return
+	}
+
+	conn.mu.Lock()
+	for _, t := range c.Unsubscribe {
+		delete(conn.topics, t)
+	}
+	for _, t := range c.Subscribe {
+		if len(conn.topics) >= h.maxTopics {
+			break
+		}
+		conn.topics[t] = true
+	}
+	conn.mu.Unlock()
+
+	// Confirm the new subscriptions to the client
+	data, _ := json.Marshal(map[string][]string{"topics": conn.Topics()})
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	if !conn.closed {
+		select {
+		case conn.Send <- Message{Event: EventSubscriptions, Data: data, At: time.Now()}:
+		default:
+		}
+	}
+}
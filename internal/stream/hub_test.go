@@ -0,0 +1,233 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"wata-bot-BE/internal/address"
+)
+
+// syncBroker delivers every payload to the hub before Publish returns
+type syncBroker struct {
+	hub *Hub
+}
+
+func (b *syncBroker) Publish(ctx context.Context, payload []byte) error {
+	b.hub.receive(payload)
+	return nil
+}
+
+func (b *syncBroker) Run(ctx context.Context, deliver func(payload []byte)) {}
+
+func newTestHub(bufferSize, maxTopics int) *Hub {
+	broker := &syncBroker{}
+	hub := NewHub(broker, bufferSize, maxTopics)
+	broker.hub = hub
+	return hub
+}
+
+// drain returns the messages queued on conn without blocking
+func drain(conn *Conn) []Message {
+	var msgs []Message
+	for {
+		select {
+		case msg, ok := <-conn.Send:
+			if !ok {
+				return msgs
+			}
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+func TestValidTopic(t *testing.T) {
+	tests := []struct {
+		topic string
+		want  bool
+	}{
+		{topic: TopicBalances, want: true},
+		{topic: TopicTransactions, want: true},
+		{topic: BotTopic("grid"), want: true},
+		{topic: TopicBotPrefix, want: false},
+		{topic: "notifications", want: false},
+		{topic: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := ValidTopic(tt.topic); got != tt.want {
+			t.Errorf("ValidTopic(%q) = %v, want %v", tt.topic, got, tt.want)
+		}
+	}
+}
+
+func TestHubConnectChecksTopics(t *testing.T) {
+	hub := newTestHub(4, 2)
+
+	tests := []struct {
+		name    string
+		topics  []string
+		wantErr error
+	}{
+		{name: "valid topics", topics: []string{TopicBalances, BotTopic("grid")}},
+		{name: "unknown topic", topics: []string{"orders"}, wantErr: ErrInvalidTopic},
+		{name: "too many topics", topics: []string{TopicBalances, TopicTransactions, BotTopic("grid")}, wantErr: ErrInvalidTopic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := hub.Connect("0xabc", tt.topics); err != tt.wantErr {
+				t.Errorf("Connect() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+	if got := hub.Connections(); got != 1 {
+		t.Errorf("Connections() = %d, want 1", got)
+	}
+}
+
+func TestHubPublish(t *testing.T) {
+	hub := newTestHub(4, 4)
+	owner, _ := hub.Connect("0xAbC", []string{TopicBalances, BotTopic("grid")})
+	other, _ := hub.Connect("0xdef", []string{TopicBalances})
+	watcher, _ := hub.Connect("0x123", []string{BotTopic("grid"), BotTopic("dca")})
+
+	ctx := context.Background()
+	hub.PublishJSON(ctx, TopicBalances, "balance", "0xabc", map[string]string{"usdt": "10"})
+	hub.PublishJSON(ctx, BotTopic("grid"), "metrics", "", map[string]string{"roi30d": "4.2"})
+	hub.PublishJSON(ctx, TopicTransactions, "transaction", "0xabc", map[string]string{"id": "1"})
+
+	tests := []struct {
+		name       string
+		conn       *Conn
+		wantEvents []string
+	}{
+		{name: "owner gets its balance and the bot metrics", conn: owner, wantEvents: []string{"balance", "metrics"}},
+		{name: "other wallet gets nothing", conn: other},
+		{name: "bot watcher gets the bot metrics only", conn: watcher, wantEvents: []string{"metrics"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []string
+			for _, msg := range drain(tt.conn) {
+				events = append(events, msg.Event)
+				if msg.Address != "" {
+					t.Errorf("message %s carries address %s", msg.Event, msg.Address)
+				}
+				if msg.At.IsZero() {
+					t.Errorf("message %s has no time", msg.Event)
+				}
+			}
+			if !reflect.DeepEqual(events, tt.wantEvents) {
+				t.Errorf("events = %v, want %v", events, tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestHubDropsSlowConnection(t *testing.T) {
+	hub := newTestHub(1, 4)
+	conn, _ := hub.Connect("0xabc", []string{BotTopic("grid")})
+
+	ctx := context.Background()
+	hub.Publish(ctx, Message{Topic: BotTopic("grid"), Event: "metrics", At: time.Now()})
+	hub.Publish(ctx, Message{Topic: BotTopic("grid"), Event: "metrics", At: time.Now()})
+
+	if got := hub.Connections(); got != 0 {
+		t.Errorf("Connections() = %d, want the slow connection dropped", got)
+	}
+	if msgs := drain(conn); len(msgs) != 1 {
+		t.Errorf("queued messages = %d, want the 1 that fit", len(msgs))
+	}
+	if _, ok := <-conn.Send; ok {
+		t.Error("Send is still open after the connection was dropped")
+	}
+}
+
+func TestHubUpdate(t *testing.T) {
+	tests := []struct {
+		name        string
+		addr        address.Address
+		subscribe   []string
+		unsubscribe []string
+		wantTopics  []string
+		wantConfirm bool
+	}{
+		{
+			name: "subscribe and unsubscribe", addr: "0xABC",
+			subscribe: []string{BotTopic("dca")}, unsubscribe: []string{TopicBalances},
+			wantTopics: []string{BotTopic("dca"), BotTopic("grid")}, wantConfirm: true,
+		},
+		{
+			name: "capped at max topics", addr: "0xabc",
+			subscribe:  []string{TopicTransactions, BotTopic("dca")},
+			wantTopics: []string{TopicBalances, BotTopic("grid"), TopicTransactions}, wantConfirm: true,
+		},
+		{
+			name: "another wallet's connection", addr: "0xdef",
+			subscribe:  []string{TopicTransactions},
+			wantTopics: []string{TopicBalances, BotTopic("grid")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := newTestHub(4, 3)
+			conn, _ := hub.Connect("0xabc", []string{TopicBalances, BotTopic("grid")})
+
+			if err := hub.Update(context.Background(), conn.Id, tt.addr, tt.subscribe, tt.unsubscribe); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+
+			topics := conn.Topics()
+			sort.Strings(topics)
+			if !reflect.DeepEqual(topics, tt.wantTopics) {
+				t.Errorf("Topics() = %v, want %v", topics, tt.wantTopics)
+			}
+			msgs := drain(conn)
+			if got := len(msgs) == 1 && msgs[0].Event == EventSubscriptions; got != tt.wantConfirm {
+				t.Errorf("confirmation = %v, want %v", msgs, tt.wantConfirm)
+			}
+			if tt.wantConfirm {
+				var data struct{ Topics []string }
+				json.Unmarshal(msgs[0].Data, &data)
+				if len(data.Topics) != len(tt.wantTopics) {
+					t.Errorf("confirmed topics = %v, want %v", data.Topics, tt.wantTopics)
+				}
+			}
+		})
+	}
+}
+
+func TestHubUpdateRejectsInvalidTopic(t *testing.T) {
+	hub := newTestHub(4, 4)
+	conn, _ := hub.Connect("0xabc", nil)
+	if err := hub.Update(context.Background(), conn.Id, "0xabc", []string{"orders"}, nil); err != ErrInvalidTopic {
+		t.Errorf("Update() error = %v, want ErrInvalidTopic", err)
+	}
+}
+
+func TestLocalBroker(t *testing.T) {
+	broker := NewLocalBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan string, 1)
+	go broker.Run(ctx, func(payload []byte) {
+		received <- string(payload)
+	})
+	if err := broker.Publish(ctx, []byte("hello")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	select {
+	case got := <-received:
+		if got != "hello" {
+			t.Errorf("delivered %q, want hello", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("payload was not delivered")
+	}
+}
@@ -1,19 +1,56 @@
 package svc
 
 import (
+	"fmt"
+	"net/http"
+
+	"wata-bot-BE/internal/chain"
 	"wata-bot-BE/internal/config"
+	"wata-bot-BE/internal/event"
+	"wata-bot-BE/internal/health"
+	"wata-bot-BE/internal/middleware"
 	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/notify"
+	"wata-bot-BE/internal/ratelimit"
+	"wata-bot-BE/internal/stream"
+	"wata-bot-BE/internal/webhook"
 
 	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/redis"
 	"github.com/zeromicro/go-zero/core/stores/sqlx"
+	"github.com/zeromicro/go-zero/rest"
 )
 
 type ServiceContext struct {
-	Config                   config.Config
-	UserModel                model.UserModel
-	BotModel                 model.BotModel
-	UserBotSubscriptionModel model.UserBotSubscriptionModel
-	TransactionModel         model.TransactionModel
+	Config                      config.Config
+	UserModel                   model.UserModel
+	BotModel                    model.BotModel
+	UserBotSubscriptionModel    model.UserBotSubscriptionModel
+	TransactionModel            model.TransactionModel
+	BotPerformanceSnapshotModel model.BotPerformanceSnapshotModel
+	BotTradeModel               model.BotTradeModel
+	BotWaitlistModel            model.BotWaitlistModel
+	BotVersionModel             model.BotVersionModel
+	BotChangeNoticeModel        model.BotChangeNoticeModel
+	NotificationModel           model.NotificationModel
+	NotificationPreferenceModel model.NotificationPreferenceModel
+	NotificationDeliveryModel   model.NotificationDeliveryModel
+	Notifiers                   map[string]notify.Sender // Sender per external channel
+	OutboxModel                 model.OutboxModel
+	EventBus                    *event.Bus
+	WebhookEndpointModel        model.WebhookEndpointModel
+	WebhookDeliveryModel        model.WebhookDeliveryModel
+	WebhookSender               *webhook.Sender
+	Stream                      *stream.Hub
+	EngineAuth                  rest.Middleware
+	EngineSignature             rest.Middleware
+	AdminAuth                   rest.Middleware
+	UserAuth                    rest.Middleware
+	ApiRateLimit                rest.Middleware
+	AuthRateLimit               rest.Middleware
+	BalanceRateLimit            rest.Middleware
+	Health                      *health.Checker
+	Chains                      *chain.Registry
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
@@ -27,11 +64,130 @@ func NewServiceContext(c config.Config) *ServiceContext {
 		cacheConf = make([]cache.NodeConf, 0)
 	}
 
+	rateLimitStore := newRateLimitStore(c)
+
 	return &ServiceContext{
-		Config:                   c,
-		UserModel:                model.NewUserModel(sqlConn, cacheConf),
-		BotModel:                 model.NewBotModel(sqlConn, cacheConf),
-		UserBotSubscriptionModel: model.NewUserBotSubscriptionModel(sqlConn, cacheConf),
-		TransactionModel:         model.NewTransactionModel(sqlConn, cacheConf),
+		Config:                      c,
+		UserModel:                   model.NewUserModel(sqlConn, cacheConf),
+		BotModel:                    model.NewBotModel(sqlConn, cacheConf),
+		UserBotSubscriptionModel:    model.NewUserBotSubscriptionModel(sqlConn, cacheConf),
+		TransactionModel:            model.NewTransactionModel(sqlConn, cacheConf),
+		BotPerformanceSnapshotModel: model.NewBotPerformanceSnapshotModel(sqlConn, cacheConf),
+		BotTradeModel:               model.NewBotTradeModel(sqlConn, cacheConf),
+		BotWaitlistModel:            model.NewBotWaitlistModel(sqlConn, cacheConf),
+		BotVersionModel:             model.NewBotVersionModel(sqlConn, cacheConf),
+		BotChangeNoticeModel:        model.NewBotChangeNoticeModel(sqlConn, cacheConf),
+		NotificationModel:           model.NewNotificationModel(sqlConn, cacheConf),
+		NotificationPreferenceModel: model.NewNotificationPreferenceModel(sqlConn, cacheConf),
+		NotificationDeliveryModel:   model.NewNotificationDeliveryModel(sqlConn, cacheConf),
+		Notifiers:                   newNotifiers(c.Notification),
+		OutboxModel:                 model.NewOutboxModel(sqlConn, cacheConf),
+		EventBus:                    newEventBus(c.Outbox),
+		WebhookEndpointModel:        model.NewWebhookEndpointModel(sqlConn, cacheConf),
+		WebhookDeliveryModel:        model.NewWebhookDeliveryModel(sqlConn, cacheConf),
+		WebhookSender:               webhook.NewSender(c.Webhooks.Timeout),
+		Stream:                      newStreamHub(c),
+		EngineAuth:                  middleware.NewEngineAuthMiddleware(c.Engine.ApiKey).Handle,
+		EngineSignature:             middleware.NewEngineSignatureMiddleware(c.Engine.HmacSecret).Handle,
+		AdminAuth:                   middleware.NewAdminAuthMiddleware(c.Admin.ApiKey).Handle,
+		UserAuth:                    middleware.NewUserAuthMiddleware(c.JWTSecret).Handle,
+		ApiRateLimit:                newRateLimit(c, rateLimitStore, "api", ratelimit.PolicyConf(c.RateLimit.Api), middleware.AddressFromToken),
+		AuthRateLimit:               newRateLimit(c, rateLimitStore, "auth", ratelimit.PolicyConf(c.RateLimit.Auth), middleware.AddressFromToken),
+		BalanceRateLimit:            newRateLimit(c, rateLimitStore, "balance", ratelimit.PolicyConf(c.RateLimit.Balance), middleware.AddressFromBody),
+		Health:                      newHealthChecker(c, sqlConn),
+		Chains:                      chain.NewRegistry(c.Chains),
+	}
+}
+
+// newHealthChecker checks MySQL, every Redis cache node and the RPC node of
+// every chain that has one
+func newHealthChecker(c config.Config, sqlConn sqlx.SqlConn) *health.Checker {
+	checker := health.NewChecker(c.Health.Timeout)
+	checker.Add("mysql", health.MySQL(sqlConn))
+	for _, node := range c.Cache {
+		checker.Add("redis:"+node.Host, health.Redis(redis.MustNewRedis(node.RedisConf)))
+	}
+	rpcClient := &http.Client{Timeout: c.Health.Timeout}
+	for _, ch := range c.Chains {
+		if ch.RpcUrl != "" {
+			checker.Add(fmt.Sprintf("chain:%d", ch.Id), health.EVM(rpcClient, ch.RpcUrl, ch.Id))
+		}
+	}
+	return checker
+}
+
+// newNotifiers builds the sender for each external channel, or fakes that only
+// record deliveries when configured
+func newNotifiers(c config.NotificationConf) map[string]notify.Sender {
+	if c.Fake {
+		fake := notify.NewFakeSender()
+		return map[string]notify.Sender{
+			notify.ChannelEmail:    fake,
+			notify.ChannelTelegram: fake,
+			notify.ChannelWebhook:  fake,
+		}
+	}
+	return map[string]notify.Sender{
+		notify.ChannelEmail:    notify.NewSMTPSender(c.SMTP),
+		notify.ChannelTelegram: notify.NewTelegramSender(c.Telegram),
+		notify.ChannelWebhook:  notify.NewWebhookSender(c.Webhook),
+	}
+}
+
+// newEventBus creates the domain event bus with the configured external sinks.
+// In-process handlers are subscribed by logic.RegisterEventHandlers.
+func newEventBus(c config.OutboxConf) *event.Bus {
+	bus := event.NewBus()
+	if c.LogEvents {
+		bus.AddSink(event.LogSink{})
+	}
+	for _, sink := range c.Sinks {
+		bus.AddSink(event.NewHTTPSink(sink))
+	}
+	return bus
+}
+
+// newRateLimitStore returns the Redis shared by the rate limiters, or nil to
+// count in memory
+func newRateLimitStore(c config.Config) *redis.Redis {
+	redisConf := c.RateLimit.Redis
+	if redisConf.Host == "" && len(c.Cache) > 0 {
+		redisConf = c.Cache[0].RedisConf
+	}
+	if !c.RateLimit.Enabled || redisConf.Host == "" {
+		return nil
+	}
+	return redis.MustNewRedis(redisConf)
+}
+
+// newRateLimit creates the middleware enforcing one route group's policy
+func newRateLimit(c config.Config, store *redis.Redis, name string, policy ratelimit.PolicyConf,
+	addressSource middleware.AddressSource) rest.Middleware {
+	if !c.RateLimit.Enabled {
+		return func(next http.HandlerFunc) http.HandlerFunc {
+			return next
+		}
+	}
+	proxyHops := 0
+	if c.RateLimit.TrustProxy {
+		proxyHops = c.RateLimit.ProxyHops
+	}
+	return middleware.NewRateLimitMiddleware(store, c.RateLimit.KeyPrefix, name, policy, addressSource, proxyHops, c.JWTSecret).Handle
+}
+
+// newStreamHub creates the real-time update hub, fanning out over Redis so
+// that clients connected to any replica receive every update
+func newStreamHub(c config.Config) *stream.Hub {
+	redisConf := c.Stream.Redis
+	if redisConf.Host == "" && len(c.Cache) > 0 {
+		redisConf = c.Cache[0].RedisConf
+	}
+
+	var broker stream.Broker
+	if redisConf.Host != "" {
+		broker = stream.NewRedisBroker(redisConf, c.Stream.Channel)
+	} else {
+		broker = stream.NewLocalBroker()
 	}
+	return stream.NewHub(broker, c.Stream.BufferSize, c.Stream.MaxTopics)
 }
@@ -1,6 +1,7 @@
-// Code generated by goctl. DO NOT EDIT.
 package types
 
+import "wata-bot-BE/internal/address"
+
 type HelloReq struct {
 	Name string `json:"name,optional"`
 }
@@ -10,14 +11,16 @@ type HelloResp struct {
 }
 
 type WalletAuthReq struct {
-	Signature  string `json:"signature"`
-	Message    string `json:"message"`
+	Signature  string `json:"signature" validate:"required"`
+	Message    string `json:"message" validate:"required"`
 	InviteCode string `json:"invite_code"`
+	ChainId    int64  `json:"chain_id,optional"`
 }
 
 type WalletAuthNotSignReq struct {
-	Address      string `json:"address"`
-	ReferralCode string `json:"referral_code"`
+	Address      address.Address `json:"address" validate:"address"`
+	ReferralCode string          `json:"referral_code"`
+	ChainId      int64           `json:"chain_id,optional"`
 }
 
 type WalletAuthData struct {
@@ -34,8 +37,16 @@ type WalletAuthResp struct {
 }
 
 type ErrorResp struct {
-	ErrorCode string `json:"error_code"`
-	Message   string `json:"message"`
+	ErrorCode string       `json:"error_code"`
+	Message   string       `json:"message"`
+	RequestId string       `json:"request_id,omitempty"`
+	Details   []FieldError `json:"details,omitempty"` // Fields that failed validation
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
 }
 
 type BotMetrics struct {
@@ -65,6 +76,9 @@ type Bot struct {
 	Author                string     `json:"author"`
 	Description           string     `json:"description"`
 	IsActive              bool       `json:"isActive"`
+	MaxAllocation         float64    `json:"maxAllocation,omitempty"`  // 0 means no cap
+	MaxSubscribers        int        `json:"maxSubscribers,omitempty"` // 0 means no cap
+	Version               int        `json:"version"`                  // Current definition version
 	Metrics               BotMetrics `json:"metrics"`
 }
 
@@ -73,28 +87,546 @@ type BotsResp struct {
 	Data    []Bot  `json:"data"`
 }
 
+type BotDetailReq struct {
+	Id      string          `path:"id" validate:"botid"`
+	Address address.Address `form:"address,optional" validate:"omitempty,address"`
+}
+
+type BotStats struct {
+	SubscriberCount  int64  `json:"subscriberCount"`
+	TotalValueLocked string `json:"totalValueLocked"`
+	Full             bool   `json:"full"` // New subscribers must join the waitlist
+	WaitlistLength   int64  `json:"waitlistLength"`
+}
+
+type BotSubscriptionState struct {
+	Subscribed      bool   `json:"subscribed"`
+	ActivePositions int    `json:"activePositions,omitempty"`
+	DurationDays    int    `json:"durationDays,omitempty"`
+	Amount          string `json:"amount,omitempty"`
+	SubscribedAt    string `json:"subscribedAt,omitempty"`
+}
+
+type BotDetailData struct {
+	Bot          Bot                   `json:"bot"`
+	Stats        BotStats              `json:"stats"`
+	Subscription *BotSubscriptionState `json:"subscription,omitempty"`
+}
+
+type BotDetailResp struct {
+	Message string        `json:"message"`
+	Data    BotDetailData `json:"data"`
+}
+
+type BotChangelogReq struct {
+	Id string `path:"id" validate:"botid"`
+}
+
+type BotTermChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+type BotTerms struct {
+	RiskLevel             string `json:"riskLevel"`
+	DurationDays          []int  `json:"durationDays"`
+	ExpectedReturnPercent int    `json:"expectedReturnPercent"`
+	AprDisplay            string `json:"aprDisplay"`
+	MinInvestment         int    `json:"minInvestment"`
+	MaxInvestment         int    `json:"maxInvestment"`
+	LockupPeriod          string `json:"lockupPeriod"`
+	ExpectedReturn        string `json:"expectedReturn"`
+	TradingPair           string `json:"tradingPair"`
+}
+
+type BotVersionEntry struct {
+	Version   int             `json:"version"`
+	CreatedAt string          `json:"createdAt"`
+	Changes   []BotTermChange `json:"changes"` // Changes from the previous version
+	Terms     BotTerms        `json:"terms"`
+}
+
+type BotChangelogResp struct {
+	Message string            `json:"message"`
+	Data    []BotVersionEntry `json:"data"`
+}
+
+type PerformanceSnapshot struct {
+	Date          string  `json:"date"` // YYYY-MM-DD
+	Pnl           float64 `json:"pnl"`
+	RoiPercent    float64 `json:"roiPercent"` // Daily return in percent
+	Trades        int     `json:"trades"`
+	WinningTrades int     `json:"winningTrades"`
+}
+
+type IngestPerformanceReq struct {
+	BotId     string                `path:"id" validate:"botid"`
+	Snapshots []PerformanceSnapshot `json:"snapshots"`
+}
+
+type IngestPerformanceResp struct {
+	Message string     `json:"message"`
+	Data    BotMetrics `json:"data"`
+}
+
+type BotPerformanceReq struct {
+	BotId       string `path:"id" validate:"botid"`
+	Granularity string `form:"granularity,default=day,options=day|week|month"`
+	From        string `form:"from,optional"` // YYYY-MM-DD
+	To          string `form:"to,optional"`   // YYYY-MM-DD
+}
+
+type PerformancePoint struct {
+	Period               string  `json:"period"` // First day of the bucket, YYYY-MM-DD
+	Pnl                  float64 `json:"pnl"`
+	RoiPercent           float64 `json:"roiPercent"`
+	CumulativeRoiPercent float64 `json:"cumulativeRoiPercent"`
+	Trades               int     `json:"trades"`
+	WinningTrades        int     `json:"winningTrades"`
+}
+
+type BotPerformanceData struct {
+	BotId       string             `json:"botId"`
+	Granularity string             `json:"granularity"`
+	Points      []PerformancePoint `json:"points"`
+}
+
+type BotPerformanceResp struct {
+	Message string             `json:"message"`
+	Data    BotPerformanceData `json:"data"`
+}
+
+type BotTrade struct {
+	ExternalId string  `json:"externalId"`
+	Pair       string  `json:"pair"`
+	Side       string  `json:"side"` // long, short
+	EntryPrice string  `json:"entryPrice"`
+	ExitPrice  string  `json:"exitPrice"`
+	Size       string  `json:"size"`
+	Pnl        float64 `json:"pnl"`
+	OpenedAt   string  `json:"openedAt"` // RFC3339
+	ClosedAt   string  `json:"closedAt"` // RFC3339
+}
+
+type IngestTradesReq struct {
+	BotId  string     `path:"id" validate:"botid"`
+	Trades []BotTrade `json:"trades"`
+}
+
+type IngestTradesData struct {
+	Inserted   int        `json:"inserted"`
+	Duplicates int        `json:"duplicates"`
+	Metrics    BotMetrics `json:"metrics"`
+}
+
+type IngestTradesResp struct {
+	Message string           `json:"message"`
+	Data    IngestTradesData `json:"data"`
+}
+
+type BotTradesReq struct {
+	BotId    string `path:"id" validate:"botid"`
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=20"`
+}
+
+type BotTradesData struct {
+	Items    []BotTrade `json:"items"`
+	Page     int        `json:"page"`
+	PageSize int        `json:"pageSize"`
+	Total    int64      `json:"total"`
+}
+
+type BotTradesResp struct {
+	Message string        `json:"message"`
+	Data    BotTradesData `json:"data"`
+}
+
 type SubscribeBotReq struct {
-	Address      string `json:"address"`
-	BotId        string `json:"bot_id"`
-	DurationDays int    `json:"duration_days"`
+	Address           address.Address `json:"address" validate:"address"`
+	BotId             string          `json:"bot_id" validate:"botid"`
+	DurationDays      int             `json:"duration_days" validate:"duration"`
+	Amount            string          `json:"amount" validate:"amount"`
+	AutoRenew         bool            `json:"auto_renew,optional"`
+	RenewDurationDays int             `json:"renew_duration_days,optional" validate:"omitempty,duration"` // 0 renews with duration_days
+	RenewMode         string          `json:"renew_mode,optional,options=compound|payout"`
 }
 
 type UnsubscribeBotReq struct {
-	Address string `json:"address"`
-	BotId   string `json:"bot_id"`
+	Address        address.Address `json:"address" validate:"address"`
+	BotId          string          `json:"bot_id" validate:"botid"`
+	SubscriptionId int64           `json:"subscription_id,optional" validate:"min=0"`
 }
 
 type GetUserBotsReq struct {
-	Address string `json:"address"`
+	Address address.Address `json:"address" validate:"address"`
+	Status  string          `json:"status,optional,options=active|cancelled|matured|settled"`
+}
+
+type UpdateAutoRenewReq struct {
+	Address           address.Address `json:"address" validate:"address"`
+	SubscriptionId    int64           `json:"subscription_id" validate:"min=1"`
+	AutoRenew         bool            `json:"auto_renew"`
+	RenewDurationDays int             `json:"renew_duration_days,optional" validate:"omitempty,duration"` // 0 renews with the same duration
+	RenewMode         string          `json:"renew_mode,optional,options=compound|payout"`
+}
+
+type UserBotAutoRenew struct {
+	Enabled      bool   `json:"enabled"`
+	DurationDays int    `json:"durationDays"` // Duration of the next term
+	Mode         string `json:"mode"`         // compound, payout
+}
+
+type AutoRenewResp struct {
+	Message        string           `json:"message"`
+	SubscriptionId int64            `json:"subscription_id"`
+	Data           UserBotAutoRenew `json:"data"`
+}
+
+type UserBotSubscription struct {
+	Id            int64            `json:"id"`
+	Status        string           `json:"status"` // active, cancelled, matured, settled
+	DurationDays  int              `json:"durationDays"`
+	Amount        string           `json:"amount"`
+	StartedAt     string           `json:"startedAt"`
+	MaturesAt     string           `json:"maturesAt"`
+	CancelledAt   string           `json:"cancelledAt,omitempty"`
+	MaturedAt     string           `json:"maturedAt,omitempty"`
+	SettledAt     string           `json:"settledAt,omitempty"`
+	AutoRenew     UserBotAutoRenew `json:"autoRenew"`
+	RenewedFromId int64            `json:"renewedFromId,omitempty"` // Subscription this term renewed
+	BotVersion    int              `json:"botVersion"`              // Bot version the subscription joined under
+	TermsChanged  bool             `json:"termsChanged,omitempty"`  // The bot has moved to a newer version since
+}
+
+type UserBotPosition struct {
+	CurrentValue  string  `json:"currentValue"`
+	UnrealizedPnl string  `json:"unrealizedPnl"`
+	RoiPercent    float64 `json:"roiPercent"`
+	ValuedAt      string  `json:"valuedAt"` // Last snapshot day included, YYYY-MM-DD
+}
+
+type UserBot struct {
+	Bot          Bot                 `json:"bot"`
+	Subscription UserBotSubscription `json:"subscription"`
+	Position     UserBotPosition     `json:"position"`
+}
+
+type UserBotsResp struct {
+	Message string    `json:"message"`
+	Data    []UserBot `json:"data"`
 }
 
 type SubscribeResp struct {
+	Message        string `json:"message"`
+	SubscriptionId int64  `json:"subscription_id,omitempty"`
+	Data           *Bot   `json:"data,omitempty"`
+}
+
+type JoinWaitlistReq struct {
+	Address      address.Address `json:"address" validate:"address"`
+	BotId        string          `json:"bot_id" validate:"botid"`
+	DurationDays int             `json:"duration_days" validate:"duration"`
+	Amount       string          `json:"amount" validate:"amount"`
+}
+
+type LeaveWaitlistReq struct {
+	Address address.Address `json:"address" validate:"address"`
+	BotId   string          `json:"bot_id" validate:"botid"`
+}
+
+type GetWaitlistReq struct {
+	Address address.Address `json:"address" validate:"address"`
+}
+
+type WaitlistEntry struct {
+	Id             int64  `json:"id"`
+	BotId          string `json:"botId"`
+	Amount         string `json:"amount"`
+	DurationDays   int    `json:"durationDays"`
+	Status         string `json:"status"`             // waiting, offered
+	Position       int64  `json:"position,omitempty"` // 1-based queue position while waiting
+	JoinedAt       string `json:"joinedAt"`
+	OfferedAt      string `json:"offeredAt,omitempty"`
+	OfferExpiresAt string `json:"offerExpiresAt,omitempty"` // Subscribe before this to take the slot
+}
+
+type WaitlistResp struct {
+	Message string         `json:"message"`
+	Data    *WaitlistEntry `json:"data,omitempty"`
+}
+
+type WaitlistsResp struct {
+	Message string          `json:"message"`
+	Data    []WaitlistEntry `json:"data"`
+}
+
+type GetBotNoticesReq struct {
+	Address    address.Address `json:"address" validate:"address"`
+	UnreadOnly bool            `json:"unread_only,optional"`
+}
+
+type BotNotice struct {
+	Id          int64           `json:"id"`
+	BotId       string          `json:"botId"`
+	BotName     string          `json:"botName"`
+	FromVersion int             `json:"fromVersion"`
+	ToVersion   int             `json:"toVersion"`
+	Changes     []BotTermChange `json:"changes"`
+	Read        bool            `json:"read"`
+	CreatedAt   string          `json:"createdAt"`
+}
+
+type BotNoticesResp struct {
+	Message string      `json:"message"`
+	Data    []BotNotice `json:"data"`
+}
+
+type MarkBotNoticesReadReq struct {
+	Address address.Address `json:"address" validate:"address"`
+	Ids     []int64         `json:"ids,optional"` // Empty marks every notice as read
+}
+
+type MarkBotNoticesReadResp struct {
+	Message string `json:"message"`
+	Updated int64  `json:"updated"`
+}
+
+type GetNotificationsReq struct {
+	Address    address.Address `json:"-"` // Set from the access token
+	UnreadOnly bool            `json:"unread_only,optional"`
+}
+
+type Notification struct {
+	Id        int64             `json:"id"`
+	Event     string            `json:"event"`
+	Title     string            `json:"title"`
+	Body      string            `json:"body"`
+	Data      map[string]string `json:"data,omitempty"`
+	Read      bool              `json:"read"`
+	CreatedAt string            `json:"createdAt"`
+}
+
+type NotificationsResp struct {
+	Message string         `json:"message"`
+	Unread  int64          `json:"unread"`
+	Data    []Notification `json:"data"`
+}
+
+type MarkNotificationsReadReq struct {
+	Address address.Address `json:"-"`            // Set from the access token
+	Ids     []int64         `json:"ids,optional"` // Empty marks every notification as read
+}
+
+type MarkNotificationsReadResp struct {
+	Message string `json:"message"`
+	Updated int64  `json:"updated"`
+}
+
+type GetNotificationPreferencesReq struct {
+	Address address.Address `json:"-"` // Set from the access token
+}
+
+type NotificationPreference struct {
+	Channel string   `json:"channel"` // email, telegram or webhook
+	Target  string   `json:"target"`  // Email address, Telegram chat id or webhook URL
+	Enabled bool     `json:"enabled"`
+	Events  []string `json:"events"` // Empty sends every event
+}
+
+type NotificationPreferencesResp struct {
+	Message string                   `json:"message"`
+	Events  []string                 `json:"events"` // Events that can be subscribed to
+	Data    []NotificationPreference `json:"data"`
+}
+
+type UpdateNotificationPreferenceReq struct {
+	Address address.Address `json:"-"` // Set from the access token
+	Channel string          `json:"channel" validate:"required"`
+	Target  string          `json:"target,optional"` // Keeps the current target when empty
+	Enabled bool            `json:"enabled"`
+	Events  []string        `json:"events,optional"`
+}
+
+type NotificationPreferenceResp struct {
+	Message string                 `json:"message"`
+	Data    NotificationPreference `json:"data"`
+}
+
+type CreateWebhookReq struct {
+	Url         string   `json:"url" validate:"required,max=2048"`
+	Events      []string `json:"events,optional"` // Empty subscribes to every event
+	Description string   `json:"description,optional" validate:"max=255"`
+}
+
+type UpdateWebhookReq struct {
+	Id           int64    `path:"id" validate:"min=1"`
+	Url          string   `json:"url" validate:"required,max=2048"`
+	Events       []string `json:"events,optional"`
+	Description  string   `json:"description,optional" validate:"max=255"`
+	Active       bool     `json:"active"`
+	RotateSecret bool     `json:"rotateSecret,optional"`
+}
+
+type WebhookIdReq struct {
+	Id int64 `path:"id" validate:"min=1"`
+}
+
+type WebhookEndpoint struct {
+	Id          int64    `json:"id"`
+	Url         string   `json:"url"`
+	Events      []string `json:"events"` // Empty receives every event
+	Description string   `json:"description"`
+	Active      bool     `json:"active"`
+	Secret      string   `json:"secret,omitempty"` // Only returned when created or rotated
+	CreatedAt   string   `json:"createdAt"`
+	UpdatedAt   string   `json:"updatedAt"`
+}
+
+type WebhookEndpointResp struct {
+	Message string          `json:"message"`
+	Data    WebhookEndpoint `json:"data"`
+}
+
+type WebhookEndpointsResp struct {
+	Message string            `json:"message"`
+	Events  []string          `json:"events"` // Event types that can be subscribed to
+	Data    []WebhookEndpoint `json:"data"`
+}
+
+type DeleteWebhookResp struct {
+	Message string `json:"message"`
+}
+
+type WebhookDeliveriesReq struct {
+	Id       int64  `path:"id" validate:"min=1"`
+	Status   string `form:"status,optional"` // pending, delivered or failed; empty for all
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"pageSize,default=20"`
+}
+
+type WebhookDelivery struct {
+	Id             int64  `json:"id"`
+	EndpointId     int64  `json:"endpointId"`
+	EventId        int64  `json:"eventId"`
+	EventType      string `json:"eventType"`
+	Status         string `json:"status"` // pending, delivered or failed
+	Attempts       int    `json:"attempts"`
+	LastStatusCode int    `json:"lastStatusCode"`
+	LastError      string `json:"lastError"`
+	NextAttemptAt  string `json:"nextAttemptAt,omitempty"` // Only while pending
+	DeliveredAt    string `json:"deliveredAt,omitempty"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+type WebhookDeliveriesData struct {
+	Items    []WebhookDelivery `json:"items"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"pageSize"`
+	Total    int64             `json:"total"`
+}
+
+type WebhookDeliveriesResp struct {
+	Message string                `json:"message"`
+	Data    WebhookDeliveriesData `json:"data"`
+}
+
+type WebhookDeliveryAttempt struct {
+	StatusCode int    `json:"statusCode"` // 0 when no response was received
+	Error      string `json:"error"`
+	DurationMs int64  `json:"durationMs"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+type WebhookDeliveryDetail struct {
+	Delivery WebhookDelivery          `json:"delivery"`
+	Payload  string                   `json:"payload"` // Signed JSON body
+	Attempts []WebhookDeliveryAttempt `json:"attempts"`
+}
+
+type WebhookDeliveryDetailResp struct {
+	Message string                `json:"message"`
+	Data    WebhookDeliveryDetail `json:"data"`
+}
+
+type WebhookDeliveryResp struct {
+	Message string          `json:"message"`
+	Data    WebhookDelivery `json:"data"`
+}
+
+type StreamReq struct {
+	Address address.Address `json:"-"`               // Set from the access token
+	Topics  string          `form:"topics,optional"` // Comma separated: balances, transactions, bot:<id>
+}
+
+type UpdateStreamSubscriptionsReq struct {
+	Address      address.Address `json:"-"` // Set from the access token
+	ConnectionId string          `json:"connectionId" validate:"required"`
+	Subscribe    []string        `json:"subscribe,optional"`
+	Unsubscribe  []string        `json:"unsubscribe,optional"`
+}
+
+type UpdateStreamSubscriptionsResp struct {
 	Message string `json:"message"`
-	Data    *Bot   `json:"data,omitempty"`
+}
+
+type DependencyCheck struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type HealthResp struct {
+	Status string            `json:"status"`
+	Checks []DependencyCheck `json:"checks,omitempty"`
+}
+
+type Chain struct {
+	Id            int64             `json:"id"`
+	Name          string            `json:"name"`
+	Confirmations int               `json:"confirmations"`
+	Tokens        map[string]string `json:"tokens"`
+	Default       bool              `json:"default"`
+}
+
+type ChainsResp struct {
+	Message string  `json:"message"`
+	Data    []Chain `json:"data"`
+}
+
+type StreamConnected struct {
+	ConnectionId string   `json:"connectionId"`
+	Topics       []string `json:"topics"`
+}
+
+type StreamBalances struct {
+	WataBalance string `json:"wataBalance"`
+	UsdtBalance string `json:"usdtBalance"`
+}
+
+type StreamTransaction struct {
+	TransactionId int64  `json:"transactionId"`
+	Type          string `json:"type"` // deposit or withdraw
+	ChainId       int64  `json:"chainId"`
+	Currency      string `json:"currency"`
+	Amount        string `json:"amount"`
+	BalanceAfter  string `json:"balanceAfter"`
+	Status        string `json:"status"`
+	TxHash        string `json:"txHash,omitempty"`
+}
+
+type StreamBotMetrics struct {
+	BotId       string  `json:"botId"`
+	Roi30d      string  `json:"roi30d"`
+	WinRate     string  `json:"winRate"`
+	TotalTrades int     `json:"totalTrades"`
+	Pnl30d      float64 `json:"pnl30d"`
 }
 
 type GetProfileReq struct {
-	Address string `json:"address"`
+	Address address.Address `json:"address" validate:"address"`
 }
 
 type UserProfileData struct {
@@ -105,6 +637,8 @@ type UserProfileData struct {
 	WataBalance  string `json:"wata_balance"`
 	UsdtBalance  string `json:"usdt_balance"`
 	Role         string `json:"role"`
+	Language     string `json:"language"` // Empty when notifications use the default language
+	ChainId      int64  `json:"chain_id"`
 	CreatedAt    string `json:"created_at"`
 	UpdatedAt    string `json:"updated_at"`
 }
@@ -114,22 +648,30 @@ type ProfileResp struct {
 	Data    UserProfileData `json:"data"`
 }
 
+type UpdateLanguageReq struct {
+	Address  address.Address `json:"address" validate:"address"`
+	Language string          `json:"language,optional"` // en or vi; empty clears the preference
+}
+
 type DepositReq struct {
-	Address  string `json:"address"`
-	Currency string `json:"currency"`
-	Amount   string `json:"amount"`
-	TxHash   string `json:"tx_hash,omitempty"`
+	Address  address.Address `json:"address" validate:"address"`
+	Currency string          `json:"currency" validate:"currency"`
+	Amount   string          `json:"amount" validate:"amount"`
+	TxHash   string          `json:"tx_hash,omitempty"`
+	ChainId  int64           `json:"chain_id,optional"` // The user's chain when omitted
 }
 
 type WithdrawReq struct {
-	Address  string `json:"address"`
-	Currency string `json:"currency"`
-	Amount   string `json:"amount"`
-	TxHash   string `json:"tx_hash,omitempty"`
+	Address  address.Address `json:"address" validate:"address"`
+	Currency string          `json:"currency" validate:"currency"`
+	Amount   string          `json:"amount" validate:"amount"`
+	TxHash   string          `json:"tx_hash,omitempty"`
+	ChainId  int64           `json:"chain_id,optional"` // The user's chain when omitted
 }
 
 type TransactionData struct {
 	Type          string `json:"type"`
+	ChainId       int64  `json:"chain_id"`
 	Currency      string `json:"currency"`
 	Amount        string `json:"amount"`
 	BalanceBefore string `json:"balance_before"`
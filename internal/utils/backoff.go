@@ -0,0 +1,19 @@
+package utils
+
+import "time"
+
+// Backoff returns the delay before retry number attempt (1-based): base doubled
+// for every earlier attempt, capped at max
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,330 @@
+package validate
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"wata-bot-BE/internal/address"
+	"wata-bot-BE/internal/i18n"
+	"wata-bot-BE/internal/model"
+)
+
+// Tag is the struct tag listing a field's rules, separated by commas, such as
+// `validate:"omitempty,address"`. Rules:
+//
+//	required   the field is not empty
+//	omitempty  the other rules are skipped when the field is empty
+//	address    0x followed by 40 hex characters, in any case; fields of a
+//	           parsed request are set to the EIP-55 checksummed form
+//	amount     a positive decimal such as 10.5, without sign or exponent
+//	currency   wata or usdt, in any case
+//	duration   a term of 1 to MaxDurationDays days
+//	botid      1 to 20 letters, digits, '-' or '_'
+//	min=N      numbers are at least N
+//	max=N      numbers are at most N; strings and lists have at most N items
+//
+// Struct fields and lists of structs are validated recursively. The tags of a
+// type are checked the first time it is validated, see CheckTag.
+const Tag = "validate"
+
+// Bounds of the rules
+const (
+	MaxDurationDays   = 3650
+	maxAmountDecimals = 18
+	maxAmountDigits   = 30
+)
+
+var (
+	amountPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+	botIdPattern  = regexp.MustCompile(`^[A-Za-z0-9_-]{1,20}$`)
+)
+
+// ruleCodes gives the error code of rules that have one of their own; other
+// rules report model.ErrCodeInvalidRequest
+var ruleCodes = map[string]string{
+	"address":  model.ErrCodeInvalidAddressFormat,
+	"amount":   model.ErrCodeInvalidAmount,
+	"currency": model.ErrCodeInvalidCurrency,
+}
+
+// rules lists every rule that may fail, for checking translations
+var rules = []string{"required", "address", "amount", "currency", "duration", "botid", "min", "max"}
+
+// tagErrors caches the tagError of every type validated so far
+var tagErrors sync.Map
+
+type tagError struct {
+	err error
+}
+
+// Validator validates every request parsed by httpx.Parse; register it with
+// httpx.SetValidator
+type Validator struct{}
+
+func (Validator) Validate(_ *http.Request, data any) error {
+	return Struct(data)
+}
+
+// Struct checks the fields of v, a struct or a pointer to one, against their
+// rules, and normalizes the addresses of a pointer's fields. It returns nil or
+// a *model.APIError listing every failed field: with the failed rule's code
+// when only one field failed, otherwise with model.ErrCodeInvalidRequest.
+// Types whose tags CheckTag rejects are not validated; the tag error is
+// returned instead.
+func Struct(v any) error {
+	if t := reflect.TypeOf(v); t != nil {
+		if err := checkTags(t); err != nil {
+			return err
+		}
+	}
+
+	var fields []model.FieldError
+	checkStruct(reflect.ValueOf(v), "", &fields)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	code := model.ErrCodeInvalidRequest
+	if len(fields) == 1 {
+		if c, ok := ruleCodes[fields[0].Rule]; ok {
+			code = c
+		}
+	}
+	apiErr := model.NewAPIErrorFromCode(code)
+	apiErr.Fields = fields
+	return apiErr
+}
+
+// Message returns the message of a failed field in lang
+func Message(lang string, field model.FieldError) string {
+	msg, _ := i18n.T(lang, messageKey(field.Rule))
+	if field.Param != "" {
+		msg = fmt.Sprintf(msg, field.Param)
+	}
+	return msg
+}
+
+// MessageKeys returns the i18n keys of the rule messages, for checking that
+// each is translated
+func MessageKeys() []string {
+	keys := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		keys = append(keys, messageKey(rule))
+	}
+	return keys
+}
+
+func messageKey(rule string) string {
+	return "validation." + rule
+}
+
+// CheckTag returns an error when a validate tag uses an unknown rule or a min
+// or max parameter that is not an integer
+func CheckTag(tag string) error {
+	for _, r := range strings.Split(tag, ",") {
+		rule, param, _ := strings.Cut(r, "=")
+		switch rule {
+		case "omitempty", "required", "address", "amount", "currency", "duration", "botid":
+		case "min", "max":
+			if _, err := strconv.ParseInt(param, 10, 64); err != nil {
+				return fmt.Errorf("bad %s parameter %q", rule, param)
+			}
+		default:
+			return fmt.Errorf("unknown rule %q", rule)
+		}
+	}
+	return nil
+}
+
+// checkTags checks the tags of t and of the structs it holds, once per type
+func checkTags(t reflect.Type) error {
+	if cached, ok := tagErrors.Load(t); ok {
+		return cached.(tagError).err
+	}
+	err := checkTypeTags(t, make(map[reflect.Type]bool))
+	tagErrors.Store(t, tagError{err: err})
+	return err
+}
+
+func checkTypeTags(t reflect.Type, seen map[reflect.Type]bool) error {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return nil
+	}
+	seen[t] = true
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		if tag := sf.Tag.Get(Tag); tag != "" {
+			if err := CheckTag(tag); err != nil {
+				return fmt.Errorf("validate: %s.%s: %w", t.Name(), sf.Name, err)
+			}
+		}
+		if err := checkTypeTags(sf.Type, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkStruct(v reflect.Value, prefix string, fields *[]model.FieldError) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := prefix + fieldName(sf)
+		fv := v.Field(i)
+
+		if tag := sf.Tag.Get(Tag); tag != "" {
+			if rule, param, ok := checkField(fv, tag); !ok {
+				field := model.FieldError{Field: name, Rule: rule, Param: param}
+				field.Message = Message(i18n.Default, field)
+				*fields = append(*fields, field)
+				continue
+			}
+		}
+
+		switch fv.Kind() {
+		case reflect.Struct, reflect.Pointer:
+			checkStruct(fv, name+".", fields)
+		case reflect.Slice, reflect.Array:
+			for j := 0; j < fv.Len(); j++ {
+				checkStruct(fv.Index(j), fmt.Sprintf("%s[%d].", name, j), fields)
+			}
+		}
+	}
+}
+
+// checkField returns the first rule of tag that v fails, with its parameter.
+// The tag has passed CheckTag.
+func checkField(v reflect.Value, tag string) (rule, param string, ok bool) {
+	names := strings.Split(tag, ",")
+	for _, r := range names {
+		if r == "omitempty" && v.IsZero() {
+			return "", "", true
+		}
+	}
+
+	for _, r := range names {
+		rule, param, _ = strings.Cut(r, "=")
+		if !checkRule(v, rule, param) {
+			return rule, param, false
+		}
+	}
+	return "", "", true
+}
+
+func checkRule(v reflect.Value, rule, param string) bool {
+	switch rule {
+	case "omitempty":
+		return true
+	case "required":
+		if v.Kind() == reflect.String {
+			return strings.TrimSpace(v.String()) != ""
+		}
+		return !v.IsZero()
+	case "address":
+		if v.Kind() != reflect.String {
+			return false
+		}
+		parsed, err := address.Parse(v.String())
+		if err != nil {
+			return false
+		}
+		if v.CanSet() {
+			v.SetString(parsed.String())
+		}
+		return true
+	case "amount":
+		return v.Kind() == reflect.String && isAmount(v.String())
+	case "currency":
+		if v.Kind() != reflect.String {
+			return false
+		}
+		currency := strings.ToLower(v.String())
+		return currency == "wata" || currency == "usdt"
+	case "duration":
+		return isInt(v) && v.Int() >= 1 && v.Int() <= MaxDurationDays
+	case "botid":
+		return v.Kind() == reflect.String && botIdPattern.MatchString(v.String())
+	case "min", "max":
+		limit, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return false
+		}
+		var n int64
+		switch {
+		case isInt(v):
+			n = v.Int()
+		case v.Kind() == reflect.String:
+			n = int64(len([]rune(v.String())))
+		case v.Kind() == reflect.Slice, v.Kind() == reflect.Array, v.Kind() == reflect.Map:
+			n = int64(v.Len())
+		default:
+			return false
+		}
+		if rule == "min" {
+			return n >= limit
+		}
+		return n <= limit
+	default:
+		return false
+	}
+}
+
+// isAmount reports whether s is a positive decimal. ParseFloat alone would
+// also accept "NaN", "Inf", "-1" and "1e9".
+func isAmount(s string) bool {
+	if !amountPattern.MatchString(s) {
+		return false
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+	if len(whole) > maxAmountDigits || len(frac) > maxAmountDecimals {
+		return false
+	}
+	return strings.Trim(whole+frac, "0") != ""
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// fieldName returns the name clients use for a field: its json, form or path
+// key, or the Go name
+func fieldName(sf reflect.StructField) string {
+	for _, key := range []string{"json", "form", "path"} {
+		if tag, ok := sf.Tag.Lookup(key); ok {
+			name, _, _ := strings.Cut(tag, ",")
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+	}
+	return sf.Name
+}
@@ -0,0 +1,227 @@
+package validate
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"wata-bot-BE/internal/address"
+	"wata-bot-BE/internal/model"
+)
+
+type trade struct {
+	Side   string `json:"side" validate:"required"`
+	Amount string `json:"amount" validate:"amount"`
+}
+
+type request struct {
+	Address  address.Address `json:"address" validate:"address"`
+	Referrer string          `json:"referrer,optional" validate:"omitempty,address"`
+	Currency string          `json:"currency" validate:"currency"`
+	Amount   string          `json:"amount" validate:"amount"`
+	Days     int             `json:"duration_days" validate:"duration"`
+	BotId    string          `path:"id" validate:"botid"`
+	Note     string          `json:"note,optional" validate:"max=5"`
+	Limit    int             `form:"limit,optional" validate:"omitempty,min=1,max=100"`
+	Trades   []trade         `json:"trades" validate:"max=2"`
+}
+
+func validRequest() request {
+	return request{
+		Address:  "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		Currency: "USDT",
+		Amount:   "10.5",
+		Days:     30,
+		BotId:    "alpha_1",
+		Trades:   []trade{{Side: "buy", Amount: "1"}},
+	}
+}
+
+func TestStruct(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(r *request)
+		wantCode string
+		want     []model.FieldError // Field, Rule and Param only
+	}{
+		{name: "valid", modify: func(r *request) {}},
+		{name: "invalid address", modify: func(r *request) { r.Address = "0x123" },
+			wantCode: model.ErrCodeInvalidAddressFormat, want: []model.FieldError{{Field: "address", Rule: "address"}}},
+		{name: "empty optional address", modify: func(r *request) { r.Referrer = "" }},
+		{name: "invalid optional address", modify: func(r *request) { r.Referrer = "nope" },
+			wantCode: model.ErrCodeInvalidAddressFormat, want: []model.FieldError{{Field: "referrer", Rule: "address"}}},
+		{name: "unknown currency", modify: func(r *request) { r.Currency = "btc" },
+			wantCode: model.ErrCodeInvalidCurrency, want: []model.FieldError{{Field: "currency", Rule: "currency"}}},
+		{name: "negative amount", modify: func(r *request) { r.Amount = "-1" },
+			wantCode: model.ErrCodeInvalidAmount, want: []model.FieldError{{Field: "amount", Rule: "amount"}}},
+		{name: "duration too long", modify: func(r *request) { r.Days = MaxDurationDays + 1 },
+			wantCode: model.ErrCodeInvalidRequest, want: []model.FieldError{{Field: "duration_days", Rule: "duration"}}},
+		{name: "zero duration", modify: func(r *request) { r.Days = 0 },
+			wantCode: model.ErrCodeInvalidRequest, want: []model.FieldError{{Field: "duration_days", Rule: "duration"}}},
+		{name: "bot id with slash", modify: func(r *request) { r.BotId = "a/b" },
+			wantCode: model.ErrCodeInvalidRequest, want: []model.FieldError{{Field: "id", Rule: "botid"}}},
+		{name: "string over max counts runes", modify: func(r *request) { r.Note = "héllo" }},
+		{name: "string over max", modify: func(r *request) { r.Note = "hello!" },
+			wantCode: model.ErrCodeInvalidRequest, want: []model.FieldError{{Field: "note", Rule: "max", Param: "5"}}},
+		{name: "number under min", modify: func(r *request) { r.Limit = -1 },
+			wantCode: model.ErrCodeInvalidRequest, want: []model.FieldError{{Field: "limit", Rule: "min", Param: "1"}}},
+		{name: "number over max", modify: func(r *request) { r.Limit = 101 },
+			wantCode: model.ErrCodeInvalidRequest, want: []model.FieldError{{Field: "limit", Rule: "max", Param: "100"}}},
+		{name: "too many items", modify: func(r *request) { r.Trades = append(r.Trades, r.Trades[0], r.Trades[0]) },
+			wantCode: model.ErrCodeInvalidRequest, want: []model.FieldError{{Field: "trades", Rule: "max", Param: "2"}}},
+		{name: "nested field", modify: func(r *request) { r.Trades[0].Side = " " },
+			wantCode: model.ErrCodeInvalidRequest, want: []model.FieldError{{Field: "trades[0].side", Rule: "required"}}},
+		{name: "several fields", modify: func(r *request) { r.Currency = ""; r.Amount = "1e9" },
+			wantCode: model.ErrCodeInvalidRequest, want: []model.FieldError{{Field: "currency", Rule: "currency"}, {Field: "amount", Rule: "amount"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validRequest()
+			tt.modify(&req)
+			err := Struct(&req)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Struct() = %v, want nil", err)
+				}
+				return
+			}
+
+			var apiErr *model.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Struct() = %v, want an *model.APIError", err)
+			}
+			if apiErr.Code != tt.wantCode {
+				t.Errorf("code = %s, want %s", apiErr.Code, tt.wantCode)
+			}
+			var got []model.FieldError
+			for _, f := range apiErr.Fields {
+				if f.Message == "" {
+					t.Errorf("field %s has no message", f.Field)
+				}
+				got = append(got, model.FieldError{Field: f.Field, Rule: f.Rule, Param: f.Param})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fields = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStructNormalizesAddresses(t *testing.T) {
+	req := validRequest()
+	req.Referrer = "0X52908400098527886E0F7030069857D2E4169EE7"
+	if err := Struct(&req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Address != "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed" {
+		t.Errorf("Address = %s, want it checksummed", req.Address)
+	}
+	if req.Referrer != "0x52908400098527886E0F7030069857D2E4169EE7" {
+		t.Errorf("Referrer = %s, want it checksummed", req.Referrer)
+	}
+}
+
+func TestCheckTag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		wantErr bool
+	}{
+		{tag: "required"},
+		{tag: "omitempty,min=1,max=100"},
+		{tag: "min=-5"},
+		{tag: "adress", wantErr: true},
+		{tag: "max=ten", wantErr: true},
+		{tag: "min", wantErr: true},
+		{tag: "required,", wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := CheckTag(tt.tag); (err != nil) != tt.wantErr {
+			t.Errorf("CheckTag(%q) error = %v, want error %v", tt.tag, err, tt.wantErr)
+		}
+	}
+}
+
+func TestStructBadTag(t *testing.T) {
+	type page struct {
+		Limit int `form:"limit" validate:"max=ten"`
+	}
+	type badRequest struct {
+		Address address.Address `json:"address" validate:"address"`
+		Pages   []page          `json:"pages"`
+	}
+
+	err := Struct(&badRequest{Address: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", Pages: []page{{Limit: 5}}})
+	var apiErr *model.APIError
+	if err == nil || errors.As(err, &apiErr) {
+		t.Fatalf("Struct() error = %v, want the tag error", err)
+	}
+	if err2 := Struct(&badRequest{}); err2 == nil || err2.Error() != err.Error() {
+		t.Errorf("Struct() error = %v on the second call, want %v", err2, err)
+	}
+}
+
+// TestRequestTags checks the validate tags of every request type, so that a bad
+// tag fails here rather than on the first request using it
+func TestRequestTags(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "../types/types.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checked := 0
+	ast.Inspect(file, func(n ast.Node) bool {
+		field, ok := n.(*ast.Field)
+		if !ok || field.Tag == nil {
+			return true
+		}
+		tag, err := strconv.Unquote(field.Tag.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rules := reflect.StructTag(tag).Get(Tag); rules != "" {
+			checked++
+			if err := CheckTag(rules); err != nil {
+				t.Errorf("field %s: %v", field.Names[0].Name, err)
+			}
+		}
+		return true
+	})
+	if checked == 0 {
+		t.Fatal("no validate tags found in types.go")
+	}
+}
+
+func TestIsAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"1", true},
+		{"10.5", true},
+		{"0.000000000000000001", true},
+		{"000123.4500", true},
+		{"0", false},
+		{"0.000", false},
+		{"", false},
+		{"-1", false},
+		{"+1", false},
+		{"1.", false},
+		{".5", false},
+		{"1e9", false},
+		{"NaN", false},
+		{"Inf", false},
+		{" 1", false},
+		{"1,5", false},
+		{"0.0000000000000000001", false}, // 19 decimals
+		{"1000000000000000000000000000000", false}, // 31 digits
+		{"100000000000000000000000000000.5", true}, // 30 digits
+	}
+	for _, tt := range tests {
+		if got := isAmount(tt.in); got != tt.want {
+			t.Errorf("isAmount(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Package webhook signs and sends partner webhook requests
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// TimestampHeader is the unix time (seconds) the request was signed at
+	TimestampHeader = "X-Webhook-Timestamp"
+	// SignatureHeader is "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+	SignatureHeader = "X-Webhook-Signature"
+	// EventHeader is the domain event type
+	EventHeader = "X-Webhook-Event"
+	// DeliveryHeader identifies the delivery; it is the same across retries
+	// and replays so receivers can drop duplicates
+	DeliveryHeader = "X-Webhook-Delivery"
+
+	// maxResponseBody bounds how much of a response is kept in the delivery log
+	maxResponseBody = 1024
+)
+
+// Payload is the JSON body POSTed to partners
+type Payload struct {
+	Id        int64       `json:"id"` // Domain event id
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// Result is the outcome of one delivery attempt
+type Result struct {
+	StatusCode int
+	Response   string
+	Duration   time.Duration
+}
+
+// OK reports whether the partner accepted the delivery
+func (r Result) OK() bool {
+	return r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Sign computes the signature sent in SignatureHeader
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret generates a signing secret for a new endpoint
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sender POSTs signed payloads
+type Sender struct {
+	client *http.Client
+}
+
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Send signs body with secret at the current time and POSTs it to url. A
+// non-nil error means no response was received.
+func (s *Sender) Send(ctx context.Context, url, secret string, deliveryId int64, eventType string, body []byte) (Result, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+	req.Header.Set(EventHeader, eventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(deliveryId, 10))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Result{Duration: time.Since(start)}, err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	return Result{
+		StatusCode: resp.StatusCode,
+		Response:   string(respBody),
+		Duration:   time.Since(start),
+	}, nil
+}
@@ -0,0 +1,97 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	// Computed with: printf '1700000000.{"id":1}' | openssl dgst -sha256 -hmac whsec_test
+	want := "sha256=2f441ba4b3b2d50d28a9ab9d9fd8880376ecd1eb5d0435401553f5d8d0a5dcf8"
+	if got := Sign("whsec_test", "1700000000", []byte(`{"id":1}`)); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+	if got := Sign("whsec_other", "1700000000", []byte(`{"id":1}`)); got == want {
+		t.Error("Sign() with another secret gave the same signature")
+	}
+	if got := Sign("whsec_test", "1700000001", []byte(`{"id":1}`)); got == want {
+		t.Error("Sign() at another timestamp gave the same signature")
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	a, err := NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewSecret()
+	if !strings.HasPrefix(a, "whsec_") || len(a) != len("whsec_")+64 {
+		t.Errorf("NewSecret() = %q, want whsec_ and 64 hex characters", a)
+	}
+	if a == b {
+		t.Error("NewSecret() returned the same secret twice")
+	}
+}
+
+func TestSenderSend(t *testing.T) {
+	body := []byte(`{"id":7,"type":"Subscribed"}`)
+
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		wantOK   bool
+		wantBody string
+	}{
+		{name: "accepted", status: http.StatusNoContent, wantOK: true},
+		{name: "rejected", status: http.StatusBadRequest, response: "bad signature", wantBody: "bad signature"},
+		{name: "long response truncated", status: http.StatusInternalServerError, response: strings.Repeat("x", 2*maxResponseBody), wantBody: strings.Repeat("x", maxResponseBody)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received *http.Request
+			var receivedBody []byte
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received = r
+				receivedBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.response)
+			}))
+			defer srv.Close()
+
+			result, err := NewSender(time.Second).Send(context.Background(), srv.URL, "whsec_test", 42, "Subscribed", body)
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if result.StatusCode != tt.status || result.OK() != tt.wantOK || result.Response != tt.wantBody {
+				t.Errorf("Send() = %d %v %q, want %d %v %q", result.StatusCode, result.OK(), result.Response, tt.status, tt.wantOK, tt.wantBody)
+			}
+
+			if string(receivedBody) != string(body) {
+				t.Errorf("received body = %s, want %s", receivedBody, body)
+			}
+			timestamp := received.Header.Get(TimestampHeader)
+			if got := received.Header.Get(SignatureHeader); got != Sign("whsec_test", timestamp, body) {
+				t.Errorf("%s = %s, does not verify against %s %s", SignatureHeader, got, TimestampHeader, timestamp)
+			}
+			if received.Header.Get(EventHeader) != "Subscribed" || received.Header.Get(DeliveryHeader) != "42" {
+				t.Errorf("event and delivery headers = %q %q, want Subscribed 42", received.Header.Get(EventHeader), received.Header.Get(DeliveryHeader))
+			}
+		})
+	}
+}
+
+func TestSenderSendUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	if _, err := NewSender(time.Second).Send(context.Background(), url, "whsec_test", 1, "Subscribed", []byte("{}")); err == nil {
+		t.Error("Send() to a closed server returned no error")
+	}
+}
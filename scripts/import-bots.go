@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -31,6 +32,8 @@ type BotJSON struct {
 	Author               string     `json:"author"`
 	Description          string     `json:"description"`
 	IsActive             bool       `json:"isActive"`
+	MaxAllocation        float64    `json:"maxAllocation"`  // Optional capacity cap, 0 for none
+	MaxSubscribers       int        `json:"maxSubscribers"` // Optional capacity cap, 0 for none
 	Metrics              BotMetricsJSON `json:"metrics"`
 }
 
@@ -65,6 +68,7 @@ func main() {
 	}
 
 	botModel := model.NewBotModel(sqlConn, cacheConf)
+	ctx := context.Background()
 
 	// Read JSON file
 	jsonFile := "docs/api.md"
@@ -127,13 +131,15 @@ func main() {
 			TradingPair:          botJSON.Metrics.TradingPair,
 			TotalTrades:          botJSON.Metrics.TotalTrades,
 			Pnl30d:               botJSON.Metrics.Pnl30d,
+			MaxAllocation:        botJSON.MaxAllocation,
+			MaxSubscribers:       botJSON.MaxSubscribers,
 		}
 
 		// Check if bot exists
-		_, err := botModel.FindOne(bot.Id)
+		_, err := botModel.FindOne(ctx, bot.Id)
 		if err == nil {
 			// Bot exists, update it
-			if err := botModel.Update(bot); err != nil {
+			if err := botModel.Update(ctx, bot); err != nil {
 				log.Printf("Failed to update bot %s (%s): %v", bot.Id, bot.Name, err)
 				errorCount++
 				continue
@@ -141,7 +147,7 @@ func main() {
 			fmt.Printf("Updated bot: %s (%s)\n", bot.Id, bot.Name)
 		} else if err == model.ErrNotFound {
 			// Bot doesn't exist, insert it
-			if _, err := botModel.Insert(bot); err != nil {
+			if _, err := botModel.Insert(ctx, bot); err != nil {
 				log.Printf("Failed to insert bot %s (%s): %v", bot.Id, bot.Name, err)
 				errorCount++
 				continue
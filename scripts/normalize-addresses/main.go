@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"wata-bot-BE/internal/address"
+	"wata-bot-BE/internal/config"
+	"wata-bot-BE/internal/model"
+
+	"github.com/joho/godotenv"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+// Rewrites every user address to its EIP-55 checksummed form and merges users
+// whose addresses differ only in case into the oldest of them: balances and
+// WATA rewards are added up and subscriptions, transactions and notifications
+// move to the kept user. Bots the kept user now holds positions in have their
+// subscriber counts recounted and duplicate open waitlist entries are
+// cancelled, keeping the oldest.
+// Usage: go run ./scripts/normalize-addresses [-f etc/wata-bot-api.yaml] [-dry-run]
+var (
+	configFile = flag.String("f", "etc/wata-bot-api.yaml", "the config file")
+	dryRun     = flag.Bool("dry-run", false, "print the changes without applying them")
+)
+
+func main() {
+	flag.Parse()
+
+	// Load .env file if exists
+	godotenv.Load()
+
+	// Load config
+	var c config.Config
+	conf.MustLoad(*configFile, &c)
+	c.LoadFromEnv()
+
+	// Connect to database
+	sqlConn := sqlx.NewMysql(c.Database.DataSource)
+	cacheConf := c.Cache
+	if len(cacheConf) == 0 {
+		cacheConf = make([]cache.NodeConf, 0)
+	}
+
+	userModel := model.NewUserModel(sqlConn, cacheConf)
+	ctx := context.Background()
+
+	users, err := userModel.FindAll(ctx)
+	if err != nil && err != model.ErrNotFound {
+		log.Fatalf("Failed to load users: %v", err)
+	}
+
+	// Group users by normalized address; users are ordered by id so the first
+	// of each group is the one kept
+	var order []address.Address
+	groups := make(map[address.Address][]*model.User)
+	invalidCount := 0
+	for _, user := range users {
+		addr, err := address.Parse(user.Address.String())
+		if err != nil {
+			log.Printf("Skipping user %d: invalid address %q", user.Id, user.Address)
+			invalidCount++
+			continue
+		}
+		if _, ok := groups[addr]; !ok {
+			order = append(order, addr)
+		}
+		groups[addr] = append(groups[addr], user)
+	}
+
+	normalized := 0
+	merged := 0
+	errorCount := 0
+	for _, addr := range order {
+		group := groups[addr]
+		keeper := group[0]
+
+		for _, dup := range group[1:] {
+			fmt.Printf("User %d (%s): merge into %d (%s)\n", dup.Id, dup.Address, keeper.Id, keeper.Address)
+			if *dryRun {
+				merged++
+				continue
+			}
+
+			into := *keeper
+			into.Address = addr
+			into.WataReward += dup.WataReward
+			into.WataBalance = addBalances(keeper.WataBalance, dup.WataBalance)
+			into.UsdtBalance = addBalances(keeper.UsdtBalance, dup.UsdtBalance)
+			if into.InviteCode == "" {
+				into.InviteCode = dup.InviteCode
+			}
+			if err := userModel.Merge(ctx, &into, dup); err != nil {
+				log.Printf("Failed to merge user %d into %d: %v", dup.Id, keeper.Id, err)
+				errorCount++
+				continue
+			}
+			*keeper = into
+			merged++
+		}
+
+		if keeper.Address == addr {
+			continue
+		}
+		fmt.Printf("User %d: %s -> %s\n", keeper.Id, keeper.Address, addr)
+		if !*dryRun {
+			if err := userModel.UpdateAddress(ctx, keeper, addr); err != nil {
+				log.Printf("Failed to normalize address of user %d: %v", keeper.Id, err)
+				errorCount++
+				continue
+			}
+		}
+		normalized++
+	}
+
+	fmt.Printf("\nNormalization completed: %d users checked, %d addresses normalized, %d duplicates merged, %d invalid, %d errors\n",
+		len(users), normalized, merged, invalidCount, errorCount)
+	if *dryRun {
+		fmt.Println("Dry run, no changes were written")
+	}
+}
+
+// addBalances adds two stored balances, formatted like the transaction logic
+// with up to 8 decimal places; unparsable balances count as 0
+func addBalances(a, b string) string {
+	x, _ := strconv.ParseFloat(a, 64)
+	y, _ := strconv.ParseFloat(b, 64)
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.8f", x+y), "0"), ".")
+}
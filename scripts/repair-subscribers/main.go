@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"wata-bot-BE/internal/config"
+	"wata-bot-BE/internal/model"
+
+	"github.com/joho/godotenv"
+	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/stores/cache"
+	"github.com/zeromicro/go-zero/core/stores/sqlx"
+)
+
+// Recomputes every bot's subscriber count from its active subscriptions.
+// Usage: go run ./scripts/repair-subscribers [-f etc/wata-bot-api.yaml]
+var configFile = flag.String("f", "etc/wata-bot-api.yaml", "the config file")
+
+func main() {
+	flag.Parse()
+
+	// Load .env file if exists
+	godotenv.Load()
+
+	// Load config
+	var c config.Config
+	conf.MustLoad(*configFile, &c)
+	c.LoadFromEnv()
+
+	// Connect to database
+	sqlConn := sqlx.NewMysql(c.Database.DataSource)
+	cacheConf := c.Cache
+	if len(cacheConf) == 0 {
+		cacheConf = make([]cache.NodeConf, 0)
+	}
+
+	botModel := model.NewBotModel(sqlConn, cacheConf)
+	ctx := context.Background()
+
+	bots, err := botModel.FindAll(ctx)
+	if err != nil && err != model.ErrNotFound {
+		log.Fatalf("Failed to load bots: %v", err)
+	}
+
+	repaired := 0
+	errorCount := 0
+	for _, bot := range bots {
+		count, err := botModel.RecountSubscribers(ctx, bot.Id)
+		if err != nil {
+			log.Printf("Failed to recount subscribers for bot %s (%s): %v", bot.Id, bot.Name, err)
+			errorCount++
+			continue
+		}
+		if count != bot.Subscribers {
+			fmt.Printf("Bot %s (%s): %d -> %d\n", bot.Id, bot.Name, bot.Subscribers, count)
+			repaired++
+		}
+	}
+
+	fmt.Printf("\nRepair completed: %d bots checked, %d corrected, %d errors\n", len(bots), repaired, errorCount)
+}
@@ -0,0 +1,27 @@
+-- Migration: Add bot capacity limits and the bot_waitlist table
+-- max_allocation caps the total amount of active positions and max_subscribers
+-- caps the users holding one; 0 means unlimited. When a bot is full users can
+-- join its FIFO waitlist and are offered freed capacity for a limited time.
+
+ALTER TABLE `bot`
+ADD COLUMN `max_allocation` DECIMAL(30, 8) NOT NULL DEFAULT 0 COMMENT 'Max total amount of active positions (USDT), 0 for no cap' AFTER `pnl30d`,
+ADD COLUMN `max_subscribers` INT NOT NULL DEFAULT 0 COMMENT 'Max users with an active position, 0 for no cap' AFTER `max_allocation`;
+
+CREATE TABLE IF NOT EXISTS `bot_waitlist` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Waitlist entry ID, defines FIFO order',
+  `bot_id` VARCHAR(20) NOT NULL COMMENT 'Bot ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT 'User ID',
+  `amount` DECIMAL(30, 8) NOT NULL COMMENT 'Amount the user wants to invest (USDT)',
+  `duration_day` VARCHAR(20) NOT NULL COMMENT 'Requested duration day',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'waiting' COMMENT 'Status: waiting, offered, accepted, expired, cancelled',
+  `offered_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Offered time',
+  `offer_expires_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Reserved capacity is released after this time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_bot_status` (`bot_id`, `status`),
+  KEY `idx_user_status` (`user_id`, `status`),
+  KEY `idx_status_expires` (`status`, `offer_expires_at`),
+  CONSTRAINT `fk_waitlist_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_waitlist_bot` FOREIGN KEY (`bot_id`) REFERENCES `bot` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Bot waitlist table';
@@ -0,0 +1,18 @@
+-- Migration: Add bot_performance_snapshot table
+-- Stores daily PnL and trade stats pushed by the trading engine. The 30-day
+-- metrics on the bot row (roi30d, win_rate, pnl30d, total_trades) are derived from it.
+
+CREATE TABLE IF NOT EXISTS `bot_performance_snapshot` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Snapshot ID',
+  `bot_id` VARCHAR(20) NOT NULL COMMENT 'Bot ID',
+  `snapshot_date` DATE NOT NULL COMMENT 'Trading day',
+  `pnl` DECIMAL(20, 2) NOT NULL DEFAULT 0.00 COMMENT 'P&L for the day',
+  `roi_percent` DECIMAL(10, 4) NOT NULL DEFAULT 0.0000 COMMENT 'Return for the day in percent',
+  `trades` INT NOT NULL DEFAULT 0 COMMENT 'Trades closed during the day',
+  `winning_trades` INT NOT NULL DEFAULT 0 COMMENT 'Winning trades during the day',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_bot_date` (`bot_id`, `snapshot_date`),
+  CONSTRAINT `fk_performance_bot` FOREIGN KEY (`bot_id`) REFERENCES `bot` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Bot daily performance snapshot table';
@@ -0,0 +1,22 @@
+-- Migration: Add bot_trade table
+-- Stores individual trades pushed by the trading engine. Win rate and total trades
+-- on the bot row are derived from it once a bot has reported trades.
+
+CREATE TABLE IF NOT EXISTS `bot_trade` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Trade ID',
+  `bot_id` VARCHAR(20) NOT NULL COMMENT 'Bot ID',
+  `external_id` VARCHAR(64) NOT NULL COMMENT 'Trade ID assigned by the trading engine',
+  `pair` VARCHAR(20) NOT NULL COMMENT 'Trading pair',
+  `side` VARCHAR(10) NOT NULL COMMENT 'Side: long, short',
+  `entry_price` DECIMAL(30, 10) NOT NULL COMMENT 'Entry price',
+  `exit_price` DECIMAL(30, 10) NOT NULL COMMENT 'Exit price',
+  `size` DECIMAL(30, 10) NOT NULL COMMENT 'Position size',
+  `pnl` DECIMAL(20, 2) NOT NULL DEFAULT 0.00 COMMENT 'Realized P&L',
+  `opened_at` DATETIME NOT NULL COMMENT 'Opened time',
+  `closed_at` DATETIME NOT NULL COMMENT 'Closed time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_bot_external` (`bot_id`, `external_id`),
+  KEY `idx_bot_closed_at` (`bot_id`, `closed_at`),
+  CONSTRAINT `fk_trade_bot` FOREIGN KEY (`bot_id`) REFERENCES `bot` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Bot trade log table';
@@ -0,0 +1,49 @@
+-- Migration: Version bot terms and notify subscribers when they change
+-- Every change to a bot's subscriber-facing terms creates a new row in
+-- bot_version. Subscriptions keep the version they joined under and every
+-- user with an active position on an older version gets a bot_change_notice.
+
+ALTER TABLE `bot`
+ADD COLUMN `version` INT NOT NULL DEFAULT 1 COMMENT 'Current terms version' AFTER `max_subscribers`;
+
+ALTER TABLE `user_bot_subscription`
+ADD COLUMN `bot_version` INT NOT NULL DEFAULT 1 COMMENT 'Bot terms version the position was opened under' AFTER `bot_id`;
+
+CREATE TABLE IF NOT EXISTS `bot_version` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Version record ID',
+  `bot_id` VARCHAR(20) NOT NULL COMMENT 'Bot ID',
+  `version` INT NOT NULL COMMENT 'Terms version',
+  `risk_level` VARCHAR(50) NOT NULL COMMENT 'Risk level',
+  `duration_days` VARCHAR(200) NOT NULL COMMENT 'Offered durations in days',
+  `expected_return_percent` INT NOT NULL COMMENT 'Expected return percentage',
+  `apr_display` VARCHAR(100) NOT NULL COMMENT 'APR display text',
+  `min_investment` INT NOT NULL COMMENT 'Minimum investment',
+  `max_investment` INT NOT NULL COMMENT 'Maximum investment',
+  `lockup_period` VARCHAR(50) NOT NULL COMMENT 'Lockup period',
+  `expected_return` VARCHAR(50) NOT NULL COMMENT 'Expected return',
+  `trading_pair` VARCHAR(200) NOT NULL COMMENT 'Trading pair',
+  `changes` TEXT NOT NULL COMMENT 'JSON list of terms changed from the previous version',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_bot_version` (`bot_id`, `version`),
+  CONSTRAINT `fk_bot_version_bot` FOREIGN KEY (`bot_id`) REFERENCES `bot` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Bot terms version table';
+
+-- Existing bots start at version 1 with their current terms
+INSERT IGNORE INTO `bot_version` (`bot_id`, `version`, `risk_level`, `duration_days`, `expected_return_percent`, `apr_display`, `min_investment`, `max_investment`, `lockup_period`, `expected_return`, `trading_pair`, `changes`)
+SELECT `id`, `version`, `risk_level`, `duration_days`, `expected_return_percent`, `apr_display`, `min_investment`, `max_investment`, `lockup_period`, `expected_return`, `trading_pair`, '[]'
+FROM `bot`;
+
+CREATE TABLE IF NOT EXISTS `bot_change_notice` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Notice ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT 'User ID',
+  `bot_id` VARCHAR(20) NOT NULL COMMENT 'Bot ID',
+  `from_version` INT NOT NULL COMMENT 'Previous terms version',
+  `to_version` INT NOT NULL COMMENT 'New terms version',
+  `read_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Read time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_read` (`user_id`, `read_at`),
+  CONSTRAINT `fk_notice_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_notice_bot` FOREIGN KEY (`bot_id`) REFERENCES `bot` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Bot change notice table';
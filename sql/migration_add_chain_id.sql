@@ -0,0 +1,23 @@
+-- Migration: Add chain id to users and transactions
+-- Users and transactions created before multi-chain support were all on one
+-- network. Pass its EIP-155 chain id as @chain_id; it must be the default
+-- network, the first entry of Chains in the config:
+--
+--   mysql --init-command='SET @chain_id = 1' wata_bot < sql/migration_add_chain_id.sql
+--
+-- or, from a mysql session: SET @chain_id = 1; SOURCE sql/migration_add_chain_id.sql;
+-- The migration stops before changing anything when @chain_id is not set or 0.
+
+-- Fails with "Column 'chain_id' cannot be null" when @chain_id is not set or 0
+CREATE TEMPORARY TABLE `migration_chain_id` (`chain_id` BIGINT UNSIGNED NOT NULL);
+INSERT INTO `migration_chain_id` (`chain_id`) VALUES (NULLIF(@chain_id, 0));
+DROP TEMPORARY TABLE `migration_chain_id`;
+
+ALTER TABLE `user`
+ADD COLUMN `chain_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Network the user signed up on (EIP-155 chain id), the default for deposits and withdrawals' AFTER `language`;
+
+ALTER TABLE `transaction`
+ADD COLUMN `chain_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Network the funds moved on (EIP-155 chain id)' AFTER `user_id`;
+
+UPDATE `user` SET `chain_id` = @chain_id WHERE `chain_id` = 0;
+UPDATE `transaction` SET `chain_id` = @chain_id WHERE `chain_id` = 0;
@@ -0,0 +1,51 @@
+-- Migration: Add notification inbox, channel preferences and delivery queue
+-- Every notification is stored in the user's inbox. Users can opt into email,
+-- Telegram or webhook delivery per channel; each delivery is queued in
+-- notification_delivery and retried with backoff until it is sent or gives up.
+
+CREATE TABLE IF NOT EXISTS `notification` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Notification ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT 'User ID',
+  `event` VARCHAR(50) NOT NULL COMMENT 'Event: deposit_completed, withdraw_completed, subscription_settled, ...',
+  `title` VARCHAR(255) NOT NULL COMMENT 'Rendered title',
+  `body` TEXT NOT NULL COMMENT 'Rendered body',
+  `data` TEXT NOT NULL COMMENT 'JSON object with the template fields',
+  `read_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Read time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_read` (`user_id`, `read_at`),
+  CONSTRAINT `fk_notification_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Notification inbox table';
+
+CREATE TABLE IF NOT EXISTS `notification_preference` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Preference ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT 'User ID',
+  `channel` VARCHAR(20) NOT NULL COMMENT 'Channel: email, telegram, webhook',
+  `target` VARCHAR(500) NOT NULL DEFAULT '' COMMENT 'Email address, Telegram chat id or webhook URL',
+  `enabled` TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Send notifications over this channel',
+  `events` VARCHAR(500) NOT NULL DEFAULT '' COMMENT 'Comma separated events to send, empty for all',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_channel` (`user_id`, `channel`),
+  CONSTRAINT `fk_notification_preference_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Notification channel preference table';
+
+CREATE TABLE IF NOT EXISTS `notification_delivery` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Delivery ID',
+  `notification_id` BIGINT UNSIGNED NOT NULL COMMENT 'Notification ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT 'User ID',
+  `channel` VARCHAR(20) NOT NULL COMMENT 'Channel: email, telegram, webhook',
+  `target` VARCHAR(500) NOT NULL COMMENT 'Recipient at the time the notification was created',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT 'Status: pending, sent, failed',
+  `attempts` INT NOT NULL DEFAULT 0 COMMENT 'Attempts made',
+  `next_attempt_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Next attempt time',
+  `last_error` VARCHAR(500) NOT NULL DEFAULT '' COMMENT 'Error of the last failed attempt',
+  `sent_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Sent time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_status_next_attempt` (`status`, `next_attempt_at`),
+  KEY `idx_notification_id` (`notification_id`),
+  CONSTRAINT `fk_delivery_notification` FOREIGN KEY (`notification_id`) REFERENCES `notification` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Notification delivery queue table';
@@ -0,0 +1,26 @@
+-- Migration: Add the transactional outbox for domain events
+-- UserRegistered, DepositCompleted, WithdrawRequested, Subscribed and
+-- Unsubscribed events are written in the same transaction as the change they
+-- describe and delivered at least once by the outbox dispatcher. Notifications
+-- created by event handlers carry a dedupe_key so redelivery does not repeat them.
+
+CREATE TABLE IF NOT EXISTS `outbox_event` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Event ID, defines dispatch order',
+  `event_type` VARCHAR(50) NOT NULL COMMENT 'Event type: UserRegistered, DepositCompleted, WithdrawRequested, Subscribed, Unsubscribed',
+  `aggregate_id` VARCHAR(64) NOT NULL COMMENT 'ID of the user, transaction or subscription the event is about',
+  `payload` TEXT NOT NULL COMMENT 'JSON encoded event',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT 'Status: pending, dispatched, failed',
+  `attempts` INT NOT NULL DEFAULT 0 COMMENT 'Dispatch attempts made',
+  `next_attempt_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Next dispatch time',
+  `last_error` VARCHAR(1000) NOT NULL DEFAULT '' COMMENT 'Error of the last failed dispatch',
+  `dispatched_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Dispatched time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_status_next_attempt` (`status`, `next_attempt_at`),
+  KEY `idx_type_aggregate` (`event_type`, `aggregate_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Domain event outbox table';
+
+ALTER TABLE `notification`
+ADD COLUMN `dedupe_key` VARCHAR(100) NULL DEFAULT NULL COMMENT 'Set when the notification must only be created once' AFTER `data`,
+ADD UNIQUE KEY `uk_dedupe_key` (`dedupe_key`);
@@ -0,0 +1,10 @@
+-- Migration: Add amount and started_at columns to user_bot_subscription table
+-- Subscriptions carry the invested amount and the start of the position so each
+-- user's share of the bot's performance can be attributed.
+
+ALTER TABLE `user_bot_subscription`
+ADD COLUMN `amount` DECIMAL(30, 8) NOT NULL DEFAULT 0 COMMENT 'Invested amount (USDT)' AFTER `duration_day`,
+ADD COLUMN `started_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Position start time' AFTER `amount`;
+
+-- Existing subscriptions started when they were created
+UPDATE `user_bot_subscription` SET `started_at` = `created_at`;
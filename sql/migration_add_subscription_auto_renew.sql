@@ -0,0 +1,11 @@
+-- Migration: Add auto-renew settings to user_bot_subscription table
+-- When auto_renew is set, the settlement process rolls a matured position into a
+-- new term in the same transaction as settling it. renew_mode 'compound' rolls
+-- the settled value, 'payout' rolls the principal only.
+
+ALTER TABLE `user_bot_subscription`
+ADD COLUMN `auto_renew` TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Renew into a new term at settlement' AFTER `settled_at`,
+ADD COLUMN `renew_duration_day` VARCHAR(20) NOT NULL DEFAULT '' COMMENT 'Duration of the next term, empty for the same duration' AFTER `auto_renew`,
+ADD COLUMN `renew_mode` VARCHAR(20) NOT NULL DEFAULT 'compound' COMMENT 'Renew mode: compound, payout' AFTER `renew_duration_day`,
+ADD COLUMN `renewed_from_id` BIGINT UNSIGNED NULL DEFAULT NULL COMMENT 'Subscription this one renewed' AFTER `renew_mode`,
+ADD KEY `idx_renewed_from_id` (`renewed_from_id`);
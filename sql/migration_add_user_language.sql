@@ -0,0 +1,6 @@
+-- Migration: Add preferred notification language to user table
+-- Notifications are rendered in this language; an empty value falls back to
+-- the default language (en). API error messages follow Accept-Language.
+
+ALTER TABLE `user`
+ADD COLUMN `language` VARCHAR(10) NOT NULL DEFAULT '' COMMENT 'Preferred notification language (en, vi), empty for the default (en)' AFTER `role`;
@@ -0,0 +1,49 @@
+-- Migration: Add outbound partner webhooks
+-- Partners register endpoints that subscribe to domain event types. Every
+-- matching event dispatched from the outbox becomes a webhook_delivery, sent
+-- as a signed POST and retried with exponential backoff; each attempt is kept
+-- in webhook_delivery_attempt as the delivery log.
+
+CREATE TABLE IF NOT EXISTS `webhook_endpoint` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Endpoint ID',
+  `url` VARCHAR(500) NOT NULL COMMENT 'URL deliveries are POSTed to',
+  `secret` VARCHAR(100) NOT NULL COMMENT 'HMAC-SHA256 signing secret',
+  `events` VARCHAR(500) NOT NULL DEFAULT '' COMMENT 'Comma separated event types, empty for all',
+  `description` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Partner or purpose of the endpoint',
+  `active` TINYINT(1) NOT NULL DEFAULT 1 COMMENT 'Inactive endpoints receive no new deliveries',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Partner webhook endpoint table';
+
+CREATE TABLE IF NOT EXISTS `webhook_delivery` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Delivery ID, sent in X-Webhook-Delivery',
+  `endpoint_id` BIGINT UNSIGNED NOT NULL COMMENT 'Endpoint ID',
+  `event_id` BIGINT UNSIGNED NOT NULL COMMENT 'Outbox event ID',
+  `event_type` VARCHAR(50) NOT NULL COMMENT 'Event type',
+  `payload` TEXT NOT NULL COMMENT 'JSON body that is signed and sent',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT 'Status: pending, delivered, failed',
+  `attempts` INT NOT NULL DEFAULT 0 COMMENT 'Attempts made since the delivery was created or replayed',
+  `next_attempt_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Next attempt time',
+  `last_status_code` INT NOT NULL DEFAULT 0 COMMENT 'HTTP status of the last attempt, 0 when no response',
+  `last_error` VARCHAR(500) NOT NULL DEFAULT '' COMMENT 'Error of the last failed attempt',
+  `delivered_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Delivered time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_endpoint_event` (`endpoint_id`, `event_id`),
+  KEY `idx_status_next_attempt` (`status`, `next_attempt_at`),
+  CONSTRAINT `fk_webhook_delivery_endpoint` FOREIGN KEY (`endpoint_id`) REFERENCES `webhook_endpoint` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Partner webhook delivery table';
+
+CREATE TABLE IF NOT EXISTS `webhook_delivery_attempt` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Attempt ID',
+  `delivery_id` BIGINT UNSIGNED NOT NULL COMMENT 'Delivery ID',
+  `status_code` INT NOT NULL DEFAULT 0 COMMENT 'HTTP status, 0 when no response was received',
+  `error` VARCHAR(500) NOT NULL DEFAULT '' COMMENT 'Transport error or start of the response body',
+  `duration_ms` INT NOT NULL DEFAULT 0 COMMENT 'Request duration in milliseconds',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Attempt time',
+  PRIMARY KEY (`id`),
+  KEY `idx_delivery_id` (`delivery_id`),
+  CONSTRAINT `fk_webhook_attempt_delivery` FOREIGN KEY (`delivery_id`) REFERENCES `webhook_delivery` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Partner webhook delivery attempt log';
@@ -0,0 +1,22 @@
+-- Migration: Normalize user addresses to their EIP-55 checksummed form
+-- The checksum needs keccak256, which MySQL lacks, so the rewrite and the
+-- merge of users whose addresses differ only in case are done by
+-- scripts/normalize-addresses:
+--
+--   go run ./scripts/normalize-addresses -f etc/wata-bot-api.yaml -dry-run
+--   go run ./scripts/normalize-addresses -f etc/wata-bot-api.yaml
+--
+-- The queries below list what the script will change.
+
+USE `wata_bot`;
+
+-- Addresses with surrounding spaces or in a single case, most of which are not
+-- checksummed yet
+SELECT `id`, `address` FROM `user`
+WHERE `address` <> TRIM(`address`) OR BINARY `address` = BINARY LOWER(`address`) OR BINARY `address` = BINARY UPPER(`address`);
+
+-- Users sharing a wallet, merged into the oldest
+SELECT LOWER(TRIM(`address`)) AS `address`, GROUP_CONCAT(`id` ORDER BY `id`) AS `user_ids`
+FROM `user`
+GROUP BY LOWER(TRIM(`address`))
+HAVING COUNT(*) > 1;
@@ -0,0 +1,15 @@
+-- Migration: Add lifecycle status to user_bot_subscription table
+-- Subscriptions are no longer deleted on unsubscribe. Each row is a position
+-- that moves active -> cancelled, or active -> matured -> settled, so a user can
+-- hold several positions in the same bot and keep their history.
+
+ALTER TABLE `user_bot_subscription`
+DROP INDEX `idx_user_bot`,
+ADD COLUMN `status` VARCHAR(20) NOT NULL DEFAULT 'active' COMMENT 'Status: active, cancelled, matured, settled' AFTER `started_at`,
+ADD COLUMN `final_value` DECIMAL(30, 8) NULL COMMENT 'Position value fixed at settlement (USDT)' AFTER `status`,
+ADD COLUMN `cancelled_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Cancelled time' AFTER `final_value`,
+ADD COLUMN `matured_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Matured time' AFTER `cancelled_at`,
+ADD COLUMN `settled_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Settled time' AFTER `matured_at`,
+ADD KEY `idx_user_bot_status` (`user_id`, `bot_id`, `status`),
+ADD KEY `idx_bot_status` (`bot_id`, `status`),
+ADD KEY `idx_status` (`status`);
@@ -11,6 +11,8 @@ CREATE TABLE IF NOT EXISTS `user` (
   `invite_code` VARCHAR(42) DEFAULT NULL COMMENT 'Invite code used',
   `wata_reward` INT NOT NULL DEFAULT 0 COMMENT 'WATA reward points',
   `role` VARCHAR(20) NOT NULL DEFAULT 'user' COMMENT 'User role',
+  `language` VARCHAR(10) NOT NULL DEFAULT '' COMMENT 'Preferred notification language (en, vi), empty for the default (en)',
+  `chain_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Network the user signed up on (EIP-155 chain id), the default for deposits and withdrawals',
   `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
   `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
   PRIMARY KEY (`id`),
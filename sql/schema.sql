@@ -13,6 +13,8 @@ CREATE TABLE IF NOT EXISTS `user` (
   `wata_balance` VARCHAR(50) NOT NULL DEFAULT '0' COMMENT 'WATA balance',
   `usdt_balance` VARCHAR(50) NOT NULL DEFAULT '0' COMMENT 'USDT balance',
   `role` VARCHAR(20) NOT NULL DEFAULT 'user' COMMENT 'User role',
+  `language` VARCHAR(10) NOT NULL DEFAULT '' COMMENT 'Preferred notification language (en, vi), empty for the default (en)',
+  `chain_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Network the user signed up on (EIP-155 chain id), the default for deposits and withdrawals',
   `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
   `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
   PRIMARY KEY (`id`),
@@ -45,6 +47,9 @@ CREATE TABLE IF NOT EXISTS `bot` (
   `trading_pair` VARCHAR(200) NOT NULL COMMENT 'Trading pair',
   `total_trades` INT NOT NULL DEFAULT 0 COMMENT 'Total trades',
   `pnl30d` DECIMAL(20, 2) NOT NULL DEFAULT 0.00 COMMENT 'P&L 30 days',
+  `max_allocation` DECIMAL(30, 8) NOT NULL DEFAULT 0 COMMENT 'Max total amount of active positions (USDT), 0 for no cap',
+  `max_subscribers` INT NOT NULL DEFAULT 0 COMMENT 'Max users with an active position, 0 for no cap',
+  `version` INT NOT NULL DEFAULT 1 COMMENT 'Current terms version',
   `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
   `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
   PRIMARY KEY (`id`),
@@ -57,13 +62,28 @@ CREATE TABLE IF NOT EXISTS `user_bot_subscription` (
   `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Subscription ID',
   `user_id` BIGINT UNSIGNED NOT NULL COMMENT 'User ID',
   `bot_id` VARCHAR(20) NOT NULL COMMENT 'Bot ID',
+  `bot_version` INT NOT NULL DEFAULT 1 COMMENT 'Bot terms version the position was opened under',
   `duration_day` VARCHAR(20) NOT NULL COMMENT 'Selected duration day from API',
+  `amount` DECIMAL(30, 8) NOT NULL DEFAULT 0 COMMENT 'Invested amount (USDT)',
+  `started_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Position start time',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'active' COMMENT 'Status: active, cancelled, matured, settled',
+  `final_value` DECIMAL(30, 8) NULL COMMENT 'Position value fixed at settlement (USDT)',
+  `cancelled_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Cancelled time',
+  `matured_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Matured time',
+  `settled_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Settled time',
+  `auto_renew` TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Renew into a new term at settlement',
+  `renew_duration_day` VARCHAR(20) NOT NULL DEFAULT '' COMMENT 'Duration of the next term, empty for the same duration',
+  `renew_mode` VARCHAR(20) NOT NULL DEFAULT 'compound' COMMENT 'Renew mode: compound, payout',
+  `renewed_from_id` BIGINT UNSIGNED NULL DEFAULT NULL COMMENT 'Subscription this one renewed',
   `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
   `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
   PRIMARY KEY (`id`),
-  UNIQUE KEY `idx_user_bot` (`user_id`, `bot_id`),
   KEY `idx_user_id` (`user_id`),
   KEY `idx_bot_id` (`bot_id`),
+  KEY `idx_user_bot_status` (`user_id`, `bot_id`, `status`),
+  KEY `idx_bot_status` (`bot_id`, `status`),
+  KEY `idx_status` (`status`),
+  KEY `idx_renewed_from_id` (`renewed_from_id`),
   CONSTRAINT `fk_subscription_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE,
   CONSTRAINT `fk_subscription_bot` FOREIGN KEY (`bot_id`) REFERENCES `bot` (`id`) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='User bot subscription table';
@@ -72,7 +92,8 @@ CREATE TABLE IF NOT EXISTS `user_bot_subscription` (
 CREATE TABLE IF NOT EXISTS `transaction` (
   `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Transaction ID',
   `user_id` BIGINT UNSIGNED NOT NULL COMMENT 'User ID',
-  `type` VARCHAR(20) NOT NULL COMMENT 'Transaction type: deposit, withdraw',
+  `chain_id` BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Network the funds moved on (EIP-155 chain id)',
+  `type` VARCHAR(20) NOT NULL COMMENT 'Transaction type: deposit, withdraw, subscribe, settle, refund',
   `currency` VARCHAR(10) NOT NULL COMMENT 'Currency: wata, usdt',
   `amount` VARCHAR(50) NOT NULL COMMENT 'Transaction amount',
   `balance_before` VARCHAR(50) NOT NULL COMMENT 'Balance before transaction',
@@ -88,3 +109,211 @@ CREATE TABLE IF NOT EXISTS `transaction` (
   CONSTRAINT `fk_transaction_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Transaction table';
 
+-- Create bot_performance_snapshot table
+CREATE TABLE IF NOT EXISTS `bot_performance_snapshot` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Snapshot ID',
+  `bot_id` VARCHAR(20) NOT NULL COMMENT 'Bot ID',
+  `snapshot_date` DATE NOT NULL COMMENT 'Trading day',
+  `pnl` DECIMAL(20, 2) NOT NULL DEFAULT 0.00 COMMENT 'P&L for the day',
+  `roi_percent` DECIMAL(10, 4) NOT NULL DEFAULT 0.0000 COMMENT 'Return for the day in percent',
+  `trades` INT NOT NULL DEFAULT 0 COMMENT 'Trades closed during the day',
+  `winning_trades` INT NOT NULL DEFAULT 0 COMMENT 'Winning trades during the day',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_bot_date` (`bot_id`, `snapshot_date`),
+  CONSTRAINT `fk_performance_bot` FOREIGN KEY (`bot_id`) REFERENCES `bot` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Bot daily performance snapshot table';
+
+-- Create bot_trade table
+CREATE TABLE IF NOT EXISTS `bot_trade` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Trade ID',
+  `bot_id` VARCHAR(20) NOT NULL COMMENT 'Bot ID',
+  `external_id` VARCHAR(64) NOT NULL COMMENT 'Trade ID assigned by the trading engine',
+  `pair` VARCHAR(20) NOT NULL COMMENT 'Trading pair',
+  `side` VARCHAR(10) NOT NULL COMMENT 'Side: long, short',
+  `entry_price` DECIMAL(30, 10) NOT NULL COMMENT 'Entry price',
+  `exit_price` DECIMAL(30, 10) NOT NULL COMMENT 'Exit price',
+  `size` DECIMAL(30, 10) NOT NULL COMMENT 'Position size',
+  `pnl` DECIMAL(20, 2) NOT NULL DEFAULT 0.00 COMMENT 'Realized P&L',
+  `opened_at` DATETIME NOT NULL COMMENT 'Opened time',
+  `closed_at` DATETIME NOT NULL COMMENT 'Closed time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_bot_external` (`bot_id`, `external_id`),
+  KEY `idx_bot_closed_at` (`bot_id`, `closed_at`),
+  CONSTRAINT `fk_trade_bot` FOREIGN KEY (`bot_id`) REFERENCES `bot` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Bot trade log table';
+
+-- Create bot_waitlist table
+CREATE TABLE IF NOT EXISTS `bot_waitlist` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Waitlist entry ID, defines FIFO order',
+  `bot_id` VARCHAR(20) NOT NULL COMMENT 'Bot ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT 'User ID',
+  `amount` DECIMAL(30, 8) NOT NULL COMMENT 'Amount the user wants to invest (USDT)',
+  `duration_day` VARCHAR(20) NOT NULL COMMENT 'Requested duration day',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'waiting' COMMENT 'Status: waiting, offered, accepted, expired, cancelled',
+  `offered_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Offered time',
+  `offer_expires_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Reserved capacity is released after this time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_bot_status` (`bot_id`, `status`),
+  KEY `idx_user_status` (`user_id`, `status`),
+  KEY `idx_status_expires` (`status`, `offer_expires_at`),
+  CONSTRAINT `fk_waitlist_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_waitlist_bot` FOREIGN KEY (`bot_id`) REFERENCES `bot` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Bot waitlist table';
+
+-- Create bot_version table
+CREATE TABLE IF NOT EXISTS `bot_version` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Version record ID',
+  `bot_id` VARCHAR(20) NOT NULL COMMENT 'Bot ID',
+  `version` INT NOT NULL COMMENT 'Terms version',
+  `risk_level` VARCHAR(50) NOT NULL COMMENT 'Risk level',
+  `duration_days` VARCHAR(200) NOT NULL COMMENT 'Offered durations in days',
+  `expected_return_percent` INT NOT NULL COMMENT 'Expected return percentage',
+  `apr_display` VARCHAR(100) NOT NULL COMMENT 'APR display text',
+  `min_investment` INT NOT NULL COMMENT 'Minimum investment',
+  `max_investment` INT NOT NULL COMMENT 'Maximum investment',
+  `lockup_period` VARCHAR(50) NOT NULL COMMENT 'Lockup period',
+  `expected_return` VARCHAR(50) NOT NULL COMMENT 'Expected return',
+  `trading_pair` VARCHAR(200) NOT NULL COMMENT 'Trading pair',
+  `changes` TEXT NOT NULL COMMENT 'JSON list of terms changed from the previous version',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_bot_version` (`bot_id`, `version`),
+  CONSTRAINT `fk_bot_version_bot` FOREIGN KEY (`bot_id`) REFERENCES `bot` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Bot terms version table';
+
+-- Create bot_change_notice table
+CREATE TABLE IF NOT EXISTS `bot_change_notice` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Notice ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT 'User ID',
+  `bot_id` VARCHAR(20) NOT NULL COMMENT 'Bot ID',
+  `from_version` INT NOT NULL COMMENT 'Previous terms version',
+  `to_version` INT NOT NULL COMMENT 'New terms version',
+  `read_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Read time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_read` (`user_id`, `read_at`),
+  CONSTRAINT `fk_notice_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_notice_bot` FOREIGN KEY (`bot_id`) REFERENCES `bot` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Bot change notice table';
+
+-- Create notification table
+CREATE TABLE IF NOT EXISTS `notification` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Notification ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT 'User ID',
+  `event` VARCHAR(50) NOT NULL COMMENT 'Event: deposit_completed, withdraw_completed, subscription_settled, ...',
+  `title` VARCHAR(255) NOT NULL COMMENT 'Rendered title',
+  `body` TEXT NOT NULL COMMENT 'Rendered body',
+  `data` TEXT NOT NULL COMMENT 'JSON object with the template fields',
+  `dedupe_key` VARCHAR(100) NULL DEFAULT NULL COMMENT 'Set when the notification must only be created once',
+  `read_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Read time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  PRIMARY KEY (`id`),
+  KEY `idx_user_read` (`user_id`, `read_at`),
+  UNIQUE KEY `uk_dedupe_key` (`dedupe_key`),
+  CONSTRAINT `fk_notification_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Notification inbox table';
+
+-- Create notification_preference table
+CREATE TABLE IF NOT EXISTS `notification_preference` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Preference ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT 'User ID',
+  `channel` VARCHAR(20) NOT NULL COMMENT 'Channel: email, telegram, webhook',
+  `target` VARCHAR(500) NOT NULL DEFAULT '' COMMENT 'Email address, Telegram chat id or webhook URL',
+  `enabled` TINYINT(1) NOT NULL DEFAULT 0 COMMENT 'Send notifications over this channel',
+  `events` VARCHAR(500) NOT NULL DEFAULT '' COMMENT 'Comma separated events to send, empty for all',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_channel` (`user_id`, `channel`),
+  CONSTRAINT `fk_notification_preference_user` FOREIGN KEY (`user_id`) REFERENCES `user` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Notification channel preference table';
+
+-- Create notification_delivery table
+CREATE TABLE IF NOT EXISTS `notification_delivery` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Delivery ID',
+  `notification_id` BIGINT UNSIGNED NOT NULL COMMENT 'Notification ID',
+  `user_id` BIGINT UNSIGNED NOT NULL COMMENT 'User ID',
+  `channel` VARCHAR(20) NOT NULL COMMENT 'Channel: email, telegram, webhook',
+  `target` VARCHAR(500) NOT NULL COMMENT 'Recipient at the time the notification was created',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT 'Status: pending, sent, failed',
+  `attempts` INT NOT NULL DEFAULT 0 COMMENT 'Attempts made',
+  `next_attempt_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Next attempt time',
+  `last_error` VARCHAR(500) NOT NULL DEFAULT '' COMMENT 'Error of the last failed attempt',
+  `sent_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Sent time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_status_next_attempt` (`status`, `next_attempt_at`),
+  KEY `idx_notification_id` (`notification_id`),
+  CONSTRAINT `fk_delivery_notification` FOREIGN KEY (`notification_id`) REFERENCES `notification` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Notification delivery queue table';
+
+-- Create outbox_event table
+CREATE TABLE IF NOT EXISTS `outbox_event` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Event ID, defines dispatch order',
+  `event_type` VARCHAR(50) NOT NULL COMMENT 'Event type: UserRegistered, DepositCompleted, WithdrawRequested, Subscribed, Unsubscribed',
+  `aggregate_id` VARCHAR(64) NOT NULL COMMENT 'ID of the user, transaction or subscription the event is about',
+  `payload` TEXT NOT NULL COMMENT 'JSON encoded event',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT 'Status: pending, dispatched, failed',
+  `attempts` INT NOT NULL DEFAULT 0 COMMENT 'Dispatch attempts made',
+  `next_attempt_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Next dispatch time',
+  `last_error` VARCHAR(1000) NOT NULL DEFAULT '' COMMENT 'Error of the last failed dispatch',
+  `dispatched_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Dispatched time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  KEY `idx_status_next_attempt` (`status`, `next_attempt_at`),
+  KEY `idx_type_aggregate` (`event_type`, `aggregate_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Domain event outbox table';
+
+-- Create webhook_endpoint table
+CREATE TABLE IF NOT EXISTS `webhook_endpoint` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Endpoint ID',
+  `url` VARCHAR(500) NOT NULL COMMENT 'URL deliveries are POSTed to',
+  `secret` VARCHAR(100) NOT NULL COMMENT 'HMAC-SHA256 signing secret',
+  `events` VARCHAR(500) NOT NULL DEFAULT '' COMMENT 'Comma separated event types, empty for all',
+  `description` VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Partner or purpose of the endpoint',
+  `active` TINYINT(1) NOT NULL DEFAULT 1 COMMENT 'Inactive endpoints receive no new deliveries',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Partner webhook endpoint table';
+
+-- Create webhook_delivery table
+CREATE TABLE IF NOT EXISTS `webhook_delivery` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Delivery ID, sent in X-Webhook-Delivery',
+  `endpoint_id` BIGINT UNSIGNED NOT NULL COMMENT 'Endpoint ID',
+  `event_id` BIGINT UNSIGNED NOT NULL COMMENT 'Outbox event ID',
+  `event_type` VARCHAR(50) NOT NULL COMMENT 'Event type',
+  `payload` TEXT NOT NULL COMMENT 'JSON body that is signed and sent',
+  `status` VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT 'Status: pending, delivered, failed',
+  `attempts` INT NOT NULL DEFAULT 0 COMMENT 'Attempts made since the delivery was created or replayed',
+  `next_attempt_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Next attempt time',
+  `last_status_code` INT NOT NULL DEFAULT 0 COMMENT 'HTTP status of the last attempt, 0 when no response',
+  `last_error` VARCHAR(500) NOT NULL DEFAULT '' COMMENT 'Error of the last failed attempt',
+  `delivered_at` TIMESTAMP NULL DEFAULT NULL COMMENT 'Delivered time',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Created time',
+  `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT 'Updated time',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_endpoint_event` (`endpoint_id`, `event_id`),
+  KEY `idx_status_next_attempt` (`status`, `next_attempt_at`),
+  CONSTRAINT `fk_webhook_delivery_endpoint` FOREIGN KEY (`endpoint_id`) REFERENCES `webhook_endpoint` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Partner webhook delivery table';
+
+-- Create webhook_delivery_attempt table
+CREATE TABLE IF NOT EXISTS `webhook_delivery_attempt` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT 'Attempt ID',
+  `delivery_id` BIGINT UNSIGNED NOT NULL COMMENT 'Delivery ID',
+  `status_code` INT NOT NULL DEFAULT 0 COMMENT 'HTTP status, 0 when no response was received',
+  `error` VARCHAR(500) NOT NULL DEFAULT '' COMMENT 'Transport error or start of the response body',
+  `duration_ms` INT NOT NULL DEFAULT 0 COMMENT 'Request duration in milliseconds',
+  `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT 'Attempt time',
+  PRIMARY KEY (`id`),
+  KEY `idx_delivery_id` (`delivery_id`),
+  CONSTRAINT `fk_webhook_attempt_delivery` FOREIGN KEY (`delivery_id`) REFERENCES `webhook_delivery` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='Partner webhook delivery attempt log';
@@ -1,21 +1,33 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"wata-bot-BE/internal/chain"
 	"wata-bot-BE/internal/config"
+	"wata-bot-BE/internal/errorlog"
 	"wata-bot-BE/internal/handler"
+	"wata-bot-BE/internal/i18n"
+	"wata-bot-BE/internal/logic"
 	"wata-bot-BE/internal/middleware"
+	"wata-bot-BE/internal/model"
+	"wata-bot-BE/internal/notify"
 	"wata-bot-BE/internal/svc"
 	"wata-bot-BE/internal/utils"
+	"wata-bot-BE/internal/validate"
 
 	"github.com/joho/godotenv"
 	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/proc"
+	"github.com/zeromicro/go-zero/core/threading"
 	"github.com/zeromicro/go-zero/rest"
+	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
 func contains(s, substr string) bool {
@@ -47,9 +59,19 @@ func main() {
 	var c config.Config
 	conf.MustLoad(*configFile, &c)
 
+	// Missing translations fall back to English; the i18n tests fail on them
+	if err := checkTranslations(); err != nil {
+		log.Printf("Warning: incomplete translations: %v", err)
+	}
+
 	// Override config with environment variables if they exist
 	c.LoadFromEnv()
 
+	// Deposits and withdrawals need at least one valid network
+	if err := chain.Validate(c.Chains); err != nil {
+		log.Fatalf("Invalid chain configuration: %v", err)
+	}
+
 	// Check database connection before starting server
 	maskedDSN := utils.MaskDataSource(c.Database.DataSource)
 	dbName := utils.ExtractDatabaseName(c.Database.DataSource)
@@ -66,28 +88,42 @@ func main() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Initialize error log
-	logPath := c.Log.Path
-	if logPath == "" {
-		logPath = "logs"
+	// Initialize error reporting (daily JSON files, and Sentry when configured)
+	if c.ErrorLog.Path == "" {
+		logPath := c.Log.Path
+		if logPath == "" {
+			logPath = "logs"
+		}
+		c.ErrorLog.Path = filepath.Join(logPath, "error")
 	}
-	if err := utils.InitErrorLog(logPath); err != nil {
+	if reporter, err := errorlog.New(c.ErrorLog); err != nil {
 		log.Printf("Failed to initialize error log: %v", err)
+	} else {
+		errorlog.Setup(reporter)
 	}
-	defer utils.CloseErrorLog()
-
-	server := rest.MustNewServer(c.RestConf)
+	defer errorlog.Close()
+
+	// Add CORS middleware (must be first so every response carries the
+	// headers); preflight requests are answered by its not-allowed handler,
+	// and unknown routes get a JSON error with CORS headers and a request id
+	corsMiddleware := middleware.NewCorsMiddleware(c.Cors)
+	requestIdMiddleware := middleware.NewRequestIdMiddleware()
+	languageMiddleware := middleware.NewLanguageMiddleware()
+	server := rest.MustNewServer(c.RestConf,
+		rest.WithNotAllowedHandler(corsMiddleware.NotAllowedHandler()),
+		rest.WithNotFoundHandler(corsMiddleware.Handle(requestIdMiddleware.Handle(languageMiddleware.Handle(handler.NotFoundHandler)))))
 	defer server.Stop()
-
-	// Add CORS middleware (must be first to handle preflight requests)
-	// Allow only http://localhost:3000 for development
-	corsMiddleware := middleware.NewCorsMiddlewareWithOrigins([]string{
-		"http://localhost:3000",
-	})
 	server.Use(corsMiddleware.Handle)
 
-	// Add request/response logging middleware (log to terminal)
-	requestLogMiddleware := middleware.NewRequestLogMiddleware()
+	// Assign every request an id before anything logs it
+	server.Use(requestIdMiddleware.Handle)
+
+	// Translate error messages into the language of Accept-Language
+	server.Use(languageMiddleware.Handle)
+
+	// Add structured access logging; headers and redacted bodies are only
+	// logged at debug level
+	requestLogMiddleware := middleware.NewRequestLogMiddleware(c.RequestLog, c.Log.Level == "debug")
 	server.Use(requestLogMiddleware.Handle)
 
 	// Add error logging middleware
@@ -95,8 +131,78 @@ func main() {
 	server.Use(errorLogMiddleware.Handle)
 
 	ctx := svc.NewServiceContext(c)
+
+	// Limit every route per client IP; sign-in and balance routes have
+	// stricter policies of their own. Health probes are not limited, so that
+	// frequent polling never gets a replica restarted or taken out.
+	server.Use(middleware.SkipPaths(ctx.ApiRateLimit, "/livez", "/healthz", "/readyz"))
+
+	// Report not ready as soon as shutdown starts; requests keep being served
+	// for Shutdown.WrapUpTime so load balancers can take the replica out first
+	proc.AddWrapUpListener(ctx.Health.SetShuttingDown)
+
+	// Check the validate tags of every request parsed by the handlers and log
+	// the wallet address it acts on
+	httpx.SetValidator(middleware.LogAddressValidator{Validator: validate.Validator{}})
+
 	handler.RegisterHandlers(server, ctx)
+	logic.RegisterEventHandlers(ctx)
+
+	// Mature and settle subscriptions in the background
+	if c.Settlement.Enabled {
+		settlement := logic.NewSettlementLogic(context.Background(), ctx)
+		threading.GoSafe(func() {
+			settlement.Start(c.Settlement.Interval)
+		})
+		defer settlement.Stop()
+	}
+
+	// Deliver domain events from the outbox to handlers and sinks
+	if c.Outbox.Enabled {
+		outbox := logic.NewOutboxDispatchLogic(context.Background(), ctx)
+		threading.GoSafe(func() {
+			outbox.Start(c.Outbox.Interval)
+		})
+		defer outbox.Stop()
+	}
+
+	// Send queued notifications over email, Telegram and webhooks
+	if c.Notification.Enabled {
+		dispatcher := logic.NewNotificationDispatchLogic(context.Background(), ctx)
+		threading.GoSafe(func() {
+			dispatcher.Start(c.Notification.Interval)
+		})
+		defer dispatcher.Stop()
+	}
+
+	// Send signed domain events to partner webhook endpoints
+	if c.Webhooks.Enabled {
+		webhooks := logic.NewWebhookDispatchLogic(context.Background(), ctx)
+		threading.GoSafe(func() {
+			webhooks.Start(c.Webhooks.Interval)
+		})
+		defer webhooks.Stop()
+	}
+
+	// Receive real-time updates published by any replica
+	streamCtx, stopStream := context.WithCancel(context.Background())
+	threading.GoSafe(func() {
+		ctx.Stream.Run(streamCtx)
+	})
+	defer stopStream()
 
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
 	server.Start()
 }
+
+// checkTranslations reports the error catalog and notification template keys
+// that lack a translation
+func checkTranslations() error {
+	var keys []string
+	for _, spec := range model.ErrorSpecs() {
+		keys = append(keys, spec.Key)
+	}
+	keys = append(keys, notify.MessageKeys()...)
+	keys = append(keys, validate.MessageKeys()...)
+	return i18n.Check(keys)
+}